@@ -109,7 +109,8 @@ export function QueryHeader({
   };
 
   const datasetDropdownIsAvailable = () => {
-    if (dialect === 'influx') {
+    // InfluxDB SQL and SQLite have no datasets to choose from.
+    if (dialect === 'influx' || dialect === 'sqlite') {
       return false;
     }
     // If the feature flag is DISABLED, && the datasource is Postgres (`dialect = 'postgres`),
@@ -169,4 +169,4 @@ export interface MetaDefinition {
   kind: CompletionItemKind;
 }
 
-export type SQLDialect = 'postgres' | 'influx' | 'other';
+export type SQLDialect = 'postgres' | 'influx' | 'sqlite' | 'other';
@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	errMissingOutputFlag        = errors.New("missing output flag")
+	errMissingFolderUIDFlag     = errors.New("missing folder-uid flag")
+	errMissingDatasourceUIDFlag = errors.New("missing datasource-uid flag")
+	errMissingRuleFile          = errors.New("missing rule file")
+)
+
+// alertingExportCommand downloads the export of all alerting resources of the organization and extracts it
+// in the output directory.
+func alertingExportCommand(c utils.CommandLine) error {
+	output := c.String("output")
+	if output == "" {
+		return errMissingOutputFlag
+	}
+
+	query := url.Values{}
+	if c.Bool("decrypt") {
+		query.Set("decrypt", "true")
+	}
+	body, err := alertingRequest(c, http.MethodGet, "/api/v1/provisioning/export", query, "", nil)
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("failed to read the export: %w", err)
+	}
+	for _, f := range archive.File {
+		if err := extractFile(f, output); err != nil {
+			return err
+		}
+		logger.Infof("%s %s\n", color.GreenString("✔"), filepath.Join(output, f.Name))
+	}
+	return nil
+}
+
+// alertingImportCommand imports the Prometheus rule files as Grafana-managed alert rules.
+func alertingImportCommand(c utils.CommandLine) error {
+	folderUID := c.String("folder-uid")
+	if folderUID == "" {
+		return errMissingFolderUIDFlag
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errMissingDatasourceUIDFlag
+	}
+	if c.Args().Len() == 0 {
+		return errMissingRuleFile
+	}
+
+	query := url.Values{}
+	query.Set("datasource_uid", datasourceUID)
+	if c.Bool("dry-run") {
+		query.Set("dry_run", "true")
+	}
+	for _, file := range c.Args().Slice() {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the file is provided by the user running the command.
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		body, err := alertingRequest(c, http.MethodPost, "/api/ruler/grafana/api/v1/rules/"+url.PathEscape(folderUID)+"/import", query, "application/yaml", bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", file, err)
+		}
+		var result apimodels.ImportRuleGroupsResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("failed to read the response: %w", err)
+		}
+		logImportResult(file, result)
+	}
+	return nil
+}
+
+func logImportResult(file string, result apimodels.ImportRuleGroupsResponse) {
+	logger.Infof("%s: %s\n", file, result.Message)
+	for _, group := range result.Groups {
+		logger.Infof("group %s\n", group.Name)
+		for _, title := range group.Created {
+			logger.Infof("  %s %s\n", color.GreenString("+"), title)
+		}
+		for _, diff := range group.Updated {
+			logger.Infof("  %s %s\n", color.YellowString("~"), diff.Title)
+			if result.DryRun {
+				logger.Info(diff.Diff)
+			}
+		}
+		for _, title := range group.Deleted {
+			logger.Infof("  %s %s\n", color.RedString("-"), title)
+		}
+	}
+	for _, warning := range result.Warnings {
+		logger.Warnf("%s %s\n", color.YellowString("warning:"), warning)
+	}
+}
+
+// alertingRequest sends a request to the HTTP API of the Grafana server, and returns the body of the response.
+func alertingRequest(c utils.CommandLine, method, path string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.String("url"), "/") + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Grafana URL: %w", err)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user := c.String("user"); user != "" {
+		req.SetBasicAuth(user, c.String("password"))
+	}
+	if org := c.String("org-id"); org != "" {
+		req.Header.Set("X-Grafana-Org-Id", org)
+	}
+
+	res, err := services.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warnf("Failed to close response body: %s\n", err)
+		}
+	}()
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, &services.BadRequestError{Status: res.Status, Message: strings.TrimSpace(string(content))}
+	}
+	return content, nil
+}
+
+func extractFile(f *zip.File, output string) error {
+	path := filepath.Join(output, filepath.FromSlash(f.Name))
+	if !strings.HasPrefix(path, filepath.Clean(output)+string(os.PathSeparator)) {
+		return fmt.Errorf("file %q of the export is outside of the output directory", f.Name)
+	}
+	if f.FileInfo().IsDir() {
+		return os.MkdirAll(path, 0750)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is checked to be in the output directory above.
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	// nolint:gosec
+	// The size of the archive is bounded by the size of the alerting resources of the organization.
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+func TestAlertingExportCommand(t *testing.T) {
+	archive := func(t *testing.T, files map[string]string) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, content := range files {
+			f, err := w.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	t.Run("should extract the export in the output directory", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v1/provisioning/export", r.URL.Path)
+			require.Equal(t, "true", r.URL.Query().Get("decrypt"))
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write(archive(t, map[string]string{
+				"terraform/alert_rules.tf": "resource",
+				"prometheus/folder.yaml":   "groups: []",
+			}))
+		}))
+		t.Cleanup(srv.Close)
+
+		output := t.TempDir()
+		c, err := commandstest.NewCliContext(map[string]string{"url": srv.URL, "token": "token", "output": output, "decrypt": "true"})
+		require.NoError(t, err)
+
+		require.NoError(t, alertingExportCommand(c))
+
+		content, err := os.ReadFile(filepath.Join(output, "terraform", "alert_rules.tf"))
+		require.NoError(t, err)
+		require.Equal(t, "resource", string(content))
+		content, err = os.ReadFile(filepath.Join(output, "prometheus", "folder.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, "groups: []", string(content))
+	})
+
+	t.Run("should reject files outside of the output directory", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(archive(t, map[string]string{"../evil.tf": "resource"}))
+		}))
+		t.Cleanup(srv.Close)
+
+		c, err := commandstest.NewCliContext(map[string]string{"url": srv.URL, "output": t.TempDir()})
+		require.NoError(t, err)
+
+		require.ErrorContains(t, alertingExportCommand(c), "outside of the output directory")
+	})
+
+	t.Run("should return the error of the server", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"permission denied"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		c, err := commandstest.NewCliContext(map[string]string{"url": srv.URL, "output": t.TempDir()})
+		require.NoError(t, err)
+
+		require.ErrorContains(t, alertingExportCommand(c), "permission denied")
+	})
+}
+
+func TestAlertingImportCommand(t *testing.T) {
+	ruleFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(ruleFile, []byte("groups: []"), 0600))
+
+	t.Run("should post the rule file", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "/api/ruler/grafana/api/v1/rules/folder-uid/import", r.URL.Path)
+			require.Equal(t, "prom", r.URL.Query().Get("datasource_uid"))
+			require.Equal(t, "true", r.URL.Query().Get("dry_run"))
+			require.Equal(t, "application/yaml", r.Header.Get("Content-Type"))
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "admin", user)
+			require.Equal(t, "secret", password)
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, "groups: []", string(body))
+			_, _ = w.Write([]byte(`{"message": "rule groups were not imported because of dry run", "dryRun": true, "groups": [{"name": "group", "created": ["rule"]}]}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		flagSet := flag.NewFlagSet("Test", 0)
+		for name, value := range map[string]string{
+			"url":            srv.URL,
+			"user":           "admin",
+			"password":       "secret",
+			"folder-uid":     "folder-uid",
+			"datasource-uid": "prom",
+			"dry-run":        "true",
+		} {
+			flagSet.String(name, value, "")
+		}
+		require.NoError(t, flagSet.Parse([]string{ruleFile}))
+		c := &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+
+		require.NoError(t, alertingImportCommand(c))
+	})
+
+	t.Run("should require the data source", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"folder-uid": "folder-uid"})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, alertingImportCommand(c), errMissingDatasourceUIDFlag)
+	})
+}
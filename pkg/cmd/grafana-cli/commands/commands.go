@@ -226,6 +226,70 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var alertingFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "url",
+		Usage: "URL of the Grafana server",
+		Value: "http://localhost:3000",
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "Service account token used to authenticate",
+		EnvVars: []string{"GRAFANA_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:  "user",
+		Usage: "User used to authenticate when no token is set",
+	},
+	&cli.StringFlag{
+		Name:    "password",
+		Usage:   "Password of the user",
+		EnvVars: []string{"GRAFANA_PASSWORD"},
+	},
+	&cli.StringFlag{
+		Name:  "org-id",
+		Usage: "ID of the organization, defaults to the organization of the user",
+	},
+}
+
+var alertingCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "exports the alerting resources of the organization as Terraform and Prometheus rule files",
+		Action: runPluginCommand(alertingExportCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Directory the export is written to",
+				Value: "alerting-export",
+			},
+			&cli.BoolFlag{
+				Name:  "decrypt",
+				Usage: "Export the secure settings of contact points unencrypted",
+			},
+		}, alertingFlags...),
+	},
+	{
+		Name:   "import",
+		Usage:  "import <rule file...>. Imports Prometheus rule files as Grafana-managed alert rules",
+		Action: runPluginCommand(alertingImportCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "folder-uid",
+				Usage: "UID of the folder the rules are imported to",
+			},
+			&cli.StringFlag{
+				Name:  "datasource-uid",
+				Usage: "UID of the data source the imported rules query",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes without applying them",
+			},
+		}, alertingFlags...),
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -237,4 +301,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Manage alerting resources of a Grafana server",
+		Subcommands: alertingCommands,
+	},
 }
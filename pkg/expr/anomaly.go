@@ -0,0 +1,429 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AnomalyOutputLabel is the label added to the series returned by an anomaly command
+// when it returns more than one series for each input series.
+const AnomalyOutputLabel = "anomaly_output"
+
+const (
+	defaultAnomalySensitivity = 3.0
+
+	// the smoothing parameters of Holt-Winters for the level, trend and seasonal components
+	holtWintersAlpha = 0.5
+	holtWintersBeta  = 0.1
+	holtWintersGamma = 0.3
+
+	// madScale scales the median absolute deviation to be comparable to the standard deviation of normally distributed data
+	madScale = 1.4826
+)
+
+// AnomalyCommand is an expression that detects anomalies in time series without the ML plugin.
+// For each point it computes a baseline, and bands at Sensitivity deviations around it.
+// The anomaly score of a point is its distance from the baseline divided by the distance from the
+// baseline to the bands, so a score greater than 1 means that the point is outside of the bands.
+type AnomalyCommand struct {
+	RefID        string
+	ReferenceVar string
+	Method       AnomalyMethod
+	Sensitivity  float64
+	// Season is the length of a season. Baselines are seasonal when it is not zero.
+	Season time.Duration
+	Output AnomalyOutput
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, referenceVar string, method AnomalyMethod, sensitivity float64, season time.Duration, output AnomalyOutput) (*AnomalyCommand, error) {
+	switch method {
+	case AnomalyMethodZScore, AnomalyMethodMAD, AnomalyMethodHoltWinters:
+	default:
+		return nil, fmt.Errorf("expected anomaly method to be one of [%s, %s, %s], got %s", AnomalyMethodZScore, AnomalyMethodMAD, AnomalyMethodHoltWinters, method)
+	}
+	switch output {
+	case "":
+		output = AnomalyOutputScore
+	case AnomalyOutputScore, AnomalyOutputBands, AnomalyOutputAll:
+	default:
+		return nil, fmt.Errorf("expected anomaly output to be one of [%s, %s, %s], got %s", AnomalyOutputScore, AnomalyOutputBands, AnomalyOutputAll, output)
+	}
+	if sensitivity <= 0 {
+		return nil, fmt.Errorf("anomaly sensitivity must be greater than zero, got %v", sensitivity)
+	}
+	if season < 0 {
+		return nil, fmt.Errorf("anomaly season must not be negative, got %s", season)
+	}
+	return &AnomalyCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Method:       method,
+		Sensitivity:  sensitivity,
+		Season:       season,
+		Output:       output,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	return newAnomalyCommandFromQuery(rn.RefID, q)
+}
+
+func newAnomalyCommandFromQuery(refID string, q AnomalyQuery) (*AnomalyCommand, error) {
+	referenceVar, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	sensitivity := defaultAnomalySensitivity
+	if q.Sensitivity != nil {
+		sensitivity = *q.Sensitivity
+	}
+	var season time.Duration
+	if q.Season != "" {
+		season, err = gtime.ParseDuration(q.Season)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse anomaly season %q: %w", q.Season, err)
+		}
+	}
+	return NewAnomalyCommand(refID, referenceVar, q.Method, sensitivity, season, q.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+
+	refVarResult := vars[ac.ReferenceVar]
+	newRes := mathexp.Results{Values: make(mathexp.Values, 0, len(refVarResult.Values))}
+	for _, val := range refVarResult.Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			series, err := ac.detect(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, series...)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, mathexp.NewNoData())
+		default:
+			return newRes, fmt.Errorf("anomaly detection requires time series data, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// detect returns the series of the configured output for one input series.
+func (ac *AnomalyCommand) detect(s mathexp.Series) ([]mathexp.Value, error) {
+	sorted := mathexp.NewSeries(ac.RefID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		sorted.SetPoint(i, t, v)
+	}
+	sorted.SortByTime(false)
+
+	n := sorted.Len()
+	times := make([]time.Time, n)
+	values := make([]*float64, n)
+	for i := 0; i < n; i++ {
+		times[i], values[i] = sorted.GetPoint(i)
+		if values[i] != nil && (math.IsNaN(*values[i]) || math.IsInf(*values[i], 0)) {
+			values[i] = nil
+		}
+	}
+
+	positions, period, err := ac.seasonPositions(times)
+	if err != nil {
+		return nil, fmt.Errorf("series %s: %w", seriesName(s), err)
+	}
+
+	var baseline, width []*float64
+	switch ac.Method {
+	case AnomalyMethodHoltWinters:
+		baseline, width = holtWinters(values, positions, period, ac.Sensitivity)
+	default:
+		baseline, width = seasonalStats(values, positions, period, ac.Method, ac.Sensitivity)
+	}
+
+	outputs := map[string]func(i int) *float64{
+		"baseline": func(i int) *float64 { return baseline[i] },
+		"lower":    func(i int) *float64 { return offset(baseline[i], width[i], -1) },
+		"upper":    func(i int) *float64 { return offset(baseline[i], width[i], 1) },
+		"score":    func(i int) *float64 { return anomalyScore(values[i], baseline[i], width[i]) },
+	}
+	var names []string
+	switch ac.Output {
+	case AnomalyOutputScore:
+		names = []string{"score"}
+	case AnomalyOutputBands:
+		names = []string{"lower", "baseline", "upper"}
+	case AnomalyOutputAll:
+		names = []string{"score", "lower", "baseline", "upper"}
+	}
+
+	result := make([]mathexp.Value, 0, len(names))
+	for _, name := range names {
+		labels := s.GetLabels().Copy()
+		if len(names) > 1 {
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			labels[AnomalyOutputLabel] = name
+		}
+		out := mathexp.NewSeries(ac.RefID, labels, n)
+		for i := 0; i < n; i++ {
+			out.SetPoint(i, times[i], outputs[name](i))
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
+
+func seriesName(s mathexp.Series) string {
+	if labels := s.GetLabels(); len(labels) > 0 {
+		return labels.String()
+	}
+	return s.GetName()
+}
+
+// seasonPositions returns the position of each point in the series, counted in intervals of the series
+// from the first point, and the number of intervals in a season. The period is zero if the command is not seasonal.
+func (ac *AnomalyCommand) seasonPositions(times []time.Time) ([]int, int, error) {
+	positions := make([]int, len(times))
+	if len(times) < 2 {
+		return positions, 0, nil
+	}
+
+	intervals := make([]time.Duration, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	if len(intervals) == 0 {
+		return positions, 0, nil
+	}
+	slices.Sort(intervals)
+	interval := intervals[len(intervals)/2]
+	for i, t := range times {
+		positions[i] = int(math.Round(float64(t.Sub(times[0])) / float64(interval)))
+	}
+
+	if ac.Season == 0 {
+		return positions, 0, nil
+	}
+	period := int(math.Round(float64(ac.Season) / float64(interval)))
+	if period < 2 {
+		return nil, 0, fmt.Errorf("season %s must be at least two intervals of the series, which are %s", ac.Season, interval)
+	}
+	return positions, period, nil
+}
+
+// seasonalStats computes the baseline and the width of the bands of each point from the statistics of
+// all the points at the same position in the season, or of all the points if there is no season.
+// Positions in the season with too few points use the statistics of all the points.
+func seasonalStats(values []*float64, positions []int, period int, method AnomalyMethod, sensitivity float64) ([]*float64, []*float64) {
+	stats := func(vals []float64) (float64, float64) {
+		if method == AnomalyMethodMAD {
+			median := medianOf(vals)
+			deviations := make([]float64, len(vals))
+			for i, v := range vals {
+				deviations[i] = math.Abs(v - median)
+			}
+			return median, madScale * medianOf(deviations)
+		}
+		return meanAndStdDev(vals)
+	}
+
+	var all []float64
+	buckets := map[int][]float64{}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		all = append(all, *v)
+		if period > 0 {
+			buckets[positions[i]%period] = append(buckets[positions[i]%period], *v)
+		}
+	}
+
+	baseline := make([]*float64, len(values))
+	width := make([]*float64, len(values))
+	if len(all) < 2 {
+		return baseline, width
+	}
+	center, scale := stats(all)
+	bucketStats := map[int][2]float64{}
+	for b, vals := range buckets {
+		if len(vals) >= 2 {
+			c, s := stats(vals)
+			bucketStats[b] = [2]float64{c, s}
+		}
+	}
+	for i := range values {
+		c, s := center, scale
+		if bs, ok := bucketStats[positionInSeason(positions[i], period)]; ok {
+			c, s = bs[0], bs[1]
+		}
+		baseline[i] = &c
+		w := s * sensitivity
+		width[i] = &w
+	}
+	return baseline, width
+}
+
+func positionInSeason(position, period int) int {
+	if period == 0 {
+		return -1
+	}
+	return position % period
+}
+
+// holtWinters computes the baseline of each point as the forecast of additive Holt-Winters exponential smoothing
+// from the points before it. Without a season it is Holt's linear trend method. The width of the bands is
+// computed from the standard deviation of the difference between the points and their forecasts.
+// The first season is used to initialize the model, so its points have no baseline.
+func holtWinters(values []*float64, positions []int, period int, sensitivity float64) ([]*float64, []*float64) {
+	baseline := make([]*float64, len(values))
+	width := make([]*float64, len(values))
+
+	gamma := holtWintersGamma
+	if period == 0 {
+		// a single seasonal component that is always zero
+		period = 1
+		gamma = 0
+	}
+
+	seasonal := make([]float64, period)
+	var first []float64
+	start := len(values)
+	for i, v := range values {
+		if positions[i] >= period {
+			start = i
+			break
+		}
+		if v != nil {
+			first = append(first, *v)
+		}
+	}
+	if len(first) == 0 {
+		return baseline, width
+	}
+	level, _ := meanAndStdDev(first)
+	if gamma > 0 {
+		for i := 0; i < start; i++ {
+			if values[i] != nil {
+				seasonal[positions[i]%period] = *values[i] - level
+			}
+		}
+	}
+
+	trend := 0.0
+	last := positions[max(start-1, 0)]
+	var residuals []float64
+	for i := start; i < len(values); i++ {
+		// advance the model over the positions without points
+		for p := last + 1; p < positions[i]; p++ {
+			level += trend
+		}
+		last = positions[i]
+
+		idx := positions[i] % period
+		forecast := level + trend + seasonal[idx]
+		baseline[i] = &forecast
+		v := values[i]
+		if v == nil {
+			level += trend
+			continue
+		}
+		residuals = append(residuals, *v-forecast)
+		prevLevel := level
+		level = holtWintersAlpha*(*v-seasonal[idx]) + (1-holtWintersAlpha)*(level+trend)
+		trend = holtWintersBeta*(level-prevLevel) + (1-holtWintersBeta)*trend
+		seasonal[idx] = gamma*(*v-level) + (1-gamma)*seasonal[idx]
+	}
+
+	if len(residuals) < 2 {
+		return make([]*float64, len(values)), width
+	}
+	_, scale := meanAndStdDev(residuals)
+	w := scale * sensitivity
+	for i := range values {
+		if baseline[i] != nil {
+			width[i] = &w
+		}
+	}
+	return baseline, width
+}
+
+func offset(baseline, width *float64, sign float64) *float64 {
+	if baseline == nil || width == nil {
+		return nil
+	}
+	v := *baseline + sign**width
+	return &v
+}
+
+// anomalyScore returns how far a value is from the baseline, relative to the width of the bands.
+func anomalyScore(v, baseline, width *float64) *float64 {
+	if v == nil || baseline == nil || width == nil {
+		return nil
+	}
+	distance := math.Abs(*v - *baseline)
+	var score float64
+	switch {
+	case *width > 0:
+		score = distance / *width
+	case distance > 0:
+		score = math.Inf(1)
+	}
+	return &score
+}
+
+func meanAndStdDev(vals []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range vals {
+		sum += v
+	}
+	mean := sum / float64(len(vals))
+	variance := 0.0
+	for _, v := range vals {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(vals)))
+}
+
+func medianOf(vals []float64) float64 {
+	sorted := slices.Clone(vals)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
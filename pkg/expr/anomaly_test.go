@@ -0,0 +1,207 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewAnomalyCommand(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        AnomalyMethod
+		sensitivity   float64
+		season        time.Duration
+		output        AnomalyOutput
+		expectedError string
+	}{
+		{name: "zscore", method: AnomalyMethodZScore, sensitivity: 3},
+		{name: "mad with bands", method: AnomalyMethodMAD, sensitivity: 1, output: AnomalyOutputBands},
+		{name: "seasonal holt-winters", method: AnomalyMethodHoltWinters, sensitivity: 2, season: time.Hour, output: AnomalyOutputAll},
+		{name: "unknown method", method: "prophet", sensitivity: 3, expectedError: "expected anomaly method"},
+		{name: "unknown output", method: AnomalyMethodZScore, sensitivity: 3, output: "forecast", expectedError: "expected anomaly output"},
+		{name: "sensitivity is zero", method: AnomalyMethodZScore, expectedError: "sensitivity must be greater than zero"},
+		{name: "negative season", method: AnomalyMethodZScore, sensitivity: 3, season: -time.Hour, expectedError: "season must not be negative"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewAnomalyCommand("B", "A", tc.method, tc.sensitivity, tc.season, tc.output)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			if tc.output == "" {
+				require.Equal(t, AnomalyOutputScore, cmd.Output)
+			}
+		})
+	}
+}
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cmd, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"type": "anomaly", "expression": "$A", "method": "mad"}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, &AnomalyCommand{
+			RefID:        "B",
+			ReferenceVar: "A",
+			Method:       AnomalyMethodMAD,
+			Sensitivity:  defaultAnomalySensitivity,
+			Output:       AnomalyOutputScore,
+		}, cmd)
+	})
+
+	t.Run("all options", func(t *testing.T) {
+		cmd, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"type": "anomaly", "expression": "A", "method": "holt_winters", "sensitivity": 2.5, "season": "1d", "output": "all"}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, &AnomalyCommand{
+			RefID:        "B",
+			ReferenceVar: "A",
+			Method:       AnomalyMethodHoltWinters,
+			Sensitivity:  2.5,
+			Season:       24 * time.Hour,
+			Output:       AnomalyOutputAll,
+		}, cmd)
+	})
+
+	t.Run("invalid season", func(t *testing.T) {
+		_, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"type": "anomaly", "expression": "$A", "method": "zscore", "season": "often"}`),
+		})
+		require.ErrorContains(t, err, "failed to parse anomaly season")
+	})
+
+	t.Run("missing expression", func(t *testing.T) {
+		_, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID:    "B",
+			QueryRaw: []byte(`{"type": "anomaly", "method": "zscore"}`),
+		})
+		require.ErrorContains(t, err, "no variable specified")
+	})
+}
+
+func anomalyTestSeries(labels data.Labels, values ...float64) mathexp.Series {
+	t0 := time.Unix(0, 0)
+	s := mathexp.NewSeries("A", labels, len(values))
+	for i, v := range values {
+		s.SetPoint(i, t0.Add(time.Duration(i)*time.Minute), util.Pointer(v))
+	}
+	return s
+}
+
+func executeAnomaly(t *testing.T, cmd *AnomalyCommand, values ...mathexp.Value) mathexp.Results {
+	t.Helper()
+	vars := mathexp.Vars{"A": mathexp.Results{Values: values}}
+	res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	return res
+}
+
+func seriesValues(t *testing.T, v mathexp.Value) []float64 {
+	t.Helper()
+	s, ok := v.(mathexp.Series)
+	require.True(t, ok)
+	result := make([]float64, s.Len())
+	for i := range result {
+		_, p := s.GetPoint(i)
+		if p == nil {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = *p
+	}
+	return result
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	labels := data.Labels{"host": "a"}
+
+	t.Run("zscore returns a score per point with the labels of the series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodZScore, 1, 0, AnomalyOutputScore)
+		require.NoError(t, err)
+		res := executeAnomaly(t, cmd, anomalyTestSeries(labels, 1, 2, 3, 4, 5))
+		require.Len(t, res.Values, 1)
+		require.Equal(t, labels, res.Values[0].GetLabels())
+		require.InDeltaSlice(t, []float64{math.Sqrt2, 1 / math.Sqrt2, 0, 1 / math.Sqrt2, math.Sqrt2}, seriesValues(t, res.Values[0]), 1e-9)
+	})
+
+	t.Run("mad is not affected by outliers", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodMAD, 3, 0, AnomalyOutputScore)
+		require.NoError(t, err)
+		res := executeAnomaly(t, cmd, anomalyTestSeries(labels, 1, 1, 1, 1, 100))
+		require.Equal(t, []float64{0, 0, 0, 0, math.Inf(1)}, seriesValues(t, res.Values[0]))
+	})
+
+	t.Run("bands of a seasonal baseline", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodZScore, 3, 2*time.Minute, AnomalyOutputBands)
+		require.NoError(t, err)
+		res := executeAnomaly(t, cmd, anomalyTestSeries(labels, 1, 10, 1, 10, 1, 10))
+		require.Len(t, res.Values, 3)
+		for i, output := range []string{"lower", "baseline", "upper"} {
+			require.Equal(t, data.Labels{"host": "a", AnomalyOutputLabel: output}, res.Values[i].GetLabels())
+			require.Equal(t, []float64{1, 10, 1, 10, 1, 10}, seriesValues(t, res.Values[i]))
+		}
+	})
+
+	t.Run("season shorter than two intervals", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodZScore, 3, time.Minute, AnomalyOutputScore)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{anomalyTestSeries(labels, 1, 2, 3)}}}
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "must be at least two intervals")
+	})
+
+	t.Run("holt-winters detects a point that does not follow the season", func(t *testing.T) {
+		var values []float64
+		for i := 0; i < 6; i++ {
+			values = append(values, 0, 10, 0, 10)
+		}
+		values[len(values)-1] = 40
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodHoltWinters, 3, 4*time.Minute, AnomalyOutputAll)
+		require.NoError(t, err)
+		res := executeAnomaly(t, cmd, anomalyTestSeries(labels, values...))
+		require.Len(t, res.Values, 4)
+
+		scores := seriesValues(t, res.Values[0])
+		for i, score := range scores[:4] {
+			require.Truef(t, math.IsNaN(score), "expected no score in the first season, got %v at %d", score, i)
+		}
+		for i, score := range scores[4 : len(scores)-1] {
+			require.InDeltaf(t, 0, score, 1e-9, "unexpected score at %d", i+4)
+		}
+		require.Greater(t, scores[len(scores)-1], 1.0)
+		require.InDelta(t, 10, seriesValues(t, res.Values[2])[len(values)-1], 1e-9)
+	})
+
+	t.Run("no data is passed through", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodZScore, 3, 0, AnomalyOutputScore)
+		require.NoError(t, err)
+		res := executeAnomaly(t, cmd, mathexp.NewNoData())
+		require.Equal(t, mathexp.Values{mathexp.NewNoData()}, res.Values)
+	})
+
+	t.Run("numbers are not supported", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyMethodZScore, 3, 0, AnomalyOutputScore)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}}}
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "requires time series data")
+	})
+}
@@ -123,7 +123,7 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	if !ok {
 		return nil, fmt.Errorf("expected reducer to be a string, got %T", rawReducer)
 	}
-	redFunc := mathexp.ReducerID(strings.ToLower(redString))
+	redFunc := mathexp.NormalizeReducerID(redString)
 
 	var mapper mathexp.ReduceMapper = nil
 	settings, ok := rn.Query["settings"]
@@ -189,6 +189,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 				}
 			}
 			copyV := mathexp.NewNumber(gr.refID, v.GetLabels())
+			copyV.AddFields(v.GetFields()...)
 			copyV.SetValue(value)
 			if gr.seriesMapper == nil && i == 0 { // Add notice to only the first result to not multiple them in presentation
 				copyV.AddNotice(data.Notice{
@@ -222,11 +223,13 @@ type ResampleCommand struct {
 
 // NewResampleCommand creates a new ResampleCMD.
 func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
-	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	if _, err := mathexp.GetReduceFunc(downsampler); err != nil {
+		return nil, fmt.Errorf("invalid resample downsampler: %w", err)
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
@@ -282,7 +285,7 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
-		mathexp.ReducerID(downsampler),
+		mathexp.NormalizeReducerID(downsampler),
 		mathexp.Upsampler(upsampler),
 		rn.TimeRange)
 }
@@ -343,6 +346,8 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +364,8 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -379,6 +386,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -99,6 +99,66 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Reducer(t *testing.T) {
+	var tests = []struct {
+		name            string
+		reducer         string
+		isError         bool
+		expectedReducer mathexp.ReducerID
+	}{
+		{
+			name:            "reducer is lower cased",
+			reducer:         "Median",
+			expectedReducer: mathexp.ReducerMedian,
+		},
+		{
+			name:            "named percentile reducer",
+			reducer:         "p95",
+			expectedReducer: mathexp.ReducerP95,
+		},
+		{
+			name:            "parameterized percentile reducer",
+			reducer:         "percentile( 0.9 )",
+			expectedReducer: mathexp.NewPercentileReducer(0.9),
+		},
+		{
+			name:    "error when percentile is out of range",
+			reducer: "percentile(95)",
+			isError: true,
+		},
+		{
+			name:    "error when percentile is not a number",
+			reducer: "percentile(high)",
+			isError: true,
+		},
+		{
+			name:    "error when reducer is not known",
+			reducer: "mode",
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID: "A",
+				Query: map[string]any{
+					"expression": "$B",
+					"reducer":    test.reducer,
+				},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedReducer, cmd.Reducer)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
@@ -272,4 +332,9 @@ func TestResampleCommand_Execute(t *testing.T) {
 		require.Empty(t, result.Values)
 		require.NoError(t, err)
 	})
+
+	t.Run("should fail to create command with unknown downsampler", func(t *testing.T) {
+		_, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "mode", "pad", tr)
+		require.Error(t, err)
+	})
 }
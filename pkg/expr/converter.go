@@ -18,6 +18,21 @@ type ResultConverter struct {
 	Tracer   tracing.Tracer
 }
 
+type numberFieldsKey struct{}
+
+// WithNumberFields returns a context in which the string columns of a table that is converted to numbers are kept,
+// in addition to the labels, as fields of the numbers. The fields follow the numbers through the Math, Reduce and
+// Threshold expressions, so that alert rule templates can read them from the condition. Other consumers of the
+// pipeline get numbers with a single field.
+func WithNumberFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, numberFieldsKey{}, true)
+}
+
+func numberFieldsFromContext(ctx context.Context) bool {
+	withFields, _ := ctx.Value(numberFieldsKey{}).(bool)
+	return withFields
+}
+
 func (c *ResultConverter) Convert(ctx context.Context,
 	datasourceType string,
 	frames data.Frames,
@@ -52,7 +67,7 @@ func (c *ResultConverter) Convert(ctx context.Context,
 
 		// Handle Numeric Table
 		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot && isNumberTable(frame) {
-			numberSet, err := extractNumberSet(frame)
+			numberSet, err := extractNumberSet(frame, numberFieldsFromContext(ctx))
 			if err != nil {
 				return "", mathexp.Results{}, err
 			}
@@ -203,7 +218,9 @@ func isNumberTable(frame *data.Frame) bool {
 	return numericCount == 1 && otherCount == 0
 }
 
-func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
+// extractNumberSet returns a Number for every row of the frame, labeled with the string columns of the row. If
+// withFields is true, the string columns are also kept as fields of the Number (see WithNumberFields).
+func extractNumberSet(frame *data.Frame, withFields bool) ([]mathexp.Number, error) {
 	numericField := 0
 	stringFieldIdxs := []int{}
 	stringFieldNames := []string{}
@@ -222,6 +239,7 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 	for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
 		val, _ := frame.FloatAt(numericField, rowIdx)
 		var labels data.Labels
+		var fields []*data.Field
 		for i := 0; i < len(stringFieldIdxs); i++ {
 			if i == 0 {
 				labels = make(data.Labels)
@@ -229,9 +247,13 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 			key := stringFieldNames[i] // TODO check for duplicate string column names
 			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
 			labels[key] = val.(string) // TODO check assertion / return error
+			if withFields {
+				fields = append(fields, data.NewField(key, nil, []string{labels[key]}))
+			}
 		}
 
 		n := mathexp.NewNumber(frame.Fields[numericField].Name, labels)
+		n.AddFields(fields...)
 
 		// The new value fields' configs gets pointed to the one in the original frame
 		n.Frame.Fields[0].Config = frame.Fields[numericField].Config
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestConvertDataFramesToResults(t *testing.T) {
@@ -118,4 +119,43 @@ func TestConvertDataFramesToResults(t *testing.T) {
 			}
 		})
 	})
+
+	numberTable := []*data.Frame{
+		data.NewFrame("",
+			data.NewField("host", nil, []string{"a", "b"}),
+			data.NewField("error", nil, []*string{util.Pointer("timeout"), util.Pointer("refused")}),
+			data.NewField("value", nil, []*float64{fp(1), fp(2)})),
+	}
+
+	t.Run("should read the string columns of a number table as labels of the numbers", func(t *testing.T) {
+		resultType, res, err := converter.Convert(context.Background(), "mysql", numberTable, s.allowLongFrames)
+		require.NoError(t, err)
+		assert.Equal(t, "number set", resultType)
+		require.Len(t, res.Values, 2)
+
+		for i, expected := range []struct{ host, err string }{{"a", "timeout"}, {"b", "refused"}} {
+			require.IsType(t, mathexp.Number{}, res.Values[i])
+			n := res.Values[i].(mathexp.Number)
+			require.Equal(t, data.Labels{"host": expected.host, "error": expected.err}, n.GetLabels())
+			require.Empty(t, n.GetFields())
+		}
+	})
+
+	t.Run("should keep the string columns of a number table as fields of the numbers if requested", func(t *testing.T) {
+		resultType, res, err := converter.Convert(WithNumberFields(context.Background()), "mysql", numberTable, s.allowLongFrames)
+		require.NoError(t, err)
+		assert.Equal(t, "number set", resultType)
+		require.Len(t, res.Values, 2)
+
+		for i, expected := range []struct{ host, err string }{{"a", "timeout"}, {"b", "refused"}} {
+			require.IsType(t, mathexp.Number{}, res.Values[i])
+			n := res.Values[i].(mathexp.Number)
+			require.Equal(t, data.Labels{"host": expected.host, "error": expected.err}, n.GetLabels())
+			require.Len(t, n.GetFields(), 2)
+			require.Equal(t, "host", n.GetFields()[0].Name)
+			require.Equal(t, expected.host, n.GetFields()[0].At(0))
+			require.Equal(t, "error", n.GetFields()[1].Name)
+			require.Equal(t, expected.err, n.GetFields()[1].At(0))
+		}
+	})
 }
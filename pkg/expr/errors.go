@@ -3,7 +3,9 @@ package expr
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -91,3 +93,59 @@ func makeUnexpectedNodeTypeError(refID, nodeType string) error {
 
 	return UnexpectedNodeTypeError.Build(data)
 }
+
+var sqlParseErrStr = "invalid SQL expression [{{ .Public.refId }}] at position {{ .Public.position }}: {{ .Public.error }}"
+
+var SQLParseError = errutil.BadRequest("sse.sqlParseError").MustTemplate(
+	sqlParseErrStr,
+	errutil.WithPublic(sqlParseErrStr))
+
+func makeSQLParseError(refID string, err error) error {
+	position := 0
+	msg := err.Error()
+	var parseErr *sql.ParseError
+	if errors.As(err, &parseErr) {
+		position = parseErr.Pos
+		msg = parseErr.Msg
+	}
+
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"refId":    refID,
+			"position": position,
+			"error":    msg,
+		},
+		Error: err,
+	}
+
+	return SQLParseError.Build(data)
+}
+
+var sqlUnknownRefIDErrStr = "SQL expression [{{ .Public.refId }}] reads from queries or expressions that do not exist: " +
+	"{{ range $i, $ref := .Public.unknownRefIds }}{{ if $i }}, {{ end }}[{{ $ref.refId }}] at position {{ $ref.position }}{{ end }}"
+
+var SQLUnknownRefIDError = errutil.BadRequest("sse.sqlUnknownRefId").MustTemplate(
+	sqlUnknownRefIDErrStr,
+	errutil.WithPublic(sqlUnknownRefIDErrStr))
+
+func makeSQLUnknownRefIDError(refID string, refs []sql.TableRef) error {
+	unknown := make([]map[string]any, 0, len(refs))
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		unknown = append(unknown, map[string]any{
+			"refId":    ref.Name,
+			"position": ref.Pos,
+		})
+		names = append(names, ref.Name)
+	}
+
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"refId":         refID,
+			"unknownRefIds": unknown,
+		},
+		Error: fmt.Errorf("SQL expression %v reads from unknown queries or expressions %v", refID, strings.Join(names, ", ")),
+	}
+
+	return SQLUnknownRefIDError.Build(data)
+}
@@ -62,6 +62,8 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	limits := LimitsFromContext(c)
+	series := 0
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -75,6 +77,11 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 		}
 
 		executeDSNodesGrouped(c, now, vars, s, dsNodes)
+		for _, node := range dsNodes {
+			if err := limits.check(node.RefID(), vars[node.RefID()], &series); err != nil {
+				return vars, err
+			}
+		}
 	}
 
 	s.allowLongFrames = hasSqlExpression(*dp)
@@ -121,6 +128,11 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 		}
 
 		vars[node.RefID()] = res
+		if node.NodeType() == TypeDatasourceNode {
+			if err := limits.check(node.RefID(), res, &series); err != nil {
+				return vars, err
+			}
+		}
 	}
 	return vars, nil
 }
@@ -279,7 +291,7 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 		case TypeDatasourceNode:
 			node, err = s.buildDSNode(dp, rn, req)
 		case TypeCMDNode:
-			node, err = buildCMDNode(rn, s.features)
+			node, err = buildCMDNode(rn, s.features, s.cfg)
 		case TypeMLNode:
 			if s.features.IsEnabledGlobally(featuremgmt.FlagMlExpressions) {
 				node, err = s.buildMLNode(dp, rn, req)
@@ -317,13 +329,15 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 
 		cmdNode := node.(*CMDNode)
 
+		if sqlCmd, ok := cmdNode.Command.(*SQLCommand); ok {
+			if err := sqlCmd.validateRefIDs(registry); err != nil {
+				return err
+			}
+		}
+
 		for _, neededVar := range cmdNode.Command.NeedsVars() {
 			neededNode, ok := registry[neededVar]
 			if !ok {
-				_, ok := cmdNode.Command.(*SQLCommand)
-				if ok {
-					continue
-				}
 				return fmt.Errorf("unable to find dependent node '%v'", neededVar)
 			}
 
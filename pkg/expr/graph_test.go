@@ -231,6 +231,78 @@ func TestServicebuildPipeLine(t *testing.T) {
 			},
 			expectedOrder: []string{"B", "A"},
 		},
+		{
+			name: "sql expression depends on the tables it reads from",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "WITH b AS (SELECT * FROM B) SELECT * FROM b JOIN \"C\" ON true",
+							"type": "sql"
+						}`),
+						TimeRange: AbsoluteTimeRange{},
+					},
+					{
+						RefID: "B",
+						DataSource: &datasources.DataSource{
+							UID: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+					{
+						RefID: "C",
+						DataSource: &datasources.DataSource{
+							UID: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+				},
+			},
+			expectedOrder: []string{"B", "C", "A"},
+		},
+		{
+			name: "sql expression with unknown tables will error",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "SELECT * FROM B JOIN X ON true, Y",
+							"type": "sql"
+						}`),
+						TimeRange: AbsoluteTimeRange{},
+					},
+					{
+						RefID: "B",
+						DataSource: &datasources.DataSource{
+							UID: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+				},
+			},
+			expectErrContains: "[X] at position 21, [Y] at position 32",
+		},
+		{
+			name: "sql expression that is not a select will error",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "DROP TABLE B",
+							"type": "sql"
+						}`),
+						TimeRange: AbsoluteTimeRange{},
+					},
+				},
+			},
+			expectErrContains: "only SELECT statements are supported",
+		},
 	}
 	s := Service{
 		features: featuremgmt.WithFeatures(featuremgmt.FlagExpressionParser),
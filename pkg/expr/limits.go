@@ -0,0 +1,64 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+var (
+	// ErrMaxSeriesExceeded is returned when the queries of a pipeline return more series than allowed.
+	ErrMaxSeriesExceeded = errors.New("evaluation exceeded the maximum number of series")
+	// ErrMaxFrameSizeExceeded is returned when a query of a pipeline returns a data frame with more rows than allowed.
+	ErrMaxFrameSizeExceeded = errors.New("evaluation exceeded the maximum data frame size")
+)
+
+// Limits limit the size of the results of the data source queries of a pipeline. They are checked as soon as a query
+// returns, so a pipeline fails before its expressions are executed on results that are too large. Zero means no limit.
+type Limits struct {
+	// MaxSeries is the maximum number of series returned by all the queries of the pipeline.
+	MaxSeries int
+	// MaxFrameSize is the maximum number of rows of a data frame returned by a query.
+	MaxFrameSize int
+}
+
+type limitsKey struct{}
+
+// WithLimits returns a context that limits the size of the query results of the pipelines executed with it.
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// LimitsFromContext returns the limits of the context, or no limits if the context has none.
+func LimitsFromContext(ctx context.Context) Limits {
+	limits, _ := ctx.Value(limitsKey{}).(Limits)
+	return limits
+}
+
+// check returns an error if the results of the query refID contain a frame with more than MaxFrameSize rows, or if
+// they bring the number of series returned by the queries of the pipeline above MaxSeries. series is the number of
+// series returned by the queries executed before, and is incremented by the number of series of the results.
+func (l Limits) check(refID string, res mathexp.Results, series *int) error {
+	if l.MaxSeries <= 0 && l.MaxFrameSize <= 0 {
+		return nil
+	}
+	for _, v := range res.Values {
+		if v.Type() == parse.TypeNoData {
+			continue
+		}
+		*series++
+		if l.MaxFrameSize <= 0 {
+			continue
+		}
+		if frame := v.AsDataFrame(); frame != nil && frame.Rows() > l.MaxFrameSize {
+			return fmt.Errorf("%w: a frame of %s has %d rows, the limit is %d", ErrMaxFrameSizeExceeded, refID, frame.Rows(), l.MaxFrameSize)
+		}
+	}
+	if l.MaxSeries > 0 && *series > l.MaxSeries {
+		return fmt.Errorf("%w: %d series were returned, the limit is %d", ErrMaxSeriesExceeded, *series, l.MaxSeries)
+	}
+	return nil
+}
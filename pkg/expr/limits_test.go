@@ -0,0 +1,112 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLimits(t *testing.T) {
+	series := func(name string, values ...float64) *data.Frame {
+		times := make([]time.Time, 0, len(values))
+		for i := range values {
+			times = append(times, time.Unix(int64(i), 0))
+		}
+		return data.NewFrame("",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"series": name}, values))
+	}
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{series("a", 1, 2, 3), series("b", 4, 5, 6)}},
+		},
+	}
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	for _, groupByDS := range []bool{false, true} {
+		features := featuremgmt.WithFeatures()
+		if groupByDS {
+			features = featuremgmt.WithFeatures(featuremgmt.FlagSseGroupByDatasource)
+		}
+		s := Service{
+			cfg:          setting.NewCfg(),
+			dataService:  me,
+			pCtxProvider: pCtxProvider,
+			features:     features,
+			tracer:       tracing.InitializeTracerForTest(),
+			metrics:      newMetrics(nil),
+			converter: &ResultConverter{
+				Features: features,
+				Tracer:   tracing.InitializeTracerForTest(),
+			},
+		}
+		queries := []Query{
+			{
+				RefID: "A",
+				DataSource: &datasources.DataSource{
+					OrgID: 1,
+					UID:   "test",
+					Type:  "test",
+				},
+				JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+				TimeRange: AbsoluteTimeRange{
+					From: time.Time{},
+					To:   time.Time{},
+				},
+			},
+			{
+				RefID:      "B",
+				DataSource: dataSourceModel(),
+				JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+			},
+		}
+		pl, err := s.BuildPipeline(&Request{Queries: queries, User: &user.SignedInUser{}})
+		require.NoError(t, err)
+
+		testCases := []struct {
+			name     string
+			limits   Limits
+			expError error
+		}{
+			{name: "no limits"},
+			{name: "within the limits", limits: Limits{MaxSeries: 2, MaxFrameSize: 3}},
+			{name: "too many series", limits: Limits{MaxSeries: 1}, expError: ErrMaxSeriesExceeded},
+			{name: "frame too large", limits: Limits{MaxFrameSize: 2}, expError: ErrMaxFrameSizeExceeded},
+		}
+		for _, tc := range testCases {
+			t.Run(fmt.Sprintf("%s, grouped by data source %t", tc.name, groupByDS), func(t *testing.T) {
+				ctx := WithLimits(context.Background(), tc.limits)
+				res, err := s.ExecutePipeline(ctx, time.Now(), pl)
+				if tc.expError != nil {
+					require.ErrorIs(t, err, tc.expError)
+					require.Nil(t, res)
+					return
+				}
+				require.NoError(t, err)
+				require.NoError(t, res.Responses["B"].Error)
+				require.Len(t, res.Responses["B"].Frames, 2)
+			})
+		}
+	}
+}
@@ -29,6 +29,9 @@ type State struct {
 	RefID     string
 	Drops     map[string]map[string][]data.Labels // binary node text -> LH/RH -> Drop Labels
 	DropCount int64
+	// Unmatched are the items of binary operations with an on or ignoring clause that have no match on the other side.
+	Unmatched      map[string][]string // binary node text -> descriptions of the unmatched items
+	UnmatchedCount int64
 
 	tracer tracing.Tracer
 }
@@ -65,6 +68,7 @@ func (e *Expr) executeState(s *State) (r Results, err error) {
 	defer errRecover(&err, s)
 	r, err = s.walk(e.Tree.Root)
 	s.addDropNotices(&r)
+	s.addUnmatchedNotices(&r)
 	return
 }
 
@@ -159,6 +163,7 @@ func (e *State) unarySeries(s Series, op string) (Series, error) {
 
 func (e *State) unaryNumber(n Number, op string) (Number, error) {
 	newNumber := NewNumber(e.RefID, n.GetLabels())
+	newNumber.AddFields(n.GetFields()...)
 
 	f := n.GetFloat64Value()
 	if f != nil {
@@ -316,7 +321,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchUnion(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -376,6 +389,9 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 			case Number:
 				bFloat := bt.GetFloat64Value()
 				value, err = e.biScalarNumber(uni.Labels, node.OpStr, at, bFloat, true)
+				if err == nil {
+					value.(Number).AddFields(bt.GetFields()...)
+				}
 			case Series:
 				value, err = e.biSeriesNumber(uni.Labels, node.OpStr, bt, aFloat, false)
 			case NoData:
@@ -483,6 +499,7 @@ func binaryOp(op string, a, b float64) (r float64, err error) {
 
 func (e *State) biScalarNumber(labels data.Labels, op string, number Number, scalarVal *float64, numberFirst bool) (Number, error) {
 	newNumber := NewNumber(e.RefID, labels)
+	newNumber.AddFields(number.GetFields()...)
 	f := number.GetFloat64Value()
 	if f == nil || scalarVal == nil {
 		newNumber.SetValue(nil)
@@ -4,6 +4,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/assert"
 )
@@ -125,6 +126,39 @@ func TestNumberExpr(t *testing.T) {
 			vars:      Vars{"A": resultValuesNoErr(makeNumber("", nil, nil))},
 			results:   resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:      "unary: number keeps its fields",
+			expr:      "- $A",
+			vars:      Vars{"A": resultValuesNoErr(makeNumberWithFields("temp", nil, float64Pointer(2.0), data.NewField("host", nil, []string{"a"})))},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   resultValuesNoErr(makeNumberWithFields("", nil, float64Pointer(-2.0), data.NewField("host", nil, []string{"a"}))),
+		},
+		{
+			name: "binary: number Op number keeps the fields of both numbers",
+			expr: "$A > $B",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumberWithFields("temp", data.Labels{"host": "a"}, float64Pointer(2.0),
+					data.NewField("host", nil, []string{"a"}))),
+				"B": resultValuesNoErr(makeNumberWithFields("temp", data.Labels{"host": "a"}, float64Pointer(1.0),
+					data.NewField("host", nil, []string{"b"}), data.NewField("error", nil, []string{"timeout"}))),
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results: resultValuesNoErr(makeNumberWithFields("", data.Labels{"host": "a"}, float64Pointer(1.0),
+				data.NewField("host", nil, []string{"a"}), data.NewField("error", nil, []string{"timeout"}))),
+		},
+		{
+			name:      "function: number keeps its fields",
+			expr:      "abs($A)",
+			vars:      Vars{"A": resultValuesNoErr(makeNumberWithFields("temp", nil, float64Pointer(-2.0), data.NewField("host", nil, []string{"a"})))},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			results:   resultValuesNoErr(makeNumberWithFields("", nil, float64Pointer(2.0), data.NewField("host", nil, []string{"a"}))),
+		},
 	}
 
 	for _, tt := range tests {
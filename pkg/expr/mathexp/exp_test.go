@@ -27,6 +27,12 @@ func makeNumber(name string, labels data.Labels, f *float64) Number {
 	return newNumber
 }
 
+func makeNumberWithFields(name string, labels data.Labels, f *float64, fields ...*data.Field) Number {
+	newNumber := makeNumber(name, labels, f)
+	newNumber.AddFields(fields...)
+	return newNumber
+}
+
 func unixTimePointer(sec, nsec int64) *time.Time {
 	t := time.Unix(sec, nsec)
 	return &t
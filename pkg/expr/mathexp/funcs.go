@@ -1,7 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +72,33 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"deriv": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      deriv,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1, false),
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+		Check:  checkDurationArg(1, true),
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -203,6 +234,7 @@ func perFloat(e *State, val Value, floatF func(x float64) float64) (Value, error
 	switch val.Type() {
 	case parse.TypeNumberSet:
 		n := NewNumber(e.RefID, val.GetLabels())
+		n.AddFields(val.(Number).GetFields()...)
 		f := val.(Number).GetFloat64Value()
 		nF := math.NaN()
 		if f != nil {
@@ -246,6 +278,7 @@ func perNullableFloat(e *State, val Value, floatF func(x *float64) *float64) (Va
 	switch val.Type() {
 	case parse.TypeNumberSet:
 		n := NewNumber(e.RefID, val.GetLabels())
+		n.AddFields(val.(Number).GetFields()...)
 		f := val.(Number).GetFloat64Value()
 		n.SetValue(floatF(f))
 		newVal = n
@@ -307,3 +340,171 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// rate returns the per-second rate of increase between consecutive points of each series.
+// A decrease in value is treated as a counter reset, in which case the new value is
+// taken as the increase since the reset. The first point of a series has no rate and is dropped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prev, cur float64, dt time.Duration) float64 {
+			increase := cur - prev
+			if cur < prev {
+				increase = cur
+			}
+			return increase / dt.Seconds()
+		})
+	})
+}
+
+// delta returns the difference between consecutive points of each series.
+// The first point of a series has no delta and is dropped.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prev, cur float64, _ time.Duration) float64 {
+			return cur - prev
+		})
+	})
+}
+
+// deriv returns the per-second derivative between consecutive points of each series.
+// Unlike rate, it does not account for counter resets and can be negative.
+// The first point of a series has no derivative and is dropped.
+func deriv(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "deriv", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prev, cur float64, dt time.Duration) float64 {
+			return (cur - prev) / dt.Seconds()
+		})
+	})
+}
+
+// movingAvg returns, for each point of each series, the average of the non-null values
+// within the trailing window ending at (and including) that point.
+// Points with a null value remain null.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_avg: failed to parse window %q: %w", rawWindow, err)
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		sorted := sortedSeriesCopy(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), sorted.Len())
+		start := 0
+		for i := 0; i < sorted.Len(); i++ {
+			t, f := sorted.GetPoint(i)
+			for sorted.GetTime(start).Add(window).Compare(t) <= 0 {
+				start++
+			}
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum, count := 0.0, 0
+			for j := start; j <= i; j++ {
+				if v := sorted.GetValue(j); v != nil {
+					sum += *v
+					count++
+				}
+			}
+			avg := sum / float64(count)
+			newSeries.SetPoint(i, t, &avg)
+		}
+		return newSeries
+	})
+}
+
+// shift moves the timestamps of each series by the given duration. A positive duration
+// moves points forward in time, so that the value from an hour ago lines up with now when
+// shifted by "1h". Values are not changed.
+func shift(e *State, varSet Results, rawOffset string) (Results, error) {
+	offset, err := gtime.ParseDuration(rawOffset)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: failed to parse duration %q: %w", rawOffset, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(offset), f)
+		}
+		return newSeries
+	})
+}
+
+// perSeries passes each Series in varSet to seriesF and collects the returned series.
+// NoData values are passed through unchanged, while numbers and scalars are an error
+// since functions that look across time are meaningless for them.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch res.Type() {
+		case parse.TypeSeriesSet:
+			newRes.Values = append(newRes.Values, seriesF(res.(Series)))
+		case parse.TypeNoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected a series but got %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perPointPair calls pairF with each non-null value of the time sorted series and the previous
+// non-null value, along with the time between them. Null values stay null in the output,
+// and points that share a timestamp with the previous value are dropped. NaN values
+// are passed to pairF, so they propagate to the result.
+func perPointPair(e *State, s Series, pairF func(prev, cur float64, dt time.Duration) float64) Series {
+	sorted := sortedSeriesCopy(s)
+	newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+	var prev *float64
+	var prevTime time.Time
+	for i := 0; i < sorted.Len(); i++ {
+		t, f := sorted.GetPoint(i)
+		if f == nil {
+			if prev != nil {
+				newSeries.AppendPoint(t, nil)
+			}
+			continue
+		}
+		if prev != nil {
+			dt := t.Sub(prevTime)
+			if dt <= 0 {
+				continue
+			}
+			nF := pairF(*prev, *f, dt)
+			newSeries.AppendPoint(t, &nF)
+		}
+		prev, prevTime = f, t
+	}
+	return newSeries
+}
+
+// sortedSeriesCopy returns a copy of the series sorted by time from oldest to newest,
+// so that the input, which may be shared with other nodes, is not mutated.
+func sortedSeriesCopy(s Series) Series {
+	c := NewSeries("", nil, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		c.SetPoint(i, t, f)
+	}
+	c.SortByTime(false)
+	return c
+}
+
+// checkDurationArg returns a parse time check that the string argument at argIdx is a
+// valid duration. Unless allowNonPositive is set, the duration must also be greater than zero.
+func checkDurationArg(argIdx int, allowNonPositive bool) func(*parse.Tree, *parse.FuncNode) error {
+	return func(_ *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[argIdx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration string for argument %v of %s", argIdx, f.Name)
+		}
+		d, err := gtime.ParseDuration(s.Text)
+		if err != nil {
+			return fmt.Errorf("parse: invalid duration %q for argument %v of %s: %w", s.Text, argIdx, f.Name, err)
+		}
+		if !allowNonPositive && d <= 0 {
+			return fmt.Errorf("parse: duration for argument %v of %s must be greater than zero, got %q", argIdx, f.Name, s.Text)
+		}
+		return nil
+	}
+}
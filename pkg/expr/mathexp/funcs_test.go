@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/require"
 )
@@ -140,3 +142,162 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesWindowFuncs(t *testing.T) {
+	counter := makeSeries("", data.Labels{"host": "a"},
+		tp{time.Unix(0, 0), float64Pointer(10)},
+		tp{time.Unix(10, 0), float64Pointer(30)},
+		tp{time.Unix(20, 0), nil},
+		tp{time.Unix(30, 0), float64Pointer(70)},
+		tp{time.Unix(40, 0), float64Pointer(20)},
+	)
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "rate handles nulls and counter resets",
+			expr:      "rate($A)",
+			vars:      Vars{"A": resultValuesNoErr(counter)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(2)},
+				),
+			),
+		},
+		{
+			name:      "delta does not treat decreases as resets",
+			expr:      "delta($A)",
+			vars:      Vars{"A": resultValuesNoErr(counter)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(40)},
+					tp{time.Unix(40, 0), float64Pointer(-50)},
+				),
+			),
+		},
+		{
+			name:      "deriv is per second",
+			expr:      "deriv($A)",
+			vars:      Vars{"A": resultValuesNoErr(counter)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(-5)},
+				),
+			),
+		},
+		{
+			name: "rate sorts input by time and propagates NaN",
+			expr: "rate($A)",
+			vars: Vars{"A": resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(math.NaN())},
+				),
+			)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(math.NaN())},
+					tp{time.Unix(20, 0), float64Pointer(math.NaN())},
+				),
+			),
+		},
+		{
+			name:      "moving_avg skips nulls in the window",
+			expr:      `moving_avg($A, "20s")`,
+			vars:      Vars{"A": resultValuesNoErr(counter)},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(70)},
+					tp{time.Unix(40, 0), float64Pointer(45)},
+				),
+			),
+		},
+		{
+			name:      "shift moves timestamps",
+			expr:      `shift($A, "1m")`,
+			vars:      Vars{"A": resultValuesNoErr(makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}))},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(makeSeries("", nil, tp{time.Unix(60, 0), float64Pointer(1)})),
+		},
+		{
+			name:      "rate on no data returns no data",
+			expr:      "rate($A)",
+			vars:      Vars{"A": resultValuesNoErr(NewNoData())},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(NewNoData()),
+		},
+		{
+			name:      "rate on number should error",
+			expr:      "rate($A)",
+			vars:      Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1)))},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with invalid window should error",
+			expr:     `moving_avg($A, "five minutes")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with zero window should error",
+			expr:     `moving_avg($A, "0s")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift without a duration should error",
+			expr:     `shift($A)`,
+			newErrIs: require.Error,
+		},
+	}
+
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if tt.results.Values != nil {
+					if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+						t.Errorf("Result mismatch (-want +got):\n%s", diff)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package mathexp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// matchUnion creates the Unions of a binary operation with an on or ignoring clause, like vector matching in Prometheus.
+// Values are matched by their match labels: the labels in on(...), or all labels except the ones in ignoring(...).
+//   - Without group_left or group_right, each value must have at most one value on the other side with the same
+//     match labels, and the labels of the result are the match labels.
+//   - With group_left, many values on the left side can match one value on the right side, and the labels of the result
+//     are the labels of the left side, with the labels in group_left(...) copied from the right side.
+//     group_right is the same with the sides swapped.
+//
+// Values without a match are dropped, and are reported in a notice of the result.
+func (e *State) matchUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	if isNoDataResults(aResults) || isNoDataResults(bResults) {
+		return e.union(aResults, bResults, biNode), nil
+	}
+	m := biNode.Matching
+
+	type side struct {
+		name    string
+		values  []Value
+		keys    []string
+		matched []bool
+	}
+	newSide := func(name string, r Results) (*side, error) {
+		s := &side{name: name}
+		for _, v := range r.Values {
+			switch v.Type() {
+			case parse.TypeNoData:
+				continue
+			case parse.TypeScalar:
+				return nil, fmt.Errorf("vector matching %s requires series or numbers, got a scalar from %s", m, name)
+			}
+			s.values = append(s.values, v)
+			s.keys = append(s.keys, matchLabels(m, v.GetLabels()).String())
+		}
+		s.matched = make([]bool, len(s.values))
+		return s, nil
+	}
+	left, err := newSide(biNode.Args[0].String(), aResults)
+	if err != nil {
+		return nil, err
+	}
+	right, err := newSide(biNode.Args[1].String(), bResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// the "one" side must have a single value for each match labels
+	one, many := right, left
+	if m.Card == parse.CardOneToMany {
+		one, many = left, right
+	}
+	checkUnique := func(s *side) (map[string]int, error) {
+		index := make(map[string]int, len(s.keys))
+		for i, key := range s.keys {
+			if _, ok := index[key]; ok {
+				hint := "matching labels must be unique on one side"
+				if m.Card == parse.CardOneToOne {
+					hint = "use group_left or group_right for many-to-one matching"
+				}
+				return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of %s: %s", key, s.name, biNode, hint)
+			}
+			index[key] = i
+		}
+		return index, nil
+	}
+	oneIndex, err := checkUnique(one)
+	if err != nil {
+		return nil, err
+	}
+	if m.Card == parse.CardOneToOne {
+		if _, err := checkUnique(many); err != nil {
+			return nil, err
+		}
+	}
+
+	unions := []*Union{}
+	results := map[string]struct{}{}
+	for i, v := range many.values {
+		j, ok := oneIndex[many.keys[i]]
+		if !ok {
+			continue
+		}
+		many.matched[i] = true
+		one.matched[j] = true
+
+		labels := resultLabels(m, v.GetLabels(), one.values[j].GetLabels())
+		key := labels.String()
+		if _, ok := results[key]; ok {
+			return nil, fmt.Errorf("multiple matches for labels {%s} in %s: grouping labels must ensure unique matches", key, biNode)
+		}
+		results[key] = struct{}{}
+
+		u := &Union{Labels: labels, A: v, B: one.values[j]}
+		if many == right {
+			u.A, u.B = u.B, u.A
+		}
+		unions = append(unions, u)
+	}
+
+	for _, s := range []*side{left, right} {
+		for i, v := range s.values {
+			if s.matched[i] {
+				continue
+			}
+			if e.Unmatched == nil {
+				e.Unmatched = make(map[string][]string)
+			}
+			e.UnmatchedCount++
+			e.Unmatched[biNode.String()] = append(e.Unmatched[biNode.String()],
+				fmt.Sprintf("%s{%s} has no match for {%s}", s.name, v.GetLabels(), s.keys[i]))
+		}
+	}
+	return unions, nil
+}
+
+func isNoDataResults(r Results) bool {
+	return len(r.Values) == 1 && r.Values[0].Type() == parse.TypeNoData
+}
+
+// matchLabels returns the labels used to match a value with the values on the other side of a binary operation.
+func matchLabels(m *parse.VectorMatching, labels data.Labels) data.Labels {
+	result := data.Labels{}
+	for k, v := range labels {
+		if slices.Contains(m.MatchingLabels, k) == m.On {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// resultLabels returns the labels of the result of matching a value on the "many" side with a value on the "one" side.
+// When matching is one-to-one, there is no "many" side and the result has the match labels.
+func resultLabels(m *parse.VectorMatching, many, one data.Labels) data.Labels {
+	if m.Card == parse.CardOneToOne {
+		return matchLabels(m, many)
+	}
+	labels := many.Copy()
+	for _, k := range m.Include {
+		if v, ok := one[k]; ok {
+			labels[k] = v
+		} else {
+			delete(labels, k)
+		}
+	}
+	return labels
+}
+
+// addUnmatchedNotices adds a notice with the items without a match to the first value of the results.
+// If nothing matched, the result is no data so that the notice explains why.
+func (e *State) addUnmatchedNotices(r *Results) {
+	if e.UnmatchedCount == 0 {
+		return
+	}
+	if len(r.Values) == 0 {
+		r.Values = Values{NewNoData()}
+	}
+	itemsPerNodeLimit := 5 // Limit on unmatched items shown per each binary node
+
+	nT := strings.Builder{}
+	nT.WriteString(fmt.Sprintf("%v items without a match in vector matching(s): ", e.UnmatchedCount))
+	nodes := make([]string, 0, len(e.Unmatched))
+	for biNodeText := range e.Unmatched {
+		nodes = append(nodes, biNodeText)
+	}
+	slices.Sort(nodes)
+	for i, biNodeText := range nodes {
+		if i > 0 {
+			nT.WriteString(" ")
+		}
+		items := e.Unmatched[biNodeText]
+		shown := items[:min(len(items), itemsPerNodeLimit)]
+		nT.WriteString(fmt.Sprintf(`["%s": %s`, biNodeText, strings.Join(shown, "; ")))
+		if len(items) > itemsPerNodeLimit {
+			nT.WriteString(fmt.Sprintf("...%v more...", len(items)-itemsPerNodeLimit))
+		}
+		nT.WriteString("]")
+	}
+
+	r.Values[0].AddNotice(data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     nT.String(),
+	})
+}
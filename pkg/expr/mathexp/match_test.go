@@ -0,0 +1,155 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestVectorMatching(t *testing.T) {
+	numbers := func(values ...Value) Results {
+		return Results{Values: values}
+	}
+	vars := Vars{
+		"errors": numbers(
+			makeNumber("errors", data.Labels{"host": "a", "code": "500"}, float64Pointer(4)),
+			makeNumber("errors", data.Labels{"host": "a", "code": "502"}, float64Pointer(2)),
+			makeNumber("errors", data.Labels{"host": "b", "code": "500"}, float64Pointer(1)),
+		),
+		"requests": numbers(
+			makeNumber("requests", data.Labels{"host": "a", "service": "api"}, float64Pointer(100)),
+			makeNumber("requests", data.Labels{"host": "b", "service": "api"}, float64Pointer(10)),
+		),
+		"errors500": numbers(
+			makeNumber("errors", data.Labels{"host": "a", "code": "500"}, float64Pointer(4)),
+			makeNumber("errors", data.Labels{"host": "b", "code": "500"}, float64Pointer(1)),
+		),
+		"hosts": numbers(
+			makeNumber("hosts", data.Labels{"host": "a"}, float64Pointer(2)),
+			makeNumber("hosts", data.Labels{"host": "c"}, float64Pointer(3)),
+		),
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected Values
+	}{
+		{
+			name: "one-to-one on",
+			expr: "$errors500 / on(host) $requests",
+			expected: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0.04)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0.1)),
+			},
+		},
+		{
+			name: "one-to-one ignoring",
+			expr: "$errors500 / ignoring(code, service) $requests",
+			expected: Values{
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(0.04)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(0.1)),
+			},
+		},
+		{
+			name: "many-to-one with group_left",
+			expr: "$errors / on(host) group_left(service) $requests",
+			expected: Values{
+				makeNumber("", data.Labels{"host": "a", "code": "500", "service": "api"}, float64Pointer(0.04)),
+				makeNumber("", data.Labels{"host": "a", "code": "502", "service": "api"}, float64Pointer(0.02)),
+				makeNumber("", data.Labels{"host": "b", "code": "500", "service": "api"}, float64Pointer(0.1)),
+			},
+		},
+		{
+			name: "one-to-many with group_right keeps the order of the operands",
+			expr: "$requests / on(host) group_right $errors",
+			expected: Values{
+				makeNumber("", data.Labels{"host": "a", "code": "500"}, float64Pointer(25)),
+				makeNumber("", data.Labels{"host": "a", "code": "502"}, float64Pointer(50)),
+				makeNumber("", data.Labels{"host": "b", "code": "500"}, float64Pointer(10)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tt.expected))
+			for i, v := range tt.expected {
+				require.Equal(t, v.GetLabels(), res.Values[i].GetLabels())
+				require.InDelta(t, *v.(Number).GetFloat64Value(), *res.Values[i].(Number).GetFloat64Value(), 1e-9)
+			}
+		})
+	}
+
+	t.Run("series are matched point by point", func(t *testing.T) {
+		seriesVars := Vars{
+			"A": numbers(makeSeries("A", data.Labels{"host": "a", "code": "500"}, tp{time.Unix(5, 0), float64Pointer(2)})),
+			"B": numbers(makeSeries("B", data.Labels{"host": "a", "dc": "eu"}, tp{time.Unix(5, 0), float64Pointer(4)})),
+		}
+		e, err := New("$A / on(host) $B")
+		require.NoError(t, err)
+		res, err := e.Execute("", seriesVars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, Values{makeSeries("", data.Labels{"host": "a"}, tp{time.Unix(5, 0), float64Pointer(0.5)})}, res.Values)
+	})
+
+	t.Run("unmatched items are reported in a notice", func(t *testing.T) {
+		e, err := New("$errors500 + on(host) $hosts")
+		require.NoError(t, err)
+		res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		notices := res.Values[0].AsDataFrame().Meta.Notices
+		require.Len(t, notices, 1)
+		require.Equal(t, `2 items without a match in vector matching(s): ["$errors500 + on(host) $hosts": $errors500{code=500, host=b} has no match for {host=b}; $hosts{host=c} has no match for {host=c}]`, notices[0].Text)
+	})
+
+	t.Run("no data with a notice when nothing matches", func(t *testing.T) {
+		e, err := New("$errors500 + ignoring(code) $requests")
+		require.NoError(t, err)
+		res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+		require.Contains(t, res.Values[0].AsDataFrame().Meta.Notices[0].Text, "4 items without a match")
+	})
+
+	errorTests := []struct {
+		name string
+		expr string
+		err  string
+	}{
+		{
+			name: "duplicates without group_left",
+			expr: "$errors / on(host) $requests",
+			err:  "found duplicate series for the match group {host=a} on the $errors side of $errors / on(host) $requests: use group_left or group_right",
+		},
+		{
+			name: "duplicates on the one side",
+			expr: "$requests / on(host) group_left $errors",
+			err:  "found duplicate series for the match group {host=a} on the $errors side",
+		},
+		{
+			name: "results with the same labels",
+			expr: "$errors / on(host) group_left(code) $hosts",
+			err:  "multiple matches for labels {host=a}",
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			_, err = e.Execute("", vars, tracing.InitializeTracerForTest())
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
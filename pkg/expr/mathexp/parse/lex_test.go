@@ -113,6 +113,22 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"vector matching", "$A / on(host, k8s_pod) group_left(env) $B", []item{
+		{itemVar, 0, "$A"},
+		tDiv,
+		{itemFunc, 0, "on"},
+		{itemLeftParen, 0, "("},
+		{itemFunc, 0, "host"},
+		{itemComma, 0, ","},
+		{itemFunc, 0, "k8s_pod"},
+		{itemRightParen, 0, ")"},
+		{itemFunc, 0, "group_left"},
+		{itemLeftParen, 0, "("},
+		{itemFunc, 0, "env"},
+		{itemRightParen, 0, ")"},
+		{itemVar, 0, "$B"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
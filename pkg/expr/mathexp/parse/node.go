@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -300,6 +301,9 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is how the values of both arguments are matched by their labels,
+	// or nil if the operator has no on or ignoring clause.
+	Matching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,11 +312,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -331,6 +341,50 @@ func (b *BinaryNode) Return() ReturnType {
 	return t0
 }
 
+// VectorMatchCardinality is the number of values on each side of a binary operation that can match each other.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne matches a value on each side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne matches many values on the left side with one on the right side: group_left.
+	CardManyToOne
+	// CardOneToMany matches one value on the left side with many on the right side: group_right.
+	CardOneToMany
+)
+
+// VectorMatching holds the on or ignoring clause of a binary operation, and its optional group_left or group_right clause,
+// like vector matching in Prometheus.
+type VectorMatching struct {
+	Card VectorMatchCardinality
+	// On is true if values are matched on the MatchingLabels, and false if they are matched on all labels
+	// but the MatchingLabels.
+	On             bool
+	MatchingLabels []string
+	// Include are the labels copied from the "one" side to the result of group_left and group_right.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching as it is written in an expression.
+func (m *VectorMatching) String() string {
+	s := fmt.Sprintf("ignoring(%s)", strings.Join(m.MatchingLabels, ", "))
+	if m.On {
+		s = fmt.Sprintf("on(%s)", strings.Join(m.MatchingLabels, ", "))
+	}
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left"
+	case CardOneToMany:
+		s += " group_right"
+	default:
+		return s
+	}
+	if len(m.Include) > 0 {
+		s += fmt.Sprintf("(%s)", strings.Join(m.Include, ", "))
+	}
+	return s
+}
+
 // UnaryNode holds one argument and an operator.
 type UnaryNode struct {
 	NodeType
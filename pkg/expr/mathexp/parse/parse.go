@@ -165,16 +165,18 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [Match] A}
+A -> C {"&&" [Match] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [Match] P}
+P -> M {( "+" | "-" ) [Match] M}
+M -> E {( "*" | "/" ) [Match] F}
+E -> F {( "**" ) [Match] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Match -> ( "on" | "ignoring" ) Labels [( "group_left" | "group_right" ) [Labels]]
+Labels -> "(" [label {"," label}] ")"
 */
 
 // expr:
@@ -185,7 +187,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +200,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +213,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +226,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +239,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +252,94 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary parses the optional Match of a binary operator, then its right hand side with rhs.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) Node {
+	matching := t.vectorMatching()
+	n := newBinary(operator, lhs, rhs())
+	if matching == nil {
+		return n
+	}
+	if n.Args[0].Return() == TypeScalar || n.Args[1].Return() == TypeScalar {
+		t.errorf("vector matching %s is not allowed with a scalar in %s %s %s", matching, n.Args[0], operator.val, n.Args[1])
+	}
+	n.Matching = matching
+	return n
+}
+
+// vectorMatching is Match in the grammar. It returns nil if the next token does not start a Match.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc {
+		return nil
+	}
+	switch token.val {
+	case "on", "ignoring":
+	case "group_left", "group_right":
+		t.errorf("%s must follow on(...) or ignoring(...)", token.val)
+	default:
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		On:             token.val == "on",
+		MatchingLabels: t.labels(token.val),
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labels(token.val)
+	}
+	if m.On {
+		for _, l := range m.Include {
+			for _, ml := range m.MatchingLabels {
+				if l == ml {
+					t.errorf("label %s must not be in both on(...) and %s(...)", l, token.val)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// labels is Labels in the grammar.
+func (t *Tree) labels(context string) []string {
+	labels := []string{}
+	t.expect(itemLeftParen, context)
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+			// continue to the next label
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -332,6 +415,14 @@ func (t *Tree) Func() (f *FuncNode) {
 		case itemRightParen:
 			return
 		}
+		switch token = t.next(); token.typ {
+		case itemComma:
+			// continue to the next argument
+		case itemRightParen:
+			return
+		default:
+			t.unexpected(token, "func")
+		}
 	}
 }
 
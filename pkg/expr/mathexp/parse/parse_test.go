@@ -0,0 +1,93 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVectorMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		matching *VectorMatching
+		text     string
+	}{
+		{
+			name:     "on",
+			expr:     "$A / on(host, region) $B",
+			matching: &VectorMatching{On: true, MatchingLabels: []string{"host", "region"}},
+			text:     "$A / on(host, region) $B",
+		},
+		{
+			name:     "ignoring with no labels",
+			expr:     "$A+ignoring()$B",
+			matching: &VectorMatching{MatchingLabels: []string{}},
+			text:     "$A + ignoring() $B",
+		},
+		{
+			name:     "group_left with labels",
+			expr:     "$A * on(host) group_left(env, team) $B",
+			matching: &VectorMatching{Card: CardManyToOne, On: true, MatchingLabels: []string{"host"}, Include: []string{"env", "team"}},
+			text:     "$A * on(host) group_left(env, team) $B",
+		},
+		{
+			name:     "group_right without labels",
+			expr:     "$A > ignoring(code) group_right $B",
+			matching: &VectorMatching{Card: CardOneToMany, MatchingLabels: []string{"code"}},
+			text:     "$A > ignoring(code) group_right $B",
+		},
+		{
+			name:     "function on the right side",
+			expr:     "$A - on(host) abs($B)",
+			matching: &VectorMatching{On: true, MatchingLabels: []string{"host"}},
+			text:     "$A - on(host) abs($B)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := Parse(tt.expr, testFuncs)
+			require.NoError(t, err)
+			node, ok := tree.Root.(*BinaryNode)
+			require.True(t, ok)
+			require.Equal(t, tt.matching, node.Matching)
+			require.Equal(t, tt.text, tree.String())
+		})
+	}
+
+	t.Run("without matching", func(t *testing.T) {
+		tree, err := Parse("$A / $B")
+		require.NoError(t, err)
+		require.Nil(t, tree.Root.(*BinaryNode).Matching)
+	})
+}
+
+func TestParseVectorMatchingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		err  string
+	}{
+		{name: "group_left without on", expr: "$A / group_left $B", err: "group_left must follow on(...) or ignoring(...)"},
+		{name: "missing labels", expr: "$A / on $B", err: "unexpected"},
+		{name: "unterminated labels", expr: "$A / on(host $B", err: "unexpected"},
+		{name: "label in on and group_left", expr: "$A / on(host) group_left(host) $B", err: "label host must not be in both on(...) and group_left(...)"},
+		{name: "scalar", expr: "$A / on(host) 2", err: "is not allowed with a scalar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+var testFuncs = map[string]Func{
+	"abs": {
+		Args:          []ReturnType{TypeVariantSet},
+		VariantReturn: true,
+		F:             func() {},
+	},
+}
@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -20,11 +23,60 @@ const (
 	ReducerMax   ReducerID = "max"
 	ReducerCount ReducerID = "count"
 	ReducerLast  ReducerID = "last"
+
+	ReducerFirst        ReducerID = "first"
+	ReducerMedian       ReducerID = "median"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerRange        ReducerID = "range"
+	ReducerCountNonNull ReducerID = "count_non_null"
+	ReducerP95          ReducerID = "p95"
+	ReducerP99          ReducerID = "p99"
 )
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// percentileReducerPrefix is the prefix of the parameterized percentile reducer,
+// which takes the percentile as a fraction between 0 and 1, e.g. "percentile(0.95)".
+const percentileReducerPrefix = "percentile("
+
+// PercentileReducerPattern is the regular expression, in the syntax of JSON schema patterns, of the parameterized
+// percentile reducer with a percentile between 0 and 1.
+const PercentileReducerPattern = `^percentile\((0?\.[0-9]+|0\.?|1(\.0*)?)\)$`
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// The parameterized percentile reducer is supported as well but is not part of the collection.
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast,
+		ReducerFirst, ReducerMedian, ReducerStdDev, ReducerRange, ReducerCountNonNull, ReducerP95, ReducerP99,
+	}
+}
+
+// NewPercentileReducer returns the ReducerID of the parameterized percentile reducer for
+// the percentile p, which is a fraction between 0 and 1.
+func NewPercentileReducer(p float64) ReducerID {
+	return ReducerID(percentileReducerPrefix + strconv.FormatFloat(p, 'f', -1, 64) + ")")
+}
+
+// NormalizeReducerID lower-cases the reducer and removes any whitespace,
+// so that "Percentile( 0.95 )" and "percentile(0.95)" are the same reducer.
+func NormalizeReducerID(s string) ReducerID {
+	return ReducerID(strings.Join(strings.Fields(strings.ToLower(s)), ""))
+}
+
+// parsePercentileReducer returns the percentile of a parameterized percentile reducer.
+// ok is false if rFunc is not a percentile reducer.
+func parsePercentileReducer(rFunc ReducerID) (p float64, ok bool, err error) {
+	s := string(rFunc)
+	if !strings.HasPrefix(s, percentileReducerPrefix) || !strings.HasSuffix(s, ")") {
+		return 0, false, nil
+	}
+	p, err = strconv.ParseFloat(s[len(percentileReducerPrefix):len(s)-1], 64)
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to parse percentile of reducer %v: %w", rFunc, err)
+	}
+	if math.IsNaN(p) || p < 0 || p > 1 {
+		return 0, true, fmt.Errorf("percentile of reducer %v must be between 0 and 1", rFunc)
+	}
+	return p, true, nil
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,6 +150,85 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// CountNonNull returns the number of values that are not null. NaN and Inf values are counted.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if fv.GetValue(i) != nil {
+			f++
+		}
+	}
+	return &f
+}
+
+// Range returns the difference between the maximum and the minimum value.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	mean := *Avg(fv)
+	if fv.Len() == 0 || math.IsNaN(mean) { // Avg is NaN if there is a null or NaN value
+		nan := math.NaN()
+		return &nan
+	}
+	var sumSq float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - mean
+		sumSq += d * d
+	}
+	f := math.Sqrt(sumSq / float64(fv.Len()))
+	return &f
+}
+
+// Percentile returns the p-th percentile of the values, where p is a fraction between 0 and 1.
+// It linearly interpolates between the two closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		values = append(values, *v)
+	}
+	if len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	sort.Float64s(values)
+	rank := p * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower]
+	if lower != upper {
+		f += (values[upper] - values[lower]) * (rank - float64(lower))
+	}
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(fv, 0.5)
+}
+
+func percentileFunc(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		return Percentile(fv, p)
+	}
+}
+
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -112,7 +243,28 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Count, nil
 	case ReducerLast:
 		return Last, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerMedian:
+		return Median, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerRange:
+		return Range, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
+	case ReducerP95:
+		return percentileFunc(0.95), nil
+	case ReducerP99:
+		return percentileFunc(0.99), nil
 	default:
+		p, ok, err := parsePercentileReducer(rFunc)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return percentileFunc(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
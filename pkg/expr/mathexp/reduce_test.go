@@ -3,6 +3,7 @@ package mathexp
 import (
 	"math"
 	"math/rand"
+	"regexp"
 	"testing"
 	"time"
 
@@ -204,6 +205,80 @@ func TestSeriesReduce(t *testing.T) {
 	}
 }
 
+func TestSeriesReduceAdditionalReducers(t *testing.T) {
+	series := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("temp", nil,
+				tp{time.Unix(5, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(25, 0), float64Pointer(5)},
+			),
+		),
+	}
+
+	var tests = []struct {
+		name   string
+		red    ReducerID
+		vars   Vars
+		mapper ReduceMapper
+		errIs  require.ErrorAssertionFunc
+		result *float64
+	}{
+		{name: "first", red: ReducerFirst, vars: series, errIs: require.NoError, result: float64Pointer(4)},
+		{name: "first of empty series", red: ReducerFirst, vars: seriesEmpty, errIs: require.NoError, result: NaN},
+		{name: "median", red: ReducerMedian, vars: series, errIs: require.NoError, result: float64Pointer(3)},
+		{name: "median of series with nil value", red: ReducerMedian, vars: seriesWithNil, errIs: require.NoError, result: NaN},
+		{name: "median of series with nil value dropNN", red: ReducerMedian, vars: seriesWithNil, mapper: DropNonNumber{}, errIs: require.NoError, result: float64Pointer(2)},
+		{name: "median of empty series dropNN", red: ReducerMedian, vars: seriesEmpty, mapper: DropNonNumber{}, errIs: require.NoError, result: nil},
+		{name: "stddev", red: ReducerStdDev, vars: series, errIs: require.NoError, result: float64Pointer(math.Sqrt2)},
+		{name: "stddev of series with nil value replaceNN", red: ReducerStdDev, vars: seriesWithNil, mapper: ReplaceNonNumberWithValue{Value: 4}, errIs: require.NoError, result: float64Pointer(1)},
+		{name: "range", red: ReducerRange, vars: series, errIs: require.NoError, result: float64Pointer(4)},
+		{name: "range of series with nil value", red: ReducerRange, vars: seriesWithNil, errIs: require.NoError, result: NaN},
+		{name: "count_non_null", red: ReducerCountNonNull, vars: seriesWithNil, errIs: require.NoError, result: float64Pointer(1)},
+		{name: "p95", red: ReducerP95, vars: series, errIs: require.NoError, result: float64Pointer(4.8)},
+		{name: "p99 of empty series", red: ReducerP99, vars: seriesEmpty, errIs: require.NoError, result: NaN},
+		{name: "percentile(0.25)", red: NewPercentileReducer(0.25), vars: series, errIs: require.NoError, result: float64Pointer(2)},
+		{name: "percentile(1)", red: "percentile(1)", vars: series, errIs: require.NoError, result: float64Pointer(5)},
+		{name: "percentile out of range will error", red: "percentile(1.5)", vars: series, errIs: require.Error},
+		{name: "percentile without value will error", red: "percentile()", vars: series, errIs: require.Error},
+	}
+
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, series := range tt.vars["A"].Values {
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				if diff := cmp.Diff(tt.result, ns.GetFloat64Value(), options...); diff != "" {
+					t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentileReducerPattern(t *testing.T) {
+	pattern := regexp.MustCompile(PercentileReducerPattern)
+	for _, rFunc := range []string{"percentile(0)", "percentile(0.25)", "percentile(.5)", "percentile(1)", "percentile(1.0)", string(NewPercentileReducer(0.999))} {
+		require.True(t, pattern.MatchString(rFunc), rFunc)
+		_, ok, err := parsePercentileReducer(ReducerID(rFunc))
+		require.True(t, ok, rFunc)
+		require.NoError(t, err, rFunc)
+	}
+	for _, rFunc := range []string{"percentile()", "percentile(1.5)", "percentile(2)", "percentile(-0.5)", "percentile(a)", "p95"} {
+		require.False(t, pattern.MatchString(rFunc), rFunc)
+	}
+}
+
 var seriesNonNumbers = Vars{
 	"A": resultValuesNoErr(
 		makeSeries("temp", nil,
@@ -28,6 +28,10 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
 	}
+	downsample, err := GetReduceFunc(downsampler)
+	if err != nil {
+		return s, fmt.Errorf("invalid downsampler: %w", err)
+	}
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
@@ -74,22 +78,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
-			}
-			value = tmp
+			value = downsample(&ff)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -49,6 +49,47 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(7, 0), float64Pointer(1),
 			}),
 		},
+		{
+			name:        "resample series: unknown downsampler",
+			interval:    time.Second * 5,
+			downsampler: "mode",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(16, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (range / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "range",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(9, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}),
+		},
 		{
 			name:        "resample series: downsampling (mean / pad)",
 			interval:    time.Second * 5,
@@ -130,6 +130,29 @@ func (n Number) GetFloat64Value() *float64 {
 	return n.Frame.At(0, 0).(*float64)
 }
 
+// GetFields returns the non-numeric fields that follow the value of the Number, such as the string columns
+// of the table row the Number was read from. Each field holds a single value.
+func (n Number) GetFields() []*data.Field {
+	return n.Frame.Fields[1:]
+}
+
+// AddFields appends copies of the non-numeric fields to the Number, skipping the fields whose name is already
+// used by a field of the Number. Each field must hold a single value.
+func (n Number) AddFields(fields ...*data.Field) {
+	for _, field := range fields {
+		if _, idx := n.Frame.FieldByName(field.Name); idx != -1 {
+			continue
+		}
+		copied := data.NewFieldFromFieldType(field.Type(), field.Len())
+		copied.Name = field.Name
+		copied.Labels = field.Labels.Copy()
+		for i := 0; i < field.Len(); i++ {
+			copied.Set(i, field.CopyAt(i))
+		}
+		n.Frame.Fields = append(n.Frame.Fields, copied)
+	}
+}
+
 // NewNumber returns a data that holds a float64Vector
 func NewNumber(name string, labels data.Labels) Number {
 	return Number{
@@ -323,3 +323,16 @@ func TestSeriesName(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberAddFields(t *testing.T) {
+	field := data.NewField("host", nil, []string{"a"})
+	n := NewNumber("", nil)
+	n.AddFields(field, data.NewField("host", nil, []string{"b"}))
+
+	require.Len(t, n.GetFields(), 1)
+	require.Equal(t, "a", n.GetFields()[0].At(0))
+	require.NotSame(t, field, n.GetFields()[0])
+
+	field.Set(0, "changed")
+	require.Equal(t, "a", n.GetFields()[0].At(0))
+}
@@ -27,6 +27,9 @@ const (
 
 	// SQL query via DuckDB
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect anomalies in time series
+	QueryTypeAnomaly QueryType = "anomaly"
 )
 
 type MathQuery struct {
@@ -77,6 +80,54 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to the time series to check for anomalies
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The method used to compute the baseline and the bands
+	Method AnomalyMethod `json:"method"`
+
+	// The number of deviations between the baseline and the bands, 3 by default
+	Sensitivity *float64 `json:"sensitivity,omitempty" jsonschema:"example=3"`
+
+	// The length of a season, the baseline is not seasonal when empty
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The series returned for each input series, score by default
+	Output AnomalyOutput `json:"output,omitempty"`
+}
+
+// Anomaly detection method
+// +enum
+type AnomalyMethod string
+
+const (
+	// Mean and standard deviation
+	AnomalyMethodZScore AnomalyMethod = "zscore"
+
+	// Median and median absolute deviation
+	AnomalyMethodMAD AnomalyMethod = "mad"
+
+	// Forecast of additive Holt-Winters exponential smoothing
+	AnomalyMethodHoltWinters AnomalyMethod = "holt_winters"
+)
+
+// Anomaly detection output
+// +enum
+type AnomalyOutput string
+
+const (
+	// The anomaly score of each point
+	AnomalyOutputScore AnomalyOutput = "score"
+
+	// The lower band, the baseline and the upper band
+	AnomalyOutputBands AnomalyOutput = "bands"
+
+	// The anomaly score and the bands
+	AnomalyOutputAll AnomalyOutput = "all"
+)
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -7,6 +7,7 @@ import (
 
 	data "github.com/grafana/grafana-plugin-sdk-go/experimental/apis/data/v0alpha1"
 	"github.com/grafana/grafana-plugin-sdk-go/experimental/schemabuilder"
+	"github.com/invopop/jsonschema"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/expr/classic"
@@ -27,9 +28,29 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(AnomalyMethodZScore),
+				reflect.TypeOf(AnomalyOutputScore),
 			},
 		})
 	require.NoError(t, err)
+
+	// The parameterized percentile reducer is not one of the constants of the enum,
+	// so reducers are either one of the constants or match the percentile pattern.
+	mapper := builder.Reflector().Mapper
+	builder.Reflector().Mapper = func(t reflect.Type) *jsonschema.Schema {
+		if t != reflect.TypeOf(mathexp.ReducerSum) {
+			return mapper(t)
+		}
+		enum := mapper(t)
+		return &jsonschema.Schema{
+			Type: "string",
+			AnyOf: []*jsonschema.Schema{
+				{Enum: enum.Enum, Extras: enum.Extras},
+				{Pattern: mathexp.PercentileReducerPattern, Description: "The percentile p of the values, a fraction between 0 and 1, e.g. percentile(0.95)"},
+			},
+		}
+	}
+
 	err = builder.AddQueries(
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeMath),
@@ -63,6 +84,13 @@ func TestQueryTypeDefinitions(t *testing.T) {
 						},
 					}),
 				},
+				{
+					Name: "get the 90th percentile",
+					SaveModel: data.AsUnstructured(ReduceQuery{
+						Expression: "$A",
+						Reducer:    mathexp.NewPercentileReducer(0.9),
+					}),
+				},
 			},
 		},
 		schemabuilder.QueryTypeInfo{
@@ -161,6 +189,21 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Daily seasonal bands of query A",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Method:     AnomalyMethodHoltWinters,
+						Season:     "1d",
+						Output:     AnomalyOutputBands,
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 )
 
@@ -35,11 +36,13 @@ func (q ExpressionQuery) ID() int64 {
 
 type ExpressionQueryReader struct {
 	features featuremgmt.FeatureToggles
+	cfg      *setting.Cfg
 }
 
-func NewExpressionQueryReader(features featuremgmt.FeatureToggles) *ExpressionQueryReader {
+func NewExpressionQueryReader(features featuremgmt.FeatureToggles, cfg *setting.Cfg) *ExpressionQueryReader {
 	return &ExpressionQueryReader{
 		features: features,
+		cfg:      cfg,
 	}
 }
 
@@ -89,7 +92,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			eq.Properties = q
 			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+				mathexp.NormalizeReducerID(string(q.Reducer)), referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -107,7 +110,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Command, err = NewResampleCommand(common.RefID,
 				q.Window,
 				referenceVar,
-				q.Downsampler,
+				mathexp.NormalizeReducerID(string(q.Downsampler)),
 				q.Upsampler,
 				AbsoluteTimeRange{
 					From: tr.GetFromAsTimeUTC(),
@@ -129,7 +132,15 @@ func (h *ExpressionQueryReader) ReadQuery(
 		err = iter.ReadVal(q)
 		if err == nil {
 			eq.Properties = q
-			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
+			eq.Command, err = NewSQLCommand(common.RefID, q.Expression, h.cfg)
+		}
+
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = newAnomalyCommandFromQuery(common.RefID, *q)
 		}
 
 	case QueryTypeThreshold:
@@ -0,0 +1,157 @@
+package sql
+
+// The types in this file are the syntax tree of the statements supported by the embedded engine.
+// Expressions are value types so that two expressions written the same way compare equal,
+// which is how GROUP BY expressions are matched with the select list.
+
+// selectStmt is a complete query, with its CTEs and the clauses that apply to the result of a UNION.
+type selectStmt struct {
+	with    []cte
+	body    queryBody
+	orderBy []orderItem
+	limit   expr
+	offset  expr
+}
+
+type cte struct {
+	name    string
+	columns []string
+	query   *selectStmt
+}
+
+// queryBody is either a *selectCore or a *setOp.
+type queryBody interface{}
+
+type selectCore struct {
+	distinct bool
+	items    []selectItem
+	// from is nil for a SELECT without a FROM clause.
+	from    tableExpr
+	where   expr
+	groupBy []expr
+	having  expr
+}
+
+// setOp is a UNION of two queries. Other set operations are not supported.
+type setOp struct {
+	all         bool
+	left, right queryBody
+}
+
+type selectItem struct {
+	expr expr
+	// alias is the name given with AS, or the text of the expression otherwise.
+	alias string
+	// hasAlias is true if the name was given with AS.
+	hasAlias bool
+	// star is set for * and t.* items, in which case table holds the optional qualifier.
+	star  bool
+	table string
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+	// nullsFirst defaults to false, so nulls sort last in either direction.
+	nullsFirst bool
+}
+
+// tableExpr is a tableName, a subqueryTable or a joinTable.
+type tableExpr interface{}
+
+type tableName struct {
+	name  string
+	alias string
+	pos   int
+}
+
+type subqueryTable struct {
+	query *selectStmt
+	alias string
+}
+
+type joinKind int
+
+const (
+	joinInner joinKind = iota
+	joinLeft
+	joinRight
+	joinFull
+	joinCross
+)
+
+type joinTable struct {
+	kind        joinKind
+	left, right tableExpr
+	on          expr
+	using       []string
+}
+
+// expr is any of the expression types below.
+type expr interface{}
+
+type literal struct {
+	val value
+}
+
+type columnRef struct {
+	table string
+	name  string
+}
+
+// starExpr is the argument of COUNT(*).
+type starExpr struct{}
+
+type unaryExpr struct {
+	op string
+	x  expr
+}
+
+type binaryExpr struct {
+	op   string
+	l, r expr
+}
+
+type isNullExpr struct {
+	x   expr
+	not bool
+}
+
+type inExpr struct {
+	x    expr
+	list []expr
+	// query is set instead of list for IN (SELECT ...).
+	query *selectStmt
+	not   bool
+}
+
+type betweenExpr struct {
+	x, lo, hi expr
+	not       bool
+}
+
+type whenClause struct {
+	cond, result expr
+}
+
+type caseExpr struct {
+	operand expr
+	whens   []whenClause
+	els     expr
+}
+
+type castExpr struct {
+	x   expr
+	typ string
+}
+
+type funcCall struct {
+	name     string
+	args     []expr
+	distinct bool
+}
+
+// subqueryExpr is a subquery used as a scalar value.
+type subqueryExpr struct {
+	query *selectStmt
+}
@@ -0,0 +1,269 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	// ErrRowLimitExceeded is returned when a query creates more rows than allowed by its limits.
+	ErrRowLimitExceeded = errors.New("row limit exceeded")
+	// ErrMemoryLimitExceeded is returned when the rows of a query use more memory than allowed by its limits.
+	ErrMemoryLimitExceeded = errors.New("memory limit exceeded")
+)
+
+// Limits are the limits enforced when running a query with the embedded engine. A limit of zero is no limit.
+type Limits struct {
+	// MaxRows is the maximum number of rows of the input tables, of the result and of every step in between,
+	// such as a JOIN.
+	MaxRows int64
+	// MaxBytes is the maximum approximate size of all the rows that a query reads and creates.
+	MaxBytes int64
+}
+
+// QueryFrames runs a SELECT statement over frames in process, without writing them anywhere.
+// Each table of the statement is the union of the frames with that RefID, where the columns are the
+// fields of the frames, matched by name, and the labels of the fields as string columns.
+// The result is a single frame with the given name.
+func QueryFrames(ctx context.Context, name, rawSQL string, frames []*data.Frame, limits Limits) (*data.Frame, error) {
+	stmt, err := parseStatement(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := &executor{ctx: ctx, limits: limits, tables: map[string]*relation{}, ctes: map[string]*relation{}}
+	var refIDs []string
+	byRefID := map[string][]*data.Frame{}
+	for _, f := range frames {
+		if _, ok := byRefID[f.RefID]; !ok {
+			refIDs = append(refIDs, f.RefID)
+		}
+		byRefID[f.RefID] = append(byRefID[f.RefID], f)
+	}
+	for _, refID := range refIDs {
+		rel, err := ex.relationFromFrames(byRefID[refID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frames of %s: %w", refID, err)
+		}
+		ex.tables[refID] = rel
+	}
+
+	rel, err := ex.execQuery(stmt)
+	if err != nil {
+		return nil, err
+	}
+	return frameFromRelation(name, rel), nil
+}
+
+// relationFromFrames reads frames into a table. A column that is missing from one of the frames is NULL for its rows.
+func (e *executor) relationFromFrames(frames []*data.Frame) (*relation, error) {
+	rel := &relation{}
+	index := map[string]int{}
+	addColumn := func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		index[name] = len(rel.cols)
+		rel.cols = append(rel.cols, column{name: name})
+		return len(rel.cols) - 1
+	}
+
+	type source struct {
+		field *data.Field
+		label string
+		col   int
+	}
+	perFrame := make([][]source, len(frames))
+	fieldNames := map[string]struct{}{}
+	for i, f := range frames {
+		for _, field := range f.Fields {
+			perFrame[i] = append(perFrame[i], source{field: field, col: addColumn(field.Name)})
+			fieldNames[field.Name] = struct{}{}
+		}
+	}
+	// labels are added after the fields, so that a field always takes precedence over a label with the same name
+	for i, f := range frames {
+		for _, field := range f.Fields {
+			keys := maps.Keys(field.Labels)
+			slices.Sort(keys)
+			for _, key := range keys {
+				if _, ok := fieldNames[key]; ok {
+					continue
+				}
+				perFrame[i] = append(perFrame[i], source{label: field.Labels[key], col: addColumn(key)})
+			}
+		}
+	}
+
+	for i, f := range frames {
+		n, err := f.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for r := 0; r < n; r++ {
+			row := make([]value, len(rel.cols))
+			for _, s := range perFrame[i] {
+				if s.field == nil {
+					row[s.col] = s.label
+					continue
+				}
+				row[s.col] = fieldValue(s.field.At(r))
+			}
+			if rel.rows, err = e.addRow(rel.rows, row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rel, nil
+}
+
+// fieldValue converts a value of a field to a value of the engine.
+func fieldValue(v any) value {
+	switch n := v.(type) {
+	case nil:
+		return nil
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case uint64:
+		if n > math.MaxInt64 {
+			return float64(n)
+		}
+		return int64(n)
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	case string:
+		return n
+	case bool:
+		return n
+	case time.Time:
+		return n
+	case json.RawMessage:
+		return string(n)
+	case data.EnumItemIndex:
+		return int64(n)
+	case *int8:
+		return derefValue(n)
+	case *int16:
+		return derefValue(n)
+	case *int32:
+		return derefValue(n)
+	case *int64:
+		return derefValue(n)
+	case *uint8:
+		return derefValue(n)
+	case *uint16:
+		return derefValue(n)
+	case *uint32:
+		return derefValue(n)
+	case *uint64:
+		return derefValue(n)
+	case *float32:
+		return derefValue(n)
+	case *float64:
+		return derefValue(n)
+	case *string:
+		return derefValue(n)
+	case *bool:
+		return derefValue(n)
+	case *time.Time:
+		return derefValue(n)
+	case *json.RawMessage:
+		return derefValue(n)
+	case *data.EnumItemIndex:
+		return derefValue(n)
+	}
+	return fmt.Sprint(v)
+}
+
+func derefValue[T any](p *T) value {
+	if p == nil {
+		return nil
+	}
+	return fieldValue(*p)
+}
+
+// frameFromRelation converts the result of a query to a frame. The type of each field is the type
+// of the values of its column, and fields are only nullable if the column has NULL values.
+func frameFromRelation(name string, rel *relation) *data.Frame {
+	frame := data.NewFrame(name)
+	for i, c := range rel.cols {
+		kind := ""
+		nullable := false
+		for _, row := range rel.rows {
+			v := row[i]
+			if v == nil {
+				nullable = true
+				continue
+			}
+			k := typeName(v)
+			switch {
+			case kind == "":
+				kind = k
+			case kind == k:
+			case isNumber(v) && (kind == typeBigint || kind == typeDouble):
+				kind = typeDouble
+			default:
+				kind = typeVarchar
+			}
+		}
+		frame.Fields = append(frame.Fields, newField(c.name, kind, nullable, rel.rows, i))
+	}
+	return frame
+}
+
+func newField(name, kind string, nullable bool, rows [][]value, col int) *data.Field {
+	switch kind {
+	case typeBigint:
+		return buildField(name, nullable, rows, col, func(v value) int64 { return v.(int64) })
+	case typeDouble:
+		return buildField(name, nullable, rows, col, func(v value) float64 { f, _ := toFloat(v); return f })
+	case typeBoolean:
+		return buildField(name, nullable, rows, col, func(v value) bool { return v.(bool) })
+	case typeTimestamp:
+		return buildField(name, nullable, rows, col, func(v value) time.Time { return v.(time.Time) })
+	case typeVarchar:
+		return buildField(name, nullable, rows, col, formatValue)
+	}
+	// a column that only has NULL values
+	return data.NewField(name, nil, make([]*float64, len(rows)))
+}
+
+func buildField[T any](name string, nullable bool, rows [][]value, col int, convert func(value) T) *data.Field {
+	if !nullable {
+		values := make([]T, len(rows))
+		for i, row := range rows {
+			values[i] = convert(row[col])
+		}
+		return data.NewField(name, nil, values)
+	}
+	values := make([]*T, len(rows))
+	for i, row := range rows {
+		if row[col] != nil {
+			v := convert(row[col])
+			values[i] = &v
+		}
+	}
+	return data.NewField(name, nil, values)
+}
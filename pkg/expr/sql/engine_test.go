@@ -0,0 +1,242 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrames() []*data.Frame {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{fp(1), fp(2), nil}),
+	)
+	a.RefID = "A"
+	a2 := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t0, t0.Add(time.Minute)}),
+		data.NewField("value", data.Labels{"host": "b"}, []*float64{fp(10), fp(20)}),
+	)
+	a2.RefID = "A"
+	b := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("region", nil, []string{"eu", "us", "us"}),
+		data.NewField("weight", nil, []int64{1, 2, 3}),
+	)
+	b.RefID = "B"
+	return []*data.Frame{a, a2, b}
+}
+
+func fp(f float64) *float64 {
+	return &f
+}
+
+func ip(i int64) *int64 {
+	return &i
+}
+
+func TestQueryFrames(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		sql      string
+		expected *data.Frame
+	}{
+		{
+			name: "select with where and order by",
+			sql:  "SELECT host, value FROM A WHERE value > 1 ORDER BY value DESC",
+			expected: data.NewFrame("C",
+				data.NewField("host", nil, []string{"b", "b", "a"}),
+				data.NewField("value", nil, []float64{20, 10, 2}),
+			),
+		},
+		{
+			name: "order by a column that is not selected",
+			sql:  "SELECT value FROM A WHERE host = 'a' ORDER BY time DESC",
+			expected: data.NewFrame("C",
+				data.NewField("value", nil, []*float64{nil, fp(2), fp(1)}),
+			),
+		},
+		{
+			name: "aggregates with group by and having",
+			sql: `SELECT host, count(*) AS n, count(value) AS non_null, sum(value) AS total, avg(value), min(time) AS first_seen
+				FROM A GROUP BY host HAVING count(*) > 1 ORDER BY 1`,
+			expected: data.NewFrame("C",
+				data.NewField("host", nil, []string{"a", "b"}),
+				data.NewField("n", nil, []int64{3, 2}),
+				data.NewField("non_null", nil, []int64{2, 2}),
+				data.NewField("total", nil, []float64{3, 30}),
+				data.NewField("avg(value)", nil, []float64{1.5, 15}),
+				data.NewField("first_seen", nil, []time.Time{t0, t0}),
+			),
+		},
+		{
+			name: "aggregate without group by",
+			sql:  "SELECT max(value) - min(value) AS spread, count(DISTINCT host) AS hosts FROM A",
+			expected: data.NewFrame("C",
+				data.NewField("spread", nil, []float64{19}),
+				data.NewField("hosts", nil, []int64{2}),
+			),
+		},
+		{
+			name: "aggregate of no rows",
+			sql:  "SELECT count(*) AS n, sum(value) AS total FROM A WHERE false",
+			expected: data.NewFrame("C",
+				data.NewField("n", nil, []int64{0}),
+				data.NewField("total", nil, []*float64{nil}),
+			),
+		},
+		{
+			name: "join with using",
+			sql:  "SELECT A.time, host, region, value * weight AS weighted FROM A JOIN B USING (host) WHERE value IS NOT NULL ORDER BY host, time",
+			expected: data.NewFrame("C",
+				data.NewField("time", nil, []time.Time{t0, t0.Add(time.Minute), t0, t0.Add(time.Minute)}),
+				data.NewField("host", nil, []string{"a", "a", "b", "b"}),
+				data.NewField("region", nil, []string{"eu", "eu", "us", "us"}),
+				data.NewField("weighted", nil, []float64{1, 2, 20, 40}),
+			),
+		},
+		{
+			name: "left join with on",
+			sql:  "SELECT b.host, sum(a.value) AS total FROM B b LEFT JOIN A a ON a.host = b.host GROUP BY b.host ORDER BY b.host",
+			expected: data.NewFrame("C",
+				data.NewField("host", nil, []string{"a", "b", "c"}),
+				data.NewField("total", nil, []*float64{fp(3), fp(30), nil}),
+			),
+		},
+		{
+			name: "full join on a condition that is not an equality",
+			sql:  "SELECT x.weight AS l, y.weight AS r FROM B x FULL JOIN B y ON x.weight + 1 = y.weight ORDER BY l NULLS FIRST, r",
+			expected: data.NewFrame("C",
+				data.NewField("l", nil, []*int64{nil, ip(1), ip(2), ip(3)}),
+				data.NewField("r", nil, []*int64{ip(1), ip(2), ip(3), nil}),
+			),
+		},
+		{
+			name: "cte, subquery and union",
+			sql: `WITH totals AS (SELECT host, sum(value) AS total FROM A GROUP BY host)
+				SELECT host, total FROM totals WHERE total > (SELECT min(total) FROM totals)
+				UNION ALL SELECT 'all', sum(total) FROM totals
+				ORDER BY total`,
+			expected: data.NewFrame("C",
+				data.NewField("host", nil, []string{"b", "all"}),
+				data.NewField("total", nil, []float64{30, 33}),
+			),
+		},
+		{
+			name: "expressions",
+			sql: `SELECT DISTINCT upper(region) || '-' || CAST(weight AS VARCHAR) AS id,
+				CASE WHEN weight >= 2 THEN 'big' ELSE 'small' END AS size,
+				weight / 2 AS half, weight % 2 AS odd, coalesce(NULL, weight) AS w
+				FROM B WHERE host IN ('a', 'b') AND region LIKE '%u%' AND weight BETWEEN 1 AND 2 ORDER BY id`,
+			expected: data.NewFrame("C",
+				data.NewField("id", nil, []string{"EU-1", "US-2"}),
+				data.NewField("size", nil, []string{"small", "big"}),
+				data.NewField("half", nil, []float64{0.5, 1}),
+				data.NewField("odd", nil, []int64{1, 0}),
+				data.NewField("w", nil, []int64{1, 2}),
+			),
+		},
+		{
+			name: "timestamps compared with strings",
+			sql:  "SELECT date_trunc('hour', time) AS hour, value FROM A WHERE time >= '2024-01-01 00:01:00' AND host = 'a'",
+			expected: data.NewFrame("C",
+				data.NewField("hour", nil, []time.Time{t0, t0}),
+				data.NewField("value", nil, []*float64{fp(2), nil}),
+			),
+		},
+		{
+			name: "star, limit and offset",
+			sql:  "SELECT * FROM B ORDER BY weight DESC LIMIT 1 OFFSET 1",
+			expected: data.NewFrame("C",
+				data.NewField("host", nil, []string{"b"}),
+				data.NewField("region", nil, []string{"us"}),
+				data.NewField("weight", nil, []int64{2}),
+			),
+		},
+		{
+			name: "select without from",
+			sql:  "SELECT 1 + 2 AS three, NULL AS nothing, 'x' AS s",
+			expected: data.NewFrame("C",
+				data.NewField("three", nil, []int64{3}),
+				data.NewField("nothing", nil, []*float64{nil}),
+				data.NewField("s", nil, []string{"x"}),
+			),
+		},
+		{
+			name: "case insensitive names",
+			sql:  "select HOST as h from b where Weight = 3",
+			expected: data.NewFrame("C",
+				data.NewField("h", nil, []string{"c"}),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := QueryFrames(context.Background(), "C", tt.sql, testFrames(), Limits{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, frame)
+		})
+	}
+}
+
+func TestQueryFramesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		err  string
+	}{
+		{name: "unknown table", sql: "SELECT * FROM X", err: `table "X" not found`},
+		{name: "unknown column", sql: "SELECT foo FROM B", err: `column "foo" not found`},
+		{name: "ambiguous column", sql: "SELECT host FROM A JOIN B ON A.host = B.host", err: `column reference "host" is ambiguous`},
+		{name: "column not grouped", sql: "SELECT host, region FROM B GROUP BY host", err: `column "region" must appear in the GROUP BY clause`},
+		{name: "aggregate in where", sql: "SELECT host FROM B WHERE count(*) > 1", err: "aggregate functions are not allowed in WHERE"},
+		{name: "unknown function", sql: "SELECT foo(weight) FROM B", err: "unknown function foo"},
+		{name: "not a boolean", sql: "SELECT host FROM B WHERE weight", err: "WHERE expects a boolean"},
+		{name: "incompatible comparison", sql: "SELECT host FROM B WHERE region > 1", err: `can not cast VARCHAR "eu" to BIGINT`},
+		{name: "union of different columns", sql: "SELECT host FROM B UNION SELECT host, region FROM B", err: "same number of columns"},
+		{name: "window functions", sql: "SELECT sum(weight) OVER () FROM B", err: "OVER clause is not supported"},
+		{name: "not a select", sql: "DELETE FROM B", err: "expected SELECT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := QueryFrames(context.Background(), "C", tt.sql, testFrames(), Limits{})
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestQueryFramesLimits(t *testing.T) {
+	t.Run("row limit of the result", func(t *testing.T) {
+		_, err := QueryFrames(context.Background(), "C", "SELECT * FROM B x CROSS JOIN B y", testFrames(), Limits{MaxRows: 5})
+		require.ErrorIs(t, err, ErrRowLimitExceeded)
+	})
+
+	t.Run("row limit of the input", func(t *testing.T) {
+		_, err := QueryFrames(context.Background(), "C", "SELECT count(*) FROM A", testFrames(), Limits{MaxRows: 4})
+		require.ErrorIs(t, err, ErrRowLimitExceeded)
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		_, err := QueryFrames(context.Background(), "C", "SELECT * FROM B x, B y, B z", testFrames(), Limits{MaxBytes: 4096})
+		require.ErrorIs(t, err, ErrMemoryLimitExceeded)
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		frame, err := QueryFrames(context.Background(), "C", "SELECT * FROM B x, B y", testFrames(), Limits{MaxRows: 9, MaxBytes: 1 << 20})
+		require.NoError(t, err)
+		require.Equal(t, 9, frame.Rows())
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := QueryFrames(ctx, "C", "SELECT * FROM B x, B y, B z, B w, B v, B u, B s", testFrames(), Limits{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,660 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// evalFunc evaluates a compiled expression for a row.
+type evalFunc func(row []value) (value, error)
+
+// column is a column of a relation. table is the name or alias of the table that it comes from.
+type column struct {
+	table string
+	name  string
+	// hidden columns are the right hand side columns of a JOIN ... USING, which can only be
+	// referenced with the table name, and are not part of *.
+	hidden bool
+}
+
+// resolveColumn returns the index of the column that ref refers to. Names are matched exactly
+// first, and then case-insensitively.
+func resolveColumn(cols []column, ref columnRef) (int, error) {
+	for _, match := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		found := -1
+		for i, c := range cols {
+			if ref.table == "" && c.hidden {
+				continue
+			}
+			if ref.table != "" && !strings.EqualFold(ref.table, c.table) {
+				continue
+			}
+			if !match(c.name, ref.name) {
+				continue
+			}
+			if found >= 0 {
+				return 0, fmt.Errorf("column reference %q is ambiguous", formatColumnRef(ref))
+			}
+			found = i
+		}
+		if found >= 0 {
+			return found, nil
+		}
+	}
+	return 0, fmt.Errorf("column %q not found", formatColumnRef(ref))
+}
+
+func formatColumnRef(ref columnRef) string {
+	if ref.table == "" {
+		return ref.name
+	}
+	return ref.table + "." + ref.name
+}
+
+// exprKey returns a string that is the same for expressions that are written the same way.
+func exprKey(e expr) string {
+	return fmt.Sprintf("%#v", e)
+}
+
+// compiler compiles expressions into functions of a row with the given columns.
+type compiler struct {
+	ex   *executor
+	cols []column
+	// group is set when compiling the expressions of an aggregate query.
+	group *groupScope
+}
+
+// groupScope compiles expressions that are evaluated once per group. Their rows hold
+// the values of the GROUP BY expressions followed by the results of the aggregate functions.
+type groupScope struct {
+	source *compiler
+	keys   []string
+	// keyCols maps the index of a source column that is grouped by to the index of its key.
+	keyCols map[int]int
+	aggs    []*aggSpec
+}
+
+type aggSpec struct {
+	arg      evalFunc
+	distinct bool
+	newAgg   func() aggregator
+}
+
+func (s *aggSpec) newAggregator() aggregator {
+	agg := s.newAgg()
+	if s.distinct {
+		return &distinctAgg{agg: agg, seen: map[string]struct{}{}}
+	}
+	return agg
+}
+
+func constant(v value) evalFunc {
+	return func([]value) (value, error) { return v, nil }
+}
+
+func (c *compiler) compile(e expr) (evalFunc, error) {
+	if c.group != nil {
+		if f, ok, err := c.compileGrouped(e); ok || err != nil {
+			return f, err
+		}
+	}
+
+	switch e := e.(type) {
+	case literal:
+		return constant(e.val), nil
+	case columnRef:
+		i, err := resolveColumn(c.cols, e)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []value) (value, error) { return row[i], nil }, nil
+	case starExpr:
+		return nil, fmt.Errorf("* can only be used in COUNT(*)")
+	case unaryExpr:
+		return c.compileUnary(e)
+	case binaryExpr:
+		return c.compileBinary(e)
+	case isNullExpr:
+		x, err := c.compile(e.x)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []value) (value, error) {
+			v, err := x(row)
+			if err != nil {
+				return nil, err
+			}
+			return (v == nil) != e.not, nil
+		}, nil
+	case inExpr:
+		return c.compileIn(e)
+	case betweenExpr:
+		return c.compileBetween(e)
+	case caseExpr:
+		return c.compileCase(e)
+	case castExpr:
+		x, err := c.compile(e.x)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []value) (value, error) {
+			v, err := x(row)
+			if err != nil {
+				return nil, err
+			}
+			return castValue(v, e.typ)
+		}, nil
+	case funcCall:
+		return c.compileFunc(e)
+	case subqueryExpr:
+		return c.compileSubquery(e)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", e)
+}
+
+// compileGrouped compiles the parts of an expression that are specific to aggregate queries:
+// GROUP BY expressions, aggregate functions and columns, which must be grouped by.
+func (c *compiler) compileGrouped(e expr) (evalFunc, bool, error) {
+	g := c.group
+	key := exprKey(e)
+	for i, k := range g.keys {
+		if k == key {
+			return func(row []value) (value, error) { return row[i], nil }, true, nil
+		}
+	}
+
+	switch e := e.(type) {
+	case columnRef:
+		i, err := resolveColumn(g.source.cols, e)
+		if err != nil {
+			return nil, true, err
+		}
+		k, ok := g.keyCols[i]
+		if !ok {
+			return nil, true, fmt.Errorf("column %q must appear in the GROUP BY clause or be used in an aggregate function", formatColumnRef(e))
+		}
+		return func(row []value) (value, error) { return row[k], nil }, true, nil
+	case funcCall:
+		newAgg, ok := aggregateFuncs[e.name]
+		if !ok {
+			return nil, false, nil
+		}
+		if len(e.args) != 1 {
+			return nil, true, fmt.Errorf("%s expects exactly one argument", strings.ToUpper(e.name))
+		}
+		spec := &aggSpec{distinct: e.distinct, newAgg: newAgg}
+		if _, ok := e.args[0].(starExpr); ok {
+			if e.name != "count" {
+				return nil, true, fmt.Errorf("* can only be used in COUNT(*)")
+			}
+			spec.arg = constant(true)
+		} else {
+			for _, arg := range e.args {
+				if hasAggregate(arg) {
+					return nil, true, fmt.Errorf("aggregate function calls can not be nested")
+				}
+			}
+			arg, err := g.source.compile(e.args[0])
+			if err != nil {
+				return nil, true, err
+			}
+			spec.arg = arg
+		}
+		i := len(g.keys) + len(g.aggs)
+		g.aggs = append(g.aggs, spec)
+		return func(row []value) (value, error) { return row[i], nil }, true, nil
+	}
+	return nil, false, nil
+}
+
+func (c *compiler) compileUnary(e unaryExpr) (evalFunc, error) {
+	x, err := c.compile(e.x)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "NOT" {
+		return func(row []value) (value, error) {
+			v, err := x(row)
+			if err != nil || v == nil {
+				return nil, err
+			}
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("NOT expects a boolean, got %s", typeName(v))
+			}
+			return !b, nil
+		}, nil
+	}
+	return func(row []value) (value, error) {
+		v, err := x(row)
+		if err != nil {
+			return nil, err
+		}
+		switch n := v.(type) {
+		case nil:
+			return nil, nil
+		case int64:
+			return -n, nil
+		case float64:
+			return -n, nil
+		}
+		return nil, fmt.Errorf("can not negate %s", typeName(v))
+	}, nil
+}
+
+// toBool returns the value of a condition, which must be a boolean or NULL.
+func toBool(v value, what string) (value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if _, ok := v.(bool); !ok {
+		return nil, fmt.Errorf("%s expects a boolean, got %s", what, typeName(v))
+	}
+	return v, nil
+}
+
+func (c *compiler) compileBinary(e binaryExpr) (evalFunc, error) {
+	l, err := c.compile(e.l)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.compile(e.r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "AND", "OR":
+		// three-valued logic: false AND NULL is false, true OR NULL is true
+		stop := e.op == "OR"
+		return func(row []value) (value, error) {
+			lv, err := l(row)
+			if err == nil {
+				lv, err = toBool(lv, e.op)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if lv == stop {
+				return stop, nil
+			}
+			rv, err := r(row)
+			if err == nil {
+				rv, err = toBool(rv, e.op)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if rv == stop {
+				return stop, nil
+			}
+			if lv == nil || rv == nil {
+				return nil, nil
+			}
+			return !stop, nil
+		}, nil
+	case "LIKE", "ILIKE":
+		return c.compileLike(e, l, r)
+	}
+
+	var op func(a, b value) (value, error)
+	switch e.op {
+	case "=", "<>", "<", "<=", ">", ">=":
+		op = func(a, b value) (value, error) {
+			if a == nil || b == nil {
+				return nil, nil
+			}
+			c, err := compareValues(a, b)
+			if err != nil {
+				return nil, err
+			}
+			return compareResult(e.op, c), nil
+		}
+	case "IS DISTINCT FROM", "IS NOT DISTINCT FROM":
+		distinct := e.op == "IS DISTINCT FROM"
+		op = func(a, b value) (value, error) {
+			if a == nil || b == nil {
+				return (a == nil) != (b == nil) == distinct, nil
+			}
+			c, err := compareValues(a, b)
+			if err != nil {
+				return nil, err
+			}
+			return (c != 0) == distinct, nil
+		}
+	default:
+		op = func(a, b value) (value, error) {
+			return arithmetic(e.op, a, b)
+		}
+	}
+	return func(row []value) (value, error) {
+		lv, err := l(row)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := r(row)
+		if err != nil {
+			return nil, err
+		}
+		return op(lv, rv)
+	}, nil
+}
+
+func compareResult(op string, c int) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "<>":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func (c *compiler) compileLike(e binaryExpr, l, r evalFunc) (evalFunc, error) {
+	patterns := map[string]*regexp.Regexp{}
+	return func(row []value) (value, error) {
+		lv, err := l(row)
+		if err != nil {
+			return nil, err
+		}
+		rv, err := r(row)
+		if err != nil || lv == nil || rv == nil {
+			return nil, err
+		}
+		pattern := formatValue(rv)
+		re, ok := patterns[pattern]
+		if !ok {
+			re, err = likeRegexp(pattern, e.op == "ILIKE")
+			if err != nil {
+				return nil, err
+			}
+			patterns[pattern] = re
+		}
+		return re.MatchString(formatValue(lv)), nil
+	}, nil
+}
+
+// likeRegexp converts a LIKE pattern, where % matches any characters and _ matches a single one, to a regular expression.
+func likeRegexp(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	sb := strings.Builder{}
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func (c *compiler) compileIn(e inExpr) (evalFunc, error) {
+	x, err := c.compile(e.x)
+	if err != nil {
+		return nil, err
+	}
+
+	var list func(row []value) ([]value, error)
+	if e.query != nil {
+		var values []value
+		done := false
+		list = func([]value) ([]value, error) {
+			if done {
+				return values, nil
+			}
+			rel, err := c.ex.execQuery(e.query)
+			if err != nil {
+				return nil, err
+			}
+			if len(rel.cols) != 1 {
+				return nil, fmt.Errorf("subquery of IN must return one column, got %d", len(rel.cols))
+			}
+			for _, r := range rel.rows {
+				values = append(values, r[0])
+			}
+			done = true
+			return values, nil
+		}
+	} else {
+		items := make([]evalFunc, len(e.list))
+		for i, item := range e.list {
+			if items[i], err = c.compile(item); err != nil {
+				return nil, err
+			}
+		}
+		list = func(row []value) ([]value, error) {
+			values := make([]value, len(items))
+			for i, item := range items {
+				var err error
+				if values[i], err = item(row); err != nil {
+					return nil, err
+				}
+			}
+			return values, nil
+		}
+	}
+
+	return func(row []value) (value, error) {
+		v, err := x(row)
+		if err != nil {
+			return nil, err
+		}
+		values, err := list(row)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		sawNull := false
+		for _, item := range values {
+			if item == nil {
+				sawNull = true
+				continue
+			}
+			c, err := compareValues(v, item)
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				return !e.not, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return e.not, nil
+	}, nil
+}
+
+func (c *compiler) compileBetween(e betweenExpr) (evalFunc, error) {
+	cond := binaryExpr{op: "AND", l: binaryExpr{op: ">=", l: e.x, r: e.lo}, r: binaryExpr{op: "<=", l: e.x, r: e.hi}}
+	if e.not {
+		return c.compile(unaryExpr{op: "NOT", x: cond})
+	}
+	return c.compile(cond)
+}
+
+func (c *compiler) compileCase(e caseExpr) (evalFunc, error) {
+	var operand evalFunc
+	var err error
+	if e.operand != nil {
+		if operand, err = c.compile(e.operand); err != nil {
+			return nil, err
+		}
+	}
+	conds := make([]evalFunc, len(e.whens))
+	results := make([]evalFunc, len(e.whens))
+	for i, w := range e.whens {
+		if conds[i], err = c.compile(w.cond); err != nil {
+			return nil, err
+		}
+		if results[i], err = c.compile(w.result); err != nil {
+			return nil, err
+		}
+	}
+	els := constant(nil)
+	if e.els != nil {
+		if els, err = c.compile(e.els); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(row []value) (value, error) {
+		var ov value
+		if operand != nil {
+			var err error
+			if ov, err = operand(row); err != nil {
+				return nil, err
+			}
+		}
+		for i, cond := range conds {
+			cv, err := cond(row)
+			if err != nil {
+				return nil, err
+			}
+			matched := cv == true
+			if operand != nil {
+				matched = false
+				if ov != nil && cv != nil {
+					cmp, err := compareValues(ov, cv)
+					if err != nil {
+						return nil, err
+					}
+					matched = cmp == 0
+				}
+			}
+			if matched {
+				return results[i](row)
+			}
+		}
+		return els(row)
+	}, nil
+}
+
+func (c *compiler) compileFunc(e funcCall) (evalFunc, error) {
+	if isAggregate(e.name) {
+		return nil, fmt.Errorf("aggregate function %s is not allowed here", strings.ToUpper(e.name))
+	}
+	fn, ok := scalarFuncs[e.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", e.name)
+	}
+	if len(e.args) < fn.minArgs || (fn.maxArgs >= 0 && len(e.args) > fn.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments to %s: %d", e.name, len(e.args))
+	}
+	if e.distinct {
+		return nil, fmt.Errorf("DISTINCT is only allowed in aggregate functions")
+	}
+	args := make([]evalFunc, len(e.args))
+	for i, a := range e.args {
+		var err error
+		if args[i], err = c.compile(a); err != nil {
+			return nil, err
+		}
+	}
+	return func(row []value) (value, error) {
+		values := make([]value, len(args))
+		for i, a := range args {
+			v, err := a(row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil && !fn.nullSafe {
+				return nil, nil
+			}
+			values[i] = v
+		}
+		v, err := fn.call(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+		return v, nil
+	}, nil
+}
+
+// compileSubquery compiles a subquery that returns a single value. The subquery is run once,
+// the first time that it is evaluated.
+func (c *compiler) compileSubquery(e subqueryExpr) (evalFunc, error) {
+	var result value
+	done := false
+	return func([]value) (value, error) {
+		if done {
+			return result, nil
+		}
+		rel, err := c.ex.execQuery(e.query)
+		if err != nil {
+			return nil, err
+		}
+		if len(rel.cols) != 1 {
+			return nil, fmt.Errorf("subquery must return one column, got %d", len(rel.cols))
+		}
+		switch len(rel.rows) {
+		case 0:
+		case 1:
+			result = rel.rows[0][0]
+		default:
+			return nil, fmt.Errorf("subquery used as an expression returned more than one row")
+		}
+		done = true
+		return result, nil
+	}, nil
+}
+
+// children returns the expressions directly inside e. Subqueries are not included.
+func children(e expr) []expr {
+	switch e := e.(type) {
+	case unaryExpr:
+		return []expr{e.x}
+	case binaryExpr:
+		return []expr{e.l, e.r}
+	case isNullExpr:
+		return []expr{e.x}
+	case inExpr:
+		return append([]expr{e.x}, e.list...)
+	case betweenExpr:
+		return []expr{e.x, e.lo, e.hi}
+	case caseExpr:
+		c := []expr{e.operand, e.els}
+		for _, w := range e.whens {
+			c = append(c, w.cond, w.result)
+		}
+		return c
+	case castExpr:
+		return []expr{e.x}
+	case funcCall:
+		return e.args
+	}
+	return nil
+}
+
+// hasAggregate returns true if the expression calls an aggregate function.
+func hasAggregate(e expr) bool {
+	if e == nil {
+		return false
+	}
+	if call, ok := e.(funcCall); ok && isAggregate(call.name) {
+		return true
+	}
+	for _, child := range children(e) {
+		if hasAggregate(child) {
+			return true
+		}
+	}
+	return false
+}
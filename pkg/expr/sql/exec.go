@@ -0,0 +1,881 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// relation is a table of rows, the input and output of every step of a query.
+type relation struct {
+	cols []column
+	rows [][]value
+}
+
+// executor runs a parsed statement over the tables of a query, enforcing its limits.
+type executor struct {
+	ctx    context.Context
+	limits Limits
+	// tables are the tables that the statement can read, by name.
+	tables map[string]*relation
+	// ctes are the common table expressions in scope, by lower cased name.
+	ctes map[string]*relation
+	// bytes is the approximate size of all the rows created by the query so far.
+	bytes int64
+	// rowsSinceCheck counts the rows created since the context was last checked for cancellation.
+	rowsSinceCheck int
+}
+
+// addRow adds a row to the rows of a relation, returning an error if doing so exceeds the limits of the query.
+func (e *executor) addRow(rows [][]value, row []value) ([][]value, error) {
+	if e.limits.MaxRows > 0 && int64(len(rows)) >= e.limits.MaxRows {
+		return nil, fmt.Errorf("%w: a step of the query returned more than %d rows", ErrRowLimitExceeded, e.limits.MaxRows)
+	}
+	if e.limits.MaxBytes > 0 {
+		e.bytes += 24
+		for _, v := range row {
+			e.bytes += valueSize(v)
+		}
+		if e.bytes > e.limits.MaxBytes {
+			return nil, fmt.Errorf("%w: the query used more than %d bytes", ErrMemoryLimitExceeded, e.limits.MaxBytes)
+		}
+	}
+	e.rowsSinceCheck++
+	if e.rowsSinceCheck >= 1024 {
+		e.rowsSinceCheck = 0
+		if err := e.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return append(rows, row), nil
+}
+
+func (e *executor) execQuery(stmt *selectStmt) (*relation, error) {
+	if len(stmt.with) > 0 {
+		saved := e.ctes
+		e.ctes = maps.Clone(saved)
+		defer func() { e.ctes = saved }()
+		for _, c := range stmt.with {
+			rel, err := e.execQuery(c.query)
+			if err != nil {
+				return nil, err
+			}
+			if len(c.columns) > len(rel.cols) {
+				return nil, fmt.Errorf("common table expression %s has %d columns, but %d names were given", c.name, len(rel.cols), len(c.columns))
+			}
+			cols := make([]column, len(rel.cols))
+			for i, col := range rel.cols {
+				cols[i] = column{table: c.name, name: col.name}
+				if i < len(c.columns) {
+					cols[i].name = c.columns[i]
+				}
+			}
+			e.ctes[strings.ToLower(c.name)] = &relation{cols: cols, rows: rel.rows}
+		}
+	}
+
+	var rel *relation
+	var err error
+	if core, ok := stmt.body.(*selectCore); ok {
+		rel, err = e.execSelect(core, stmt.orderBy)
+	} else {
+		rel, err = e.execBody(stmt.body)
+		if err == nil && len(stmt.orderBy) > 0 {
+			err = e.sortRelation(rel, stmt.orderBy)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := e.evalCount(stmt.offset, "OFFSET")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := e.evalCount(stmt.limit, "LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		rel.rows = rel.rows[min(offset, len(rel.rows)):]
+	}
+	if limit >= 0 && limit < len(rel.rows) {
+		rel.rows = rel.rows[:limit]
+	}
+	return rel, nil
+}
+
+// evalCount evaluates the expression of a LIMIT or OFFSET clause. It returns -1 if there is no clause.
+func (e *executor) evalCount(x expr, clause string) (int, error) {
+	if x == nil {
+		return -1, nil
+	}
+	c := &compiler{ex: e}
+	f, err := c.compile(x)
+	if err != nil {
+		return 0, err
+	}
+	v, err := f(nil)
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return -1, nil
+	}
+	v, err = castValue(v, typeBigint)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", clause, err)
+	}
+	n := v.(int64)
+	if n < 0 {
+		return 0, fmt.Errorf("%s must not be negative", clause)
+	}
+	return int(n), nil
+}
+
+func (e *executor) execBody(body queryBody) (*relation, error) {
+	switch b := body.(type) {
+	case *selectCore:
+		return e.execSelect(b, nil)
+	case *selectStmt:
+		return e.execQuery(b)
+	case *setOp:
+		return e.execUnion(b)
+	}
+	return nil, fmt.Errorf("unsupported query %T", body)
+}
+
+func (e *executor) execUnion(op *setOp) (*relation, error) {
+	left, err := e.execBody(op.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.execBody(op.right)
+	if err != nil {
+		return nil, err
+	}
+	if len(left.cols) != len(right.cols) {
+		return nil, fmt.Errorf("each query of a UNION must have the same number of columns, got %d and %d", len(left.cols), len(right.cols))
+	}
+
+	cols := make([]column, len(left.cols))
+	for i, c := range left.cols {
+		cols[i] = column{name: c.name}
+	}
+	out := &relation{cols: cols}
+	seen := map[string]struct{}{}
+	for _, rows := range [][][]value{left.rows, right.rows} {
+		for _, row := range rows {
+			if !op.all {
+				key := rowKey(row)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			if out.rows, err = e.addRow(out.rows, row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// sortRelation sorts the result of a UNION. The ORDER BY items can only refer to its columns.
+func (e *executor) sortRelation(rel *relation, orderBy []orderItem) error {
+	c := &compiler{ex: e, cols: rel.cols}
+	keys := make([]evalFunc, len(orderBy))
+	for i, item := range orderBy {
+		if n, ok := ordinal(item.expr); ok {
+			if n < 1 || n > len(rel.cols) {
+				return fmt.Errorf("ORDER BY position %d is not in the select list", n)
+			}
+			keys[i] = func(row []value) (value, error) { return row[n-1], nil }
+			continue
+		}
+		f, err := c.compile(item.expr)
+		if err != nil {
+			return err
+		}
+		keys[i] = f
+	}
+
+	sortKeys := make([][]value, len(rel.rows))
+	for i, row := range rel.rows {
+		sortKeys[i] = make([]value, len(keys))
+		for j, key := range keys {
+			v, err := key(row)
+			if err != nil {
+				return err
+			}
+			sortKeys[i][j] = v
+		}
+	}
+	sortRows(rel.rows, sortKeys, orderBy)
+	return nil
+}
+
+// sortRows sorts rows by their keys, which hold a value for each ORDER BY item.
+func sortRows(rows [][]value, keys [][]value, orderBy []orderItem) {
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		for j, item := range orderBy {
+			c := sortCompare(keys[a][j], keys[b][j], item.nullsFirst)
+			if item.desc && keys[a][j] != nil && keys[b][j] != nil {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	sorted := make([][]value, len(rows))
+	for i, j := range idx {
+		sorted[i] = rows[j]
+	}
+	copy(rows, sorted)
+}
+
+// ordinal returns the position of a select list item referenced by number, as in ORDER BY 1.
+func ordinal(x expr) (int, bool) {
+	if l, ok := x.(literal); ok {
+		if n, ok := l.val.(int64); ok {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// execSelect runs a SELECT. ORDER BY is handled here, rather than on the result,
+// since it can refer to columns of the tables that are not selected.
+func (e *executor) execSelect(core *selectCore, orderBy []orderItem) (*relation, error) {
+	src, err := e.execFrom(core.from)
+	if err != nil {
+		return nil, err
+	}
+	source := &compiler{ex: e, cols: src.cols}
+
+	rows := src.rows
+	if core.where != nil {
+		if hasAggregate(core.where) {
+			return nil, fmt.Errorf("aggregate functions are not allowed in WHERE")
+		}
+		if rows, err = e.filter(source, core.where, rows, "WHERE"); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := expandStars(core.items, src.cols)
+	if err != nil {
+		return nil, err
+	}
+
+	// ORDER BY items that are not an output column are computed as extra columns, that are dropped after sorting.
+	outputIdx := make([]int, len(orderBy))
+	var extras []expr
+	for i, item := range orderBy {
+		outputIdx[i] = -1
+		if n, ok := ordinal(item.expr); ok {
+			if n < 1 || n > len(items) {
+				return nil, fmt.Errorf("ORDER BY position %d is not in the select list", n)
+			}
+			outputIdx[i] = n - 1
+			continue
+		}
+		if j := aliasIndex(items, item.expr); j >= 0 {
+			outputIdx[i] = j
+			continue
+		}
+		outputIdx[i] = len(items) + len(extras)
+		extras = append(extras, item.expr)
+	}
+
+	exprs := make([]expr, 0, len(items)+len(extras))
+	for _, item := range items {
+		exprs = append(exprs, item.expr)
+	}
+	exprs = append(exprs, extras...)
+
+	aggregate := len(core.groupBy) > 0 || hasAggregate(core.having)
+	for _, x := range exprs {
+		aggregate = aggregate || hasAggregate(x)
+	}
+
+	var out [][]value
+	if aggregate {
+		out, err = e.aggregate(core, source, items, exprs, rows)
+	} else {
+		if core.having != nil {
+			return nil, fmt.Errorf("HAVING requires GROUP BY or an aggregate function")
+		}
+		out, err = e.project(source, exprs, rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if core.distinct {
+		seen := map[string]struct{}{}
+		distinct := out[:0]
+		for _, row := range out {
+			key := rowKey(row[:len(items)])
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			distinct = append(distinct, row)
+		}
+		out = distinct
+	}
+
+	if len(orderBy) > 0 {
+		keys := make([][]value, len(out))
+		for i, row := range out {
+			keys[i] = make([]value, len(orderBy))
+			for j, k := range outputIdx {
+				keys[i][j] = row[k]
+			}
+		}
+		sortRows(out, keys, orderBy)
+	}
+
+	cols := make([]column, len(items))
+	for i, item := range items {
+		cols[i] = column{name: item.alias}
+	}
+	for i, row := range out {
+		out[i] = row[:len(items)]
+	}
+	return &relation{cols: cols, rows: out}, nil
+}
+
+// aliasIndex returns the index of the select list item whose name is the column referenced by x, or -1.
+func aliasIndex(items []selectItem, x expr) int {
+	ref, ok := x.(columnRef)
+	if !ok || ref.table != "" {
+		return -1
+	}
+	found := -1
+	for i, item := range items {
+		if item.alias == ref.name {
+			return i
+		}
+		if found < 0 && strings.EqualFold(item.alias, ref.name) {
+			found = i
+		}
+	}
+	return found
+}
+
+// expandStars replaces * and t.* with the columns that they select.
+func expandStars(items []selectItem, cols []column) ([]selectItem, error) {
+	var expanded []selectItem
+	for _, item := range items {
+		if !item.star {
+			expanded = append(expanded, item)
+			continue
+		}
+		found := false
+		for _, c := range cols {
+			if item.table == "" && c.hidden {
+				continue
+			}
+			if item.table != "" && !strings.EqualFold(item.table, c.table) {
+				continue
+			}
+			found = true
+			expanded = append(expanded, selectItem{expr: columnRef{table: c.table, name: c.name}, alias: c.name})
+		}
+		if !found && item.table != "" {
+			return nil, fmt.Errorf("table %q not found", item.table)
+		}
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("SELECT * returned no columns")
+	}
+	return expanded, nil
+}
+
+func (e *executor) filter(c *compiler, cond expr, rows [][]value, clause string) ([][]value, error) {
+	f, err := c.compile(cond)
+	if err != nil {
+		return nil, err
+	}
+	var kept [][]value
+	for _, row := range rows {
+		v, err := f(row)
+		if err == nil {
+			v, err = toBool(v, clause)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if v == true {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// project evaluates the expressions for each row of a query without aggregates.
+func (e *executor) project(c *compiler, exprs []expr, rows [][]value) ([][]value, error) {
+	fns := make([]evalFunc, len(exprs))
+	for i, x := range exprs {
+		var err error
+		if fns[i], err = c.compile(x); err != nil {
+			return nil, err
+		}
+	}
+	var out [][]value
+	for _, row := range rows {
+		o := make([]value, len(fns))
+		for i, f := range fns {
+			var err error
+			if o[i], err = f(row); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if out, err = e.addRow(out, o); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+type group struct {
+	keys []value
+	aggs []aggregator
+}
+
+// aggregate groups the rows by the GROUP BY expressions, and evaluates the expressions once for each group.
+func (e *executor) aggregate(core *selectCore, source *compiler, items []selectItem, exprs []expr, rows [][]value) ([][]value, error) {
+	g := &groupScope{source: source, keyCols: map[int]int{}}
+	keyFns := make([]evalFunc, len(core.groupBy))
+	for i, x := range core.groupBy {
+		// GROUP BY can refer to the select list by position or by name
+		if n, ok := ordinal(x); ok {
+			if n < 1 || n > len(items) {
+				return nil, fmt.Errorf("GROUP BY position %d is not in the select list", n)
+			}
+			x = items[n-1].expr
+		} else if ref, ok := x.(columnRef); ok {
+			if _, err := resolveColumn(source.cols, ref); err != nil {
+				if j := aliasIndex(items, ref); j >= 0 {
+					x = items[j].expr
+				}
+			}
+		}
+		if hasAggregate(x) {
+			return nil, fmt.Errorf("aggregate functions are not allowed in GROUP BY")
+		}
+		f, err := source.compile(x)
+		if err != nil {
+			return nil, err
+		}
+		keyFns[i] = f
+		g.keys = append(g.keys, exprKey(x))
+		if ref, ok := x.(columnRef); ok {
+			if j, err := resolveColumn(source.cols, ref); err == nil {
+				g.keyCols[j] = i
+			}
+		}
+	}
+
+	grouped := &compiler{ex: e, cols: source.cols, group: g}
+	fns := make([]evalFunc, len(exprs))
+	for i, x := range exprs {
+		var err error
+		if fns[i], err = grouped.compile(x); err != nil {
+			return nil, err
+		}
+	}
+	var having evalFunc
+	if core.having != nil {
+		var err error
+		if having, err = grouped.compile(core.having); err != nil {
+			return nil, err
+		}
+	}
+
+	var groups []*group
+	index := map[string]*group{}
+	newGroup := func(keys []value) *group {
+		grp := &group{keys: keys, aggs: make([]aggregator, len(g.aggs))}
+		for i, spec := range g.aggs {
+			grp.aggs[i] = spec.newAggregator()
+		}
+		groups = append(groups, grp)
+		return grp
+	}
+	for n, row := range rows {
+		keys := make([]value, len(keyFns))
+		for i, f := range keyFns {
+			var err error
+			if keys[i], err = f(row); err != nil {
+				return nil, err
+			}
+		}
+		key := rowKey(keys)
+		grp, ok := index[key]
+		if !ok {
+			grp = newGroup(keys)
+			index[key] = grp
+		}
+		for i, spec := range g.aggs {
+			v, err := spec.arg(row)
+			if err != nil {
+				return nil, err
+			}
+			if err := grp.aggs[i].add(v); err != nil {
+				return nil, err
+			}
+		}
+		if n%1024 == 0 {
+			if err := e.ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// without GROUP BY, aggregates return a single row even if there are no rows
+	if len(groups) == 0 && len(core.groupBy) == 0 {
+		newGroup(nil)
+	}
+
+	var out [][]value
+	for _, grp := range groups {
+		row := make([]value, 0, len(grp.keys)+len(grp.aggs))
+		row = append(row, grp.keys...)
+		for _, agg := range grp.aggs {
+			row = append(row, agg.result())
+		}
+		if having != nil {
+			v, err := having(row)
+			if err == nil {
+				v, err = toBool(v, "HAVING")
+			}
+			if err != nil {
+				return nil, err
+			}
+			if v != true {
+				continue
+			}
+		}
+		o := make([]value, len(fns))
+		for i, f := range fns {
+			var err error
+			if o[i], err = f(row); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if out, err = e.addRow(out, o); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (e *executor) execFrom(t tableExpr) (*relation, error) {
+	switch t := t.(type) {
+	case nil:
+		// a SELECT without FROM returns a single row
+		return &relation{rows: [][]value{{}}}, nil
+	case *tableName:
+		rel, err := e.lookupTable(t.name)
+		if err != nil {
+			return nil, err
+		}
+		name := t.alias
+		if name == "" {
+			name = t.name
+		}
+		cols := make([]column, len(rel.cols))
+		for i, c := range rel.cols {
+			cols[i] = column{table: name, name: c.name}
+		}
+		return &relation{cols: cols, rows: rel.rows}, nil
+	case *subqueryTable:
+		rel, err := e.execQuery(t.query)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rel.cols {
+			rel.cols[i].table = t.alias
+		}
+		return rel, nil
+	case *joinTable:
+		return e.execJoin(t)
+	}
+	return nil, fmt.Errorf("unsupported table %T", t)
+}
+
+func (e *executor) lookupTable(name string) (*relation, error) {
+	if rel, ok := e.ctes[strings.ToLower(name)]; ok {
+		return rel, nil
+	}
+	if rel, ok := e.tables[name]; ok {
+		return rel, nil
+	}
+	for n, rel := range e.tables {
+		if strings.EqualFold(n, name) {
+			return rel, nil
+		}
+	}
+	return nil, fmt.Errorf("table %q not found", name)
+}
+
+func (e *executor) execJoin(j *joinTable) (*relation, error) {
+	left, err := e.execFrom(j.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.execFrom(j.right)
+	if err != nil {
+		return nil, err
+	}
+
+	nLeft := len(left.cols)
+	cols := make([]column, 0, nLeft+len(right.cols))
+	cols = append(cols, left.cols...)
+	cols = append(cols, right.cols...)
+
+	// pairs of left and right columns that must be equal, for USING and for ON conditions made of equalities
+	var pairs [][2]int
+	for _, name := range j.using {
+		l, err := resolveColumn(left.cols, columnRef{name: name})
+		if err != nil {
+			return nil, fmt.Errorf("USING: %w", err)
+		}
+		r, err := resolveColumn(right.cols, columnRef{name: name})
+		if err != nil {
+			return nil, fmt.Errorf("USING: %w", err)
+		}
+		cols[nLeft+r].hidden = true
+		pairs = append(pairs, [2]int{l, r})
+	}
+
+	var cond evalFunc
+	if j.on != nil {
+		if onPairs, ok := equiJoinPairs(j.on, left.cols, right.cols); ok {
+			pairs = onPairs
+		} else {
+			c := &compiler{ex: e, cols: cols}
+			if cond, err = c.compile(j.on); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// an index of the right rows by the values of the join columns, when their values can be compared by key
+	var index map[string][]int
+	if len(pairs) > 0 {
+		if sameKinds(left.rows, right.rows, pairs) {
+			index = map[string][]int{}
+			for i, r := range right.rows {
+				if key, ok := joinKey(r, pairs, 1); ok {
+					index[key] = append(index[key], i)
+				}
+			}
+		} else {
+			cond = pairsCond(pairs, nLeft)
+		}
+	}
+	allRows := make([]int, len(right.rows))
+	for i := range allRows {
+		allRows[i] = i
+	}
+
+	out := &relation{cols: cols}
+	emit := func(l, r []value) error {
+		row := make([]value, 0, len(cols))
+		if l == nil {
+			l = make([]value, nLeft)
+		}
+		row = append(row, l...)
+		if r == nil {
+			r = make([]value, len(right.cols))
+		}
+		row = append(row, r...)
+		// the columns of USING hold the value from either side of an outer join
+		for _, p := range pairs[:len(j.using)] {
+			if row[p[0]] == nil {
+				row[p[0]] = row[nLeft+p[1]]
+			}
+		}
+		out.rows, err = e.addRow(out.rows, row)
+		return err
+	}
+
+	rightMatched := make([]bool, len(right.rows))
+	for _, l := range left.rows {
+		candidates := allRows
+		if index != nil {
+			candidates = nil
+			if key, ok := joinKey(l, pairs, 0); ok {
+				candidates = index[key]
+			}
+		}
+
+		matched := false
+		for _, i := range candidates {
+			r := right.rows[i]
+			if cond != nil {
+				row := make([]value, 0, len(cols))
+				row = append(append(row, l...), r...)
+				v, err := cond(row)
+				if err == nil {
+					v, err = toBool(v, "ON")
+				}
+				if err != nil {
+					return nil, err
+				}
+				if v != true {
+					continue
+				}
+			}
+			matched = true
+			rightMatched[i] = true
+			if err := emit(l, r); err != nil {
+				return nil, err
+			}
+		}
+		if !matched && (j.kind == joinLeft || j.kind == joinFull) {
+			if err := emit(l, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if j.kind == joinRight || j.kind == joinFull {
+		for i, r := range right.rows {
+			if !rightMatched[i] {
+				if err := emit(nil, r); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// joinKey returns the key of the join columns of a row, on side 0 for the left table and 1 for the right.
+// Rows with a NULL join column never match.
+func joinKey(row []value, pairs [][2]int, side int) (string, bool) {
+	sb := strings.Builder{}
+	for _, p := range pairs {
+		v := row[p[side]]
+		if v == nil {
+			return "", false
+		}
+		writeKey(&sb, v)
+	}
+	return sb.String(), true
+}
+
+// equiJoinPairs returns the pairs of columns compared by an ON condition that is made only of
+// equalities between a column of each table, which can be run as a hash join.
+func equiJoinPairs(on expr, left, right []column) ([][2]int, bool) {
+	var pairs [][2]int
+	var walk func(x expr) bool
+	walk = func(x expr) bool {
+		b, ok := x.(binaryExpr)
+		if !ok {
+			return false
+		}
+		if b.op == "AND" {
+			return walk(b.l) && walk(b.r)
+		}
+		if b.op != "=" {
+			return false
+		}
+		lRef, lOK := b.l.(columnRef)
+		rRef, rOK := b.r.(columnRef)
+		if !lOK || !rOK {
+			return false
+		}
+		if l, r, ok := sides(lRef, rRef, left, right); ok {
+			pairs = append(pairs, [2]int{l, r})
+			return true
+		}
+		if l, r, ok := sides(rRef, lRef, left, right); ok {
+			pairs = append(pairs, [2]int{l, r})
+			return true
+		}
+		return false
+	}
+	if !walk(on) {
+		return nil, false
+	}
+	return pairs, true
+}
+
+// sides returns the indexes of a and b if a is only a column of the left table and b only of the right one.
+func sides(a, b columnRef, left, right []column) (int, int, bool) {
+	l, err := resolveColumn(left, a)
+	if err != nil {
+		return 0, 0, false
+	}
+	if _, err := resolveColumn(right, a); err == nil {
+		return 0, 0, false
+	}
+	r, err := resolveColumn(right, b)
+	if err != nil {
+		return 0, 0, false
+	}
+	if _, err := resolveColumn(left, b); err == nil {
+		return 0, 0, false
+	}
+	return l, r, true
+}
+
+// sameKinds returns true if all the values of each pair of join columns are of the same kind, so
+// that equal values have equal keys. Otherwise values such as a timestamp and a string need to be
+// converted to be compared.
+func sameKinds(left, right [][]value, pairs [][2]int) bool {
+	kind := func(v value) string {
+		if isNumber(v) {
+			return "number"
+		}
+		return typeName(v)
+	}
+	for _, p := range pairs {
+		k := ""
+		for side, rows := range [][][]value{left, right} {
+			for _, row := range rows {
+				v := row[p[side]]
+				if v == nil {
+					continue
+				}
+				if k == "" {
+					k = kind(v)
+				} else if kind(v) != k {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// pairsCond returns a condition that is true when the values of every pair of join columns are equal.
+func pairsCond(pairs [][2]int, nLeft int) evalFunc {
+	return func(row []value) (value, error) {
+		for _, p := range pairs {
+			l, r := row[p[0]], row[nLeft+p[1]]
+			if l == nil || r == nil {
+				return false, nil
+			}
+			c, err := compareValues(l, r)
+			if err != nil || c != 0 {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
@@ -0,0 +1,394 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type scalarFunc struct {
+	minArgs, maxArgs int
+	// nullSafe functions are called with NULL arguments. Other functions return NULL if any argument is NULL.
+	nullSafe bool
+	call     func(args []value) (value, error)
+}
+
+var scalarFuncs = map[string]scalarFunc{
+	"abs":        {1, 1, false, mathFunc(math.Abs)},
+	"ceil":       {1, 1, false, mathFunc(math.Ceil)},
+	"ceiling":    {1, 1, false, mathFunc(math.Ceil)},
+	"floor":      {1, 1, false, mathFunc(math.Floor)},
+	"sqrt":       {1, 1, false, mathFunc(math.Sqrt)},
+	"ln":         {1, 1, false, mathFunc(math.Log)},
+	"log":        {1, 1, false, mathFunc(math.Log10)},
+	"log10":      {1, 1, false, mathFunc(math.Log10)},
+	"log2":       {1, 1, false, mathFunc(math.Log2)},
+	"exp":        {1, 1, false, mathFunc(math.Exp)},
+	"round":      {1, 2, false, round},
+	"power":      {2, 2, false, power},
+	"pow":        {2, 2, false, power},
+	"lower":      {1, 1, false, stringFunc(strings.ToLower)},
+	"upper":      {1, 1, false, stringFunc(strings.ToUpper)},
+	"trim":       {1, 1, false, stringFunc(strings.TrimSpace)},
+	"ltrim":      {1, 1, false, stringFunc(func(s string) string { return strings.TrimLeft(s, " \t\n\r") })},
+	"rtrim":      {1, 1, false, stringFunc(func(s string) string { return strings.TrimRight(s, " \t\n\r") })},
+	"length":     {1, 1, false, length},
+	"substr":     {2, 3, false, substr},
+	"substring":  {2, 3, false, substr},
+	"replace":    {3, 3, false, replace},
+	"concat":     {1, -1, true, concat},
+	"coalesce":   {1, -1, true, coalesce},
+	"ifnull":     {2, 2, true, coalesce},
+	"nullif":     {2, 2, true, nullIf},
+	"greatest":   {1, -1, true, extreme(1)},
+	"least":      {1, -1, true, extreme(-1)},
+	"date_trunc": {2, 2, false, dateTrunc},
+	"epoch":      {1, 1, false, epoch},
+	"epoch_ms":   {1, 1, false, epochMS},
+}
+
+func mathFunc(f func(float64) float64) func(args []value) (value, error) {
+	return func(args []value) (value, error) {
+		x, ok := toFloat(args[0])
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %s", typeName(args[0]))
+		}
+		return f(x), nil
+	}
+}
+
+func stringFunc(f func(string) string) func(args []value) (value, error) {
+	return func(args []value) (value, error) {
+		return f(formatValue(args[0])), nil
+	}
+}
+
+func round(args []value) (value, error) {
+	if i, ok := args[0].(int64); ok && len(args) == 1 {
+		return i, nil
+	}
+	x, ok := toFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", typeName(args[0]))
+	}
+	if len(args) == 1 {
+		return math.Round(x), nil
+	}
+	digits, ok := args[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("expected an integer number of digits, got %s", typeName(args[1]))
+	}
+	scale := math.Pow(10, float64(digits))
+	return math.Round(x*scale) / scale, nil
+}
+
+func power(args []value) (value, error) {
+	x, xOK := toFloat(args[0])
+	y, yOK := toFloat(args[1])
+	if !xOK || !yOK {
+		return nil, fmt.Errorf("expected numbers, got %s and %s", typeName(args[0]), typeName(args[1]))
+	}
+	return math.Pow(x, y), nil
+}
+
+func length(args []value) (value, error) {
+	return int64(utf8.RuneCountInString(formatValue(args[0]))), nil
+}
+
+// substr returns the characters of a string starting at the one based position, like SQL's SUBSTRING.
+func substr(args []value) (value, error) {
+	runes := []rune(formatValue(args[0]))
+	start, ok := args[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("expected an integer start position, got %s", typeName(args[1]))
+	}
+	end := int64(len(runes)) + 1
+	if len(args) == 3 {
+		n, ok := args[2].(int64)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("expected a positive integer length, got %s", formatValue(args[2]))
+		}
+		end = start + n
+	}
+	start = max(start, 1)
+	end = min(end, int64(len(runes))+1)
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start-1 : end-1]), nil
+}
+
+func replace(args []value) (value, error) {
+	return strings.ReplaceAll(formatValue(args[0]), formatValue(args[1]), formatValue(args[2])), nil
+}
+
+func concat(args []value) (value, error) {
+	sb := strings.Builder{}
+	for _, a := range args {
+		if a != nil {
+			sb.WriteString(formatValue(a))
+		}
+	}
+	return sb.String(), nil
+}
+
+func coalesce(args []value) (value, error) {
+	for _, a := range args {
+		if a != nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+func nullIf(args []value) (value, error) {
+	if args[0] == nil || args[1] == nil {
+		return args[0], nil
+	}
+	c, err := compareValues(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if c == 0 {
+		return nil, nil
+	}
+	return args[0], nil
+}
+
+// extreme returns GREATEST when sign is 1 and LEAST when sign is -1. NULL arguments are ignored.
+func extreme(sign int) func(args []value) (value, error) {
+	return func(args []value) (value, error) {
+		var result value
+		for _, a := range args {
+			if a == nil {
+				continue
+			}
+			if result == nil {
+				result = a
+				continue
+			}
+			c, err := compareValues(a, result)
+			if err != nil {
+				return nil, err
+			}
+			if c*sign > 0 {
+				result = a
+			}
+		}
+		return result, nil
+	}
+}
+
+func toTime(v value) (time.Time, error) {
+	t, err := castValue(v, typeTimestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.(time.Time), nil
+}
+
+func dateTrunc(args []value) (value, error) {
+	t, err := toTime(args[1])
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(formatValue(args[0])) {
+	case "second", "seconds", "s":
+		return t.Truncate(time.Second), nil
+	case "minute", "minutes", "m":
+		return t.Truncate(time.Minute), nil
+	case "hour", "hours", "h":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()), nil
+	case "day", "days", "d":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+	case "week", "weeks", "w":
+		// weeks start on Monday
+		days := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location()), nil
+	case "month", "months":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()), nil
+	case "year", "years", "y":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location()), nil
+	}
+	return nil, fmt.Errorf("unsupported date part %q", formatValue(args[0]))
+}
+
+// epoch returns the number of seconds since the Unix epoch of a timestamp.
+func epoch(args []value) (value, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return float64(t.UnixNano()) / 1e9, nil
+}
+
+// epochMS returns the timestamp for a number of milliseconds since the Unix epoch.
+func epochMS(args []value) (value, error) {
+	ms, ok := toFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("expected a number, got %s", typeName(args[0]))
+	}
+	return time.UnixMilli(int64(ms)).UTC(), nil
+}
+
+// aggregator accumulates the values of a group for an aggregate function.
+type aggregator interface {
+	add(v value) error
+	result() value
+}
+
+var aggregateFuncs = map[string]func() aggregator{
+	"count": func() aggregator { return &countAgg{} },
+	"sum":   func() aggregator { return &sumAgg{} },
+	"avg":   func() aggregator { return &avgAgg{} },
+	"mean":  func() aggregator { return &avgAgg{} },
+	"min":   func() aggregator { return &extremeAgg{sign: -1} },
+	"max":   func() aggregator { return &extremeAgg{sign: 1} },
+	"first": func() aggregator { return &firstAgg{} },
+	"last":  func() aggregator { return &lastAgg{} },
+}
+
+func isAggregate(name string) bool {
+	_, ok := aggregateFuncs[name]
+	return ok
+}
+
+// countAgg counts the values that are not NULL. COUNT(*) is given a value for every row.
+type countAgg struct{ n int64 }
+
+func (a *countAgg) add(v value) error {
+	if v != nil {
+		a.n++
+	}
+	return nil
+}
+
+func (a *countAgg) result() value { return a.n }
+
+type sumAgg struct {
+	seen    bool
+	isFloat bool
+	i       int64
+	f       float64
+}
+
+func (a *sumAgg) add(v value) error {
+	switch n := v.(type) {
+	case nil:
+		return nil
+	case int64:
+		a.i += n
+	case float64:
+		a.isFloat = true
+		a.f += n
+	default:
+		return fmt.Errorf("can not sum %s values", typeName(v))
+	}
+	a.seen = true
+	return nil
+}
+
+func (a *sumAgg) result() value {
+	switch {
+	case !a.seen:
+		return nil
+	case a.isFloat:
+		return a.f + float64(a.i)
+	}
+	return a.i
+}
+
+type avgAgg struct {
+	n   int64
+	sum float64
+}
+
+func (a *avgAgg) add(v value) error {
+	if v == nil {
+		return nil
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		return fmt.Errorf("can not average %s values", typeName(v))
+	}
+	a.n++
+	a.sum += f
+	return nil
+}
+
+func (a *avgAgg) result() value {
+	if a.n == 0 {
+		return nil
+	}
+	return a.sum / float64(a.n)
+}
+
+type extremeAgg struct {
+	sign int
+	v    value
+}
+
+func (a *extremeAgg) add(v value) error {
+	if v == nil {
+		return nil
+	}
+	if a.v == nil {
+		a.v = v
+		return nil
+	}
+	c, err := compareValues(v, a.v)
+	if err != nil {
+		return err
+	}
+	if c*a.sign > 0 {
+		a.v = v
+	}
+	return nil
+}
+
+func (a *extremeAgg) result() value { return a.v }
+
+type firstAgg struct {
+	seen bool
+	v    value
+}
+
+func (a *firstAgg) add(v value) error {
+	if !a.seen {
+		a.seen = true
+		a.v = v
+	}
+	return nil
+}
+
+func (a *firstAgg) result() value { return a.v }
+
+type lastAgg struct{ v value }
+
+func (a *lastAgg) add(v value) error {
+	a.v = v
+	return nil
+}
+
+func (a *lastAgg) result() value { return a.v }
+
+// distinctAgg passes each distinct value only once to the wrapped aggregator.
+type distinctAgg struct {
+	agg  aggregator
+	seen map[string]struct{}
+}
+
+func (a *distinctAgg) add(v value) error {
+	sb := strings.Builder{}
+	writeKey(&sb, v)
+	key := sb.String()
+	if _, ok := a.seen[key]; ok {
+		return nil
+	}
+	a.seen[key] = struct{}{}
+	return a.agg.add(v)
+}
+
+func (a *distinctAgg) result() value { return a.agg.result() }
@@ -0,0 +1,157 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	// tokenIdent is an unquoted identifier or keyword.
+	tokenIdent tokenKind = iota
+	// tokenQuotedIdent is a "double quoted" or `backtick quoted` identifier.
+	tokenQuotedIdent
+	// tokenString is a 'single quoted' string literal.
+	tokenString
+	// tokenNumber is a numeric literal.
+	tokenNumber
+	// tokenPunct is any other single character, such as a parenthesis, comma or operator.
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	// text is the token as written, except for quoted identifiers and strings,
+	// where it is the unquoted and unescaped value.
+	text string
+	// pos is the byte offset of the start of the token in the statement.
+	pos int
+	// end is the byte offset after the end of the token in the statement.
+	end int
+}
+
+// isKeyword returns true if the token is an unquoted identifier matching the
+// keyword, which must be upper case.
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && strings.ToUpper(t.text) == keyword
+}
+
+// isPunct returns true if the token is the punctuation character p.
+func (t token) isPunct(p string) bool {
+	return t.kind == tokenPunct && t.text == p
+}
+
+// isName returns true if the token can be the name of a table or an alias,
+// that is a quoted identifier or an unquoted identifier that is not a reserved keyword.
+func (t token) isName() bool {
+	switch t.kind {
+	case tokenQuotedIdent:
+		return true
+	case tokenIdent:
+		_, reserved := reservedKeywords[strings.ToUpper(t.text)]
+		return !reserved
+	default:
+		return false
+	}
+}
+
+// reservedKeywords are keywords that end a table reference, so they can not be taken as
+// an implicit table alias.
+var reservedKeywords = map[string]struct{}{
+	"ALL": {}, "AND": {}, "ANTI": {}, "AS": {}, "ASOF": {}, "BY": {}, "CASE": {}, "CROSS": {},
+	"DISTINCT": {}, "ELSE": {}, "END": {}, "EXCEPT": {}, "FETCH": {}, "FROM": {}, "FULL": {},
+	"GROUP": {}, "HAVING": {}, "IN": {}, "INNER": {}, "INTERSECT": {}, "INTO": {}, "IS": {},
+	"JOIN": {}, "LATERAL": {}, "LEFT": {}, "LIMIT": {}, "NATURAL": {}, "NOT": {}, "NULL": {},
+	"OFFSET": {}, "ON": {}, "OR": {}, "ORDER": {}, "OUTER": {}, "PIVOT": {}, "POSITIONAL": {},
+	"QUALIFY": {}, "RIGHT": {}, "SAMPLE": {}, "SELECT": {}, "SEMI": {}, "TABLESAMPLE": {},
+	"THEN": {}, "UNION": {}, "UNPIVOT": {}, "USING": {}, "VALUES": {}, "WHEN": {}, "WHERE": {},
+	"WINDOW": {}, "WITH": {},
+}
+
+// lex splits the statement into tokens, dropping whitespace and comments.
+func lex(rawSQL string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(rawSQL) {
+		c := rawSQL[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(rawSQL[i:], "--"):
+			end := strings.IndexByte(rawSQL[i:], '\n')
+			if end == -1 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(rawSQL[i:], "/*"):
+			end := strings.Index(rawSQL[i+2:], "*/")
+			if end == -1 {
+				return nil, &ParseError{Pos: i, Msg: "unterminated comment"}
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			text, next, ok := readQuoted(rawSQL, i)
+			if !ok {
+				return nil, &ParseError{Pos: i, Msg: fmt.Sprintf("unterminated quote %c", c)}
+			}
+			kind := tokenQuotedIdent
+			if c == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: i, end: next})
+			i = next
+		case isDigit(c) || (c == '.' && i+1 < len(rawSQL) && isDigit(rawSQL[i+1])):
+			start := i
+			for i < len(rawSQL) && (isIdentChar(rune(rawSQL[i])) || rawSQL[i] == '.' ||
+				((rawSQL[i] == '+' || rawSQL[i] == '-') && (rawSQL[i-1] == 'e' || rawSQL[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: rawSQL[start:i], pos: start, end: i})
+		case isIdentStart(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(rawSQL) && (isIdentChar(rune(rawSQL[i])) || rawSQL[i] >= 0x80) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: rawSQL[start:i], pos: start, end: i})
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), pos: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// readQuoted reads the quoted text starting at the quote character at start.
+// A doubled quote character inside the text is an escaped quote.
+// It returns the unescaped text and the index after the closing quote.
+func readQuoted(rawSQL string, start int) (string, int, bool) {
+	quote := rawSQL[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(rawSQL); i++ {
+		if rawSQL[i] != quote {
+			sb.WriteByte(rawSQL[i])
+			continue
+		}
+		if i+1 < len(rawSQL) && rawSQL[i+1] == quote {
+			sb.WriteByte(quote)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, true
+	}
+	return "", 0, false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentChar(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -1,99 +1,295 @@
 package sql
 
 import (
-	"errors"
+	"fmt"
 	"strings"
-
-	parser "github.com/krasun/gosqlparser"
-	"github.com/xwb1989/sqlparser"
 )
 
+// TableRef is a reference to a table that a SQL statement reads from.
+type TableRef struct {
+	// Name is the name of the table. For quoted identifiers it is the unquoted name,
+	// and for qualified names the parts are joined with a dot.
+	Name string
+	// Pos is the byte offset of the reference in the statement.
+	Pos int
+}
+
+// ParseError is returned when a SQL statement can not be parsed or is not supported.
+type ParseError struct {
+	// Pos is the byte offset in the statement where the error was found.
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
 // TablesList returns a list of tables for the sql statement
 func TablesList(rawSQL string) ([]string, error) {
-	stmt, err := sqlparser.Parse(rawSQL)
+	refs, err := TableRefs(rawSQL)
 	if err != nil {
-		tables, err := parse(rawSQL)
-		if err != nil {
-			return parseTables(rawSQL)
-		}
-		return tables, nil
+		return nil, err
 	}
-
 	tables := []string{}
-	switch kind := stmt.(type) {
-	case *sqlparser.Select:
-		for _, t := range kind.From {
-			buf := sqlparser.NewTrackedBuffer(nil)
-			t.Format(buf)
-			table := buf.String()
-			if table != "dual" {
-				tables = append(tables, buf.String())
-			}
+	seen := map[string]struct{}{}
+	for _, ref := range refs {
+		if _, ok := seen[ref.Name]; ok {
+			continue
 		}
-	default:
-		return nil, errors.New("not a select statement")
+		seen[ref.Name] = struct{}{}
+		tables = append(tables, ref.Name)
 	}
 	return tables, nil
 }
 
-// uses a simple tokenizer
-func parse(rawSQL string) ([]string, error) {
-	query, err := parser.Parse(rawSQL)
+// TableRefs parses the sql statement and returns every reference to a table in it, in the order they appear.
+// It understands CTEs, subqueries, JOINs and quoted identifiers. Names defined by CTEs and table functions
+// such as range(10) are not tables, so they are not returned.
+// An error is returned if the statement is not a single SELECT statement.
+func TableRefs(rawSQL string) ([]TableRef, error) {
+	tokens, err := lex(rawSQL)
 	if err != nil {
 		return nil, err
 	}
-	if query.GetType() == parser.StatementSelect {
-		sel, ok := query.(*parser.Select)
-		if ok {
-			return []string{sel.Table}, nil
+	// a trailing semicolon is allowed, but more than one statement is not
+	for len(tokens) > 0 && tokens[len(tokens)-1].isPunct(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, &ParseError{Pos: 0, Msg: "empty statement"}
+	}
+	for _, t := range tokens {
+		if t.isPunct(";") {
+			return nil, &ParseError{Pos: t.pos, Msg: "only a single statement is supported"}
 		}
 	}
-	return nil, err
-}
-
-func parseTables(rawSQL string) ([]string, error) {
-	checkSql := strings.ToUpper(rawSQL)
-	if strings.HasPrefix(checkSql, "SELECT") || strings.HasPrefix(rawSQL, "WITH") {
-		tables := []string{}
-		tokens := strings.Split(rawSQL, " ")
-		checkNext := false
-		takeNext := false
-		for _, t := range tokens {
-			t = strings.ToUpper(t)
-			t = strings.TrimSpace(t)
-
-			if takeNext {
-				tables = append(tables, t)
-				checkNext = false
-				takeNext = false
-				continue
-			}
-			if checkNext {
-				if strings.Contains(t, "(") {
-					checkNext = false
-					continue
-				}
-				if strings.Contains(t, ",") {
-					values := strings.Split(t, ",")
-					for _, v := range values {
-						v := strings.TrimSpace(v)
-						if v != "" {
-							tables = append(tables, v)
-						} else {
-							takeNext = true
-							break
-						}
-					}
-					continue
-				}
-				tables = append(tables, t)
-				checkNext = false
+
+	p := &refParser{tokens: tokens, ctes: map[string]struct{}{}}
+	if err := p.checkParens(); err != nil {
+		return nil, err
+	}
+	if err := p.checkSelect(); err != nil {
+		return nil, err
+	}
+	p.scan()
+
+	refs := make([]TableRef, 0, len(p.refs))
+	for _, ref := range p.refs {
+		if _, ok := p.ctes[strings.ToLower(ref.Name)]; ok {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+type refParser struct {
+	tokens []token
+	refs   []TableRef
+	// ctes holds the lower cased names of the common table expressions defined in the statement.
+	ctes map[string]struct{}
+	// closing holds the index of the matching closing parenthesis for each opening parenthesis.
+	closing map[int]int
+}
+
+// checkParens makes sure that all parentheses are balanced, and records the matching pairs.
+func (p *refParser) checkParens() error {
+	p.closing = map[int]int{}
+	var open []int
+	for i, t := range p.tokens {
+		switch {
+		case t.isPunct("("):
+			open = append(open, i)
+		case t.isPunct(")"):
+			if len(open) == 0 {
+				return &ParseError{Pos: t.pos, Msg: "unexpected closing parenthesis"}
 			}
-			if t == "FROM" {
-				checkNext = true
+			p.closing[open[len(open)-1]] = i
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return &ParseError{Pos: p.tokens[open[len(open)-1]].pos, Msg: "unclosed parenthesis"}
+	}
+	return nil
+}
+
+// checkSelect returns an error if the statement is not a query, and records the names of any CTEs.
+func (p *refParser) checkSelect() error {
+	i := p.skipOpenParens(0)
+	if p.tokens[i].isKeyword("WITH") {
+		next, err := p.readCTEs(i + 1)
+		if err != nil {
+			return err
+		}
+		i = p.skipOpenParens(next)
+		if i >= len(p.tokens) {
+			return &ParseError{Pos: p.tokens[len(p.tokens)-1].pos, Msg: "missing SELECT statement after WITH"}
+		}
+	}
+	return p.expectQuery(i)
+}
+
+// expectQuery returns an error if the token at i does not start a query.
+func (p *refParser) expectQuery(i int) error {
+	if p.startsQuery(i) {
+		return nil
+	}
+	t := p.tokens[i]
+	return &ParseError{Pos: t.pos, Msg: fmt.Sprintf("only SELECT statements are supported, got %q", t.text)}
+}
+
+// startsQuery returns true if the token at i, ignoring any opening parentheses, starts a query.
+func (p *refParser) startsQuery(i int) bool {
+	i = p.skipOpenParens(i)
+	if i >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[i]
+	// FROM is allowed to start a query, since DuckDB supports the FROM-first syntax
+	return t.isKeyword("SELECT") || t.isKeyword("WITH") || t.isKeyword("FROM") || t.isKeyword("VALUES")
+}
+
+func (p *refParser) skipOpenParens(i int) int {
+	for i < len(p.tokens) && p.tokens[i].isPunct("(") {
+		i++
+	}
+	return i
+}
+
+// readCTEs reads the common table expressions of a WITH clause, starting after the WITH keyword,
+// and returns the index of the token after the last one.
+func (p *refParser) readCTEs(i int) (int, error) {
+	if i < len(p.tokens) && p.tokens[i].isKeyword("RECURSIVE") {
+		i++
+	}
+	for {
+		if i >= len(p.tokens) || !p.tokens[i].isName() {
+			return 0, p.errorAt(i, "expected name of common table expression")
+		}
+		p.ctes[strings.ToLower(p.tokens[i].text)] = struct{}{}
+		i++
+		if i < len(p.tokens) && p.tokens[i].isPunct("(") { // column names
+			i = p.closing[i] + 1
+		}
+		if i >= len(p.tokens) || !p.tokens[i].isKeyword("AS") {
+			return 0, p.errorAt(i, "expected AS in common table expression")
+		}
+		i++
+		if i < len(p.tokens) && p.tokens[i].isKeyword("NOT") {
+			i++
+		}
+		if i < len(p.tokens) && p.tokens[i].isKeyword("MATERIALIZED") {
+			i++
+		}
+		if i >= len(p.tokens) || !p.tokens[i].isPunct("(") {
+			return 0, p.errorAt(i, "expected parenthesis in common table expression")
+		}
+		if err := p.expectQuery(i + 1); err != nil {
+			return 0, err
+		}
+		i = p.closing[i] + 1
+		if i < len(p.tokens) && p.tokens[i].isPunct(",") {
+			i++
+			continue
+		}
+		return i, nil
+	}
+}
+
+func (p *refParser) errorAt(i int, msg string) error {
+	if i >= len(p.tokens) {
+		last := p.tokens[len(p.tokens)-1]
+		return &ParseError{Pos: last.pos + len(last.text), Msg: msg}
+	}
+	return &ParseError{Pos: p.tokens[i].pos, Msg: fmt.Sprintf("%s, got %q", msg, p.tokens[i].text)}
+}
+
+// scope is the context of a pair of parentheses, or of the whole statement.
+type scope struct {
+	// query is true if the parentheses hold a query, rather than for example the arguments of a function.
+	// FROM is only a table clause in a query, since it is also used by functions such as EXTRACT(YEAR FROM t).
+	query bool
+	// fromClause is true while scanning the FROM clause of the query, where a comma is followed by another table.
+	fromClause bool
+}
+
+// clauseKeywords are the keywords that end a FROM clause.
+var clauseKeywords = map[string]struct{}{
+	"EXCEPT": {}, "FETCH": {}, "GROUP": {}, "HAVING": {}, "INTERSECT": {}, "LIMIT": {}, "OFFSET": {},
+	"ORDER": {}, "QUALIFY": {}, "SELECT": {}, "UNION": {}, "WHERE": {}, "WINDOW": {},
+}
+
+// scan walks the tokens and records every table referenced by a FROM or JOIN clause.
+func (p *refParser) scan() {
+	scopes := []*scope{{query: true}}
+	for i := 0; i < len(p.tokens); i++ {
+		t := p.tokens[i]
+		current := scopes[len(scopes)-1]
+		switch {
+		case t.isPunct("("):
+			scopes = append(scopes, &scope{query: p.startsQuery(i + 1)})
+		case t.isPunct(")"):
+			scopes = scopes[:len(scopes)-1]
+		case !current.query:
+			continue
+		case t.isKeyword("FROM") && !p.isDistinctFrom(i):
+			current.fromClause = true
+			i = p.readTable(i + 1)
+		case t.isKeyword("JOIN"), current.fromClause && t.isPunct(","):
+			i = p.readTable(i + 1)
+		case t.kind == tokenIdent:
+			if _, ok := clauseKeywords[strings.ToUpper(t.text)]; ok {
+				current.fromClause = false
 			}
 		}
-		return tables, nil
 	}
-	return nil, errors.New("not a select statement")
+}
+
+// readTable reads the table reference starting at i, which follows a FROM or JOIN keyword
+// or a comma in the FROM clause. Subqueries are left to the caller to scan.
+// It returns the index of the last token read.
+func (p *refParser) readTable(i int) int {
+	if i < len(p.tokens) && p.tokens[i].isKeyword("LATERAL") {
+		i++
+	}
+	if i >= len(p.tokens) || !p.tokens[i].isName() {
+		return i - 1
+	}
+
+	ref := TableRef{Name: p.tokens[i].text, Pos: p.tokens[i].pos}
+	i++
+	for i+1 < len(p.tokens) && p.tokens[i].isPunct(".") && p.tokens[i+1].isName() {
+		ref.Name += "." + p.tokens[i+1].text
+		i += 2
+	}
+	if i < len(p.tokens) && p.tokens[i].isPunct("(") { // table function
+		return i - 1
+	}
+	p.refs = append(p.refs, ref)
+	return p.skipAlias(i) - 1
+}
+
+// skipAlias returns the index after the optional alias of a table reference that starts at i.
+func (p *refParser) skipAlias(i int) int {
+	hasAlias := false
+	if i < len(p.tokens) && p.tokens[i].isKeyword("AS") {
+		i++
+		hasAlias = true
+	}
+	if i < len(p.tokens) && p.tokens[i].isName() {
+		i++
+		hasAlias = true
+	}
+	if hasAlias && i < len(p.tokens) && p.tokens[i].isPunct("(") { // column aliases
+		i = p.closing[i] + 1
+	}
+	return i
+}
+
+// isDistinctFrom returns true if the FROM keyword at i is part of an IS [NOT] DISTINCT FROM comparison.
+func (p *refParser) isDistinctFrom(i int) bool {
+	return i >= 2 && p.tokens[i-1].isKeyword("DISTINCT") &&
+		(p.tokens[i-2].isKeyword("IS") || p.tokens[i-2].isKeyword("NOT"))
 }
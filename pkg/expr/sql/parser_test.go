@@ -4,33 +4,34 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParse(t *testing.T) {
 	sql := "select * from foo"
-	tables, err := parseTables((sql))
+	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
-	assert.Equal(t, "FOO", tables[0])
+	assert.Equal(t, "foo", tables[0])
 }
 
 func TestParseWithComma(t *testing.T) {
 	sql := "select * from foo,bar"
-	tables, err := parseTables((sql))
+	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
-	assert.Equal(t, "FOO", tables[0])
-	assert.Equal(t, "BAR", tables[1])
+	assert.Equal(t, "foo", tables[0])
+	assert.Equal(t, "bar", tables[1])
 }
 
 func TestParseWithCommas(t *testing.T) {
 	sql := "select * from foo,bar,baz"
-	tables, err := parseTables((sql))
+	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
-	assert.Equal(t, "FOO", tables[0])
-	assert.Equal(t, "BAR", tables[1])
-	assert.Equal(t, "BAZ", tables[2])
+	assert.Equal(t, "foo", tables[0])
+	assert.Equal(t, "bar", tables[1])
+	assert.Equal(t, "baz", tables[2])
 }
 
 func TestArray(t *testing.T) {
@@ -56,3 +57,102 @@ func TestXxx(t *testing.T) {
 
 	assert.Equal(t, 0, len(tables))
 }
+
+func TestTableRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []TableRef
+	}{
+		{
+			name:     "single table with alias",
+			sql:      "SELECT a.value FROM A a WHERE a.value > 1",
+			expected: []TableRef{{Name: "A", Pos: 20}},
+		},
+		{
+			name:     "joins",
+			sql:      "SELECT * FROM A LEFT JOIN B ON A.time = B.time INNER JOIN C AS c USING (time)",
+			expected: []TableRef{{Name: "A", Pos: 14}, {Name: "B", Pos: 26}, {Name: "C", Pos: 58}},
+		},
+		{
+			name:     "comma after join",
+			sql:      "SELECT * FROM A JOIN B ON (A.x = B.x), C ORDER BY 1",
+			expected: []TableRef{{Name: "A", Pos: 14}, {Name: "B", Pos: 21}, {Name: "C", Pos: 39}},
+		},
+		{
+			name:     "quoted identifiers",
+			sql:      `SELECT * FROM "my query", ` + "`other``s`",
+			expected: []TableRef{{Name: "my query", Pos: 14}, {Name: "other`s", Pos: 26}},
+		},
+		{
+			name:     "subqueries",
+			sql:      "SELECT * FROM (SELECT * FROM A) x, B WHERE x.v IN (SELECT v FROM C)",
+			expected: []TableRef{{Name: "A", Pos: 29}, {Name: "B", Pos: 35}, {Name: "C", Pos: 65}},
+		},
+		{
+			name:     "CTE names are not tables",
+			sql:      "WITH x AS (SELECT * FROM A), y (v) AS MATERIALIZED (SELECT v FROM x) SELECT * FROM y JOIN B ON true",
+			expected: []TableRef{{Name: "A", Pos: 25}, {Name: "B", Pos: 90}},
+		},
+		{
+			name:     "FROM inside functions is not a table",
+			sql:      "SELECT EXTRACT(YEAR FROM time), TRIM(BOTH 'x' FROM name) FROM A WHERE a IS DISTINCT FROM b",
+			expected: []TableRef{{Name: "A", Pos: 62}},
+		},
+		{
+			name:     "table functions are not tables",
+			sql:      "SELECT * FROM range(10) r JOIN A ON true",
+			expected: []TableRef{{Name: "A", Pos: 31}},
+		},
+		{
+			name:     "comments and strings are ignored",
+			sql:      "-- FROM X\nSELECT 'FROM Y' /* FROM Z */ FROM A",
+			expected: []TableRef{{Name: "A", Pos: 44}},
+		},
+		{
+			name:     "FROM-first syntax",
+			sql:      "FROM A SELECT value",
+			expected: []TableRef{{Name: "A", Pos: 5}},
+		},
+		{
+			name:     "union",
+			sql:      "SELECT * FROM A UNION ALL SELECT * FROM B",
+			expected: []TableRef{{Name: "A", Pos: 14}, {Name: "B", Pos: 40}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := TableRefs(tt.sql)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, refs)
+		})
+	}
+}
+
+func TestTableRefsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		pos  int
+	}{
+		{name: "empty statement", sql: "  ", pos: 0},
+		{name: "not a select", sql: "DELETE FROM A", pos: 0},
+		{name: "not a select after CTE", sql: "WITH x AS (SELECT 1) DELETE FROM A", pos: 21},
+		{name: "not a select inside CTE", sql: "WITH x AS (DELETE FROM A) SELECT * FROM x", pos: 11},
+		{name: "multiple statements", sql: "SELECT 1; DROP TABLE A", pos: 8},
+		{name: "unclosed parenthesis", sql: "SELECT * FROM (SELECT 1", pos: 14},
+		{name: "unexpected parenthesis", sql: "SELECT 1)", pos: 8},
+		{name: "unterminated string", sql: "SELECT 'abc", pos: 7},
+		{name: "unterminated comment", sql: "SELECT 1 /* abc", pos: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := TableRefs(tt.sql)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, tt.pos, parseErr.Pos)
+		})
+	}
+}
@@ -0,0 +1,1007 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseStatement parses a single SELECT statement into the syntax tree run by the embedded engine.
+func parseStatement(rawSQL string) (*selectStmt, error) {
+	tokens, err := lex(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].isPunct(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, &ParseError{Pos: 0, Msg: "empty statement"}
+	}
+
+	p := &stmtParser{sql: rawSQL, tokens: tokens}
+	stmt, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.unexpected()
+	}
+	return stmt, nil
+}
+
+type stmtParser struct {
+	sql    string
+	tokens []token
+	i      int
+}
+
+func (p *stmtParser) done() bool {
+	return p.i >= len(p.tokens)
+}
+
+func (p *stmtParser) peek() token {
+	if p.done() {
+		return token{kind: tokenPunct, pos: len(p.sql), end: len(p.sql)}
+	}
+	return p.tokens[p.i]
+}
+
+func (p *stmtParser) peekKeyword(keywords ...string) bool {
+	t := p.peek()
+	for _, k := range keywords {
+		if t.isKeyword(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptKeyword consumes the next token if it is the keyword.
+func (p *stmtParser) acceptKeyword(keyword string) bool {
+	if p.peekKeyword(keyword) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *stmtParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+// operators are the operators made of more than one punctuation character.
+var operators = []string{"<=", ">=", "<>", "!=", "==", "||", "::"}
+
+// peekOp returns the operator at the current position, joining punctuation characters
+// that are next to each other into a single operator.
+func (p *stmtParser) peekOp() string {
+	t := p.peek()
+	if t.kind != tokenPunct || p.done() {
+		return ""
+	}
+	if p.i+1 < len(p.tokens) {
+		next := p.tokens[p.i+1]
+		if next.kind == tokenPunct && next.pos == t.end {
+			for _, op := range operators {
+				if op == t.text+next.text {
+					return op
+				}
+			}
+		}
+	}
+	return t.text
+}
+
+func (p *stmtParser) acceptOp(op string) bool {
+	if p.peekOp() == op {
+		p.i += len(op)
+		return true
+	}
+	return false
+}
+
+func (p *stmtParser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return p.errorf("expected %q", op)
+	}
+	return nil
+}
+
+func (p *stmtParser) errorf(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if p.done() {
+		return &ParseError{Pos: len(p.sql), Msg: msg + ", got end of statement"}
+	}
+	t := p.peek()
+	return &ParseError{Pos: t.pos, Msg: fmt.Sprintf("%s, got %q", msg, t.text)}
+}
+
+func (p *stmtParser) unexpected() error {
+	t := p.peek()
+	return &ParseError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func (p *stmtParser) unsupported(what string) error {
+	return &ParseError{Pos: p.peek().pos, Msg: what + " is not supported"}
+}
+
+// parseQuery parses a query with its optional WITH, ORDER BY, LIMIT and OFFSET clauses.
+func (p *stmtParser) parseQuery() (*selectStmt, error) {
+	stmt := &selectStmt{}
+	if p.acceptKeyword("WITH") {
+		if p.peekKeyword("RECURSIVE") {
+			return nil, p.unsupported("WITH RECURSIVE")
+		}
+		for {
+			c, err := p.parseCTE()
+			if err != nil {
+				return nil, err
+			}
+			stmt.with = append(stmt.with, c)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+
+	body, err := p.parseSetExpr()
+	if err != nil {
+		return nil, err
+	}
+	stmt.body = body
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		stmt.orderBy, err = p.parseOrderBy()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for p.peekKeyword("LIMIT", "OFFSET") {
+		isLimit := p.acceptKeyword("LIMIT")
+		if !isLimit {
+			p.i++
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if isLimit {
+			stmt.limit = e
+		} else {
+			stmt.offset = e
+		}
+	}
+	return stmt, nil
+}
+
+func (p *stmtParser) parseCTE() (cte, error) {
+	c := cte{}
+	if !p.peek().isName() {
+		return c, p.errorf("expected name of common table expression")
+	}
+	c.name = p.peek().text
+	p.i++
+	if p.acceptOp("(") {
+		for {
+			if !p.peek().isName() {
+				return c, p.errorf("expected column name")
+			}
+			c.columns = append(c.columns, p.peek().text)
+			p.i++
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return c, err
+		}
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return c, err
+	}
+	p.acceptKeyword("NOT")
+	p.acceptKeyword("MATERIALIZED")
+	if err := p.expectOp("("); err != nil {
+		return c, err
+	}
+	q, err := p.parseQuery()
+	if err != nil {
+		return c, err
+	}
+	c.query = q
+	return c, p.expectOp(")")
+}
+
+func (p *stmtParser) parseSetExpr() (queryBody, error) {
+	left, err := p.parseQueryPrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.peekKeyword("INTERSECT", "EXCEPT") {
+			return nil, p.unsupported(strings.ToUpper(p.peek().text))
+		}
+		if !p.acceptKeyword("UNION") {
+			return left, nil
+		}
+		op := &setOp{left: left}
+		if p.acceptKeyword("ALL") {
+			op.all = true
+		} else {
+			p.acceptKeyword("DISTINCT")
+		}
+		op.right, err = p.parseQueryPrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = op
+	}
+}
+
+func (p *stmtParser) parseQueryPrimary() (queryBody, error) {
+	if p.acceptOp("(") {
+		q, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		if len(q.with) == 0 && len(q.orderBy) == 0 && q.limit == nil && q.offset == nil {
+			return q.body, nil
+		}
+		return q, nil
+	}
+	if !p.peekKeyword("SELECT") {
+		return nil, p.errorf("expected SELECT")
+	}
+	return p.parseSelectCore()
+}
+
+func (p *stmtParser) parseSelectCore() (*selectCore, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	core := &selectCore{}
+	if p.acceptKeyword("DISTINCT") {
+		if p.peekKeyword("ON") {
+			return nil, p.unsupported("DISTINCT ON")
+		}
+		core.distinct = true
+	} else {
+		p.acceptKeyword("ALL")
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		core.items = append(core.items, item)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+
+	var err error
+	if p.acceptKeyword("FROM") {
+		core.from, err = p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("WHERE") {
+		core.where, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			core.groupBy = append(core.groupBy, e)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		core.having, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.peekKeyword("WINDOW", "QUALIFY") {
+		return nil, p.unsupported(strings.ToUpper(p.peek().text))
+	}
+	return core, nil
+}
+
+func (p *stmtParser) parseSelectItem() (selectItem, error) {
+	if p.acceptOp("*") {
+		return selectItem{star: true}, nil
+	}
+	if p.peek().isName() && p.i+2 < len(p.tokens) && p.tokens[p.i+1].isPunct(".") && p.tokens[p.i+2].isPunct("*") {
+		table := p.peek().text
+		p.i += 3
+		return selectItem{star: true, table: table}, nil
+	}
+
+	start := p.peek().pos
+	e, err := p.parseExpr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{expr: e}
+	if ref, ok := e.(columnRef); ok {
+		item.alias = ref.name
+	} else {
+		item.alias = p.sql[start:p.tokens[p.i-1].end]
+	}
+	hasAS := p.acceptKeyword("AS")
+	if t := p.peek(); t.isName() || (hasAS && t.kind == tokenString) {
+		item.alias = t.text
+		item.hasAlias = true
+		p.i++
+	} else if hasAS {
+		return item, p.errorf("expected alias")
+	}
+	return item, nil
+}
+
+func (p *stmtParser) parseOrderBy() ([]orderItem, error) {
+	var items []orderItem
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := orderItem{expr: e}
+		if p.acceptKeyword("DESC") {
+			item.desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		if p.acceptKeyword("NULLS") {
+			switch {
+			case p.acceptKeyword("FIRST"):
+				item.nullsFirst = true
+			case p.acceptKeyword("LAST"):
+			default:
+				return nil, p.errorf("expected FIRST or LAST")
+			}
+		}
+		items = append(items, item)
+		if !p.acceptOp(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseFrom parses the tables of a FROM clause. Tables separated by commas are cross joined.
+func (p *stmtParser) parseFrom() (tableExpr, error) {
+	left, err := p.parseJoins()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp(",") {
+		right, err := p.parseJoins()
+		if err != nil {
+			return nil, err
+		}
+		left = &joinTable{kind: joinCross, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *stmtParser) parseJoins() (tableExpr, error) {
+	left, err := p.parseTablePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.peekKeyword("NATURAL", "ASOF", "POSITIONAL", "SEMI", "ANTI") {
+			return nil, p.unsupported(strings.ToUpper(p.peek().text) + " JOIN")
+		}
+		kind := joinInner
+		switch {
+		case p.acceptKeyword("INNER"):
+		case p.acceptKeyword("LEFT"):
+			kind = joinLeft
+			p.acceptKeyword("OUTER")
+		case p.acceptKeyword("RIGHT"):
+			kind = joinRight
+			p.acceptKeyword("OUTER")
+		case p.acceptKeyword("FULL"):
+			kind = joinFull
+			p.acceptKeyword("OUTER")
+		case p.acceptKeyword("CROSS"):
+			kind = joinCross
+		case p.peekKeyword("JOIN"):
+		default:
+			return left, nil
+		}
+		if err := p.expectKeyword("JOIN"); err != nil {
+			return nil, err
+		}
+		right, err := p.parseTablePrimary()
+		if err != nil {
+			return nil, err
+		}
+		join := &joinTable{kind: kind, left: left, right: right}
+		switch {
+		case kind == joinCross:
+		case p.acceptKeyword("ON"):
+			join.on, err = p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("USING"):
+			if err := p.expectOp("("); err != nil {
+				return nil, err
+			}
+			for {
+				if !p.peek().isName() {
+					return nil, p.errorf("expected column name")
+				}
+				join.using = append(join.using, p.peek().text)
+				p.i++
+				if !p.acceptOp(",") {
+					break
+				}
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("expected ON or USING")
+		}
+		left = join
+	}
+}
+
+func (p *stmtParser) parseTablePrimary() (tableExpr, error) {
+	if p.acceptKeyword("LATERAL") {
+		return nil, p.unsupported("LATERAL")
+	}
+	if p.acceptOp("(") {
+		if p.peekKeyword("SELECT", "WITH") || p.peek().isPunct("(") {
+			q, err := p.parseQuery()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			alias, err := p.parseTableAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &subqueryTable{query: q, alias: alias}, nil
+		}
+		t, err := p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+		return t, p.expectOp(")")
+	}
+
+	if !p.peek().isName() {
+		return nil, p.errorf("expected table name")
+	}
+	t := &tableName{name: p.peek().text, pos: p.peek().pos}
+	p.i++
+	for p.i+1 < len(p.tokens) && p.peek().isPunct(".") && p.tokens[p.i+1].isName() {
+		t.name += "." + p.tokens[p.i+1].text
+		p.i += 2
+	}
+	if p.peek().isPunct("(") {
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("table function %s is not supported", t.name)}
+	}
+	alias, err := p.parseTableAlias()
+	if err != nil {
+		return nil, err
+	}
+	t.alias = alias
+	return t, nil
+}
+
+func (p *stmtParser) parseTableAlias() (string, error) {
+	hasAS := p.acceptKeyword("AS")
+	if !p.peek().isName() {
+		if hasAS {
+			return "", p.errorf("expected alias")
+		}
+		return "", nil
+	}
+	alias := p.peek().text
+	p.i++
+	if p.peek().isPunct("(") {
+		return "", p.unsupported("column aliases for tables")
+	}
+	return alias, nil
+}
+
+// parseExpr parses an expression. The precedence of the operators, from lowest to highest, is
+// OR, AND, NOT, comparisons (including IS, IN, BETWEEN and LIKE), ||, + and -, * / and %,
+// unary minus and finally :: casts.
+func (p *stmtParser) parseExpr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "OR", l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *stmtParser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "AND", l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *stmtParser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOps = map[string]string{"=": "=", "==": "=", "<>": "<>", "!=": "<>", "<": "<", "<=": "<=", ">": ">", ">=": ">="}
+
+func (p *stmtParser) parseComparison() (expr, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if op, ok := comparisonOps[p.peekOp()]; ok {
+			p.acceptOp(p.peekOp())
+			right, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			left = binaryExpr{op: op, l: left, r: right}
+			continue
+		}
+
+		if p.acceptKeyword("IS") {
+			not := p.acceptKeyword("NOT")
+			switch {
+			case p.acceptKeyword("NULL"):
+				left = isNullExpr{x: left, not: not}
+			case p.acceptKeyword("DISTINCT"):
+				if err := p.expectKeyword("FROM"); err != nil {
+					return nil, err
+				}
+				right, err := p.parseConcat()
+				if err != nil {
+					return nil, err
+				}
+				op := "IS DISTINCT FROM"
+				if not {
+					op = "IS NOT DISTINCT FROM"
+				}
+				left = binaryExpr{op: op, l: left, r: right}
+			case p.peekKeyword("TRUE", "FALSE"):
+				val := p.peekKeyword("TRUE")
+				p.i++
+				op := "IS NOT DISTINCT FROM"
+				if not {
+					op = "IS DISTINCT FROM"
+				}
+				left = binaryExpr{op: op, l: left, r: literal{val: val}}
+			default:
+				return nil, p.errorf("expected NULL, TRUE, FALSE or DISTINCT FROM after IS")
+			}
+			continue
+		}
+
+		// the NOT of NOT IN, NOT BETWEEN and NOT LIKE
+		not := false
+		if p.peekKeyword("NOT") && p.i+1 < len(p.tokens) {
+			next := p.tokens[p.i+1]
+			if next.isKeyword("IN") || next.isKeyword("BETWEEN") || next.isKeyword("LIKE") || next.isKeyword("ILIKE") {
+				p.i++
+				not = true
+			}
+		}
+
+		switch {
+		case p.acceptKeyword("IN"):
+			in, err := p.parseInList(left, not)
+			if err != nil {
+				return nil, err
+			}
+			left = in
+		case p.acceptKeyword("BETWEEN"):
+			lo, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			hi, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			left = betweenExpr{x: left, lo: lo, hi: hi, not: not}
+		case p.peekKeyword("LIKE", "ILIKE"):
+			op := strings.ToUpper(p.peek().text)
+			p.i++
+			right, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			left = binaryExpr{op: op, l: left, r: right}
+			if not {
+				left = unaryExpr{op: "NOT", x: left}
+			}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *stmtParser) parseInList(x expr, not bool) (expr, error) {
+	if err := p.expectOp("("); err != nil {
+		return nil, err
+	}
+	in := inExpr{x: x, not: not}
+	if p.peekKeyword("SELECT", "WITH") {
+		q, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		in.query = q
+		return in, p.expectOp(")")
+	}
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		in.list = append(in.list, e)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	return in, p.expectOp(")")
+}
+
+func (p *stmtParser) parseConcat() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "||", l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *stmtParser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peekOp()
+		if op != "+" && op != "-" {
+			return left, nil
+		}
+		p.acceptOp(op)
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, l: left, r: right}
+	}
+}
+
+func (p *stmtParser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peekOp()
+		if op != "*" && op != "/" && op != "%" {
+			return left, nil
+		}
+		p.acceptOp(op)
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, l: left, r: right}
+	}
+}
+
+func (p *stmtParser) parseUnary() (expr, error) {
+	switch {
+	case p.acceptOp("-"):
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		switch v := x.(type) {
+		case literal:
+			switch n := v.val.(type) {
+			case int64:
+				return literal{val: -n}, nil
+			case float64:
+				return literal{val: -n}, nil
+			}
+		}
+		return unaryExpr{op: "-", x: x}, nil
+	case p.acceptOp("+"):
+		return p.parseUnary()
+	}
+	return p.parsePostfix()
+}
+
+func (p *stmtParser) parsePostfix() (expr, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("::") {
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		x = castExpr{x: x, typ: typ}
+	}
+	return x, nil
+}
+
+func (p *stmtParser) parsePrimary() (expr, error) {
+	t := p.peek()
+	switch {
+	case p.done():
+		return nil, p.errorf("expected expression")
+	case t.kind == tokenNumber:
+		p.i++
+		return parseNumber(t)
+	case t.kind == tokenString:
+		p.i++
+		return literal{val: t.text}, nil
+	case t.isPunct("("):
+		p.i++
+		if p.peekKeyword("SELECT", "WITH") {
+			q, err := p.parseQuery()
+			if err != nil {
+				return nil, err
+			}
+			return subqueryExpr{query: q}, p.expectOp(")")
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expectOp(")")
+	case t.isKeyword("NULL"):
+		p.i++
+		return literal{val: nil}, nil
+	case t.isKeyword("TRUE"), t.isKeyword("FALSE"):
+		p.i++
+		return literal{val: t.isKeyword("TRUE")}, nil
+	case t.isKeyword("CASE"):
+		p.i++
+		return p.parseCase()
+	case t.isKeyword("CAST"), t.isKeyword("TRY_CAST"):
+		p.i++
+		if err := p.expectOp("("); err != nil {
+			return nil, err
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AS"); err != nil {
+			return nil, err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		return castExpr{x: x, typ: typ}, p.expectOp(")")
+	case t.isKeyword("EXISTS"):
+		return nil, p.unsupported("EXISTS")
+	case (t.isKeyword("DATE") || t.isKeyword("TIMESTAMP")) && p.i+1 < len(p.tokens) && p.tokens[p.i+1].kind == tokenString:
+		p.i += 2
+		return castExpr{x: literal{val: p.tokens[p.i-1].text}, typ: typeTimestamp}, nil
+	case t.kind == tokenQuotedIdent || t.kind == tokenIdent:
+		if _, reserved := reservedKeywords[strings.ToUpper(t.text)]; reserved && t.kind == tokenIdent {
+			return nil, p.errorf("expected expression")
+		}
+		p.i++
+		if t.kind == tokenIdent && p.peek().isPunct("(") {
+			return p.parseFuncCall(strings.ToLower(t.text))
+		}
+		if p.i+1 < len(p.tokens) && p.peek().isPunct(".") && p.tokens[p.i+1].isName() {
+			name := p.tokens[p.i+1].text
+			p.i += 2
+			return columnRef{table: t.text, name: name}, nil
+		}
+		return columnRef{name: t.text}, nil
+	}
+	return nil, p.errorf("expected expression")
+}
+
+func parseNumber(t token) (expr, error) {
+	if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+		return literal{val: i}, nil
+	}
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, &ParseError{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.text)}
+	}
+	return literal{val: f}, nil
+}
+
+func (p *stmtParser) parseFuncCall(name string) (expr, error) {
+	if err := p.expectOp("("); err != nil {
+		return nil, err
+	}
+	call := funcCall{name: name}
+	if p.acceptOp(")") {
+		return p.checkWindow(call)
+	}
+	if p.acceptOp("*") {
+		call.args = []expr{starExpr{}}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return p.checkWindow(call)
+	}
+	call.distinct = p.acceptKeyword("DISTINCT")
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, e)
+		if !p.acceptOp(",") {
+			break
+		}
+	}
+	if p.peekKeyword("ORDER") {
+		return nil, p.unsupported("ORDER BY in function arguments")
+	}
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	return p.checkWindow(call)
+}
+
+func (p *stmtParser) checkWindow(call funcCall) (expr, error) {
+	if p.peekKeyword("OVER", "FILTER") {
+		return nil, p.unsupported(strings.ToUpper(p.peek().text) + " clause")
+	}
+	return call, nil
+}
+
+func (p *stmtParser) parseCase() (expr, error) {
+	c := caseExpr{}
+	var err error
+	if !p.peekKeyword("WHEN") {
+		c.operand, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for p.acceptKeyword("WHEN") {
+		w := whenClause{}
+		w.cond, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		w.result, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.errorf("expected WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		c.els, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expectKeyword("END")
+}
+
+// The types that values can be cast to.
+const (
+	typeBigint    = "BIGINT"
+	typeDouble    = "DOUBLE"
+	typeVarchar   = "VARCHAR"
+	typeBoolean   = "BOOLEAN"
+	typeTimestamp = "TIMESTAMP"
+)
+
+var typeNames = map[string]string{
+	"TINYINT": typeBigint, "SMALLINT": typeBigint, "INT": typeBigint, "INTEGER": typeBigint, "BIGINT": typeBigint,
+	"HUGEINT": typeBigint, "INT2": typeBigint, "INT4": typeBigint, "INT8": typeBigint,
+	"FLOAT": typeDouble, "FLOAT4": typeDouble, "FLOAT8": typeDouble, "REAL": typeDouble, "DOUBLE": typeDouble,
+	"DECIMAL": typeDouble, "NUMERIC": typeDouble,
+	"VARCHAR": typeVarchar, "TEXT": typeVarchar, "STRING": typeVarchar, "CHAR": typeVarchar,
+	"BOOL": typeBoolean, "BOOLEAN": typeBoolean,
+	"TIMESTAMP": typeTimestamp, "TIMESTAMPTZ": typeTimestamp, "DATETIME": typeTimestamp, "DATE": typeTimestamp,
+}
+
+// parseType parses the name of a type, ignoring any precision, and returns one of the supported types.
+func (p *stmtParser) parseType() (string, error) {
+	t := p.peek()
+	if t.kind != tokenIdent {
+		return "", p.errorf("expected type")
+	}
+	name := strings.ToUpper(t.text)
+	p.i++
+	if name == "DOUBLE" {
+		p.acceptKeyword("PRECISION")
+	}
+	if name == "TIMESTAMP" && p.acceptKeyword("WITH") {
+		if !p.acceptKeyword("TIME") || !p.acceptKeyword("ZONE") {
+			return "", p.errorf("expected TIME ZONE")
+		}
+	}
+	if p.acceptOp("(") {
+		for !p.done() && !p.peek().isPunct(")") {
+			p.i++
+		}
+		if err := p.expectOp(")"); err != nil {
+			return "", err
+		}
+	}
+	typ, ok := typeNames[name]
+	if !ok {
+		return "", &ParseError{Pos: t.pos, Msg: fmt.Sprintf("unsupported type %s", t.text)}
+	}
+	return typ, nil
+}
@@ -0,0 +1,365 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// value is a single value in the embedded engine. It is nil for NULL, or one of
+// int64, float64, string, bool and time.Time.
+type value = any
+
+func typeName(v value) string {
+	switch v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return typeBigint
+	case float64:
+		return typeDouble
+	case string:
+		return typeVarchar
+	case bool:
+		return typeBoolean
+	case time.Time:
+		return typeTimestamp
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toFloat(v value) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func isNumber(v value) bool {
+	_, ok := toFloat(v)
+	return ok
+}
+
+// timeLayouts are the formats that strings are parsed with when they are compared with or cast to a timestamp.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// coerce converts a string to the type of the other operand of a comparison, the way a
+// literal such as '2024-01-01' or '10' is compared with a timestamp or number column.
+func coerce(a, b value) (value, value, error) {
+	sa, aIsString := a.(string)
+	sb, bIsString := b.(string)
+	var err error
+	switch {
+	case aIsString && !bIsString:
+		a, err = castValue(sa, typeName(b))
+	case bIsString && !aIsString:
+		b, err = castValue(sb, typeName(a))
+	}
+	return a, b, err
+}
+
+// compareValues compares two non-null values, returning -1, 0 or 1.
+func compareValues(a, b value) (int, error) {
+	a, b, err := coerce(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if ia, ok := a.(int64); ok {
+		if ib, ok := b.(int64); ok {
+			return compareOrdered(ia, ib), nil
+		}
+	}
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return compareOrdered(fa, fb), nil
+		}
+	}
+	switch va := a.(type) {
+	case string:
+		if vb, ok := b.(string); ok {
+			return strings.Compare(va, vb), nil
+		}
+	case bool:
+		if vb, ok := b.(bool); ok {
+			switch {
+			case va == vb:
+				return 0, nil
+			case vb:
+				return -1, nil
+			default:
+				return 1, nil
+			}
+		}
+	case time.Time:
+		if vb, ok := b.(time.Time); ok {
+			return va.Compare(vb), nil
+		}
+	}
+	return 0, fmt.Errorf("can not compare %s with %s", typeName(a), typeName(b))
+}
+
+func compareOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// sortCompare orders any two values, including nulls and values of different types,
+// so that sorting never fails. Nulls sort last unless nullsFirst is set.
+func sortCompare(a, b value, nullsFirst bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		if nullsFirst {
+			return -1
+		}
+		return 1
+	case b == nil:
+		if nullsFirst {
+			return 1
+		}
+		return -1
+	}
+	if c, err := compareValues(a, b); err == nil {
+		return c
+	}
+	return strings.Compare(typeName(a), typeName(b))
+}
+
+func arithmetic(op string, a, b value) (value, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if op == "||" {
+		return formatValue(a) + formatValue(b), nil
+	}
+	ia, aIsInt := a.(int64)
+	ib, bIsInt := b.(int64)
+	if aIsInt && bIsInt {
+		switch op {
+		case "+":
+			return ia + ib, nil
+		case "-":
+			return ia - ib, nil
+		case "*":
+			return ia * ib, nil
+		case "%":
+			if ib == 0 {
+				return nil, nil
+			}
+			return ia % ib, nil
+		}
+	}
+	fa, aOK := toFloat(a)
+	fb, bOK := toFloat(b)
+	if !aOK || !bOK {
+		return nil, fmt.Errorf("can not apply operator %s to %s and %s", op, typeName(a), typeName(b))
+	}
+	switch op {
+	case "+":
+		return fa + fb, nil
+	case "-":
+		return fa - fb, nil
+	case "*":
+		return fa * fb, nil
+	case "/":
+		// division always returns a DOUBLE, and division by zero returns NULL
+		if fb == 0 {
+			return nil, nil
+		}
+		return fa / fb, nil
+	case "%":
+		if fb == 0 {
+			return nil, nil
+		}
+		return math.Mod(fa, fb), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// castValue converts a value to one of the types that can be named in a CAST.
+func castValue(v value, typ string) (value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	invalid := func() (value, error) {
+		return nil, fmt.Errorf("can not cast %s %q to %s", typeName(v), formatValue(v), typ)
+	}
+	switch typ {
+	case typeBigint:
+		switch n := v.(type) {
+		case int64:
+			return n, nil
+		case float64:
+			if math.IsNaN(n) || math.IsInf(n, 0) {
+				return invalid()
+			}
+			return int64(math.Round(n)), nil
+		case bool:
+			if n {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			s := strings.TrimSpace(n)
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return castValue(f, typ)
+			}
+		case time.Time:
+			return n.UnixMilli(), nil
+		}
+	case typeDouble:
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		case bool:
+			if n {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(n), 64); err == nil {
+				return f, nil
+			}
+		case time.Time:
+			return float64(n.UnixNano()) / 1e9, nil
+		}
+	case typeVarchar:
+		return formatValue(v), nil
+	case typeBoolean:
+		switch n := v.(type) {
+		case bool:
+			return n, nil
+		case int64:
+			return n != 0, nil
+		case float64:
+			return n != 0, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(n)) {
+			case "true", "t", "1", "yes", "y":
+				return true, nil
+			case "false", "f", "0", "no", "n":
+				return false, nil
+			}
+		}
+	case typeTimestamp:
+		switch n := v.(type) {
+		case time.Time:
+			return n, nil
+		case string:
+			t, err := parseTime(n)
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
+		}
+	case "NULL":
+		return nil, nil
+	}
+	return invalid()
+}
+
+func formatValue(v value) string {
+	switch n := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return n
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(n)
+	case time.Time:
+		return n.Format("2006-01-02 15:04:05.999999999Z07:00")
+	}
+	return fmt.Sprint(v)
+}
+
+// valueSize is the approximate number of bytes used to hold a value, used to enforce the memory limit.
+func valueSize(v value) int64 {
+	const interfaceSize = 16
+	switch n := v.(type) {
+	case string:
+		return interfaceSize + 16 + int64(len(n))
+	case time.Time:
+		return interfaceSize + 24
+	case int64, float64:
+		return interfaceSize + 8
+	}
+	return interfaceSize
+}
+
+// writeKey writes an encoding of the value that is equal for equal values, used to group rows
+// and to remove duplicates.
+func writeKey(sb *strings.Builder, v value) {
+	switch n := v.(type) {
+	case nil:
+		sb.WriteString("n")
+	case int64:
+		// integers and floats that are equal must have the same key
+		if n > -1<<53 && n < 1<<53 {
+			sb.WriteString("f")
+			sb.WriteString(strconv.FormatFloat(float64(n), 'g', -1, 64))
+		} else {
+			sb.WriteString("i")
+			sb.WriteString(strconv.FormatInt(n, 10))
+		}
+	case float64:
+		sb.WriteString("f")
+		sb.WriteString(strconv.FormatFloat(n, 'g', -1, 64))
+	case string:
+		sb.WriteString("s")
+		sb.WriteString(strconv.Itoa(len(n)))
+		sb.WriteString(":")
+		sb.WriteString(n)
+	case bool:
+		sb.WriteString("b")
+		sb.WriteString(strconv.FormatBool(n))
+	case time.Time:
+		sb.WriteString("t")
+		sb.WriteString(strconv.FormatInt(n.UnixNano(), 10))
+	}
+	sb.WriteString(";")
+}
+
+func rowKey(row []value) string {
+	sb := strings.Builder{}
+	for _, v := range row {
+		writeKey(&sb, v)
+	}
+	return sb.String()
+}
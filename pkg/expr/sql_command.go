@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -12,38 +13,72 @@ import (
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
+// The engines that can run SQL expressions.
+const (
+	// SQLEngineDuckDB runs SQL expressions with the duckdb binary.
+	SQLEngineDuckDB = "duckdb"
+	// SQLEngineEmbedded runs SQL expressions in process with the engine of the sql package.
+	SQLEngineEmbedded = "embedded"
+)
+
 // SQLCommand is an expression to run SQL over results
 type SQLCommand struct {
 	query       string
 	varsToQuery []string
+	tableRefs   []sql.TableRef
 	refID       string
+	engine      string
+	limits      sql.Limits
 }
 
-// NewSQLCommand creates a new SQLCommand.
-func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+// NewSQLCommand creates a new SQLCommand, which runs with the engine and limits of the given configuration.
+func NewSQLCommand(refID, rawSQL string, cfg *setting.Cfg) (*SQLCommand, error) {
 	if rawSQL == "" {
 		return nil, errutil.BadRequest("sql-missing-query",
 			errutil.WithPublicMessage("missing SQL query"))
 	}
-	tables, err := sql.TablesList(rawSQL)
+	refs, err := sql.TableRefs(rawSQL)
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
-		return nil, errutil.BadRequest("sql-invalid-sql",
-			errutil.WithPublicMessage("error reading SQL command"),
-		)
+		return nil, makeSQLParseError(refID, err)
+	}
+	tables := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if !slices.Contains(tables, ref.Name) {
+			tables = append(tables, ref.Name)
+		}
 	}
-	return &SQLCommand{
+	cmd := &SQLCommand{
 		query:       rawSQL,
 		varsToQuery: tables,
+		tableRefs:   refs,
 		refID:       refID,
-	}, nil
+		engine:      SQLEngineDuckDB,
+	}
+	cmd.configure(cfg)
+	return cmd, nil
+}
+
+// configure sets the engine that runs the command, and the limits enforced by the embedded engine.
+func (gr *SQLCommand) configure(cfg *setting.Cfg) {
+	if cfg == nil {
+		return
+	}
+	if cfg.SQLExpressionsEngine != "" {
+		gr.engine = cfg.SQLExpressionsEngine
+	}
+	gr.limits = sql.Limits{
+		MaxRows:  cfg.SQLExpressionsMaxRows,
+		MaxBytes: cfg.SQLExpressionsMaxMemoryBytes,
+	}
 }
 
 // UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
-func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+func UnmarshalSQLCommand(rn *rawNode, cfg *setting.Cfg) (*SQLCommand, error) {
 	if rn.TimeRange == nil {
 		return nil, fmt.Errorf("time range must be specified for refID %s", rn.RefID)
 	}
@@ -57,7 +92,7 @@ func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
 		return nil, fmt.Errorf("expected sql expression to be type string, but got type %T", expressionRaw)
 	}
 
-	return NewSQLCommand(rn.RefID, expression)
+	return NewSQLCommand(rn.RefID, expression, cfg)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -81,9 +116,14 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 
 	rsp := mathexp.Results{}
 
-	duckDB := duck.NewInMemoryDB()
 	var frame = &data.Frame{}
-	err := duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	var err error
+	if gr.engine == SQLEngineEmbedded {
+		frame, err = sql.QueryFrames(ctx, gr.refID, gr.query, allFrames, gr.limits)
+	} else {
+		duckDB := duck.NewInMemoryDB()
+		err = duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	}
 	if err != nil {
 		rsp.Error = err
 		return rsp, nil
@@ -104,6 +144,21 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 	return rsp, nil
 }
 
+// validateRefIDs returns an error listing every table of the query, and its position,
+// that is not a query or expression in the request.
+func (gr *SQLCommand) validateRefIDs(registry map[string]Node) error {
+	var unknown []sql.TableRef
+	for _, ref := range gr.tableRefs {
+		if _, ok := registry[ref.Name]; !ok {
+			unknown = append(unknown, ref)
+		}
+	}
+	if len(unknown) > 0 {
+		return makeSQLUnknownRefIDError(gr.refID, unknown)
+	}
+	return nil
+}
+
 func (gr *SQLCommand) Type() string {
 	return TypeSQL.String()
 }
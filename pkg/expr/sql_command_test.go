@@ -1,12 +1,27 @@
 package expr
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/jsoniter"
+	sdkdata "github.com/grafana/grafana-plugin-sdk-go/experimental/apis/data/v0alpha1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 func TestNewCommand(t *testing.T) {
-	cmd, err := NewSQLCommand("a", "select a from foo, bar")
+	cmd, err := NewSQLCommand("a", "select a from foo, bar", nil)
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
 	}
@@ -24,3 +39,87 @@ func TestNewCommand(t *testing.T) {
 		return
 	}
 }
+
+func TestNewCommandInvalidSQL(t *testing.T) {
+	_, err := NewSQLCommand("A", "SELECT * FROM (SELECT * FROM B", nil)
+	require.Error(t, err)
+
+	var utilErr errutil.Error
+	require.True(t, errors.As(err, &utilErr))
+	require.Equal(t, "sse.sqlParseError", utilErr.MessageID)
+	require.Equal(t, map[string]any{
+		"refId":    "A",
+		"position": 14,
+		"error":    "unclosed parenthesis",
+	}, utilErr.PublicPayload)
+}
+
+func TestSQLCommandEmbeddedEngine(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "a"}),
+		data.NewField("value", nil, []float64{1, 2, 3}),
+	)
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}},
+	}
+
+	t.Run("runs the query in process", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT host, sum(value) AS total FROM A GROUP BY host ORDER BY host", &setting.Cfg{SQLExpressionsEngine: SQLEngineEmbedded})
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Values, 1)
+
+		result := rsp.Values[0].AsDataFrame()
+		require.Equal(t, "B", result.RefID)
+		require.Equal(t, []string{"a", "b"}, []string{result.Fields[0].At(0).(string), result.Fields[0].At(1).(string)})
+		require.Equal(t, []float64{4, 2}, []float64{result.Fields[1].At(0).(float64), result.Fields[1].At(1).(float64)})
+	})
+
+	t.Run("enforces the row limit", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A x, A y", &setting.Cfg{SQLExpressionsEngine: SQLEngineEmbedded, SQLExpressionsMaxRows: 5})
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.ErrorIs(t, rsp.Error, sql.ErrRowLimitExceeded)
+	})
+
+	t.Run("enforces the row limit of commands read by the query reader", func(t *testing.T) {
+		cfg := &setting.Cfg{SQLExpressionsEngine: SQLEngineEmbedded, SQLExpressionsMaxRows: 5}
+		reader := NewExpressionQueryReader(featuremgmt.WithFeatures(), cfg)
+		iter, err := jsoniter.ParseBytes(jsoniter.ConfigDefault, []byte(`{"expression": "SELECT * FROM A x, A y"}`))
+		require.NoError(t, err)
+		q, err := reader.ReadQuery(sdkdata.NewDataQuery(map[string]any{
+			"refId": "B",
+			"type":  string(QueryTypeSQL),
+		}), iter)
+		require.NoError(t, err)
+
+		rsp, err := q.Command.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.ErrorIs(t, rsp.Error, sql.ErrRowLimitExceeded)
+	})
+
+	t.Run("enforces the row limit of commands of the pipeline", func(t *testing.T) {
+		cfg := &setting.Cfg{SQLExpressionsEngine: SQLEngineEmbedded, SQLExpressionsMaxRows: 5}
+		for _, features := range []featuremgmt.FeatureToggles{
+			featuremgmt.WithFeatures(),
+			featuremgmt.WithFeatures(featuremgmt.FlagExpressionParser),
+		} {
+			node, err := buildCMDNode(&rawNode{
+				RefID:     "B",
+				Query:     map[string]any{"type": "sql", "expression": "SELECT * FROM A x, A y"},
+				QueryRaw:  []byte(`{"type": "sql", "expression": "SELECT * FROM A x, A y"}`),
+				TimeRange: AbsoluteTimeRange{From: time.Now().Add(-time.Hour), To: time.Now()},
+			}, features, cfg)
+			require.NoError(t, err)
+
+			rsp, err := node.Command.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.ErrorIs(t, rsp.Error, sql.ErrRowLimitExceeded)
+		}
+	})
+}
@@ -160,6 +160,7 @@ func (tc *ThresholdCommand) Execute(_ context.Context, _ time.Time, vars mathexp
 			newRes.Values = append(newRes.Values, s)
 		case mathexp.Number:
 			copyV := mathexp.NewNumber(tc.RefID, v.GetLabels())
+			copyV.AddFields(v.GetFields()...)
 			copyV.SetValue(eval(v.GetFloat64Value()))
 			newRes.Values = append(newRes.Values, copyV)
 		case mathexp.Scalar:
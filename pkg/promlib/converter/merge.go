@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/exp/slices"
+)
+
+// MergeRangeResults merges the results of consecutive sub-ranges of a range query into a single result. The frames of
+// the same series are concatenated, and samples that are not later than the last merged sample of the series are
+// dropped, so that overlapping sub-ranges do not produce duplicate samples. The results must be ordered by time.
+// The frames of the results are not modified, so they can be reused, for example when they are cached.
+// If any of the results has an error, that result is returned.
+func MergeRangeResults(rsps ...backend.DataResponse) backend.DataResponse {
+	merged := backend.DataResponse{Status: backend.StatusOK}
+	series := make(map[string]*data.Frame)
+	for _, rsp := range rsps {
+		if rsp.Error != nil {
+			return rsp
+		}
+		for _, frame := range rsp.Frames {
+			key, ok := seriesKey(frame)
+			if !ok {
+				merged.Frames = append(merged.Frames, cloneFrame(frame, true))
+				continue
+			}
+			target, exists := series[key]
+			if !exists {
+				target = cloneFrame(frame, false)
+				series[key] = target
+				merged.Frames = append(merged.Frames, target)
+			}
+			appendSamples(target, frame)
+			mergeNotices(target, frame)
+		}
+	}
+	return merged
+}
+
+// seriesKey returns a key that identifies the series of a time series frame across the results of sub-ranges.
+// It returns false if the frame is not a time series that can be merged.
+func seriesKey(frame *data.Frame) (string, bool) {
+	if len(frame.Fields) == 0 || frame.Fields[0].Type() != data.FieldTypeTime {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	if frame.Meta != nil {
+		sb.WriteString("\x00")
+		sb.WriteString(string(frame.Meta.Type))
+	}
+	for _, field := range frame.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString("\x00")
+		sb.WriteString(field.Type().ItemTypeString())
+		sb.WriteString("\x00")
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String(), true
+}
+
+// cloneFrame returns a copy of the frame. If withRows is false, the fields of the copy are empty.
+func cloneFrame(frame *data.Frame, withRows bool) *data.Frame {
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		clone := data.NewFieldFromFieldType(field.Type(), 0)
+		clone.Name = field.Name
+		if field.Labels != nil {
+			clone.Labels = field.Labels.Copy()
+		}
+		if field.Config != nil {
+			config := *field.Config
+			clone.Config = &config
+		}
+		if withRows {
+			for i := 0; i < field.Len(); i++ {
+				clone.Append(field.CopyAt(i))
+			}
+		}
+		fields = append(fields, clone)
+	}
+	clone := data.NewFrame(frame.Name, fields...)
+	clone.RefID = frame.RefID
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		meta.Notices = slices.Clone(frame.Meta.Notices)
+		clone.Meta = &meta
+	}
+	return clone
+}
+
+// appendSamples appends the samples of the frame that are later than the last sample of the target.
+func appendSamples(target, frame *data.Frame) {
+	n := target.Fields[0].Len()
+	var last time.Time
+	if n > 0 {
+		last = target.Fields[0].At(n - 1).(time.Time)
+	}
+	for i := 0; i < frame.Fields[0].Len(); i++ {
+		if n > 0 && !frame.Fields[0].At(i).(time.Time).After(last) {
+			continue
+		}
+		for j, field := range frame.Fields {
+			target.Fields[j].Append(field.CopyAt(i))
+		}
+	}
+}
+
+// mergeNotices adds the notices of the frame, such as warnings, that the target does not have yet.
+func mergeNotices(target, frame *data.Frame) {
+	if frame.Meta == nil || len(frame.Meta.Notices) == 0 {
+		return
+	}
+	if target.Meta == nil {
+		target.Meta = &data.FrameMeta{}
+	}
+	for _, notice := range frame.Meta.Notices {
+		if !slices.Contains(target.Meta.Notices, notice) {
+			target.Meta.Notices = append(target.Meta.Notices, notice)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func newSeriesFrame(labels data.Labels, start time.Time, values ...float64) *data.Frame {
+	times := make([]time.Time, 0, len(values))
+	for i := range values {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+	}
+	frame := data.NewFrame("",
+		data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+		data.NewField(data.TimeSeriesValueFieldName, labels, values),
+	)
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
+	return frame
+}
+
+func TestMergeRangeResults(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	up := data.Labels{"__name__": "up"}
+	down := data.Labels{"__name__": "down"}
+
+	t.Run("concatenates the samples of the same series", func(t *testing.T) {
+		first := backend.DataResponse{Frames: data.Frames{newSeriesFrame(up, start, 1, 2)}}
+		second := backend.DataResponse{Frames: data.Frames{
+			newSeriesFrame(down, start.Add(2*time.Minute), 10),
+			newSeriesFrame(up, start.Add(2*time.Minute), 3, 4),
+		}}
+
+		merged := MergeRangeResults(first, second)
+		require.NoError(t, merged.Error)
+		require.Equal(t, backend.StatusOK, merged.Status)
+		require.Len(t, merged.Frames, 2)
+		require.Equal(t, newSeriesFrame(up, start, 1, 2, 3, 4), merged.Frames[0])
+		require.Equal(t, newSeriesFrame(down, start.Add(2*time.Minute), 10), merged.Frames[1])
+	})
+
+	t.Run("drops overlapping samples", func(t *testing.T) {
+		first := backend.DataResponse{Frames: data.Frames{newSeriesFrame(up, start, 1, 2, 3)}}
+		second := backend.DataResponse{Frames: data.Frames{newSeriesFrame(up, start.Add(2*time.Minute), 3, 4)}}
+
+		merged := MergeRangeResults(first, second)
+		require.Len(t, merged.Frames, 1)
+		require.Equal(t, newSeriesFrame(up, start, 1, 2, 3, 4), merged.Frames[0])
+	})
+
+	t.Run("does not modify the frames of the results", func(t *testing.T) {
+		frame := newSeriesFrame(up, start, 1)
+		merged := MergeRangeResults(backend.DataResponse{Frames: data.Frames{frame}}, backend.DataResponse{Frames: data.Frames{newSeriesFrame(up, start.Add(time.Minute), 2)}})
+		require.Equal(t, 2, merged.Frames[0].Rows())
+		require.Equal(t, 1, frame.Rows())
+
+		merged.Frames[0].Meta.ExecutedQueryString = "up"
+		require.Empty(t, frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("merges the notices of the series", func(t *testing.T) {
+		first := newSeriesFrame(up, start, 1)
+		first.Meta.Notices = []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "warning 1"}}
+		second := newSeriesFrame(up, start.Add(time.Minute), 2)
+		second.Meta.Notices = []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "warning 1"}, {Severity: data.NoticeSeverityWarning, Text: "warning 2"}}
+
+		merged := MergeRangeResults(backend.DataResponse{Frames: data.Frames{first}}, backend.DataResponse{Frames: data.Frames{second}})
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "warning 1"}, {Severity: data.NoticeSeverityWarning, Text: "warning 2"}}, merged.Frames[0].Meta.Notices)
+	})
+
+	t.Run("returns the first result with an error", func(t *testing.T) {
+		failed := backend.DataResponse{Error: errors.New("bad_data: query timed out"), Status: backend.StatusBadRequest}
+		merged := MergeRangeResults(backend.DataResponse{Frames: data.Frames{newSeriesFrame(up, start, 1)}}, failed)
+		require.Equal(t, failed, merged)
+	})
+}
@@ -40,9 +40,13 @@ type QueryData struct {
 	client             *client.Client
 	log                log.Logger
 	ID                 int64
+	UID                string
+	Updated            time.Time
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+	split              splitSettings
+	splitCache         *splitCache
 }
 
 func New(
@@ -65,6 +69,11 @@ func New(
 		httpMethod = http.MethodPost
 	}
 
+	split, err := parseSplitSettings(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
 	// standard deviation sampler is the default for backwards compatibility
@@ -77,8 +86,12 @@ func New(
 		client:             promClient,
 		TimeInterval:       timeInterval,
 		ID:                 settings.ID,
+		UID:                settings.UID,
+		Updated:            settings.Updated,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+		split:              split,
+		splitCache:         newSplitCache(splitCacheTTL, splitCacheMaxSize),
 	}, nil
 }
 
@@ -91,9 +104,10 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	cfg := backend.GrafanaConfigFromContext(ctx)
 	hasPromQLScopeFeatureFlag := cfg.FeatureToggles().IsEnabled("promQLScope")
 	hasPrometheusDataplaneFeatureFlag := cfg.FeatureToggles().IsEnabled("prometheusDataplane")
+	identity := splitCacheIdentity(s.UID, s.Updated, req.GetHTTPHeaders())
 
 	for _, q := range req.Queries {
-		r := s.handleQuery(ctx, q, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
+		r := s.handleQuery(ctx, q, identity, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
 		if r == nil {
 			continue
 		}
@@ -103,7 +117,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
-func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
+func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, identity string, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
 	traceCtx, span := s.tracer.Start(ctx, "datasource.prometheus")
 	defer span.End()
 	query, err := models.Parse(span, bq, s.TimeInterval, s.intervalCalculator, fromAlert, hasPromQLScopeFeatureFlag)
@@ -113,14 +127,14 @@ func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromA
 		}
 	}
 
-	r := s.fetch(traceCtx, s.client, query, hasPrometheusDataplaneFeatureFlag)
+	r := s.fetch(traceCtx, s.client, query, identity, hasPrometheusDataplaneFeatureFlag)
 	if r == nil {
 		s.log.FromContext(ctx).Debug("Received nil response from runQuery", "query", query.Expr)
 	}
 	return r
 }
 
-func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, enablePrometheusDataplane bool) *backend.DataResponse {
+func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, identity string, enablePrometheusDataplane bool) *backend.DataResponse {
 	logger := s.log.FromContext(traceCtx)
 	logger.Debug("Sending query", "start", q.Start, "end", q.End, "step", q.Step, "query", q.Expr)
 
@@ -137,7 +151,7 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, enablePrometheusDataplane)
+		res := s.rangeQuery(traceCtx, client, q, identity, enablePrometheusDataplane)
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
@@ -164,7 +178,11 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	return dr
 }
 
-func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, identity string, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	if ranges := splitTimeRange(q.TimeRange(), s.split.interval, q.UtcOffsetSec); len(ranges) > 1 {
+		return s.splitRangeQuery(ctx, c, q, ranges, identity, enablePrometheusDataplaneFlag)
+	}
+
 	res, err := c.QueryRange(ctx, q)
 	if err != nil {
 		return backend.DataResponse{
@@ -34,6 +34,11 @@ func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *htt
 	})
 	r.Status = backend.Status(res.StatusCode)
 
+	return s.processResponse(ctx, q, r, enablePrometheusDataplaneFlag)
+}
+
+// processResponse adds the metadata of the query to the frames of the response and processes its exemplars.
+func (s *QueryData) processResponse(ctx context.Context, q *models.Query, r backend.DataResponse, enablePrometheusDataplaneFlag bool) backend.DataResponse {
 	// Add frame to attach metadata
 	if len(r.Frames) == 0 && !q.ExemplarQuery {
 		r.Frames = append(r.Frames, data.NewFrame(""))
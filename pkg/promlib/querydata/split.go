@@ -0,0 +1,161 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/converter"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// defaultSplitMaxConcurrency is the number of sub-ranges of a range query that are queried at the same time.
+	defaultSplitMaxConcurrency = 4
+	// splitCacheMinAge is how old the end of a sub-range must be for its result to be cached. Recent samples may
+	// still change, for example when they are ingested late.
+	splitCacheMinAge = 10 * time.Minute
+	// splitCacheTTL is how long the result of a sub-range is cached.
+	splitCacheTTL = time.Hour
+	// splitCacheMaxSize is the approximate size in bytes of the sub-range results that are cached per data source.
+	splitCacheMaxSize = 64 << 20
+)
+
+// splitSettings configures how range queries are split into sub-ranges.
+type splitSettings struct {
+	// interval is the length of the sub-ranges. Range queries are not split if it is zero.
+	interval time.Duration
+	// maxConcurrency is the number of sub-ranges that are queried at the same time.
+	maxConcurrency int
+}
+
+func parseSplitSettings(jsonData map[string]any) (splitSettings, error) {
+	settings := splitSettings{maxConcurrency: defaultSplitMaxConcurrency}
+	if interval, ok := jsonData["splitQueryInterval"].(string); ok && interval != "" {
+		d, err := gtime.ParseDuration(interval)
+		if err != nil {
+			return settings, fmt.Errorf("invalid splitQueryInterval: %w", err)
+		}
+		if d < 0 {
+			return settings, fmt.Errorf("invalid splitQueryInterval: %s must not be negative", interval)
+		}
+		settings.interval = d
+	}
+	if concurrency, ok := jsonData["splitQueryMaxConcurrency"].(float64); ok && concurrency > 0 {
+		settings.maxConcurrency = int(concurrency)
+	}
+	return settings, nil
+}
+
+// splitTimeRange splits the time range of a range query into sub-ranges that start at multiples of the interval,
+// so that the same sub-ranges are queried every time the query is refreshed. The sub-ranges are aligned to the step
+// of the query and do not overlap. The time range is not split if the interval is not longer than the step.
+func splitTimeRange(tr models.TimeRange, interval time.Duration, offset int64) []models.TimeRange {
+	if interval <= tr.Step || tr.Step <= 0 {
+		return []models.TimeRange{tr}
+	}
+	var ranges []models.TimeRange
+	for start := tr.Start; !start.After(tr.End); {
+		// the next sub-range starts at the first step that is not before the next multiple of the interval
+		boundary := models.AlignTimeRange(start.Add(interval), interval, offset)
+		next := models.AlignTimeRange(boundary, tr.Step, offset)
+		if next.Before(boundary) {
+			next = next.Add(tr.Step)
+		}
+		end := next.Add(-tr.Step)
+		if end.After(tr.End) {
+			end = tr.End
+		}
+		ranges = append(ranges, models.TimeRange{Start: start, End: end, Step: tr.Step})
+		start = next
+	}
+	return ranges
+}
+
+// splitCacheIdentity returns the identity under which the sub-range results of a request are cached. Results are
+// only shared between requests to the same version of the data source that forward the same HTTP headers, because
+// the forwarded headers, such as the OAuth token or the cookies of the user, can change what Prometheus returns.
+func splitCacheIdentity(uid string, updated time.Time, headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n%d\n", uid, updated.UnixNano())
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s: %q\n", name, headers.Values(name))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// splitRangeQuery queries the sub-ranges of a range query concurrently and merges their results.
+func (s *QueryData) splitRangeQuery(ctx context.Context, c *client.Client, q *models.Query, ranges []models.TimeRange, identity string, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	results := make([]backend.DataResponse, len(ranges))
+	sem := make(chan struct{}, s.split.maxConcurrency)
+	var wg sync.WaitGroup
+	for i, tr := range ranges {
+		// acquire the semaphore before starting the goroutine so that at most maxConcurrency goroutines exist
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, tr models.TimeRange) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.subRangeQuery(ctx, c, q, tr, identity, enablePrometheusDataplaneFlag)
+		}(i, tr)
+	}
+	wg.Wait()
+
+	r := converter.MergeRangeResults(results...)
+	return s.processResponse(ctx, q, r, enablePrometheusDataplaneFlag)
+}
+
+// subRangeQuery queries a sub-range of a range query. The results of sub-ranges that are old enough to not change
+// anymore are cached, so that refreshing the query only queries the most recent sub-ranges. The identity returned by
+// splitCacheIdentity is part of the cache key, so that results are never shared between users.
+func (s *QueryData) subRangeQuery(ctx context.Context, c *client.Client, q *models.Query, tr models.TimeRange, identity string, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	key := fmt.Sprintf("%s|%s|%d|%d|%d|%t", identity, q.Expr, tr.Step.Milliseconds(), tr.Start.UnixMilli(), tr.End.UnixMilli(), enablePrometheusDataplaneFlag)
+	cacheable := time.Since(tr.End) > splitCacheMinAge
+	if cacheable {
+		if r, ok := s.splitCache.Get(key); ok {
+			return r
+		}
+	}
+
+	subQuery := *q
+	subQuery.Start = tr.Start
+	subQuery.End = tr.End
+	res, err := c.QueryRange(ctx, &subQuery)
+	if err != nil {
+		return backend.DataResponse{
+			Error:  err,
+			Status: backend.StatusBadGateway,
+		}
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.log.FromContext(ctx).Warn("Failed to close query range response body", "error", err)
+		}
+	}()
+
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, res.Body, 1024)
+	r := converter.ReadPrometheusStyleResult(iter, converter.Options{
+		Dataplane: enablePrometheusDataplaneFlag,
+	})
+	r.Status = backend.Status(res.StatusCode)
+	if cacheable && r.Error == nil && r.Status == backend.StatusOK {
+		s.splitCache.Set(key, r)
+	}
+	return r
+}
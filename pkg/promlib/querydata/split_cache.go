@@ -0,0 +1,97 @@
+package querydata
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// splitCache caches the results of sub-ranges of range queries. It is bounded by the approximate size of the
+// cached frames and evicts the least recently used results when it is full.
+type splitCache struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	size    int
+	// entries is ordered from the most to the least recently used entry.
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+type splitCacheEntry struct {
+	key     string
+	res     backend.DataResponse
+	size    int
+	expires time.Time
+}
+
+func newSplitCache(ttl time.Duration, maxSize int) *splitCache {
+	return &splitCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+// Get returns the result cached under the key if it has not expired.
+func (c *splitCache) Get(key string) (backend.DataResponse, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return backend.DataResponse{}, false
+	}
+	entry := el.Value.(*splitCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(el)
+		return backend.DataResponse{}, false
+	}
+	c.entries.MoveToFront(el)
+	return entry.res, true
+}
+
+// Set caches the result under the key and evicts the least recently used results until the cache is not larger
+// than its maximum size. Results that are larger than the cache on their own are not cached.
+func (c *splitCache) Set(key string, res backend.DataResponse) {
+	size := responseSize(res)
+	if size > c.maxSize {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	entry := &splitCacheEntry{key: key, res: res, size: size, expires: time.Now().Add(c.ttl)}
+	c.items[key] = c.entries.PushFront(entry)
+	c.size += size
+	for c.size > c.maxSize {
+		c.remove(c.entries.Back())
+	}
+}
+
+func (c *splitCache) remove(el *list.Element) {
+	entry := c.entries.Remove(el).(*splitCacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
+
+// responseSize returns the approximate size of the frames of a response in bytes.
+func responseSize(res backend.DataResponse) int {
+	size := 0
+	for _, frame := range res.Frames {
+		size += len(frame.Name) + len(frame.RefID)
+		for _, field := range frame.Fields {
+			size += len(field.Name)
+			for name, value := range field.Labels {
+				size += len(name) + len(value)
+			}
+			// time and float values take 16 and 8 bytes, this does not need to be exact
+			size += 16 * field.Len()
+		}
+	}
+	return size
+}
@@ -0,0 +1,60 @@
+package querydata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func splitCacheTestResponse(rows int) backend.DataResponse {
+	return backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("", data.NewField("", nil, make([]float64, rows))),
+	}}
+}
+
+func TestSplitCache(t *testing.T) {
+	t.Run("evicts the least recently used results when it is full", func(t *testing.T) {
+		c := newSplitCache(time.Hour, 3*responseSize(splitCacheTestResponse(10)))
+		c.Set("a", splitCacheTestResponse(10))
+		c.Set("b", splitCacheTestResponse(10))
+		c.Set("c", splitCacheTestResponse(10))
+		_, ok := c.Get("a")
+		require.True(t, ok)
+
+		c.Set("d", splitCacheTestResponse(10))
+		_, ok = c.Get("b")
+		require.False(t, ok)
+		for _, key := range []string{"a", "c", "d"} {
+			_, ok = c.Get(key)
+			require.True(t, ok, key)
+		}
+
+		c.Set("e", splitCacheTestResponse(20))
+		require.LessOrEqual(t, c.size, c.maxSize)
+		_, ok = c.Get("e")
+		require.True(t, ok)
+		_, ok = c.Get("a")
+		require.False(t, ok)
+	})
+
+	t.Run("does not cache results that are larger than the cache", func(t *testing.T) {
+		c := newSplitCache(time.Hour, responseSize(splitCacheTestResponse(10)))
+		c.Set("a", splitCacheTestResponse(10))
+		c.Set("b", splitCacheTestResponse(11))
+		_, ok := c.Get("a")
+		require.True(t, ok)
+		_, ok = c.Get("b")
+		require.False(t, ok)
+	})
+
+	t.Run("does not return expired results", func(t *testing.T) {
+		c := newSplitCache(-time.Second, splitCacheMaxSize)
+		c.Set("a", splitCacheTestResponse(10))
+		_, ok := c.Get("a")
+		require.False(t, ok)
+		require.Zero(t, c.size)
+	})
+}
@@ -0,0 +1,179 @@
+package querydata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+	"github.com/grafana/grafana/pkg/promlib/querydata/exemplar"
+)
+
+func TestParseSplitSettings(t *testing.T) {
+	t.Run("does not split range queries by default", func(t *testing.T) {
+		settings, err := parseSplitSettings(map[string]any{})
+		require.NoError(t, err)
+		require.Equal(t, splitSettings{maxConcurrency: defaultSplitMaxConcurrency}, settings)
+	})
+
+	t.Run("reads the interval and the concurrency", func(t *testing.T) {
+		settings, err := parseSplitSettings(map[string]any{"splitQueryInterval": "1d", "splitQueryMaxConcurrency": float64(2)})
+		require.NoError(t, err)
+		require.Equal(t, splitSettings{interval: 24 * time.Hour, maxConcurrency: 2}, settings)
+	})
+
+	t.Run("fails if the interval is invalid", func(t *testing.T) {
+		_, err := parseSplitSettings(map[string]any{"splitQueryInterval": "one day"})
+		require.Error(t, err)
+	})
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("does not split if the interval is not longer than the step", func(t *testing.T) {
+		tr := models.TimeRange{Start: start, End: start.Add(48 * time.Hour), Step: time.Hour}
+		require.Equal(t, []models.TimeRange{tr}, splitTimeRange(tr, 0, 0))
+		require.Equal(t, []models.TimeRange{tr}, splitTimeRange(tr, time.Hour, 0))
+	})
+
+	t.Run("splits at multiples of the interval", func(t *testing.T) {
+		tr := models.TimeRange{Start: start, End: start.Add(48 * time.Hour), Step: time.Hour}
+		require.Equal(t, []models.TimeRange{
+			{Start: start, End: start.Add(11 * time.Hour), Step: time.Hour},
+			{Start: start.Add(12 * time.Hour), End: start.Add(35 * time.Hour), Step: time.Hour},
+			{Start: start.Add(36 * time.Hour), End: start.Add(48 * time.Hour), Step: time.Hour},
+		}, splitTimeRange(tr, 24*time.Hour, 0))
+	})
+
+	t.Run("aligns the sub-ranges to the step", func(t *testing.T) {
+		step := 7 * time.Minute
+		tr := models.TimeRange{
+			Start: models.AlignTimeRange(start, step, 0),
+			End:   models.AlignTimeRange(start.Add(6*time.Hour), step, 0),
+			Step:  step,
+		}
+		ranges := splitTimeRange(tr, time.Hour, 0)
+		require.GreaterOrEqual(t, len(ranges), 6)
+		require.Equal(t, tr.Start, ranges[0].Start)
+		require.Equal(t, tr.End, ranges[len(ranges)-1].End)
+		for i, r := range ranges {
+			require.Equal(t, r.Start, models.AlignTimeRange(r.Start, step, 0))
+			if i > 0 {
+				require.Equal(t, ranges[i-1].End.Add(step), r.Start)
+				require.False(t, r.Start.Before(r.Start.Truncate(time.Hour)))
+				require.Less(t, r.Start.Sub(r.Start.Truncate(time.Hour)), step)
+			}
+		}
+	})
+
+	t.Run("uses the same sub-ranges when the time range moves", func(t *testing.T) {
+		tr := models.TimeRange{Start: start, End: start.Add(48 * time.Hour), Step: time.Hour}
+		moved := models.TimeRange{Start: start.Add(3 * time.Hour), End: start.Add(51 * time.Hour), Step: time.Hour}
+		require.Equal(t, splitTimeRange(tr, 24*time.Hour, 0)[1], splitTimeRange(moved, 24*time.Hour, 0)[1])
+	})
+}
+
+// fakeRangeDoer responds to range queries with a single series that has the timestamp of every step as value.
+type fakeRangeDoer struct {
+	mtx      sync.Mutex
+	requests []string
+}
+
+func (d *fakeRangeDoer) Do(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	start, _ := strconv.ParseFloat(q.Get("start"), 64)
+	end, _ := strconv.ParseFloat(q.Get("end"), 64)
+	step, _ := strconv.ParseFloat(q.Get("step"), 64)
+
+	d.mtx.Lock()
+	d.requests = append(d.requests, q.Get("start")+"-"+q.Get("end"))
+	d.mtx.Unlock()
+
+	values := make([]string, 0)
+	for ts := start; ts <= end; ts += step {
+		values = append(values, fmt.Sprintf(`[%v,"%v"]`, ts, ts))
+	}
+	body := fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up"},"values":[%s]}]}}`, strings.Join(values, ","))
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+func (d *fakeRangeDoer) reset() []string {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	requests := d.requests
+	d.requests = nil
+	return requests
+}
+
+func TestQueryData_splitRangeQuery(t *testing.T) {
+	doer := &fakeRangeDoer{}
+	qd := QueryData{
+		client:          client.NewClient(doer, http.MethodGet, "http://localhost:9090"),
+		log:             log.New(),
+		exemplarSampler: exemplar.NewStandardDeviationSampler,
+		split:           splitSettings{interval: 24 * time.Hour, maxConcurrency: 2},
+		splitCache:      newSplitCache(splitCacheTTL, splitCacheMaxSize),
+	}
+	// the last sub-range ends now, so it is too recent to be cached
+	end := time.Now().UTC()
+	q := &models.Query{
+		Expr:       "up",
+		Step:       time.Minute,
+		Start:      end.Add(-72 * time.Hour),
+		End:        end,
+		RangeQuery: true,
+	}
+	rows := 72*60 + 1
+
+	identity := splitCacheIdentity("uid", time.Unix(0, 0), http.Header{"Authorization": []string{"Bearer a"}})
+	res := qd.rangeQuery(context.Background(), qd.client, q, identity, false)
+	require.NoError(t, res.Error)
+	require.Len(t, res.Frames, 1)
+	require.Equal(t, rows, res.Frames[0].Rows())
+	for i := 0; i < res.Frames[0].Rows(); i++ {
+		ts := res.Frames[0].Fields[0].At(i).(time.Time)
+		require.Equal(t, q.TimeRange().Start.Add(time.Duration(i)*time.Minute), ts)
+		require.Equal(t, float64(ts.Unix()), res.Frames[0].Fields[1].At(i))
+	}
+	require.Equal(t, "Expr: up\nStep: 1m0s", res.Frames[0].Meta.ExecutedQueryString)
+	requests := len(doer.reset())
+	assert.GreaterOrEqual(t, requests, 4)
+
+	// only the sub-ranges that are too recent to be cached are queried again
+	res = qd.rangeQuery(context.Background(), qd.client, q, identity, false)
+	require.NoError(t, res.Error)
+	require.Equal(t, rows, res.Frames[0].Rows())
+	assert.Len(t, doer.reset(), 1)
+
+	// results cached for one user are not returned to another
+	other := splitCacheIdentity("uid", time.Unix(0, 0), http.Header{"Authorization": []string{"Bearer b"}})
+	res = qd.rangeQuery(context.Background(), qd.client, q, other, false)
+	require.NoError(t, res.Error)
+	require.Equal(t, rows, res.Frames[0].Rows())
+	assert.Equal(t, requests, len(doer.reset()))
+}
+
+func TestSplitCacheIdentity(t *testing.T) {
+	updated := time.Unix(1700000000, 0)
+	headers := http.Header{"Authorization": []string{"Bearer a"}, "X-Id-Token": []string{"id"}}
+	identity := splitCacheIdentity("uid", updated, headers)
+
+	assert.Equal(t, identity, splitCacheIdentity("uid", updated, http.Header{"X-Id-Token": []string{"id"}, "Authorization": []string{"Bearer a"}}))
+	assert.NotEqual(t, identity, splitCacheIdentity("uid", updated, http.Header{"Authorization": []string{"Bearer b"}, "X-Id-Token": []string{"id"}}))
+	assert.NotEqual(t, identity, splitCacheIdentity("uid", updated, nil))
+	assert.NotEqual(t, identity, splitCacheIdentity("other", updated, headers))
+	assert.NotEqual(t, identity, splitCacheIdentity("uid", updated.Add(time.Second), headers))
+}
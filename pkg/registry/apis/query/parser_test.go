@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type parserTestObject struct {
@@ -28,7 +29,7 @@ type parserTestObject struct {
 
 func TestQuerySplitting(t *testing.T) {
 	ctx := context.Background()
-	parser := newQueryParser(expr.NewExpressionQueryReader(featuremgmt.WithFeatures()),
+	parser := newQueryParser(expr.NewExpressionQueryReader(featuremgmt.WithFeatures(), &setting.Cfg{}),
 		&legacyDataSourceRetriever{}, tracing.InitializeTracerForTest())
 
 	t.Run("missing datasource flavors", func(t *testing.T) {
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -29,20 +30,22 @@ import (
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner,
+	deleteExpiredStateHistoryService *historian.DeleteExpiredService) *CleanUpService {
 	s := &CleanUpService{
-		Cfg:                       cfg,
-		ServerLockService:         serverLockService,
-		ShortURLService:           shortURLService,
-		QueryHistoryService:       queryHistoryService,
-		store:                     sqlstore,
-		log:                       log.New("cleanup"),
-		dashboardVersionService:   dashboardVersionService,
-		dashboardSnapshotService:  dashSnapSvc,
-		deleteExpiredImageService: deleteExpiredImageService,
-		tempUserService:           tempUserService,
-		tracer:                    tracer,
-		annotationCleaner:         annotationCleaner,
+		Cfg:                              cfg,
+		ServerLockService:                serverLockService,
+		ShortURLService:                  shortURLService,
+		QueryHistoryService:              queryHistoryService,
+		store:                            sqlstore,
+		log:                              log.New("cleanup"),
+		dashboardVersionService:          dashboardVersionService,
+		dashboardSnapshotService:         dashSnapSvc,
+		deleteExpiredImageService:        deleteExpiredImageService,
+		tempUserService:                  tempUserService,
+		tracer:                           tracer,
+		annotationCleaner:                annotationCleaner,
+		deleteExpiredStateHistoryService: deleteExpiredStateHistoryService,
 	}
 	return s
 }
@@ -60,6 +63,8 @@ type CleanUpService struct {
 	deleteExpiredImageService *image.DeleteExpiredService
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
+
+	deleteExpiredStateHistoryService *historian.DeleteExpiredService
 }
 
 type cleanUpJob struct {
@@ -98,6 +103,7 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete expired snapshots", srv.deleteExpiredSnapshots},
 		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
 		{"delete expired images", srv.deleteExpiredImages},
+		{"delete expired alert state history", srv.deleteExpiredStateHistory},
 		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
@@ -224,6 +230,18 @@ func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredStateHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() || srv.deleteExpiredStateHistoryService == nil {
+		return
+	}
+	if rowsAffected, err := srv.deleteExpiredStateHistoryService.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired alert state history", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired alert state history", "rows affected", rowsAffected)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
@@ -105,6 +105,7 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
 			ActiveAt: &startsAt,
 			Value:    valString,
+			Flapping: alertState.Flapping,
 		})
 	}
 
@@ -427,6 +428,9 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
 
 		states := manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -443,6 +447,9 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			if alertState.Error != nil && rule.ExecErrState != ngmodels.ErrorErrState {
 				totals["error"] += 1
 			}
+			if alertState.Flapping {
+				totals["flapping"] += 1
+			}
 			alert := apimodels.Alert{
 				Labels:      alertState.GetLabels(labelOptions...),
 				Annotations: alertState.Annotations,
@@ -452,6 +459,7 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
 				ActiveAt: &activeAt,
 				Value:    valString,
+				Flapping: alertState.Flapping,
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
@@ -497,6 +505,9 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			if alertState.Error != nil && rule.ExecErrState != ngmodels.ErrorErrState {
 				totalsFiltered["error"] += 1
 			}
+			if alertState.Flapping {
+				totalsFiltered["flapping"] += 1
+			}
 
 			alertingRule.Alerts = append(alertingRule.Alerts, alert)
 		}
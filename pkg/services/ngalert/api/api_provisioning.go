@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -28,6 +29,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	datasourceCache     datasources.CacheService
 }
 
 type ContactPointService interface {
@@ -387,6 +389,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
 	provenance := determineProvenance(c)
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.SignedInUser, UID, alerting_models.Provenance(provenance))
+	if errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
@@ -401,6 +406,74 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleGroup(c *contextmodel.ReqContext, f
 	return response.JSON(http.StatusOK, ApiAlertRuleGroupFromAlertRuleGroup(g))
 }
 
+// RouteGetExport retrieves all alerting resources of the organization as a zip archive of Terraform and Prometheus rule files.
+func (srv *ProvisioningSrv) RouteGetExport(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	groups, err := srv.alertRules.GetAlertGroupsWithFolderTitle(ctx, c.SignedInUser, nil)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get alert rules", err)
+	}
+	bundle := exportBundle{}
+	bundle.Export, err = AlertingFileExportFromAlertRuleGroupWithFolderTitle(groups)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create alerting file export", err)
+	}
+
+	cps, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{
+		OrgID:   orgID,
+		Decrypt: c.QueryBoolWithDefault("decrypt", false),
+	}, c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrPermissionDenied) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	cpExport, err := AlertingFileExportFromEmbeddedContactPoints(orgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+	bundle.Export.ContactPoints = cpExport.ContactPoints
+
+	policies, err := srv.policies.GetPolicyTree(ctx, orgID)
+	if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	if err == nil {
+		policyExport, err := AlertingFileExportFromRoute(orgID, policies)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+		}
+		bundle.Export.Policies = policyExport.Policies
+	}
+
+	timings, err := srv.muteTimings.GetMuteTimings(ctx, orgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get mute timings", err)
+	}
+	bundle.Export.MuteTimings = AlertingFileExportFromMuteTimings(orgID, timings).MuteTimings
+
+	bundle.Templates, err = srv.templates.GetTemplates(ctx, orgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get templates")
+	}
+
+	bundle.Prometheus, bundle.Warnings = PrometheusRuleGroupsFromAlertRuleGroups(groups, func(uid string) bool {
+		ds, err := srv.datasourceCache.GetDatasourceByUID(ctx, uid, c.SignedInUser, false)
+		return err == nil && ds.Type == datasources.DS_PROMETHEUS
+	})
+
+	archive, err := bundle.Archive()
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create export archive")
+	}
+	return response.Respond(http.StatusOK, archive).
+		SetHeader("Content-Type", "application/zip").
+		SetHeader("Content-Disposition", `attachment;filename=export.zip`)
+}
+
 // RouteGetAlertRulesExport retrieves all alert rules in a format compatible with file provisioning.
 func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *contextmodel.ReqContext) response.Response {
 	folderUIDs := c.QueryStrings("folderUid")
@@ -500,6 +573,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
 	provenance := determineProvenance(c)
 	err := srv.alertRules.DeleteRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group, alerting_models.Provenance(provenance))
+	if errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
@@ -560,6 +636,25 @@ func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExp
 }
 
 func exportHcl(download bool, body definitions.AlertingFileExport) response.Response {
+	resources, err := hclResourcesFromExport(body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to convert to HCL resources", err)
+	}
+	hclBody, err := hcl.Encode(resources...)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "body hcl encode", err)
+	}
+	resp := response.Respond(http.StatusOK, hclBody)
+	if download {
+		return resp.
+			SetHeader("Content-Type", "application/terraform+hcl").
+			SetHeader("Content-Disposition", `attachment;filename=export.tf`)
+	}
+	return resp.SetHeader("Content-Type", "text/hcl")
+}
+
+// hclResourcesFromExport converts the resources of the export to Terraform resources.
+func hclResourcesFromExport(body definitions.AlertingFileExport) ([]hcl.Resource, error) {
 	resources := make([]hcl.Resource, 0, len(body.Groups)+len(body.ContactPoints)+len(body.Policies)+len(body.MuteTimings))
 	convertToResources := func() error {
 		for idx, group := range body.Groups {
@@ -605,17 +700,7 @@ func exportHcl(download bool, body definitions.AlertingFileExport) response.Resp
 		return nil
 	}
 	if err := convertToResources(); err != nil {
-		return response.Error(http.StatusInternalServerError, "failed to convert to HCL resources", err)
+		return nil, err
 	}
-	hclBody, err := hcl.Encode(resources...)
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, "body hcl encode", err)
-	}
-	resp := response.Respond(http.StatusOK, hclBody)
-	if download {
-		return resp.
-			SetHeader("Content-Type", "application/terraform+hcl").
-			SetHeader("Content-Disposition", `attachment;filename=export.tf`)
-	}
-	return resp.SetHeader("Content-Type", "text/hcl")
+	return resources, nil
 }
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -26,6 +29,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	dsfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
@@ -448,6 +453,51 @@ func TestProvisioningApi(t *testing.T) {
 	})
 
 	t.Run("exports", func(t *testing.T) {
+		t.Run("organization", func(t *testing.T) {
+			t.Run("GET returns a zip archive of Terraform and Prometheus files", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				sut.datasourceCache = &dsfakes.FakeCacheService{DataSources: []*datasources.DataSource{{UID: "prom-uid", Type: datasources.DS_PROMETHEUS}}}
+				rc := createTestRequestCtx()
+				insertRule(t, sut, createTestAlertRule("rule", 1))
+				promRule := createTestAlertRule("prom-rule", 1)
+				promRule.NotificationSettings = nil
+				promRule.Data = []definitions.AlertQuery{{
+					RefID:         "A",
+					DatasourceUID: "prom-uid",
+					Model:         json.RawMessage(`{"expr": "up == 0", "instant": true}`),
+					RelativeTimeRange: definitions.RelativeTimeRange{
+						From: definitions.Duration(60),
+						To:   definitions.Duration(0),
+					},
+				}}
+				insertRule(t, sut, promRule)
+
+				response := sut.RouteGetExport(&rc)
+				response.WriteTo(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "application/zip", rc.Context.Resp.Header().Get("Content-Type"))
+				archive, err := zip.NewReader(bytes.NewReader(response.Body()), int64(len(response.Body())))
+				require.NoError(t, err)
+				files := map[string]string{}
+				for _, f := range archive.File {
+					r, err := f.Open()
+					require.NoError(t, err)
+					content, err := io.ReadAll(r)
+					require.NoError(t, err)
+					files[f.Name] = string(content)
+				}
+				require.Contains(t, files, "terraform/alert_rules.tf")
+				require.Contains(t, files, "terraform/contact_points.tf")
+				require.Contains(t, files, "terraform/notification_policies.tf")
+				require.Contains(t, files, "terraform/message_templates.tf")
+				require.Contains(t, files["prometheus/Folder_Title.yaml"], "alert: prom-rule")
+				require.Contains(t, files["prometheus/Folder_Title.yaml"], "expr: (up == 0) != 0")
+				require.NotContains(t, files["prometheus/Folder_Title.yaml"], "alert: rule")
+				require.Contains(t, files["warnings.txt"], `rule "rule" (uid rule)`)
+			})
+		})
+
 		t.Run("alert rule group", func(t *testing.T) {
 			t.Run("are present, GET returns 200", func(t *testing.T) {
 				sut := createProvisioningSrvSut(t)
@@ -1711,6 +1761,7 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.folderService, env.dashboardService, env.quotas, env.xact, 60, 10, 100, env.log, &provisioning.NotificationSettingsValidatorProviderFake{}, env.rulesAuthz),
+		datasourceCache:     &dsfakes.FakeCacheService{},
 	}
 }
 
@@ -133,6 +133,9 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 			rulesToDelete = append(rulesToDelete, uid...)
 		}
 		if len(rulesToDelete) > 0 {
+			if err := store.ValidateRuleDependencies(ctx, srv.store, c.SignedInUser.GetOrgID(), nil, rulesToDelete); err != nil {
+				return err
+			}
 			err := srv.store.DeleteAlertRulesByUID(ctx, c.SignedInUser.GetOrgID(), rulesToDelete...)
 			if err != nil {
 				return err
@@ -153,7 +156,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 		if errors.As(err, &errutil.Error{}) {
 			return response.Err(err)
 		}
-		if errors.Is(err, errProvisionedResource) {
+		if errors.Is(err, errProvisionedResource) || errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
 			return ErrResp(http.StatusBadRequest, err, "failed to delete rule group")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
@@ -301,146 +304,167 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
-//
-//nolint:gocyclo
 func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
-		userNamespace, id := c.SignedInUser.GetNamespacedID()
-		logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
-			groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
-		groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+		var err error
+		finalChanges, dbConfig, err = srv.applyRuleGroupChanges(tranCtx, c, groupKey, rules, false)
+		return err
+	})
+
+	if err != nil {
+		return updateRuleGroupErrorResponse(err)
+	}
+
+	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
+		// This isn't strictly necessary since the alertmanager config is periodically synced.
+		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
 		if err != nil {
-			return err
+			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
 		}
+	}
 
-		if groupChanges.IsEmpty() {
-			finalChanges = groupChanges
-			logger.Info("No changes detected in the request. Do nothing")
-			return nil
-		}
+	return changesToResponse(finalChanges)
+}
+
+// updateRuleGroupErrorResponse converts the error returned by applyRuleGroupChanges to a response.
+func updateRuleGroupErrorResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+}
+
+// applyRuleGroupChanges calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
+// If dryRun is true the changes are calculated and verified but the database is not updated.
+// It returns the changes and the latest Alertmanager configuration if the notification settings of the rules changed.
+//
+//nolint:gocyclo
+func (srv RulerSrv) applyRuleGroupChanges(tranCtx context.Context, c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals, dryRun bool) (*store.GroupDelta, *ngmodels.AlertConfiguration, error) {
+	var dbConfig *ngmodels.AlertConfiguration
+	userNamespace, id := c.SignedInUser.GetNamespacedID()
+	logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
+		groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
+	groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if groupChanges.IsEmpty() {
+		logger.Info("No changes detected in the request. Do nothing")
+		return groupChanges, nil, nil
+	}
 
-		err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+		return nil, nil, err
+	}
+
+	newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
+	if len(newOrUpdatedNotificationSettings) > 0 {
+		dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
 		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("failed to get latest configuration: %w", err)
 		}
-
-		if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
-			return err
+		cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse configuration: %w", err)
 		}
-
-		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
-		if len(newOrUpdatedNotificationSettings) > 0 {
-			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
-			if err != nil {
-				return fmt.Errorf("failed to get latest configuration: %w", err)
-			}
-			cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
-			if err != nil {
-				return fmt.Errorf("failed to parse configuration: %w", err)
-			}
-			validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
-			for _, s := range newOrUpdatedNotificationSettings {
-				if err := validator.Validate(s); err != nil {
-					return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
-				}
+		validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
+		for _, s := range newOrUpdatedNotificationSettings {
+			if err := validator.Validate(s); err != nil {
+				return nil, nil, errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
 			}
 		}
+	}
 
-		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
-			return err
-		}
+	if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
+		return nil, nil, err
+	}
 
-		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
-		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+	if err := store.ValidateGroupDeltaDependencies(tranCtx, srv.store, groupChanges); err != nil {
+		return nil, nil, err
+	}
 
-		// Delete first as this could prevent future unique constraint violations.
-		if len(finalChanges.Delete) > 0 {
-			UIDs := make([]string, 0, len(finalChanges.Delete))
-			for _, rule := range finalChanges.Delete {
-				UIDs = append(UIDs, rule.UID)
-			}
+	finalChanges := store.UpdateCalculatedRuleFields(groupChanges)
+	if dryRun {
+		return finalChanges, nil, nil
+	}
+	logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
-			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
-				return fmt.Errorf("failed to delete rules: %w", err)
-			}
+	// Delete first as this could prevent future unique constraint violations.
+	if len(finalChanges.Delete) > 0 {
+		UIDs := make([]string, 0, len(finalChanges.Delete))
+		for _, rule := range finalChanges.Delete {
+			UIDs = append(UIDs, rule.UID)
 		}
 
-		if len(finalChanges.Update) > 0 {
-			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
-			for _, update := range finalChanges.Update {
-				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
-				updates = append(updates, ngmodels.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			err = srv.store.UpdateAlertRules(tranCtx, updates)
-			if err != nil {
-				return fmt.Errorf("failed to update rules: %w", err)
-			}
+		if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
-			for _, rule := range finalChanges.New {
-				inserts = append(inserts, *rule)
-			}
-			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
-			if err != nil {
-				return fmt.Errorf("failed to add rules: %w", err)
-			}
-			if len(added) != len(finalChanges.New) {
-				logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
-			} else {
-				for i, newRule := range finalChanges.New {
-					newRule.ID = added[i].ID
-					newRule.UID = added[i].UID
-				}
-			}
+	if len(finalChanges.Update) > 0 {
+		updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
+		for _, update := range finalChanges.Update {
+			logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+			updates = append(updates, ngmodels.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
+			})
 		}
-
-		if len(finalChanges.New) > 0 {
-			userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
-			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
-			}) // alert rule is table name
-			if err != nil {
-				return fmt.Errorf("failed to get alert rules quota: %w", err)
-			}
-			if limitReached {
-				return ngmodels.ErrQuotaReached
-			}
+		err = srv.store.UpdateAlertRules(tranCtx, updates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update rules: %w", err)
 		}
-		return nil
-	})
+	}
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
+	if len(finalChanges.New) > 0 {
+		inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
+		for _, rule := range finalChanges.New {
+			inserts = append(inserts, *rule)
+		}
+		added, err := srv.store.InsertAlertRules(tranCtx, inserts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add rules: %w", err)
+		}
+		if len(added) != len(finalChanges.New) {
+			logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
+		} else {
+			for i, newRule := range finalChanges.New {
+				newRule.ID = added[i].ID
+				newRule.UID = added[i].UID
+			}
 		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
 
-	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
-		// This isn't strictly necessary since the alertmanager config is periodically synced.
-		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+	if len(finalChanges.New) > 0 {
+		userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+		limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:  c.SignedInUser.GetOrgID(),
+			UserID: userID,
+		}) // alert rule is table name
 		if err != nil {
-			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
+			return nil, nil, fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return nil, nil, ngmodels.ErrQuotaReached
 		}
 	}
-
-	return changesToResponse(finalChanges)
+	return finalChanges, dbConfig, nil
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -507,6 +531,11 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			Provenance:           apimodels.Provenance(provenance),
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
+			FlapDetection:        AlertRuleFlapDetectionFromFlapDetection(r.FlapDetection),
+			RecoveryCondition:    r.RecoveryCondition,
+			Record:               AlertRuleRecordFromRecord(r.Record),
+			DependsOn:            r.DependsOn,
+			EvaluationTimeout:    ApiEvaluationTimeoutFromEvaluationTimeout(r.EvaluationTimeout),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ImportFromPrometheus converts the rule groups of a Prometheus rule file to Grafana-managed alerting and recording rules that
+// query the data source `datasourceUID`, and replaces the rule groups with the same names in the folder `namespaceUID`. Existing rules of the folder
+// are matched by title, so importing the same file again updates the rules instead of creating new ones.
+// If dryRun is true, the changes are calculated and verified but not applied.
+func (srv RulerSrv) ImportFromPrometheus(c *contextmodel.ReqContext, ruleFile apimodels.PrometheusRuleGroups, namespaceUID, datasourceUID string, dryRun bool) response.Response {
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasource_uid is required"), "")
+	}
+	if len(ruleFile.Groups) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the rule file has no rule groups"), "")
+	}
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	existing, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: []string{namespace.UID},
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	uidByTitle := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		uidByTitle[rule.Title] = rule.UID
+	}
+
+	type importedGroup struct {
+		key   ngmodels.AlertRuleGroupKey
+		rules []*ngmodels.AlertRuleWithOptionals
+	}
+	imported := make([]importedGroup, 0, len(ruleFile.Groups))
+	groupByTitle := make(map[string]string)
+	groupNames := make(map[string]struct{}, len(ruleFile.Groups))
+	var warnings []string
+	for _, group := range ruleFile.Groups {
+		if _, ok := groupNames[group.Name]; ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("rule group %q is defined more than once", group.Name), "")
+		}
+		groupNames[group.Name] = struct{}{}
+
+		ruleGroupConfig, groupWarnings, err := PostableRuleGroupFromPrometheusRuleGroup(group, datasourceUID, srv.cfg.RecordingRules.Enabled)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		warnings = append(warnings, groupWarnings...)
+		if len(ruleGroupConfig.Rules) == 0 {
+			warnings = append(warnings, fmt.Sprintf("rule group %q was not imported because it has no rules that can be imported", group.Name))
+			continue
+		}
+		for _, rule := range ruleGroupConfig.Rules {
+			title := rule.GrafanaManagedAlert.Title
+			if other, ok := groupByTitle[title]; ok {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("rule %q of group %q has the same name as a rule of group %q, but titles must be unique in the folder", title, group.Name, other), "")
+			}
+			groupByTitle[title] = group.Name
+			rule.GrafanaManagedAlert.UID = uidByTitle[title]
+		}
+
+		if err := srv.checkGroupLimits(ruleGroupConfig); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		rules, err := ValidateRuleGroup(&ruleGroupConfig, c.SignedInUser.GetOrgID(), namespace.UID, RuleLimitsFromConfig(srv.cfg))
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid rule group %q: %w", group.Name, err), "")
+		}
+		imported = append(imported, importedGroup{
+			key: ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    ruleGroupConfig.Name,
+			},
+			rules: rules,
+		})
+	}
+
+	result := apimodels.ImportRuleGroupsResponse{
+		DryRun:   dryRun,
+		Groups:   make([]apimodels.ImportRuleGroupResult, 0, len(imported)),
+		Warnings: warnings,
+	}
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for _, group := range imported {
+			changes, _, err := srv.applyRuleGroupChanges(tranCtx, c, group.key, group.rules, dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to import rule group %q: %w", group.key.RuleGroup, err)
+			}
+			result.Groups = append(result.Groups, importRuleGroupResult(group.key.RuleGroup, changes))
+		}
+		return nil
+	})
+	if err != nil {
+		return updateRuleGroupErrorResponse(err)
+	}
+
+	if dryRun {
+		result.Message = "rule groups were not imported because of dry run"
+		return response.JSON(http.StatusOK, result)
+	}
+	result.Message = "rule groups imported successfully"
+	return response.JSON(http.StatusAccepted, result)
+}
+
+func importRuleGroupResult(name string, changes *store.GroupDelta) apimodels.ImportRuleGroupResult {
+	result := apimodels.ImportRuleGroupResult{Name: name}
+	for _, r := range changes.New {
+		result.Created = append(result.Created, r.Title)
+	}
+	for _, r := range changes.Update {
+		result.Updated = append(result.Updated, apimodels.ImportRuleDiff{
+			UID:   r.Existing.UID,
+			Title: r.New.Title,
+			Diff:  r.Diff.String(),
+		})
+	}
+	for _, r := range changes.Delete {
+		result.Deleted = append(result.Deleted, r.Title)
+	}
+	return result
+}
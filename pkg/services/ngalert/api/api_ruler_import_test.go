@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+const importRuleFile = `
+groups:
+  - name: api
+    interval: 1m
+    rules:
+      - alert: HighErrorRate
+        expr: rate(errors_total[5m]) > 0.5
+        for: 5m
+        labels:
+          severity: critical
+        annotations:
+          summary: Too many errors
+      - alert: HostDown
+        expr: up == 0
+      - record: job:errors:rate5m
+        expr: sum by (job) (rate(errors_total[5m]))
+`
+
+func TestImportFromPrometheus(t *testing.T) {
+	orgID := int64(1)
+	f := &folder.Folder{UID: "folder-uid", Title: "folder"}
+	scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(f.UID)
+	permissions := map[int64]map[string][]string{orgID: {
+		ac.ActionAlertingRuleRead:    {scope},
+		ac.ActionAlertingRuleCreate:  {scope},
+		ac.ActionAlertingRuleUpdate:  {scope},
+		ac.ActionAlertingRuleDelete:  {scope},
+		dashboards.ActionFoldersRead: {scope},
+		datasources.ActionQuery:      {datasources.ScopeAll},
+	}}
+
+	var ruleFile apimodels.PrometheusRuleGroups
+	require.NoError(t, yaml.Unmarshal([]byte(importRuleFile), &ruleFile))
+
+	setup := func(t *testing.T) (*RulerSrv, *fakes.RuleStore, *models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], f)
+		existing := models.AlertRuleGen(models.WithOrgID(orgID), models.WithNamespace(f), models.WithTitle("HighErrorRate"), models.WithNoNotificationSettings())()
+		existing.RuleGroup = "old"
+		ruleStore.PutRule(context.Background(), existing)
+
+		srv := createService(ruleStore)
+		srv.cfg.DefaultRuleEvaluationInterval = time.Minute
+		srv.conditionValidator = &recordingConditionValidator{}
+		srv.QuotaService = quotatest.New(false, nil)
+		return srv, ruleStore, existing
+	}
+
+	parse := func(t *testing.T, body []byte) apimodels.ImportRuleGroupsResponse {
+		var result apimodels.ImportRuleGroupsResponse
+		require.NoError(t, json.Unmarshal(body, &result))
+		return result
+	}
+
+	t.Run("dry run should return the changes without applying them", func(t *testing.T) {
+		srv, ruleStore, existing := setup(t)
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, f.UID, "prom", true)
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		result := parse(t, resp.Body())
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, "api", result.Groups[0].Name)
+		require.Equal(t, []string{"HostDown"}, result.Groups[0].Created)
+		require.Len(t, result.Groups[0].Updated, 1)
+		require.Equal(t, existing.UID, result.Groups[0].Updated[0].UID)
+		require.Contains(t, result.Groups[0].Updated[0].Diff, "RuleGroup")
+		require.Len(t, result.Warnings, 1)
+		require.Contains(t, result.Warnings[0], `recording rule "job:errors:rate5m"`)
+
+		for _, op := range ruleStore.RecordedOps {
+			switch op.(type) {
+			case []models.AlertRule, []models.UpdateRule:
+				require.Failf(t, "unexpected write", "%T", op)
+			}
+		}
+	})
+
+	t.Run("should convert the rules and replace the existing ones", func(t *testing.T) {
+		srv, ruleStore, existing := setup(t)
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, f.UID, "prom", false)
+
+		require.Equal(t, http.StatusAccepted, resp.Status())
+		require.False(t, parse(t, resp.Body()).DryRun)
+
+		var inserted []models.AlertRule
+		var updated []models.UpdateRule
+		for _, op := range ruleStore.RecordedOps {
+			switch q := op.(type) {
+			case []models.AlertRule:
+				inserted = append(inserted, q...)
+			case []models.UpdateRule:
+				updated = append(updated, q...)
+			}
+		}
+		require.Len(t, inserted, 1)
+		require.Equal(t, "HostDown", inserted[0].Title)
+		require.Len(t, updated, 1)
+
+		rule := updated[0].New
+		require.Equal(t, existing.UID, rule.UID)
+		require.Equal(t, "api", rule.RuleGroup)
+		require.EqualValues(t, 60, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "Too many errors"}, rule.Annotations)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, "C", rule.Condition)
+		require.Len(t, rule.Data, 3)
+		require.Equal(t, "prom", rule.Data[0].DatasourceUID)
+
+		promRule, err := PrometheusRuleFromAlertRule(rule, func(uid string) bool { return uid == "prom" })
+		require.NoError(t, err)
+		require.Equal(t, "rate(errors_total[5m]) > 0.5", promRule.Expr)
+
+		t.Run("importing again should not change anything", func(t *testing.T) {
+			ruleStore := fakes.NewRuleStore(t)
+			ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], f)
+			inserted[0].UID = "host-down"
+			ruleStore.PutRule(context.Background(), &rule, &inserted[0])
+			srv.store = ruleStore
+			srv.xactManager = ruleStore
+
+			resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, f.UID, "prom", false)
+
+			require.Equal(t, http.StatusAccepted, resp.Status())
+			result := parse(t, resp.Body())
+			require.Empty(t, result.Groups[0].Created)
+			require.Empty(t, result.Groups[0].Updated)
+			require.Empty(t, result.Groups[0].Deleted)
+		})
+	})
+
+	t.Run("should import recording rules if they are enabled", func(t *testing.T) {
+		srv, ruleStore, _ := setup(t)
+		srv.cfg.RecordingRules.Enabled = true
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, f.UID, "prom", false)
+
+		require.Equal(t, http.StatusAccepted, resp.Status())
+		result := parse(t, resp.Body())
+		require.Empty(t, result.Warnings)
+		require.ElementsMatch(t, []string{"HostDown", "job:errors:rate5m"}, result.Groups[0].Created)
+
+		var recording []models.AlertRule
+		for _, op := range ruleStore.RecordedOps {
+			if q, ok := op.([]models.AlertRule); ok {
+				for _, rule := range q {
+					if rule.Type() == models.RuleTypeRecording {
+						recording = append(recording, rule)
+					}
+				}
+			}
+		}
+		require.Len(t, recording, 1)
+		require.Equal(t, "job:errors:rate5m", recording[0].GetRecord().Metric)
+		require.Equal(t, "A", recording[0].Condition)
+		require.Len(t, recording[0].Data, 1)
+	})
+
+	t.Run("should fail if the data source is not specified", func(t *testing.T) {
+		srv, _, _ := setup(t)
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), ruleFile, f.UID, "", false)
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should fail if titles are not unique", func(t *testing.T) {
+		srv, _, _ := setup(t)
+		duplicated := apimodels.PrometheusRuleGroups{Groups: []apimodels.PrometheusRuleGroup{
+			ruleFile.Groups[0],
+			{Name: "other", Rules: ruleFile.Groups[0].Rules[:1]},
+		}}
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, permissions, nil), duplicated, f.UID, "prom", false)
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Contains(t, string(resp.Body()), "titles must be unique in the folder")
+	})
+
+	t.Run("should fail if user cannot create rules", func(t *testing.T) {
+		srv, _, _ := setup(t)
+		readOnly := map[int64]map[string][]string{orgID: {
+			ac.ActionAlertingRuleRead:    {scope},
+			dashboards.ActionFoldersRead: {scope},
+			datasources.ActionQuery:      {datasources.ScopeAll},
+		}}
+
+		resp := srv.ImportFromPrometheus(createRequestContextWithPerms(orgID, readOnly, nil), ruleFile, f.UID, "prom", true)
+
+		require.Equal(t, http.StatusForbidden, resp.Status())
+	})
+}
@@ -180,6 +180,24 @@ func TestRouteDeleteAlertRules(t *testing.T) {
 				deleteCommands := getRecordedCommand(ruleStore)
 				require.Empty(t, deleteCommands)
 			})
+			t.Run("return 400 if other rules depend on the group", func(t *testing.T) {
+				ruleStore := initFakeRuleStore(t)
+
+				rulesInGroup := models.GenerateAlertRulesSmallNonEmpty(models.AlertRuleGen(withOrgID(orgID), withNamespace(folder), withGroup(groupName)))
+				ruleStore.PutRule(context.Background(), rulesInGroup...)
+				dependent := models.AlertRuleGen(withOrgID(orgID), withNamespace(folder), models.WithDependsOn(rulesInGroup[0].UID))()
+				ruleStore.PutRule(context.Background(), dependent)
+
+				permissions := createPermissionsForRules(rulesInGroup, orgID)
+				requestCtx := createRequestContextWithPerms(orgID, permissions, nil)
+
+				response := createService(ruleStore).RouteDeleteAlertRules(requestCtx, folder.UID, groupName)
+
+				require.Equalf(t, http.StatusBadRequest, response.Status(), "Expected 400 but got %d: %v", response.Status(), string(response.Body()))
+				require.Contains(t, string(response.Body()), dependent.UID)
+				deleteCommands := getRecordedCommand(ruleStore)
+				require.Empty(t, deleteCommands)
+			})
 		})
 	})
 }
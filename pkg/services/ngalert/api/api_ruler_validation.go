@@ -85,6 +85,9 @@ func validateRuleNode(
 			if ruleNode.GrafanaManagedAlert.Condition != "" {
 				return nil, fmt.Errorf("%w: query is not specified by condition is. You must specify both query and condition to update existing alert rule", ngmodels.ErrAlertRuleFailedValidation)
 			}
+			if ruleNode.GrafanaManagedAlert.RecoveryCondition != "" {
+				return nil, fmt.Errorf("%w: query is not specified but recovery condition is. You must specify query, condition and recovery condition to update existing alert rule", ngmodels.ErrAlertRuleFailedValidation)
+			}
 		} else {
 			return nil, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 		}
@@ -93,6 +96,12 @@ func validateRuleNode(
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
 		}
+		if ruleNode.GrafanaManagedAlert.RecoveryCondition != "" {
+			err = validateRecoveryCondition(ruleNode.GrafanaManagedAlert.RecoveryCondition, ruleNode.GrafanaManagedAlert.Condition, ruleNode.GrafanaManagedAlert.Data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+			}
+		}
 	}
 
 	queries := AlertQueriesFromApiAlertQueries(ruleNode.GrafanaManagedAlert.Data)
@@ -110,6 +119,9 @@ func validateRuleNode(
 		ExecErrState:    errorState,
 	}
 
+	newAlertRule.RecoveryCondition = ruleNode.GrafanaManagedAlert.RecoveryCondition
+	newAlertRule.DependsOn = ruleNode.GrafanaManagedAlert.DependsOn
+
 	if ruleNode.GrafanaManagedAlert.NotificationSettings != nil {
 		newAlertRule.NotificationSettings, err = validateNotificationSettings(ruleNode.GrafanaManagedAlert.NotificationSettings)
 		if err != nil {
@@ -117,6 +129,27 @@ func validateRuleNode(
 		}
 	}
 
+	if ruleNode.GrafanaManagedAlert.FlapDetection != nil {
+		newAlertRule.FlapDetection, err = validateFlapDetection(ruleNode.GrafanaManagedAlert.FlapDetection)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ruleNode.GrafanaManagedAlert.Record != nil {
+		newAlertRule.Record, err = validateRecord(ruleNode.GrafanaManagedAlert.Record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ruleNode.GrafanaManagedAlert.EvaluationTimeout != nil {
+		newAlertRule.EvaluationTimeout, err = validateEvaluationTimeout(time.Duration(*ruleNode.GrafanaManagedAlert.EvaluationTimeout), interval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
@@ -175,6 +208,19 @@ func validateCondition(condition string, queries []apimodels.AlertQuery) error {
 	return nil
 }
 
+// validateRecoveryCondition checks that the recovery condition refers to a query or expression other than the condition.
+func validateRecoveryCondition(recoveryCondition, condition string, queries []apimodels.AlertQuery) error {
+	if recoveryCondition == condition {
+		return errors.New("recovery condition cannot be the same as the condition")
+	}
+	for _, query := range queries {
+		if query.RefID == recoveryCondition {
+			return nil
+		}
+	}
+	return fmt.Errorf("recovery condition %s does not exist", recoveryCondition)
+}
+
 func validateInterval(interval, baseInterval time.Duration) (int64, error) {
 	intervalSeconds := int64(interval.Seconds())
 
@@ -206,6 +252,18 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateEvaluationTimeout validates the evaluation timeout of a rule. The timeout cannot be longer than the interval
+// of the rule, otherwise an evaluation could last until the next one is scheduled.
+func validateEvaluationTimeout(timeout time.Duration, interval time.Duration) (time.Duration, error) {
+	if timeout < 0 {
+		return 0, fmt.Errorf("%w: field `evaluation_timeout` cannot be negative [%v]", ngmodels.ErrAlertRuleFailedValidation, timeout)
+	}
+	if timeout > interval {
+		return 0, fmt.Errorf("%w: field `evaluation_timeout` [%v] cannot be longer than the evaluation interval [%v]", ngmodels.ErrAlertRuleFailedValidation, timeout, interval)
+	}
+	return timeout, nil
+}
+
 // ValidateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -286,3 +344,19 @@ func validateNotificationSettings(n *apimodels.AlertRuleNotificationSettings) ([
 		s,
 	}, nil
 }
+
+func validateFlapDetection(f *apimodels.AlertRuleFlapDetection) ([]ngmodels.FlapDetectionSettings, error) {
+	settings := FlapDetectionFromAlertRuleFlapDetection(f)
+	if err := settings[0].Validate(); err != nil {
+		return nil, fmt.Errorf("invalid flap detection: %w", err)
+	}
+	return settings, nil
+}
+
+func validateRecord(r *apimodels.AlertRuleRecord) ([]ngmodels.RecordSettings, error) {
+	settings := RecordFromAlertRuleRecord(r)
+	if err := settings[0].Validate(); err != nil {
+		return nil, fmt.Errorf("invalid record: %w", err)
+	}
+	return settings, nil
+}
@@ -186,6 +186,41 @@ func TestValidateCondition(t *testing.T) {
 	}
 }
 
+func TestValidateRecoveryCondition(t *testing.T) {
+	data := []apimodels.AlertQuery{{RefID: "A"}, {RefID: "B"}, {RefID: "C"}}
+	testcases := []struct {
+		name              string
+		recoveryCondition string
+		errorMsg          string
+	}{
+		{
+			name:              "error when recovery condition is the condition",
+			recoveryCondition: "B",
+			errorMsg:          "recovery condition cannot be the same as the condition",
+		},
+		{
+			name:              "error when recovery condition does not exist",
+			recoveryCondition: "D",
+			errorMsg:          "recovery condition D does not exist",
+		},
+		{
+			name:              "valid case",
+			recoveryCondition: "C",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRecoveryCondition(tc.recoveryCondition, "B", data)
+			if tc.errorMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.errorMsg)
+			}
+		})
+	}
+}
+
 func TestValidateRuleGroup(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -891,6 +926,55 @@ func TestValidateRuleNodeNotificationSettings(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNodeRecord(t *testing.T) {
+	cfg := config(t)
+
+	t.Run("maps record to the rule", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.Record = &apimodels.AlertRuleRecord{Metric: "grafana:test:value"}
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval*time.Duration(rand.Int63n(10)+1), rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.NoError(t, err)
+		require.Equal(t, []models.RecordSettings{{Metric: "grafana:test:value"}}, alert.Record)
+		require.Equal(t, models.RuleTypeRecording, alert.Type())
+	})
+
+	t.Run("fails if metric name is invalid", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.Record = &apimodels.AlertRuleRecord{Metric: "1invalid"}
+		_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval*time.Duration(rand.Int63n(10)+1), rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.ErrorContains(t, err, "invalid record")
+	})
+}
+
+func TestValidateRuleNodeEvaluationTimeout(t *testing.T) {
+	cfg := config(t)
+	interval := cfg.BaseInterval * 6
+
+	t.Run("maps evaluation timeout to the rule", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.EvaluationTimeout = util.Pointer(model.Duration(15 * time.Second))
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), interval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Second, alert.EvaluationTimeout)
+	})
+
+	t.Run("fails if evaluation timeout is negative", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.EvaluationTimeout = util.Pointer(model.Duration(-time.Second))
+		_, err := validateRuleNode(&r, util.GenerateShortUID(), interval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cannot be negative")
+	})
+
+	t.Run("fails if evaluation timeout is longer than the interval", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.EvaluationTimeout = util.Pointer(model.Duration(interval + time.Second))
+		_, err := validateRuleNode(&r, util.GenerateShortUID(), interval, rand.Int63(), randFolder().UID, RuleLimitsFromConfig(cfg))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cannot be longer than the evaluation interval")
+	})
+}
+
 func TestValidateRuleNodeReservedLabels(t *testing.T) {
 	cfg := config(t)
 
@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,8 +52,13 @@ type TestingApiSrv struct {
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	amConfigStore   AMConfigStore
+	ruleStore       RuleStore
 }
 
+// backtestRulesConcurrency is the maximum number of rules that are tested at the same time by BacktestRules.
+const backtestRulesConcurrency = 4
+
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
 // as true as possible to what would be generated by the ruler except that the resulting alerts are not filtered to
 // only Resolved / Firing and ready to send.
@@ -271,6 +277,10 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
+	if cmd.Notifications {
+		return srv.backtestNotifications(c, cmd, rule)
+	}
+
 	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
@@ -285,3 +295,201 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// backtestNotifications tests the rule like BacktestAlertRule, and simulates the notifications that the Grafana
+// Alertmanager of the organization would have sent for the alerts of the rule.
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, cmd apimodels.BacktestConfig, rule *ngmodels.AlertRule) response.Response {
+	folderTitle := ""
+	if cmd.NamespaceUID != "" {
+		folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+		}
+		rule.NamespaceUID = folder.UID
+		folderTitle = folder.Fullpath
+	}
+
+	amConfig, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), rule.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager configuration")
+	}
+	cfg, err := notifier.Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to parse the Alertmanager configuration")
+	}
+	policy := backtesting.NotificationPolicyFromConfig(&cfg.AlertmanagerConfig)
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
+	extraLabels := state.GetRuleExtraLabels(srv.log, rule, folderTitle, includeFolder)
+
+	states, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To, policy, extraLabels)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	result := apimodels.BacktestNotificationsResult{
+		States:        states,
+		Notifications: make([]apimodels.BacktestNotification, 0, len(notifications)),
+		Receivers:     make(map[string]int),
+	}
+	for _, n := range notifications {
+		result.Notifications = append(result.Notifications, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			GroupKey:    n.GroupKey,
+			GroupLabels: n.GroupLabels,
+			Status:      n.Status,
+			Firing:      labelsToMaps(n.Firing),
+			Resolved:    labelsToMaps(n.Resolved),
+		})
+		result.Receivers[n.Receiver]++
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func labelsToMaps(labels []data.Labels) []map[string]string {
+	result := make([]map[string]string, 0, len(labels))
+	for _, l := range labels {
+		result = append(result, l)
+	}
+	return result
+}
+
+// BacktestRules tests the rules of a folder or a rule group, and returns a summary of each rule. Modified definitions of
+// rules of the rule group can be tested in place of the saved ones, in which case the summary of the saved definition and
+// the difference between the summaries are returned too.
+func (srv TestingApiSrv) BacktestRules(c *contextmodel.ReqContext, cmd apimodels.BacktestRulesConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(400, nil, "From cannot be greater than To")
+	}
+	if len(cmd.Rules) > 0 && cmd.RuleGroup == "" {
+		return ErrResp(400, nil, "rule_group must be set to test modified rules")
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, orgID, c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	saved, err := srv.ruleStore.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: []string{folder.UID},
+		RuleGroup:     cmd.RuleGroup,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	saved.SortByGroupIndex()
+	savedByUID := make(map[string]*ngmodels.AlertRule, len(saved))
+	for _, r := range saved {
+		savedByUID[r.UID] = r
+	}
+
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = srv.cfg.DefaultRuleEvaluationInterval
+		if len(saved) > 0 {
+			interval = time.Duration(saved[0].IntervalSeconds) * time.Second
+		}
+	}
+	modified := make(map[string]*ngmodels.AlertRule, len(cmd.Rules))
+	var added []*ngmodels.AlertRule
+	for i := range cmd.Rules {
+		rule, err := validateRuleNode(&cmd.Rules[i], cmd.RuleGroup, interval, orgID, folder.UID, RuleLimitsFromConfig(srv.cfg))
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if rule.UID == "" {
+			// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs
+			rule.UID = "backtesting-" + util.GenerateShortUID()
+			added = append(added, rule)
+			continue
+		}
+		existing, ok := savedByUID[rule.UID]
+		if !ok {
+			return ErrResp(http.StatusNotFound, nil, "rule %s is not found in the rule group %s", rule.UID, cmd.RuleGroup)
+		}
+		patched := &ngmodels.AlertRuleWithOptionals{AlertRule: *rule}
+		ngmodels.PatchPartialAlertRule(existing, patched)
+		modified[rule.UID] = &patched.AlertRule
+	}
+
+	// the saved definition of a modified rule is tested right after the modified definition
+	rules := make([]*ngmodels.AlertRule, 0, len(saved)+len(cmd.Rules))
+	for _, r := range saved {
+		if m, ok := modified[r.UID]; ok {
+			rules = append(rules, m)
+		}
+		rules = append(rules, r)
+	}
+	rules = append(rules, added...)
+	if len(rules) == 0 {
+		return ErrResp(http.StatusNotFound, nil, "no rules to test")
+	}
+	for _, r := range rules {
+		if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, r); err != nil {
+			return errorToResponse(err)
+		}
+	}
+
+	summaries := srv.backtesting.TestRules(c.Req.Context(), c.SignedInUser, rules, cmd.From, cmd.To, backtestRulesConcurrency)
+
+	result := apimodels.BacktestRulesResult{Rules: make([]apimodels.BacktestRuleSummary, 0, len(saved)+len(added))}
+	for i := 0; i < len(summaries); i++ {
+		summary := toBacktestRuleSummary(summaries[i])
+		if _, ok := modified[summaries[i].Rule.UID]; ok {
+			i++
+			savedSummary := toBacktestRuleSummary(summaries[i])
+			summary.Saved = &savedSummary
+			summary.Diff = diffBacktestRuleSummaries(summary, savedSummary)
+		}
+		result.Rules = append(result.Rules, summary)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toBacktestRuleSummary(s backtesting.RuleSummary) apimodels.BacktestRuleSummary {
+	result := apimodels.BacktestRuleSummary{
+		UID:          s.Rule.UID,
+		Title:        s.Rule.Title,
+		RuleGroup:    s.Rule.RuleGroup,
+		Evaluations:  s.Evaluations,
+		Firings:      s.Firings,
+		Flaps:        s.Flaps,
+		StateSeconds: make(map[string]float64, len(s.StateDurations)),
+	}
+	for st, d := range s.StateDurations {
+		result.StateSeconds[st.String()] = d.Seconds()
+	}
+	if s.Error != nil {
+		result.Error = s.Error.Error()
+	}
+	return result
+}
+
+// diffBacktestRuleSummaries returns the difference between the summaries, or nil if one of them has an error.
+func diffBacktestRuleSummaries(modified, saved apimodels.BacktestRuleSummary) *apimodels.BacktestRuleSummaryDiff {
+	if modified.Error != "" || saved.Error != "" {
+		return nil
+	}
+	diff := &apimodels.BacktestRuleSummaryDiff{
+		Firings:      modified.Firings - saved.Firings,
+		Flaps:        modified.Flaps - saved.Flaps,
+		StateSeconds: make(map[string]float64),
+	}
+	for st, sec := range modified.StateSeconds {
+		diff.StateSeconds[st] += sec
+	}
+	for st, sec := range saved.StateSeconds {
+		diff.StateSeconds[st] -= sec
+	}
+	return diff
+}
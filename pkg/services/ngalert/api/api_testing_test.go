@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -387,6 +389,76 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestBacktestRules(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	from := time.Unix(0, 0)
+	to := from.Add(5 * time.Minute)
+
+	t.Run("should return NotFound if backtesting is not enabled", func(t *testing.T) {
+		srv := createTestingApiSrv(t, nil, nil, eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), featuremgmt.WithFeatures(), fakes2.NewRuleStore(t))
+
+		response := srv.BacktestRules(rc, definitions.BacktestRulesConfig{
+			From:         from,
+			To:           to,
+			NamespaceUID: uuid.NewString(),
+		})
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return a summary of each rule of the folder", func(t *testing.T) {
+		f := randFolder()
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+		rules := models.GenerateAlertRules(2, models.AlertRuleGen(
+			models.WithOrgID(rc.OrgID),
+			models.WithNamespace(f),
+			models.WithInterval(time.Minute),
+			models.WithFor(0),
+		))
+		ruleStore.PutRule(context.Background(), rules...)
+
+		ac := acMock.New().WithPermissions([]ac.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceAllScope()},
+		})
+
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.On("Evaluate", mock.Anything, mock.Anything).Return(func(_ context.Context, now time.Time) eval.Results {
+			return eval.Results{{Instance: data.Labels{}, State: eval.Alerting, EvaluatedAt: now}}
+		}, nil)
+		evalFactory := eval_mocks.NewEvaluatorFactory(evaluator)
+
+		srv := createTestingApiSrv(t, nil, ac, evalFactory, featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), ruleStore)
+		srv.ruleStore = ruleStore
+		srv.backtesting = backtesting.NewEngine(nil, evalFactory, tracing.InitializeTracerForTest())
+
+		response := srv.BacktestRules(rc, definitions.BacktestRulesConfig{
+			From:         from,
+			To:           to,
+			NamespaceUID: f.UID,
+		})
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result definitions.BacktestRulesResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Rules, len(rules))
+		for _, summary := range result.Rules {
+			require.Empty(t, summary.Error)
+			require.Equal(t, 5, summary.Evaluations)
+			require.Equal(t, 1, summary.Firings)
+			require.Equal(t, map[string]float64{"Alerting": 300}, summary.StateSeconds)
+			require.Nil(t, summary.Saved)
+		}
+	})
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory, featureManager featuremgmt.FeatureToggles, ruleStore RuleStore) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -40,7 +40,7 @@ func TestAuthorize(t *testing.T) {
 		}
 		paths[p] = methods
 	}
-	require.Len(t, paths, 58)
+	require.Len(t, paths, 61)
 
 	ac := acmock.New()
 	api := &API{AccessControl: ac}
@@ -31,6 +31,11 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
+		FlapDetection:        FlapDetectionFromAlertRuleFlapDetection(a.FlapDetection),
+		RecoveryCondition:    a.RecoveryCondition,
+		Record:               RecordFromAlertRuleRecord(a.Record),
+		DependsOn:            a.DependsOn,
+		EvaluationTimeout:    EvaluationTimeoutFromApiEvaluationTimeout(a.EvaluationTimeout),
 	}, nil
 }
 
@@ -54,6 +59,11 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
+		FlapDetection:        AlertRuleFlapDetectionFromFlapDetection(rule.FlapDetection),
+		RecoveryCondition:    rule.RecoveryCondition,
+		Record:               AlertRuleRecordFromRecord(rule.Record),
+		DependsOn:            rule.DependsOn,
+		EvaluationTimeout:    ApiEvaluationTimeoutFromEvaluationTimeout(rule.EvaluationTimeout),
 	}
 }
 
@@ -188,16 +198,27 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState),
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsExportFromNotificationSettings(rule.NotificationSettings),
+		FlapDetection:        AlertRuleFlapDetectionExportFromFlapDetection(rule.FlapDetection),
+		Record:               AlertRuleRecordExportFromRecord(rule.Record),
 	}
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.RecoveryCondition != "" {
+		result.RecoveryCondition = util.Pointer(rule.RecoveryCondition)
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if len(rule.DependsOn) > 0 {
+		result.DependsOn = &rule.DependsOn
+	}
+	if rule.EvaluationTimeout > 0 {
+		result.EvaluationTimeout = util.Pointer(model.Duration(rule.EvaluationTimeout).String())
+	}
 	return result, nil
 }
 
@@ -434,3 +455,97 @@ func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.Alert
 		},
 	}
 }
+
+// AlertRuleFlapDetectionFromFlapDetection converts []models.FlapDetectionSettings to definitions.AlertRuleFlapDetection
+func AlertRuleFlapDetectionFromFlapDetection(fd []models.FlapDetectionSettings) *definitions.AlertRuleFlapDetection {
+	if len(fd) == 0 {
+		return nil
+	}
+	m := fd[0]
+	return &definitions.AlertRuleFlapDetection{
+		Window:            util.Pointer(m.Window),
+		HighThreshold:     util.Pointer(m.HighThreshold),
+		LowThreshold:      util.Pointer(m.LowThreshold),
+		HoldNotifications: m.HoldNotifications,
+	}
+}
+
+// AlertRuleFlapDetectionExportFromFlapDetection converts []models.FlapDetectionSettings to definitions.AlertRuleFlapDetectionExport
+func AlertRuleFlapDetectionExportFromFlapDetection(fd []models.FlapDetectionSettings) *definitions.AlertRuleFlapDetectionExport {
+	if len(fd) == 0 {
+		return nil
+	}
+	m := fd[0]
+	return &definitions.AlertRuleFlapDetectionExport{
+		Window:            m.Window,
+		HighThreshold:     m.HighThreshold,
+		LowThreshold:      m.LowThreshold,
+		HoldNotifications: m.HoldNotifications,
+	}
+}
+
+// FlapDetectionFromAlertRuleFlapDetection converts definitions.AlertRuleFlapDetection to []models.FlapDetectionSettings.
+// The window and thresholds that are not set get the default values.
+func FlapDetectionFromAlertRuleFlapDetection(fd *definitions.AlertRuleFlapDetection) []models.FlapDetectionSettings {
+	if fd == nil {
+		return nil
+	}
+	s := models.NewFlapDetectionSettings()
+	if fd.Window != nil {
+		s.Window = *fd.Window
+	}
+	if fd.HighThreshold != nil {
+		s.HighThreshold = *fd.HighThreshold
+	}
+	if fd.LowThreshold != nil {
+		s.LowThreshold = *fd.LowThreshold
+	}
+	s.HoldNotifications = fd.HoldNotifications
+	return []models.FlapDetectionSettings{s}
+}
+
+// AlertRuleRecordFromRecord converts []models.RecordSettings to definitions.AlertRuleRecord
+func AlertRuleRecordFromRecord(r []models.RecordSettings) *definitions.AlertRuleRecord {
+	if len(r) == 0 {
+		return nil
+	}
+	return &definitions.AlertRuleRecord{
+		Metric: r[0].Metric,
+	}
+}
+
+// AlertRuleRecordExportFromRecord converts []models.RecordSettings to definitions.AlertRuleRecordExport
+func AlertRuleRecordExportFromRecord(r []models.RecordSettings) *definitions.AlertRuleRecordExport {
+	if len(r) == 0 {
+		return nil
+	}
+	return &definitions.AlertRuleRecordExport{
+		Metric: r[0].Metric,
+	}
+}
+
+// RecordFromAlertRuleRecord converts definitions.AlertRuleRecord to []models.RecordSettings
+func RecordFromAlertRuleRecord(r *definitions.AlertRuleRecord) []models.RecordSettings {
+	if r == nil {
+		return nil
+	}
+	return []models.RecordSettings{{
+		Metric: r.Metric,
+	}}
+}
+
+// ApiEvaluationTimeoutFromEvaluationTimeout converts the evaluation timeout of a rule to its API model. Returns nil if the timeout is not set.
+func ApiEvaluationTimeoutFromEvaluationTimeout(d time.Duration) *model.Duration {
+	if d <= 0 {
+		return nil
+	}
+	return util.Pointer(model.Duration(d))
+}
+
+// EvaluationTimeoutFromApiEvaluationTimeout converts the API model of the evaluation timeout of a rule to time.Duration
+func EvaluationTimeoutFromApiEvaluationTimeout(d *model.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(*d)
+}
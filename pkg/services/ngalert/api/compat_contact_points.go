@@ -189,6 +189,41 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 			errs = append(errs, err)
 		}
 	}
+	for _, i := range cp.Matrix {
+		el, err := marshallIntegration(j, "matrix", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, i := range cp.Mattermost {
+		el, err := marshallIntegration(j, "mattermost", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, i := range cp.Mqtt {
+		el, err := marshallIntegration(j, "mqtt", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, i := range cp.Ntfy {
+		el, err := marshallIntegration(j, "ntfy", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, i := range cp.Sns {
+		el, err := marshallIntegration(j, "sns", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if len(errs) > 0 {
 		return notify.APIReceiver{}, errors.Join(errs...)
 	}
@@ -324,6 +359,31 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Webex = append(result.Webex, integration)
 		}
+	case "matrix":
+		integration := definitions.MatrixIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Matrix = append(result.Matrix, integration)
+		}
+	case "mattermost":
+		integration := definitions.MattermostIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Mattermost = append(result.Mattermost, integration)
+		}
+	case "mqtt":
+		integration := definitions.MqttIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Mqtt = append(result.Mqtt, integration)
+		}
+	case "ntfy":
+		integration := definitions.NtfyIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Ntfy = append(result.Ntfy, integration)
+		}
+	case "sns":
+		integration := definitions.SnsIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Sns = append(result.Sns, integration)
+		}
 	default:
 		err = fmt.Errorf("integration %s is not supported", receiverType)
 	}
@@ -369,6 +429,18 @@ func (c contactPointsExtension) UpdateStructDescriptor(structDescriptor *jsonite
 		desc.Decoder = codec
 		desc.Encoder = codec
 	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.MqttIntegration{}) {
+		codec := &numberAsStringCodec{}
+		desc := structDescriptor.GetField("QoS")
+		desc.Decoder = codec
+		desc.Encoder = codec
+	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.NtfyIntegration{}) {
+		codec := &numberAsStringCodec{}
+		desc := structDescriptor.GetField("Priority")
+		desc.Decoder = codec
+		desc.Encoder = codec
+	}
 }
 
 type emailAddressCodec struct{}
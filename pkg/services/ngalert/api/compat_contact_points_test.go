@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"maps"
 	"strings"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -51,7 +53,10 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 	}
 
 	// use the configs for testing because they have all fields supported by integrations
-	for integrationType, cfg := range notify.AllKnownConfigsForTesting {
+	allKnownConfigs := make(map[string]notify.NotifierConfigTest, len(notify.AllKnownConfigsForTesting)+len(integrations.AllKnownConfigsForTesting))
+	maps.Copy(allKnownConfigs, notify.AllKnownConfigsForTesting)
+	maps.Copy(allKnownConfigs, integrations.AllKnownConfigsForTesting)
+	for integrationType, cfg := range allKnownConfigs {
 		t.Run(integrationType, func(t *testing.T) {
 			recCfg := &notify.APIReceiver{
 				ConfigReceiver: notify.ConfigReceiver{Name: "test-receiver"},
@@ -62,7 +67,7 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 				},
 			}
 
-			expected, err := notify.BuildReceiverConfiguration(context.Background(), recCfg, func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+			expected, err := integrations.BuildReceiverConfiguration(context.Background(), recCfg, func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
 				return receiversTesting.DecryptForTesting(sjd)(key, fallback)
 			})
 			require.NoError(t, err)
@@ -73,7 +78,7 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 			back, err := ContactPointToContactPointExport(result)
 			require.NoError(t, err)
 
-			actual, err := notify.BuildReceiverConfiguration(context.Background(), &back, func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+			actual, err := integrations.BuildReceiverConfiguration(context.Background(), &back, func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
 				return receiversTesting.DecryptForTesting(sjd)(key, fallback)
 			})
 			require.NoError(t, err)
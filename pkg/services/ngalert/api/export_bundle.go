@@ -0,0 +1,136 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	exportBundleTerraformDir  = "terraform"
+	exportBundlePrometheusDir = "prometheus"
+	exportBundleWarningsFile  = "warnings.txt"
+)
+
+var exportBundleFileNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// exportBundle contains all alerting resources of an organization.
+type exportBundle struct {
+	// Export contains the resources that are exported as Terraform resources.
+	Export    definitions.AlertingFileExport
+	Templates []definitions.NotificationTemplate
+	// Prometheus contains the rule files in Prometheus format, by folder title.
+	Prometheus map[string]definitions.PrometheusRuleGroups
+	// Warnings explain why resources are missing from the bundle.
+	Warnings []string
+}
+
+// Archive writes the bundle to a zip archive. The archive contains one Terraform file per type of resource in the
+// "terraform" directory, one Prometheus rule file per folder in the "prometheus" directory, and the warnings.
+func (b exportBundle) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	write := func(name string, content []byte) error {
+		f, err := w.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+
+	tfFiles := []struct {
+		name string
+		body definitions.AlertingFileExport
+	}{
+		{"alert_rules.tf", definitions.AlertingFileExport{Groups: b.Export.Groups}},
+		{"contact_points.tf", definitions.AlertingFileExport{ContactPoints: b.Export.ContactPoints}},
+		{"notification_policies.tf", definitions.AlertingFileExport{Policies: b.Export.Policies}},
+		{"mute_timings.tf", definitions.AlertingFileExport{MuteTimings: b.Export.MuteTimings}},
+	}
+	for _, tf := range tfFiles {
+		resources, err := hclResourcesFromExport(tf.body)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeHclFile(write, tf.name, resources); err != nil {
+			return nil, err
+		}
+	}
+
+	templates := make([]hcl.Resource, 0, len(b.Templates))
+	for idx, t := range b.Templates {
+		templates = append(templates, hcl.Resource{
+			Type: "grafana_message_template",
+			Name: fmt.Sprintf("message_template_%d", idx+1),
+			Body: &definitions.NotificationTemplateExportHcl{Name: t.Name, Template: t.Template},
+		})
+	}
+	if err := writeHclFile(write, "message_templates.tf", templates); err != nil {
+		return nil, err
+	}
+
+	folders := make([]string, 0, len(b.Prometheus))
+	for folder := range b.Prometheus {
+		folders = append(folders, folder)
+	}
+	slices.Sort(folders)
+	fileNames := make(map[string]struct{}, len(folders))
+	for _, folder := range folders {
+		name := exportBundleFileName(folder, fileNames)
+		content, err := yaml.Marshal(b.Prometheus[folder])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Prometheus rules of folder %q: %w", folder, err)
+		}
+		if err := write(exportBundlePrometheusDir+"/"+name+".yaml", content); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(b.Warnings) > 0 {
+		if err := write(exportBundleWarningsFile, []byte(strings.Join(b.Warnings, "\n")+"\n")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHclFile(write func(string, []byte) error, name string, resources []hcl.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	content, err := hcl.Encode(resources...)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return write(exportBundleTerraformDir+"/"+name, content)
+}
+
+// exportBundleFileName returns a file name for the folder that is not in used yet, and adds it to used.
+func exportBundleFileName(folder string, used map[string]struct{}) string {
+	base := strings.Trim(exportBundleFileNameSanitizer.ReplaceAllString(folder, "_"), "_.")
+	if base == "" {
+		base = "folder"
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := used[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = struct{}{}
+	return name
+}
@@ -1,6 +1,11 @@
 package api
 
 import (
+	"io"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -109,6 +114,19 @@ func (f *RulerApiHandler) handleRoutePostRulesGroupForExport(ctx *contextmodel.R
 	return f.GrafanaRuler.ExportFromPayload(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRoutePostRulesGroupsForImport(ctx *contextmodel.ReqContext, namespace string) response.Response {
+	body, err := io.ReadAll(ctx.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "failed to read the request body", err)
+	}
+	var ruleFile apimodels.PrometheusRuleGroups
+	// JSON is valid YAML, therefore the rule file can be sent in both formats.
+	if err := yaml.Unmarshal(body, &ruleFile); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.GrafanaRuler.ImportFromPrometheus(ctx, ruleFile, namespace, ctx.Query("datasource_uid"), ctx.QueryBool("dry_run"))
+}
+
 func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.ExportRules(ctx)
 }
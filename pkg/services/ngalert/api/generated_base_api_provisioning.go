@@ -35,6 +35,7 @@ type ProvisioningApi interface {
 	RouteGetAlertRulesExport(*contextmodel.ReqContext) response.Response
 	RouteGetContactpoints(*contextmodel.ReqContext) response.Response
 	RouteGetContactpointsExport(*contextmodel.ReqContext) response.Response
+	RouteGetExport(*contextmodel.ReqContext) response.Response
 	RouteGetMuteTiming(*contextmodel.ReqContext) response.Response
 	RouteGetMuteTimings(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTree(*contextmodel.ReqContext) response.Response
@@ -121,6 +122,9 @@ func (f *ProvisioningApiHandler) RouteGetContactpoints(ctx *contextmodel.ReqCont
 func (f *ProvisioningApiHandler) RouteGetContactpointsExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetContactpointsExport(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetMuteTiming(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -412,6 +416,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/export"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/export",
+				api.Hooks.Wrap(srv.RouteGetExport),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
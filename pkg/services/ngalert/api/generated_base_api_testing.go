@@ -21,6 +21,7 @@ import (
 
 type TestingApi interface {
 	BacktestConfig(*contextmodel.ReqContext) response.Response
+	RouteBacktestRules(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
@@ -34,6 +35,14 @@ func (f *TestingApiHandler) BacktestConfig(ctx *contextmodel.ReqContext) respons
 	}
 	return f.handleBacktestConfig(ctx, conf)
 }
+func (f *TestingApiHandler) RouteBacktestRules(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.BacktestRulesConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteBacktestRules(ctx, conf)
+}
 func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EvalQueriesPayload{}
@@ -75,6 +84,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest/rules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/backtest/rules"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest/rules",
+				api.Hooks.Wrap(srv.RouteBacktestRules),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// PrometheusRuleGroupsFromAlertRuleGroups converts the alert rules to rule files in the format of Prometheus, one per folder.
+// Only the rules that have the same behaviour in a Prometheus-compatible ruler are converted. A warning is returned for every
+// other rule. isPrometheus tells whether the data source with the given UID is a Prometheus-compatible data source.
+func PrometheusRuleGroupsFromAlertRuleGroups(groups []models.AlertRuleGroupWithFolderTitle, isPrometheus func(uid string) bool) (map[string]definitions.PrometheusRuleGroups, []string) {
+	result := make(map[string]definitions.PrometheusRuleGroups)
+	var warnings []string
+	for _, group := range groups {
+		promGroup := definitions.PrometheusRuleGroup{
+			Name:     group.Title,
+			Interval: prommodel.Duration(time.Duration(group.Interval) * time.Second),
+		}
+		for _, rule := range group.Rules {
+			promRule, err := PrometheusRuleFromAlertRule(rule, isPrometheus)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("rule %q (uid %s) in folder %q and group %q was not exported to Prometheus format: %s", rule.Title, rule.UID, group.FolderTitle, group.Title, err))
+				continue
+			}
+			promGroup.Rules = append(promGroup.Rules, promRule)
+		}
+		if len(promGroup.Rules) == 0 {
+			continue
+		}
+		file := result[group.FolderTitle]
+		file.Groups = append(file.Groups, promGroup)
+		result[group.FolderTitle] = file
+	}
+	return result, warnings
+}
+
+// PrometheusRuleFromAlertRule converts the alert rule to an alerting rule in the format of Prometheus.
+// The rule must query a single Prometheus data source, and its condition must either be the query itself, or a
+// threshold applied to the query, optionally after reducing it to its last value.
+func PrometheusRuleFromAlertRule(rule models.AlertRule, isPrometheus func(uid string) bool) (definitions.ApiRuleNode, error) {
+	if rule.Type() == models.RuleTypeRecording {
+		return definitions.ApiRuleNode{}, errors.New("the rule is a recording rule")
+	}
+	if rule.IsPaused {
+		return definitions.ApiRuleNode{}, errors.New("the rule is paused")
+	}
+	if rule.NoDataState == models.Alerting || rule.ExecErrState == models.AlertingErrState {
+		return definitions.ApiRuleNode{}, errors.New("the rule fires when there is no data or an error")
+	}
+	if rule.RecoveryCondition != "" {
+		return definitions.ApiRuleNode{}, errors.New("the rule has a recovery condition")
+	}
+	if len(rule.NotificationSettings) > 0 {
+		return definitions.ApiRuleNode{}, errors.New("the rule has notification settings")
+	}
+
+	nodes := make(map[string]promCompatNode, len(rule.Data))
+	for _, q := range rule.Data {
+		var n promCompatNode
+		if err := json.Unmarshal(q.Model, &n); err != nil {
+			return definitions.ApiRuleNode{}, fmt.Errorf("failed to parse query %s: %w", q.RefID, err)
+		}
+		n.isExpression = expr.IsDataSource(q.DatasourceUID)
+		n.datasourceUID = q.DatasourceUID
+		nodes[q.RefID] = n
+	}
+
+	var threshold *promCompatThreshold
+	reduced, counted := false, false
+	used := 1
+	n, ok := nodes[rule.Condition]
+	if !ok {
+		return definitions.ApiRuleNode{}, fmt.Errorf("condition %s does not exist", rule.Condition)
+	}
+	if n.isExpression && n.Type == string(expr.QueryTypeThreshold) {
+		if len(n.Conditions) != 1 {
+			return definitions.ApiRuleNode{}, errors.New("the threshold has more than one condition")
+		}
+		if n.Conditions[0].UnloadEvaluator != nil {
+			return definitions.ApiRuleNode{}, errors.New("the threshold has a recovery threshold")
+		}
+		threshold = &promCompatThreshold{evaluator: n.Conditions[0].Evaluator}
+		n, ok = nodes[n.Expression]
+		used++
+	}
+	if ok && n.isExpression && n.Type == string(expr.QueryTypeReduce) {
+		if n.Reducer != "last" && n.Reducer != "count" || n.Settings != nil && n.Settings.Mode != "" {
+			return definitions.ApiRuleNode{}, fmt.Errorf("the reducer %s is not supported", n.Reducer)
+		}
+		reduced, counted = n.Reducer == "last", n.Reducer == "count"
+		n, ok = nodes[n.Expression]
+		used++
+	}
+	if !ok || n.isExpression {
+		return definitions.ApiRuleNode{}, errors.New("the condition is not a threshold applied to a query")
+	}
+	if used != len(nodes) {
+		return definitions.ApiRuleNode{}, errors.New("the rule has queries or expressions that are not part of the condition")
+	}
+	if !isPrometheus(n.datasourceUID) || n.Expr == "" {
+		return definitions.ApiRuleNode{}, errors.New("the rule does not query a Prometheus data source")
+	}
+	if !reduced && (n.Range || n.Instant != nil && !*n.Instant) {
+		return definitions.ApiRuleNode{}, errors.New("the query is a range query")
+	}
+
+	query, err := parser.ParseExpr(n.Expr)
+	if err != nil {
+		return definitions.ApiRuleNode{}, fmt.Errorf("the query is not valid PromQL: %w", err)
+	}
+	switch {
+	case counted:
+		// Counting the samples of an instant query and firing above zero fires for all series, like Prometheus does.
+		// This is how rules are converted by PostableRuleGroupFromPrometheusRuleGroup.
+		if threshold == nil || threshold.evaluator.Type != expr.ThresholdIsAbove || len(threshold.evaluator.Params) < 1 || threshold.evaluator.Params[0] != 0 {
+			return definitions.ApiRuleNode{}, errors.New("the reducer count is only supported with a threshold above 0")
+		}
+	case threshold == nil:
+		// A query that is used as the condition fires for all non-zero values, whereas Prometheus fires for all series.
+		threshold = &promCompatThreshold{evaluator: expr.ConditionEvalJSON{Type: promCompatNotZero, Params: []float64{0}}}
+		fallthrough
+	default:
+		if query, err = threshold.apply(query); err != nil {
+			return definitions.ApiRuleNode{}, err
+		}
+	}
+
+	result := definitions.ApiRuleNode{
+		Alert:       rule.Title,
+		Expr:        query.String(),
+		Labels:      maps.Clone(rule.Labels),
+		Annotations: maps.Clone(rule.Annotations),
+	}
+	if rule.For > 0 {
+		forDuration := prommodel.Duration(rule.For)
+		result.For = &forDuration
+	}
+	return result, nil
+}
+
+// PostableRuleGroupFromPrometheusRuleGroup converts the rule group in the format of Prometheus to a group of
+// Grafana-managed rules that query the data source with the given UID. The condition of every alerting rule is the
+// instant query, followed by a reduce expression that counts the samples of every series and a threshold above zero,
+// so that the rule fires for all series returned by the query, like Prometheus does. Recording rules are converted to
+// Grafana-managed recording rules that record the series of the instant query, if recordingRules is true. Rules that
+// cannot be converted are skipped and a warning is returned for each of them.
+func PostableRuleGroupFromPrometheusRuleGroup(group definitions.PrometheusRuleGroup, datasourceUID string, recordingRules bool) (definitions.PostableRuleGroupConfig, []string, error) {
+	result := definitions.PostableRuleGroupConfig{
+		Name:     group.Name,
+		Interval: group.Interval,
+		Rules:    make([]definitions.PostableExtendedRuleNode, 0, len(group.Rules)),
+	}
+	var warnings []string
+	for idx, rule := range group.Rules {
+		switch {
+		case rule.Record != "" && !recordingRules:
+			warnings = append(warnings, fmt.Sprintf("recording rule %q in group %q was not imported: recording rules are not enabled", rule.Record, group.Name))
+			continue
+		case rule.Record != "":
+			if _, err := parser.ParseExpr(rule.Expr); err != nil {
+				return definitions.PostableRuleGroupConfig{}, nil, fmt.Errorf("rule %q in group %q is not valid PromQL: %w", rule.Record, group.Name, err)
+			}
+			data, err := promImportQueries(rule.Expr, datasourceUID, false)
+			if err != nil {
+				return definitions.PostableRuleGroupConfig{}, nil, err
+			}
+			result.Rules = append(result.Rules, definitions.PostableExtendedRuleNode{
+				ApiRuleNode: &definitions.ApiRuleNode{
+					Labels: maps.Clone(rule.Labels),
+				},
+				GrafanaManagedAlert: &definitions.PostableGrafanaRule{
+					Title:        rule.Record,
+					Condition:    promImportQueryRefID,
+					Data:         data,
+					NoDataState:  definitions.OK,
+					ExecErrState: definitions.ErrorErrState,
+					Record:       &definitions.AlertRuleRecord{Metric: rule.Record},
+				},
+			})
+			continue
+		case rule.KeepFiringFor != nil && *rule.KeepFiringFor > 0:
+			warnings = append(warnings, fmt.Sprintf("rule %q in group %q was not imported: keep_firing_for is not supported", rule.Alert, group.Name))
+			continue
+		case rule.Alert == "":
+			return definitions.PostableRuleGroupConfig{}, nil, fmt.Errorf("rule [%d] in group %q has neither alert nor record name", idx, group.Name)
+		}
+		if _, err := parser.ParseExpr(rule.Expr); err != nil {
+			return definitions.PostableRuleGroupConfig{}, nil, fmt.Errorf("rule %q in group %q is not valid PromQL: %w", rule.Alert, group.Name, err)
+		}
+		data, err := promImportQueries(rule.Expr, datasourceUID, true)
+		if err != nil {
+			return definitions.PostableRuleGroupConfig{}, nil, err
+		}
+		result.Rules = append(result.Rules, definitions.PostableExtendedRuleNode{
+			ApiRuleNode: &definitions.ApiRuleNode{
+				For:         rule.For,
+				Labels:      maps.Clone(rule.Labels),
+				Annotations: maps.Clone(rule.Annotations),
+			},
+			GrafanaManagedAlert: &definitions.PostableGrafanaRule{
+				Title:        rule.Alert,
+				Condition:    promImportThresholdRefID,
+				Data:         data,
+				NoDataState:  definitions.OK,
+				ExecErrState: definitions.ErrorErrState,
+			},
+		})
+	}
+	return result, warnings, nil
+}
+
+const (
+	promImportQueryRefID     = "A"
+	promImportReduceRefID    = "B"
+	promImportThresholdRefID = "C"
+	// promImportQueryTimeRange is the time range of the imported queries. Instant queries are evaluated at the end of
+	// the range, and Prometheus looks back at most 5 minutes for the latest sample of every series.
+	promImportQueryTimeRange = 10 * time.Minute
+)
+
+// promImportQueries returns the instant query, and the expressions that fire for all of its series if alerting is true.
+func promImportQueries(query, datasourceUID string, alerting bool) ([]definitions.AlertQuery, error) {
+	nodes := []struct {
+		refID         string
+		datasourceUID string
+		timeRange     time.Duration
+		model         map[string]any
+	}{
+		{promImportQueryRefID, datasourceUID, promImportQueryTimeRange, map[string]any{
+			"expr":    query,
+			"instant": true,
+			"range":   false,
+		}},
+		{promImportReduceRefID, expr.DatasourceUID, 0, map[string]any{
+			"type":       expr.QueryTypeReduce,
+			"expression": promImportQueryRefID,
+			"reducer":    "count",
+		}},
+		{promImportThresholdRefID, expr.DatasourceUID, 0, map[string]any{
+			"type":       expr.QueryTypeThreshold,
+			"expression": promImportReduceRefID,
+			"conditions": []any{map[string]any{"evaluator": map[string]any{"type": expr.ThresholdIsAbove, "params": []float64{0}}}},
+		}},
+	}
+	if !alerting {
+		nodes = nodes[:1]
+	}
+	result := make([]definitions.AlertQuery, 0, len(nodes))
+	for _, m := range nodes {
+		m.model["refId"] = m.refID
+		raw, err := json.Marshal(m.model)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, definitions.AlertQuery{
+			RefID:             m.refID,
+			DatasourceUID:     m.datasourceUID,
+			RelativeTimeRange: definitions.RelativeTimeRange{From: definitions.Duration(m.timeRange)},
+			Model:             raw,
+		})
+	}
+	return result, nil
+}
+
+// promCompatNode contains the properties of queries and expressions that are needed to convert a rule to Prometheus format.
+type promCompatNode struct {
+	// Prometheus query.
+	Expr    string `json:"expr"`
+	Range   bool   `json:"range"`
+	Instant *bool  `json:"instant"`
+
+	// Expressions.
+	Type       string                        `json:"type"`
+	Expression string                        `json:"expression"`
+	Reducer    string                        `json:"reducer"`
+	Settings   *promCompatReduceSettings     `json:"settings"`
+	Conditions []expr.ThresholdConditionJSON `json:"conditions"`
+
+	isExpression  bool
+	datasourceUID string
+}
+
+type promCompatReduceSettings struct {
+	Mode string `json:"mode"`
+}
+
+// promCompatNotZero is the threshold that is equivalent to using a query as the condition.
+const promCompatNotZero expr.ThresholdType = "ne"
+
+type promCompatThreshold struct {
+	evaluator expr.ConditionEvalJSON
+}
+
+func (t promCompatThreshold) apply(query parser.Expr) (parser.Expr, error) {
+	var op parser.ItemType
+	switch t.evaluator.Type {
+	case expr.ThresholdIsAbove:
+		op = parser.GTR
+	case expr.ThresholdIsBelow:
+		op = parser.LSS
+	case promCompatNotZero:
+		op = parser.NEQ
+	default:
+		return nil, fmt.Errorf("the threshold %s is not supported", t.evaluator.Type)
+	}
+	if len(t.evaluator.Params) < 1 {
+		return nil, errors.New("the threshold has no value")
+	}
+	if _, ok := query.(*parser.BinaryExpr); ok {
+		query = &parser.ParenExpr{Expr: query}
+	}
+	return &parser.BinaryExpr{
+		Op:  op,
+		LHS: query,
+		RHS: &parser.NumberLiteral{Val: t.evaluator.Params[0]},
+	}, nil
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestPrometheusRuleFromAlertRule(t *testing.T) {
+	isPrometheus := func(uid string) bool { return uid == "prom" }
+	promQuery := func(refID, query string, instant bool) models.AlertQuery {
+		return promCompatQuery(t, refID, "prom", map[string]any{"expr": query, "instant": instant, "range": !instant})
+	}
+	reduce := func(refID, input, reducer string) models.AlertQuery {
+		return promCompatQuery(t, refID, expr.DatasourceUID, map[string]any{"type": "reduce", "expression": input, "reducer": reducer})
+	}
+	threshold := func(refID, input string, evaluator string, params ...float64) models.AlertQuery {
+		return promCompatQuery(t, refID, expr.DatasourceUID, map[string]any{
+			"type":       "threshold",
+			"expression": input,
+			"conditions": []any{map[string]any{"evaluator": map[string]any{"type": evaluator, "params": params}}},
+		})
+	}
+	rule := func(condition string, data ...models.AlertQuery) models.AlertRule {
+		return models.AlertRule{
+			Title:        "HighErrorRate",
+			Condition:    condition,
+			Data:         data,
+			For:          5 * time.Minute,
+			NoDataState:  models.NoData,
+			ExecErrState: models.ErrorErrState,
+			Labels:       map[string]string{"severity": "critical"},
+			Annotations:  map[string]string{"summary": "Too many errors"},
+		}
+	}
+	fiveMinutes := prommodel.Duration(5 * time.Minute)
+
+	testCases := []struct {
+		name     string
+		rule     models.AlertRule
+		expected string
+		err      string
+	}{
+		{
+			name:     "query, reduce and threshold",
+			rule:     rule("C", promQuery("A", "rate(errors_total[5m])", false), reduce("B", "A", "last"), threshold("C", "B", "gt", 0.5)),
+			expected: "rate(errors_total[5m]) > 0.5",
+		},
+		{
+			name:     "instant query and threshold",
+			rule:     rule("B", promQuery("A", "sum by (job) (up)", true), threshold("B", "A", "lt", 1)),
+			expected: "sum by (job) (up) < 1",
+		},
+		{
+			name:     "binary expression is wrapped in parentheses",
+			rule:     rule("B", promQuery("A", "errors_total / requests_total", true), threshold("B", "A", "gt", 0.1)),
+			expected: "(errors_total / requests_total) > 0.1",
+		},
+		{
+			name:     "instant query as condition",
+			rule:     rule("A", promQuery("A", "up == 0", true)),
+			expected: "(up == 0) != 0",
+		},
+		{
+			name:     "instant query, count and threshold above 0",
+			rule:     rule("C", promQuery("A", "up == 0", true), reduce("B", "A", "count"), threshold("C", "B", "gt", 0)),
+			expected: "up == 0",
+		},
+		{
+			name: "count with other threshold",
+			rule: rule("C", promQuery("A", "up", true), reduce("B", "A", "count"), threshold("C", "B", "gt", 1)),
+			err:  "only supported with a threshold above 0",
+		},
+		{
+			name: "range query without reduce",
+			rule: rule("B", promQuery("A", "up", false), threshold("B", "A", "gt", 0)),
+			err:  "range query",
+		},
+		{
+			name: "unsupported reducer",
+			rule: rule("C", promQuery("A", "up", false), reduce("B", "A", "mean"), threshold("C", "B", "gt", 0)),
+			err:  "reducer mean is not supported",
+		},
+		{
+			name: "unsupported threshold",
+			rule: rule("B", promQuery("A", "up", true), threshold("B", "A", "within_range", 0, 1)),
+			err:  "threshold within_range is not supported",
+		},
+		{
+			name: "other data source",
+			rule: rule("B", promCompatQuery(t, "A", "loki", map[string]any{"expr": "up", "instant": true}), threshold("B", "A", "gt", 0)),
+			err:  "does not query a Prometheus data source",
+		},
+		{
+			name: "unused queries",
+			rule: rule("B", promQuery("A", "up", true), threshold("B", "A", "gt", 0), promQuery("C", "down", true)),
+			err:  "not part of the condition",
+		},
+		{
+			name: "invalid PromQL",
+			rule: rule("B", promQuery("A", "rate(up[$__rate_interval])", true), threshold("B", "A", "gt", 0)),
+			err:  "not valid PromQL",
+		},
+		{
+			name: "alerting on no data",
+			rule: func() models.AlertRule {
+				r := rule("B", promQuery("A", "up", true), threshold("B", "A", "gt", 0))
+				r.NoDataState = models.Alerting
+				return r
+			}(),
+			err: "no data",
+		},
+		{
+			name: "paused",
+			rule: func() models.AlertRule {
+				r := rule("B", promQuery("A", "up", true), threshold("B", "A", "gt", 0))
+				r.IsPaused = true
+				return r
+			}(),
+			err: "paused",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := PrometheusRuleFromAlertRule(tc.rule, isPrometheus)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, definitions.ApiRuleNode{
+				Alert:       "HighErrorRate",
+				Expr:        tc.expected,
+				For:         &fiveMinutes,
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "Too many errors"},
+			}, result)
+		})
+	}
+}
+
+func TestPrometheusRuleGroupsFromAlertRuleGroups(t *testing.T) {
+	translatable := models.AlertRule{
+		UID:       "ok",
+		Title:     "translatable",
+		Condition: "A",
+		Data:      []models.AlertQuery{promCompatQuery(t, "A", "prom", map[string]any{"expr": "up", "instant": true})},
+	}
+	untranslatable := models.AlertRule{
+		UID:       "ko",
+		Title:     "untranslatable",
+		Condition: "A",
+		Data:      []models.AlertQuery{promCompatQuery(t, "A", "loki", map[string]any{"expr": "up", "instant": true})},
+	}
+	groups := []models.AlertRuleGroupWithFolderTitle{
+		{AlertRuleGroup: &models.AlertRuleGroup{Title: "group-1", Interval: 60, Rules: []models.AlertRule{translatable, untranslatable}}, FolderTitle: "folder"},
+		{AlertRuleGroup: &models.AlertRuleGroup{Title: "group-2", Interval: 60, Rules: []models.AlertRule{untranslatable}}, FolderTitle: "folder"},
+		{AlertRuleGroup: &models.AlertRuleGroup{Title: "group-3", Interval: 30, Rules: []models.AlertRule{translatable}}, FolderTitle: "other folder"},
+	}
+
+	files, warnings := PrometheusRuleGroupsFromAlertRuleGroups(groups, func(uid string) bool { return uid == "prom" })
+
+	require.Len(t, files, 2)
+	require.Len(t, files["folder"].Groups, 1, "groups without any translatable rule should be omitted")
+	require.Equal(t, "group-1", files["folder"].Groups[0].Name)
+	require.Equal(t, prommodel.Duration(time.Minute), files["folder"].Groups[0].Interval)
+	require.Len(t, files["folder"].Groups[0].Rules, 1)
+	require.Equal(t, "translatable", files["folder"].Groups[0].Rules[0].Alert)
+	require.Equal(t, prommodel.Duration(30*time.Second), files["other folder"].Groups[0].Interval)
+
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0], `rule "untranslatable" (uid ko) in folder "folder" and group "group-1"`)
+}
+
+func TestPostableRuleGroupFromPrometheusRuleGroup(t *testing.T) {
+	fiveMinutes := prommodel.Duration(5 * time.Minute)
+	group := definitions.PrometheusRuleGroup{
+		Name:     "group",
+		Interval: prommodel.Duration(time.Minute),
+		Rules: []definitions.ApiRuleNode{
+			{Alert: "HighErrorRate", Expr: "rate(errors_total[5m]) > 0.5", For: &fiveMinutes, Labels: map[string]string{"severity": "critical"}},
+			{Record: "job:errors:rate5m", Expr: "sum by (job) (rate(errors_total[5m]))"},
+			{Alert: "Flapping", Expr: "up == 0", KeepFiringFor: &fiveMinutes},
+		},
+	}
+
+	result, warnings, err := PostableRuleGroupFromPrometheusRuleGroup(group, "prom", false)
+
+	require.NoError(t, err)
+	require.Equal(t, "group", result.Name)
+	require.Equal(t, group.Interval, result.Interval)
+	require.Len(t, result.Rules, 1)
+	require.Equal(t, &fiveMinutes, result.Rules[0].For)
+	require.Equal(t, map[string]string{"severity": "critical"}, result.Rules[0].Labels)
+	rule := result.Rules[0].GrafanaManagedAlert
+	require.Equal(t, "HighErrorRate", rule.Title)
+	require.Equal(t, "C", rule.Condition)
+	require.Equal(t, definitions.OK, rule.NoDataState)
+	require.Len(t, rule.Data, 3)
+	require.Equal(t, "prom", rule.Data[0].DatasourceUID)
+	require.JSONEq(t, `{"refId": "A", "expr": "rate(errors_total[5m]) > 0.5", "instant": true, "range": false}`, string(rule.Data[0].Model))
+	require.Equal(t, expr.DatasourceUID, rule.Data[1].DatasourceUID)
+	require.JSONEq(t, `{"refId": "B", "type": "reduce", "expression": "A", "reducer": "count"}`, string(rule.Data[1].Model))
+	require.JSONEq(t, `{"refId": "C", "type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [0]}}]}`, string(rule.Data[2].Model))
+
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0], "recording rules are not enabled")
+	require.Contains(t, warnings[1], "keep_firing_for is not supported")
+
+	t.Run("should convert recording rules if they are enabled", func(t *testing.T) {
+		group := definitions.PrometheusRuleGroup{Name: "group", Rules: []definitions.ApiRuleNode{
+			{Record: "job:errors:rate5m", Expr: "sum by (job) (rate(errors_total[5m]))", Labels: map[string]string{"team": "api"}},
+		}}
+
+		result, warnings, err := PostableRuleGroupFromPrometheusRuleGroup(group, "prom", true)
+
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		require.Len(t, result.Rules, 1)
+		require.Nil(t, result.Rules[0].For)
+		require.Equal(t, map[string]string{"team": "api"}, result.Rules[0].Labels)
+		rule := result.Rules[0].GrafanaManagedAlert
+		require.Equal(t, "job:errors:rate5m", rule.Title)
+		require.Equal(t, &definitions.AlertRuleRecord{Metric: "job:errors:rate5m"}, rule.Record)
+		require.Equal(t, "A", rule.Condition)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, "prom", rule.Data[0].DatasourceUID)
+		require.JSONEq(t, `{"refId": "A", "expr": "sum by (job) (rate(errors_total[5m]))", "instant": true, "range": false}`, string(rule.Data[0].Model))
+	})
+
+	t.Run("should fail if the query is not valid PromQL", func(t *testing.T) {
+		for _, rule := range []definitions.ApiRuleNode{{Alert: "Invalid", Expr: "rate(up"}, {Record: "invalid", Expr: "rate(up"}} {
+			group := definitions.PrometheusRuleGroup{Name: "group", Rules: []definitions.ApiRuleNode{rule}}
+
+			_, _, err := PostableRuleGroupFromPrometheusRuleGroup(group, "prom", true)
+
+			require.ErrorContains(t, err, "not valid PromQL")
+		}
+	})
+}
+
+func promCompatQuery(t *testing.T, refID, datasourceUID string, model map[string]any) models.AlertQuery {
+	t.Helper()
+	model["refId"] = refID
+	raw, err := json.Marshal(model)
+	require.NoError(t, err)
+	return models.AlertQuery{RefID: refID, DatasourceUID: datasourceUID, Model: raw}
+}
@@ -132,6 +132,10 @@ func (f *ProvisioningApiHandler) handleRouteExportMuteTiming(ctx *contextmodel.R
 	return f.svc.RouteGetMuteTimingExport(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteExportMuteTimings(ctx *contextmodel.ReqContext) response.Response {
 	return f.svc.RouteGetMuteTimingsExport(ctx)
 }
@@ -268,6 +268,79 @@ type WecomIntegration struct {
 	ToUser  *string `json:"touser,omitempty" yaml:"touser,omitempty" hcl:"to_user"`
 }
 
+type MatrixIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	HomeserverURL string `json:"homeserverUrl" yaml:"homeserverUrl" hcl:"homeserver_url"`
+	RoomID        string `json:"roomId" yaml:"roomId" hcl:"room_id"`
+	AccessToken   Secret `json:"accessToken" yaml:"accessToken" hcl:"access_token"`
+
+	MessageType *string `json:"messageType,omitempty" yaml:"messageType,omitempty" hcl:"message_type"`
+	Message     *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
+type MattermostIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL Secret `json:"url" yaml:"url" hcl:"url"`
+
+	Channel   *string `json:"channel,omitempty" yaml:"channel,omitempty" hcl:"channel"`
+	Username  *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	IconURL   *string `json:"icon_url,omitempty" yaml:"icon_url,omitempty" hcl:"icon_url"`
+	IconEmoji *string `json:"icon_emoji,omitempty" yaml:"icon_emoji,omitempty" hcl:"icon_emoji"`
+	Title     *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Text      *string `json:"text,omitempty" yaml:"text,omitempty" hcl:"text"`
+}
+
+type MqttIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	BrokerURL string `json:"brokerUrl" yaml:"brokerUrl" hcl:"broker_url"`
+	Topic     string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	ClientID           *string `json:"clientId,omitempty" yaml:"clientId,omitempty" hcl:"client_id"`
+	MessageFormat      *string `json:"messageFormat,omitempty" yaml:"messageFormat,omitempty" hcl:"message_format"`
+	Message            *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	Username           *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password           *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	QoS                *int64  `json:"qos,omitempty" yaml:"qos,omitempty" hcl:"qos"`
+	Retain             *bool   `json:"retain,omitempty" yaml:"retain,omitempty" hcl:"retain"`
+	InsecureSkipVerify *bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" hcl:"insecure_skip_verify"`
+}
+
+type NtfyIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	Topic string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	ServerURL *string `json:"serverUrl,omitempty" yaml:"serverUrl,omitempty" hcl:"server_url"`
+	Token     *Secret `json:"token,omitempty" yaml:"token,omitempty" hcl:"token"`
+	Username  *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password  *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	Priority  *int64  `json:"priority,omitempty" yaml:"priority,omitempty" hcl:"priority"`
+	Tags      *string `json:"tags,omitempty" yaml:"tags,omitempty" hcl:"tags"`
+	Click     *string `json:"click,omitempty" yaml:"click,omitempty" hcl:"click"`
+	Title     *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message   *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
+type SnsIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	AccessKey Secret `json:"access_key" yaml:"access_key" hcl:"access_key"`
+	SecretKey Secret `json:"secret_key" yaml:"secret_key" hcl:"secret_key"`
+
+	APIUrl        *string            `json:"api_url,omitempty" yaml:"api_url,omitempty" hcl:"api_url"`
+	Region        *string            `json:"region,omitempty" yaml:"region,omitempty" hcl:"region"`
+	AssumeRoleARN *string            `json:"assume_role_arn,omitempty" yaml:"assume_role_arn,omitempty" hcl:"assume_role_arn"`
+	TopicARN      *string            `json:"topic_arn,omitempty" yaml:"topic_arn,omitempty" hcl:"topic_arn"`
+	PhoneNumber   *string            `json:"phone_number,omitempty" yaml:"phone_number,omitempty" hcl:"phone_number"`
+	TargetARN     *string            `json:"target_arn,omitempty" yaml:"target_arn,omitempty" hcl:"target_arn"`
+	Subject       *string            `json:"subject,omitempty" yaml:"subject,omitempty" hcl:"subject"`
+	Message       *string            `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	Attributes    *map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty" hcl:"attributes"`
+}
+
 type ContactPoint struct {
 	Name         string                    `json:"name" yaml:"name" hcl:"name"`
 	Alertmanager []AlertmanagerIntegration `json:"alertmanager" yaml:"alertmanager" hcl:"alertmanager,block"`
@@ -290,4 +363,9 @@ type ContactPoint struct {
 	Webhook      []WebhookIntegration      `json:"webhook" yaml:"webhook" hcl:"webhook,block"`
 	Wecom        []WecomIntegration        `json:"wecom" yaml:"wecom" hcl:"wecom,block"`
 	Webex        []WebexIntegration        `json:"webex" yaml:"webex" hcl:"webex,block"`
+	Matrix       []MatrixIntegration       `json:"matrix" yaml:"matrix" hcl:"matrix,block"`
+	Mattermost   []MattermostIntegration   `json:"mattermost" yaml:"mattermost" hcl:"mattermost,block"`
+	Mqtt         []MqttIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
+	Ntfy         []NtfyIntegration         `json:"ntfy" yaml:"ntfy" hcl:"ntfy,block"`
+	Sns          []SnsIntegration          `json:"sns" yaml:"sns" hcl:"sns,block"`
 }
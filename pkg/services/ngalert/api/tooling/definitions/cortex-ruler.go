@@ -80,6 +80,26 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostRulesGroupsForImport
+//
+// Imports the rule groups of a Prometheus rule file as Grafana-managed alert rules that query the given data source.
+// Every group replaces the Grafana rule group with the same name in the folder. Recording rules are imported as
+// Grafana-managed recording rules if recording rules are enabled, and are skipped with a warning otherwise.
+//
+//     Consumes:
+//     - application/yaml
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ImportRuleGroupsResponse
+//       202: ImportRuleGroupsResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
 // swagger:route POST /ruler/{DatasourceUID}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
 // Creates or updates a rule group
@@ -182,6 +202,24 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostRulesGroupsForImport
+type ImportRuleGroupsParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// The UID of the data source that the imported rules query
+	// in:query
+	// required: true
+	DatasourceUID string `json:"datasource_uid"`
+	// Calculate the changes without applying them
+	// in:query
+	// required: false
+	// default: false
+	DryRun bool `json:"dry_run"`
+	// in:body
+	Body PrometheusRuleGroups
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// The UID of the rule folder
@@ -459,16 +497,63 @@ type AlertRuleNotificationSettings struct {
 	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
 }
 
+// swagger:model
+type AlertRuleFlapDetection struct {
+	// Number of the latest evaluations of an alert instance used to compute how often it changes state.
+	// minimum: 3
+	// maximum: 100
+	// default: 21
+	// example: 21
+	Window *int `json:"window,omitempty" yaml:"window,omitempty"`
+
+	// Percentage of state change above which an alert instance starts flapping. Recent state changes are weighted
+	// more than older ones.
+	// default: 50
+	// example: 50
+	HighThreshold *float64 `json:"high_threshold,omitempty" yaml:"high_threshold,omitempty"`
+
+	// Percentage of state change below which a flapping alert instance stops flapping.
+	// default: 25
+	// example: 25
+	LowThreshold *float64 `json:"low_threshold,omitempty" yaml:"low_threshold,omitempty"`
+
+	// Hold the notifications of an alert instance while it is flapping. The state of the alert instance is notified
+	// when it stops flapping.
+	// example: true
+	HoldNotifications bool `json:"hold_notifications,omitempty" yaml:"hold_notifications,omitempty"`
+}
+
+// swagger:model
+type AlertRuleRecord struct {
+	// Name of the metric the series of the condition are written to. The rule becomes a recording rule: its condition
+	// is evaluated on schedule and the resulting series are written to the remote-write endpoint configured for
+	// recording rules, instead of producing alerts.
+	// required: true
+	// example: grafana:http_requests:rate5m
+	Metric string `json:"metric" yaml:"metric"`
+}
+
 // swagger:model
 type PostableGrafanaRule struct {
 	Title                string                         `json:"title" yaml:"title"`
 	Condition            string                         `json:"condition" yaml:"condition"`
+	RecoveryCondition    string                         `json:"recovery_condition,omitempty" yaml:"recovery_condition,omitempty"`
 	Data                 []AlertQuery                   `json:"data" yaml:"data"`
 	UID                  string                         `json:"uid" yaml:"uid"`
 	NoDataState          NoDataState                    `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
+	FlapDetection        *AlertRuleFlapDetection        `json:"flap_detection,omitempty" yaml:"flap_detection,omitempty"`
+	Record               *AlertRuleRecord               `json:"record,omitempty" yaml:"record,omitempty"`
+	// UIDs of the rules of the same organization the rule depends on. The alert instances of the rule are suppressed
+	// while any of these rules is firing.
+	// example: ["bdmhvrwcj8jr4c"]
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// The maximum duration of an evaluation of the rule. It can only shorten the evaluation timeout of the
+	// organization, and cannot be longer than the evaluation interval of the rule.
+	// example: 10s
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
 }
 
 // swagger:model
@@ -477,6 +562,7 @@ type GettableGrafanaRule struct {
 	OrgID                int64                          `json:"orgId" yaml:"orgId"`
 	Title                string                         `json:"title" yaml:"title"`
 	Condition            string                         `json:"condition" yaml:"condition"`
+	RecoveryCondition    string                         `json:"recovery_condition,omitempty" yaml:"recovery_condition,omitempty"`
 	Data                 []AlertQuery                   `json:"data" yaml:"data"`
 	Updated              time.Time                      `json:"updated" yaml:"updated"`
 	IntervalSeconds      int64                          `json:"intervalSeconds" yaml:"intervalSeconds"`
@@ -489,6 +575,16 @@ type GettableGrafanaRule struct {
 	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	FlapDetection        *AlertRuleFlapDetection        `json:"flap_detection,omitempty" yaml:"flap_detection,omitempty"`
+	Record               *AlertRuleRecord               `json:"record,omitempty" yaml:"record,omitempty"`
+	// UIDs of the rules of the same organization the rule depends on. The alert instances of the rule are suppressed
+	// while any of these rules is firing.
+	// example: ["bdmhvrwcj8jr4c"]
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// The maximum duration of an evaluation of the rule. It can only shorten the evaluation timeout of the
+	// organization, and cannot be longer than the evaluation interval of the rule.
+	// example: 10s
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -565,3 +661,28 @@ type UpdateRuleGroupResponse struct {
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
 }
+
+// swagger:model
+type ImportRuleGroupsResponse struct {
+	Message string `json:"message"`
+	// DryRun is true if the changes were not applied.
+	DryRun bool                    `json:"dryRun"`
+	Groups []ImportRuleGroupResult `json:"groups"`
+	// Warnings explain why rules of the file were not imported.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ImportRuleGroupResult contains the changes of an imported rule group. Rules are identified by their titles.
+type ImportRuleGroupResult struct {
+	Name    string           `json:"name"`
+	Created []string         `json:"created,omitempty"`
+	Updated []ImportRuleDiff `json:"updated,omitempty"`
+	Deleted []string         `json:"deleted,omitempty"`
+}
+
+type ImportRuleDiff struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// Diff describes the changes to the fields of the rule.
+	Diff string `json:"diff"`
+}
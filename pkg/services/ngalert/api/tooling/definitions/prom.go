@@ -189,6 +189,8 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// Flapping is true if the alert changes state too often according to the flap detection settings of the rule.
+	Flapping bool `json:"flapping,omitempty"`
 }
 
 type StateByImportance int
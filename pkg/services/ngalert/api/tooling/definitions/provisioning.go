@@ -26,7 +26,7 @@ type ExportQueryParams struct {
 	Format string `json:"format"`
 }
 
-// swagger:parameters RouteGetContactpointsExport RouteGetContactpointExport
+// swagger:parameters RouteGetContactpointsExport RouteGetContactpointExport RouteGetExport
 type DecryptQueryParams struct {
 	// Whether any contained secure settings should be decrypted or left redacted. Redacted settings will contain RedactedValue instead. Currently, only org admin can view decrypted secure settings.
 	// in: query
@@ -146,6 +146,9 @@ type ProvisionedAlertRule struct {
 	// required: true
 	// example: A
 	Condition string `json:"condition"`
+	// RefID of the query or expression that keeps the firing alert instances firing as long as it is met.
+	// example: B
+	RecoveryCondition string `json:"recovery_condition,omitempty"`
 	// required: true
 	// example: [{"refId":"A","queryType":"","relativeTimeRange":{"from":0,"to":0},"datasourceUid":"__expr__","model":{"conditions":[{"evaluator":{"params":[0,0],"type":"gt"},"operator":{"type":"and"},"query":{"params":[]},"reducer":{"params":[],"type":"avg"},"type":"query"}],"datasource":{"type":"__expr__","uid":"__expr__"},"expression":"1 == 1","hide":false,"intervalMs":1000,"maxDataPoints":43200,"refId":"A","type":"math"}}]
 	Data []AlertQuery `json:"data"`
@@ -167,6 +170,14 @@ type ProvisionedAlertRule struct {
 	IsPaused bool `json:"isPaused"`
 	// example: {"receiver":"email","group_by":["alertname","grafana_folder","cluster"],"group_wait":"30s","group_interval":"1m","repeat_interval":"4d","mute_time_intervals":["Weekends","Holidays"]}
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
+	// example: {"window":21,"high_threshold":50,"low_threshold":25,"hold_notifications":true}
+	FlapDetection *AlertRuleFlapDetection `json:"flap_detection,omitempty"`
+	// example: {"metric":"grafana:http_requests:rate5m"}
+	Record *AlertRuleRecord `json:"record,omitempty"`
+	// example: ["bdmhvrwcj8jr4c"]
+	DependsOn []string `json:"depends_on,omitempty"`
+	// example: 10s
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -273,6 +284,11 @@ type AlertRuleExport struct {
 	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
+	FlapDetection        *AlertRuleFlapDetectionExport        `json:"flap_detection,omitempty" yaml:"flap_detection,omitempty" hcl:"flap_detection,block"`
+	RecoveryCondition    *string                              `json:"recovery_condition,omitempty" yaml:"recovery_condition,omitempty" hcl:"recovery_condition"`
+	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record,block"`
+	DependsOn            *[]string                            `json:"depends_on,omitempty" yaml:"depends_on,omitempty" hcl:"depends_on"`
+	EvaluationTimeout    *string                              `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty" hcl:"evaluation_timeout"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -300,3 +316,16 @@ type AlertRuleNotificationSettingsExport struct {
 	RepeatInterval    *string  `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty" hcl:"repeat_interval,optional"`
 	MuteTimeIntervals []string `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty" hcl:"mute_time_intervals"`
 }
+
+// AlertRuleFlapDetectionExport is the provisioned export of models.FlapDetectionSettings.
+type AlertRuleFlapDetectionExport struct {
+	Window            int     `yaml:"window" json:"window" hcl:"window"`
+	HighThreshold     float64 `yaml:"high_threshold" json:"high_threshold" hcl:"high_threshold"`
+	LowThreshold      float64 `yaml:"low_threshold" json:"low_threshold" hcl:"low_threshold"`
+	HoldNotifications bool    `yaml:"hold_notifications,omitempty" json:"hold_notifications,omitempty" hcl:"hold_notifications"`
+}
+
+// AlertRuleRecordExport is the provisioned export of models.RecordSettings.
+type AlertRuleRecordExport struct {
+	Metric string `yaml:"metric" json:"metric" hcl:"metric"`
+}
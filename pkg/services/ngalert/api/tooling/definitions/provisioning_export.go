@@ -0,0 +1,36 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/export provisioning stable RouteGetExport
+//
+// Export the alert rules, contact points, notification policies, mute timings and templates of the organization
+// as a zip archive of Terraform files, together with Prometheus rule files for the alert rules that can be
+// evaluated by a Prometheus-compatible ruler.
+//
+//     Produces:
+//     - application/zip
+//
+//     Responses:
+//       200: description: The zip archive.
+//       403: PermissionDenied
+
+// PrometheusRuleGroups is a rule file in the format of Prometheus and Mimir.
+type PrometheusRuleGroups struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRuleGroup is a rule group in the format of Prometheus and Mimir.
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// NotificationTemplateExportHcl is a representation of the NotificationTemplate in HCL
+type NotificationTemplateExportHcl struct {
+	Name     string `json:"name" hcl:"name"`
+	Template string `json:"template" hcl:"template"`
+}
@@ -68,6 +68,21 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /v1/rule/backtest/rules testing RouteBacktestRules
+//
+// Test the rules of a folder or a rule group
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestRulesResult
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -230,7 +245,94 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// Notifications enables the simulation of the notifications that would have been sent for the alerts of the rule
+	// with the notification policies, inhibition rules and mute timings of the Grafana Alertmanager.
+	// The response is a BacktestNotificationsResult instead of a BacktestResult.
+	Notifications bool `json:"notifications,omitempty"`
+	// NamespaceUID is the UID of the folder of the rule, used to route the alerts by the labels of the folder.
+	NamespaceUID string `json:"namespace_uid,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the same frame as BacktestResult.
+	States *data.Frame `json:"states"`
+	// Notifications are the notifications in chronological order.
+	Notifications []BacktestNotification `json:"notifications"`
+	// Receivers is the number of notifications of each contact point.
+	Receivers map[string]int `json:"receivers"`
+}
+
+// swagger:model
+type BacktestNotification struct {
+	Time     time.Time `json:"time"`
+	Receiver string    `json:"receiver"`
+	// GroupKey identifies the notification policy and the group of alerts of the notification.
+	GroupKey    string            `json:"groupKey"`
+	GroupLabels map[string]string `json:"groupLabels"`
+	// Status is firing if at least one alert of the notification is firing, and resolved otherwise.
+	Status   string              `json:"status"`
+	Firing   []map[string]string `json:"firing"`
+	Resolved []map[string]string `json:"resolved"`
+}
+
+// swagger:parameters RouteBacktestRules
+type BacktestRulesRequest struct {
+	// in:body
+	Body BacktestRulesConfig
+}
+
+// swagger:model
+type BacktestRulesConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// NamespaceUID is the UID of the folder of the rules.
+	NamespaceUID string `json:"namespace_uid"`
+	// RuleGroup limits the rules to a rule group of the folder.
+	RuleGroup string `json:"rule_group,omitempty"`
+
+	// Rules are modified definitions of rules of the rule group, identified by their UID, or new rules of the rule group.
+	// The saved definition of each modified rule is tested too, and the difference between the results is returned.
+	Rules []PostableExtendedRuleNode `json:"rules,omitempty"`
+	// Interval is the evaluation interval of the modified rules. It defaults to the interval of the saved rule group.
+	Interval model.Duration `json:"interval,omitempty"`
+}
+
+// swagger:model
+type BacktestRulesResult struct {
+	Rules []BacktestRuleSummary `json:"rules"`
+}
+
+// swagger:model
+type BacktestRuleSummary struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	RuleGroup string `json:"rule_group"`
+
+	Evaluations int `json:"evaluations"`
+	// Firings is the number of times that an alert of the rule started firing.
+	Firings int `json:"firings"`
+	// Flaps is the number of times that an alert of the rule started firing again after it was resolved.
+	Flaps int `json:"flaps"`
+	// StateSeconds is the total time in seconds that the alerts of the rule spent in each state.
+	StateSeconds map[string]float64 `json:"state_seconds"`
+	// Error is the error of testing the rule. The summary is empty when there is an error.
+	Error string `json:"error,omitempty"`
+
+	// Saved is the summary of the saved definition of a modified rule.
+	Saved *BacktestRuleSummary `json:"saved,omitempty"`
+	// Diff is the difference between the summary of a modified rule and the summary of its saved definition.
+	Diff *BacktestRuleSummaryDiff `json:"diff,omitempty"`
+}
+
+// swagger:model
+type BacktestRuleSummaryDiff struct {
+	Firings      int                `json:"firings"`
+	Flaps        int                `json:"flaps"`
+	StateSeconds map[string]float64 `json:"state_seconds"`
+}
@@ -64,6 +64,23 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	return e.test(ctx, user, rule, from, to, nil, nil)
+}
+
+// TestNotifications tests the rule like Test, and also simulates the notifications that the Alertmanager would have sent
+// for the alerts of the rule with the notification policy. The extra labels are added to the alerts of the rule,
+// like the labels added by the scheduler, so that they are routed like the alerts of a saved rule.
+func (e *Engine) TestNotifications(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, policy NotificationPolicy, extraLabels data.Labels) (*data.Frame, []Notification, error) {
+	simulator := newNotificationSimulator(policy)
+	result, err := e.test(ctx, user, rule, from, to, extraLabels, simulator.process)
+	if err != nil {
+		return nil, nil, err
+	}
+	simulator.advance(to)
+	return result, simulator.notifications, nil
+}
+
+func (e *Engine) test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, extraLabels data.Labels, onTransitions func(now time.Time, transitions []state.StateTransition)) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -97,7 +114,10 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
 			return nil
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels)
+		if onTransitions != nil {
+			onTransitions(currentTime, states)
+		}
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
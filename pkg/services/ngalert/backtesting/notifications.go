@@ -0,0 +1,362 @@
+package backtesting
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	NotificationStatusFiring   = "firing"
+	NotificationStatusResolved = "resolved"
+)
+
+// NotificationPolicy holds the parts of an Alertmanager configuration that decide which contact points are notified
+// about alerts, and when.
+type NotificationPolicy struct {
+	Route             *config.Route
+	InhibitRules      []config.InhibitRule
+	MuteTimeIntervals []config.MuteTimeInterval
+	TimeIntervals     []config.TimeInterval
+}
+
+// NotificationPolicyFromConfig returns the notification policy of an Alertmanager configuration.
+func NotificationPolicyFromConfig(cfg *apimodels.PostableApiAlertingConfig) NotificationPolicy {
+	p := NotificationPolicy{
+		InhibitRules:      cfg.InhibitRules,
+		MuteTimeIntervals: cfg.MuteTimeIntervals,
+		TimeIntervals:     cfg.TimeIntervals,
+	}
+	if cfg.Route != nil {
+		p.Route = cfg.Route.AsAMRoute()
+	}
+	return p
+}
+
+// Notification is a notification that the Alertmanager would have sent to a contact point.
+type Notification struct {
+	Time     time.Time
+	Receiver string
+	// GroupKey identifies the notification policy and the group of alerts of the notification.
+	GroupKey    string
+	GroupLabels data.Labels
+	// Status is firing if at least one alert of the notification is firing, and resolved otherwise.
+	Status   string
+	Firing   []data.Labels
+	Resolved []data.Labels
+}
+
+type simulatedAlert struct {
+	labels     model.LabelSet
+	resolvedAt time.Time
+}
+
+func (a *simulatedAlert) resolved() bool {
+	return !a.resolvedAt.IsZero()
+}
+
+// notificationLogEntry is the last notification of a group, like an entry of the notification log of the Alertmanager.
+type notificationLogEntry struct {
+	at       time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+// aggregationGroup is a group of alerts of a notification policy that are notified together.
+type aggregationGroup struct {
+	key       string
+	route     *dispatch.Route
+	labels    model.LabelSet
+	alerts    map[model.Fingerprint]*simulatedAlert
+	nextFlush time.Time
+}
+
+// notificationSimulator simulates how the Alertmanager dispatches the alerts of a rule to contact points:
+// routing with the notification policy tree, grouping with group_wait, group_interval and repeat_interval,
+// inhibition, and mute timings. It works with simulated time, so the alerts must be processed in chronological order.
+// Only the alerts of the rule are simulated, so alerts of other rules can not inhibit them.
+type notificationSimulator struct {
+	route        *dispatch.Route
+	inhibitRules []*inhibit.InhibitRule
+	intervals    map[string][]timeinterval.TimeInterval
+
+	// alerts are the firing alerts
+	alerts map[model.Fingerprint]*simulatedAlert
+	// byCacheID is the alert of each state of the rule, used to resolve the alert when the state or its labels change.
+	byCacheID map[string]model.Fingerprint
+	groups    map[string]*aggregationGroup
+	// log is the last notification of each group. It is kept when a group is deleted, like in the Alertmanager.
+	log           map[string]*notificationLogEntry
+	notifications []Notification
+}
+
+func newNotificationSimulator(policy NotificationPolicy) *notificationSimulator {
+	route := policy.Route
+	if route == nil {
+		route = &config.Route{}
+	}
+	s := &notificationSimulator{
+		route:     dispatch.NewRoute(route, nil),
+		intervals: make(map[string][]timeinterval.TimeInterval, len(policy.MuteTimeIntervals)+len(policy.TimeIntervals)),
+		alerts:    map[model.Fingerprint]*simulatedAlert{},
+		byCacheID: map[string]model.Fingerprint{},
+		groups:    map[string]*aggregationGroup{},
+		log:       map[string]*notificationLogEntry{},
+	}
+	for _, r := range policy.InhibitRules {
+		s.inhibitRules = append(s.inhibitRules, inhibit.NewInhibitRule(r))
+	}
+	for _, ti := range policy.MuteTimeIntervals {
+		s.intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range policy.TimeIntervals {
+		s.intervals[ti.Name] = ti.TimeIntervals
+	}
+	return s
+}
+
+// process sends the notifications that are due before now, and then updates the alerts with the state transitions
+// of an evaluation at now.
+func (s *notificationSimulator) process(now time.Time, transitions []state.StateTransition) {
+	s.advance(now)
+	for _, t := range transitions {
+		prev, hadAlert := s.byCacheID[t.CacheID]
+		switch t.State.State {
+		case eval.Alerting, eval.NoData, eval.Error:
+			// the labels of the alert depend on the state, see state.StateToPostableAlert
+			postable := state.StateToPostableAlert(t, nil)
+			labels := make(model.LabelSet, len(postable.Labels))
+			for k, v := range postable.Labels {
+				labels[model.LabelName(k)] = model.LabelValue(v)
+			}
+			fp := labels.Fingerprint()
+			if hadAlert && prev != fp {
+				s.resolve(prev, now)
+			}
+			s.byCacheID[t.CacheID] = fp
+			s.fire(fp, labels, now)
+		default:
+			if hadAlert {
+				s.resolve(prev, now)
+				delete(s.byCacheID, t.CacheID)
+			}
+		}
+	}
+}
+
+func (s *notificationSimulator) fire(fp model.Fingerprint, labels model.LabelSet, now time.Time) {
+	a, ok := s.alerts[fp]
+	if !ok {
+		a = &simulatedAlert{labels: labels}
+		s.alerts[fp] = a
+	}
+	for _, r := range s.route.Match(labels) {
+		groupLabels := model.LabelSet{}
+		for ln, lv := range labels {
+			if _, ok := r.RouteOpts.GroupBy[ln]; ok || r.RouteOpts.GroupByAll {
+				groupLabels[ln] = lv
+			}
+		}
+		key := r.ID() + ":" + groupLabels.String()
+		g, ok := s.groups[key]
+		if !ok {
+			g = &aggregationGroup{
+				key:       key,
+				route:     r,
+				labels:    groupLabels,
+				alerts:    map[model.Fingerprint]*simulatedAlert{},
+				nextFlush: now.Add(r.RouteOpts.GroupWait),
+			}
+			s.groups[key] = g
+		}
+		g.alerts[fp] = a
+	}
+}
+
+func (s *notificationSimulator) resolve(fp model.Fingerprint, now time.Time) {
+	if a, ok := s.alerts[fp]; ok {
+		a.resolvedAt = now
+		delete(s.alerts, fp)
+	}
+}
+
+// advance flushes the groups that are due before or at now, in chronological order.
+func (s *notificationSimulator) advance(now time.Time) {
+	for {
+		var next *aggregationGroup
+		for _, g := range s.groups {
+			if g.nextFlush.After(now) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+// flush sends the notification of a group if it needs one, and removes the resolved alerts from the group.
+func (s *notificationSimulator) flush(g *aggregationGroup) {
+	now := g.nextFlush
+	var firing, resolved []*simulatedAlert
+	for _, a := range g.alerts {
+		if s.inhibited(a) {
+			continue
+		}
+		if a.resolved() {
+			resolved = append(resolved, a)
+		} else {
+			firing = append(firing, a)
+		}
+	}
+
+	if !s.muted(g.route, now) && g.needsNotification(s.log[g.key], firing, resolved, now) {
+		s.notifications = append(s.notifications, g.notification(firing, resolved, now))
+		s.log[g.key] = &notificationLogEntry{at: now, firing: fingerprints(firing), resolved: fingerprints(resolved)}
+	}
+
+	for fp, a := range g.alerts {
+		if a.resolved() {
+			delete(g.alerts, fp)
+		}
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.key)
+		return
+	}
+	g.nextFlush = now.Add(g.route.RouteOpts.GroupInterval)
+}
+
+// inhibited returns true if a firing alert matches the source matchers of an inhibition rule for which the alert
+// matches the target matchers. Alerts that match both sides of a rule can not inhibit other alerts of the rule.
+func (s *notificationSimulator) inhibited(a *simulatedAlert) bool {
+	for _, r := range s.inhibitRules {
+		if !r.TargetMatchers.Matches(a.labels) {
+			continue
+		}
+		excludeTwoSidedMatch := r.SourceMatchers.Matches(a.labels)
+	sources:
+		for _, source := range s.alerts {
+			if source == a || !r.SourceMatchers.Matches(source.labels) {
+				continue
+			}
+			if excludeTwoSidedMatch && r.TargetMatchers.Matches(source.labels) {
+				continue
+			}
+			for ln := range r.Equal {
+				if source.labels[ln] != a.labels[ln] {
+					continue sources
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// muted returns true if the route is muted at the time by one of its mute timings, or if the route has active
+// timings and none of them contains the time, like the time muting and time active stages of the Alertmanager.
+func (s *notificationSimulator) muted(r *dispatch.Route, now time.Time) bool {
+	if s.inIntervals(r.RouteOpts.MuteTimeIntervals, now) {
+		return true
+	}
+	return len(r.RouteOpts.ActiveTimeIntervals) > 0 && !s.inIntervals(r.RouteOpts.ActiveTimeIntervals, now)
+}
+
+// inIntervals returns true if one of the named time intervals contains the time.
+func (s *notificationSimulator) inIntervals(names []string, now time.Time) bool {
+	for _, name := range names {
+		for _, ti := range s.intervals[name] {
+			if ti.ContainsTime(now.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsNotification decides whether to notify like the deduplication stage of the Alertmanager,
+// assuming that contact points send resolved notifications.
+func (g *aggregationGroup) needsNotification(last *notificationLogEntry, firing, resolved []*simulatedAlert, now time.Time) bool {
+	if last == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(fingerprints(firing), last.firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		// alerts that fired and resolved since the last notification are not notified
+		return len(last.firing) > 0
+	}
+	if !isSubset(fingerprints(resolved), last.resolved) {
+		return true
+	}
+	return last.at.Before(now.Add(-g.route.RouteOpts.RepeatInterval))
+}
+
+func (g *aggregationGroup) notification(firing, resolved []*simulatedAlert, now time.Time) Notification {
+	n := Notification{
+		Time:        now,
+		Receiver:    g.route.RouteOpts.Receiver,
+		GroupKey:    g.key,
+		GroupLabels: toDataLabels(g.labels),
+		Status:      NotificationStatusResolved,
+		Firing:      sortedLabels(firing),
+		Resolved:    sortedLabels(resolved),
+	}
+	if len(firing) > 0 {
+		n.Status = NotificationStatusFiring
+	}
+	return n
+}
+
+func fingerprints(alerts []*simulatedAlert) map[model.Fingerprint]struct{} {
+	result := make(map[model.Fingerprint]struct{}, len(alerts))
+	for _, a := range alerts {
+		result[a.labels.Fingerprint()] = struct{}{}
+	}
+	return result
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func toDataLabels(ls model.LabelSet) data.Labels {
+	result := make(data.Labels, len(ls))
+	for k, v := range ls {
+		result[string(k)] = string(v)
+	}
+	return result
+}
+
+func sortedLabels(alerts []*simulatedAlert) []data.Labels {
+	result := make([]data.Labels, 0, len(alerts))
+	for _, a := range alerts {
+		result = append(result, toDataLabels(a.labels))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
@@ -0,0 +1,205 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	t0 := time.Unix(0, 0).UTC()
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	matchers := func(t *testing.T, s ...string) config.Matchers {
+		t.Helper()
+		result := config.Matchers{}
+		for _, m := range s {
+			matcher, err := labels.ParseMatcher(m)
+			require.NoError(t, err)
+			result = append(result, matcher)
+		}
+		return result
+	}
+	defaultRoute := func() *config.Route {
+		return &config.Route{
+			Receiver:       "default",
+			GroupBy:        []model.LabelName{"alertname"},
+			GroupWait:      duration(30 * time.Second),
+			GroupInterval:  duration(5 * time.Minute),
+			RepeatInterval: duration(time.Hour),
+		}
+	}
+	transition := func(s eval.State, l data.Labels) state.StateTransition {
+		return state.StateTransition{State: &state.State{CacheID: l.String(), Labels: l, State: s}}
+	}
+	// simulate evaluates every minute until the end, with the transitions returned by evaluation for each minute.
+	simulate := func(policy NotificationPolicy, end time.Duration, evaluation func(minute int) []state.StateTransition) []Notification {
+		s := newNotificationSimulator(policy)
+		for m := 0; time.Duration(m)*time.Minute <= end; m++ {
+			s.process(t0.Add(time.Duration(m)*time.Minute), evaluation(m))
+		}
+		s.advance(t0.Add(end))
+		return s.notifications
+	}
+	alertA := data.Labels{"alertname": "test", "host": "a"}
+	alertB := data.Labels{"alertname": "test", "host": "b"}
+	alertC := data.Labels{"alertname": "test", "host": "c"}
+
+	t.Run("groups alerts with group_wait and group_interval", func(t *testing.T) {
+		notifications := simulate(NotificationPolicy{Route: defaultRoute()}, 10*time.Minute, func(m int) []state.StateTransition {
+			result := []state.StateTransition{transition(eval.Alerting, alertA), transition(eval.Alerting, alertB)}
+			if m >= 1 {
+				result = append(result, transition(eval.Alerting, alertC))
+			}
+			return result
+		})
+		require.Len(t, notifications, 2)
+		require.Equal(t, Notification{
+			Time:        t0.Add(30 * time.Second),
+			Receiver:    "default",
+			GroupKey:    `{}:{alertname="test"}`,
+			GroupLabels: data.Labels{"alertname": "test"},
+			Status:      NotificationStatusFiring,
+			Firing:      []data.Labels{alertA, alertB},
+			Resolved:    []data.Labels{},
+		}, notifications[0])
+		require.Equal(t, t0.Add(5*time.Minute+30*time.Second), notifications[1].Time)
+		require.Equal(t, []data.Labels{alertA, alertB, alertC}, notifications[1].Firing)
+	})
+
+	t.Run("notifies again after repeat_interval", func(t *testing.T) {
+		route := defaultRoute()
+		route.RepeatInterval = duration(10 * time.Minute)
+		notifications := simulate(NotificationPolicy{Route: route}, 30*time.Minute, func(int) []state.StateTransition {
+			return []state.StateTransition{transition(eval.Alerting, alertA)}
+		})
+		require.Len(t, notifications, 2)
+		require.Equal(t, t0.Add(30*time.Second), notifications[0].Time)
+		require.Equal(t, t0.Add(15*time.Minute+30*time.Second), notifications[1].Time)
+	})
+
+	t.Run("notifies resolved alerts", func(t *testing.T) {
+		notifications := simulate(NotificationPolicy{Route: defaultRoute()}, 20*time.Minute, func(m int) []state.StateTransition {
+			if m < 2 {
+				return []state.StateTransition{transition(eval.Alerting, alertA)}
+			}
+			return []state.StateTransition{transition(eval.Normal, alertA)}
+		})
+		require.Len(t, notifications, 2)
+		require.Equal(t, NotificationStatusFiring, notifications[0].Status)
+		require.Equal(t, t0.Add(5*time.Minute+30*time.Second), notifications[1].Time)
+		require.Equal(t, NotificationStatusResolved, notifications[1].Status)
+		require.Empty(t, notifications[1].Firing)
+		require.Equal(t, []data.Labels{alertA}, notifications[1].Resolved)
+	})
+
+	t.Run("does not notify alerts resolved before group_wait", func(t *testing.T) {
+		route := defaultRoute()
+		route.GroupWait = duration(2 * time.Minute)
+		notifications := simulate(NotificationPolicy{Route: route}, 20*time.Minute, func(m int) []state.StateTransition {
+			if m < 1 {
+				return []state.StateTransition{transition(eval.Alerting, alertA)}
+			}
+			return []state.StateTransition{transition(eval.Normal, alertA)}
+		})
+		require.Empty(t, notifications)
+	})
+
+	t.Run("routes alerts to child policies", func(t *testing.T) {
+		route := defaultRoute()
+		route.Routes = []*config.Route{{Receiver: "pager", Matchers: matchers(t, `host="b"`)}}
+		notifications := simulate(NotificationPolicy{Route: route}, time.Minute, func(int) []state.StateTransition {
+			return []state.StateTransition{transition(eval.Alerting, alertA), transition(eval.Alerting, alertB)}
+		})
+		require.Len(t, notifications, 2)
+		receivers := map[string][]data.Labels{}
+		for _, n := range notifications {
+			receivers[n.Receiver] = n.Firing
+		}
+		require.Equal(t, map[string][]data.Labels{"default": {alertA}, "pager": {alertB}}, receivers)
+	})
+
+	t.Run("does not notify inhibited alerts", func(t *testing.T) {
+		critical := data.Labels{"alertname": "test", "host": "a", "severity": "critical"}
+		warning := data.Labels{"alertname": "test", "host": "a", "severity": "warning"}
+		otherHost := data.Labels{"alertname": "test", "host": "b", "severity": "warning"}
+		policy := NotificationPolicy{
+			Route: defaultRoute(),
+			InhibitRules: []config.InhibitRule{{
+				SourceMatchers: matchers(t, `severity="critical"`),
+				TargetMatchers: matchers(t, `severity="warning"`),
+				Equal:          model.LabelNames{"host"},
+			}},
+		}
+		notifications := simulate(policy, time.Minute, func(int) []state.StateTransition {
+			return []state.StateTransition{
+				transition(eval.Alerting, critical),
+				transition(eval.Alerting, warning),
+				transition(eval.Alerting, otherHost),
+			}
+		})
+		require.Len(t, notifications, 1)
+		require.Equal(t, []data.Labels{critical, otherHost}, notifications[0].Firing)
+	})
+
+	t.Run("does not notify when the policy is muted", func(t *testing.T) {
+		route := defaultRoute()
+		route.MuteTimeIntervals = []string{"always"}
+		policy := NotificationPolicy{
+			Route:             route,
+			MuteTimeIntervals: []config.MuteTimeInterval{{Name: "always", TimeIntervals: []timeinterval.TimeInterval{{}}}},
+		}
+		notifications := simulate(policy, 10*time.Minute, func(int) []state.StateTransition {
+			return []state.StateTransition{transition(eval.Alerting, alertA)}
+		})
+		require.Empty(t, notifications)
+	})
+
+	t.Run("notifies only during the active time intervals of the policy", func(t *testing.T) {
+		never := []timeinterval.TimeInterval{{Years: []timeinterval.YearRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 2000, End: 2000}}}}}
+		policy := func(active ...string) NotificationPolicy {
+			route := defaultRoute()
+			route.ActiveTimeIntervals = active
+			return NotificationPolicy{
+				Route: route,
+				TimeIntervals: []config.TimeInterval{
+					{Name: "always", TimeIntervals: []timeinterval.TimeInterval{{}}},
+					{Name: "never", TimeIntervals: never},
+				},
+			}
+		}
+		firing := func(int) []state.StateTransition {
+			return []state.StateTransition{transition(eval.Alerting, alertA)}
+		}
+
+		require.Empty(t, simulate(policy("never"), 10*time.Minute, firing))
+		require.Len(t, simulate(policy("never", "always"), 10*time.Minute, firing), 1)
+	})
+
+	t.Run("resolves the no data alert when the state changes to alerting", func(t *testing.T) {
+		notifications := simulate(NotificationPolicy{Route: defaultRoute()}, 10*time.Minute, func(m int) []state.StateTransition {
+			if m < 2 {
+				return []state.StateTransition{transition(eval.NoData, alertA)}
+			}
+			return []state.StateTransition{transition(eval.Alerting, alertA)}
+		})
+		require.Len(t, notifications, 3)
+		noData := data.Labels{"alertname": "DatasourceNoData", "host": "a", "rulename": "test"}
+		require.Equal(t, []data.Labels{noData}, notifications[0].Firing)
+		byGroup := map[string]Notification{notifications[1].GroupKey: notifications[1], notifications[2].GroupKey: notifications[2]}
+		require.Equal(t, []data.Labels{noData}, byGroup[`{}:{alertname="DatasourceNoData"}`].Resolved)
+		require.Equal(t, []data.Labels{alertA}, byGroup[`{}:{alertname="test"}`].Firing)
+	})
+}
@@ -0,0 +1,105 @@
+package backtesting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// RuleSummary summarizes the results of testing a rule.
+type RuleSummary struct {
+	Rule        *models.AlertRule
+	Evaluations int
+	// Firings is the number of times that an alert of the rule started firing.
+	Firings int
+	// Flaps is the number of times that an alert of the rule started firing again after it was resolved.
+	Flaps int
+	// StateDurations is the total time that the alerts of the rule spent in each state.
+	// Each evaluation of an alert counts as one evaluation interval in its state.
+	StateDurations map[eval.State]time.Duration
+	// Error is the error of testing the rule, if any. The other fields are not set when there is an error.
+	Error error
+}
+
+// ruleSummarizer builds the summary of a rule from the state transitions of its evaluations.
+type ruleSummarizer struct {
+	summary  *RuleSummary
+	interval time.Duration
+	// resolved are the alerts that fired and were resolved since.
+	resolved map[string]struct{}
+}
+
+func newRuleSummarizer(rule *models.AlertRule) *ruleSummarizer {
+	return &ruleSummarizer{
+		summary: &RuleSummary{
+			Rule:           rule,
+			StateDurations: map[eval.State]time.Duration{},
+		},
+		interval: time.Duration(rule.IntervalSeconds) * time.Second,
+		resolved: map[string]struct{}{},
+	}
+}
+
+func (s *ruleSummarizer) process(_ time.Time, transitions []state.StateTransition) {
+	s.summary.Evaluations++
+	for _, t := range transitions {
+		s.summary.StateDurations[t.State.State] += s.interval
+		firing := t.State.State == eval.Alerting
+		wasFiring := t.PreviousState == eval.Alerting
+		switch {
+		case firing && !wasFiring:
+			s.summary.Firings++
+			if _, ok := s.resolved[t.CacheID]; ok {
+				s.summary.Flaps++
+				delete(s.resolved, t.CacheID)
+			}
+		case !firing && wasFiring:
+			s.resolved[t.CacheID] = struct{}{}
+		}
+	}
+}
+
+// TestSummary tests the rule like Test, and returns a summary of the results instead of the state of each evaluation.
+func (e *Engine) TestSummary(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) RuleSummary {
+	summarizer := newRuleSummarizer(rule)
+	if _, err := e.test(ctx, user, rule, from, to, nil, summarizer.process); err != nil {
+		return RuleSummary{Rule: rule, Error: err}
+	}
+	return *summarizer.summary
+}
+
+// TestRules tests the rules with at most concurrency rules tested at the same time, and returns the summaries in the order
+// of the rules. A rule that fails to be tested has the error in its summary, and does not stop the test of other rules.
+func (e *Engine) TestRules(ctx context.Context, user identity.Requester, rules []*models.AlertRule, from, to time.Time, concurrency int) []RuleSummary {
+	summaries := make([]RuleSummary, len(rules))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(concurrency, len(rules)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if err := ctx.Err(); err != nil {
+					summaries[idx] = RuleSummary{Rule: rules[idx], Error: err}
+					continue
+				}
+				summaries[idx] = e.TestSummary(ctx, user, rules[idx], from, to)
+			}
+		}()
+	}
+	for idx := range rules {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	return summaries
+}
@@ -0,0 +1,117 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestRuleSummarizer(t *testing.T) {
+	rule := models.AlertRuleGen(models.WithInterval(time.Minute))()
+	transition := func(cacheID string, previous, current eval.State) state.StateTransition {
+		return state.StateTransition{
+			State:         &state.State{CacheID: cacheID, Labels: data.Labels{"id": cacheID}, State: current},
+			PreviousState: previous,
+		}
+	}
+
+	s := newRuleSummarizer(rule)
+	evaluations := [][]state.StateTransition{
+		{transition("a", eval.Normal, eval.Pending), transition("b", eval.Normal, eval.Alerting)},
+		{transition("a", eval.Pending, eval.Alerting), transition("b", eval.Alerting, eval.Normal)},
+		{transition("a", eval.Alerting, eval.Alerting), transition("b", eval.Normal, eval.Alerting)},
+		{transition("a", eval.Alerting, eval.Normal), transition("b", eval.Alerting, eval.Normal)},
+		{transition("a", eval.Normal, eval.Alerting), transition("b", eval.Normal, eval.Normal)},
+	}
+	for i, transitions := range evaluations {
+		s.process(time.Unix(int64(i*60), 0), transitions)
+	}
+
+	require.Equal(t, RuleSummary{
+		Rule:        rule,
+		Evaluations: 5,
+		Firings:     4,
+		Flaps:       2,
+		StateDurations: map[eval.State]time.Duration{
+			eval.Normal:   4 * time.Minute,
+			eval.Pending:  time.Minute,
+			eval.Alerting: 5 * time.Minute,
+		},
+	}, *s.summary)
+}
+
+func TestEngineTestRules(t *testing.T) {
+	expectedError := errors.New("test-error")
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	failingEvaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return nil, expectedError
+		},
+	}
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{{
+				State:         &state.State{CacheID: "a", State: eval.Alerting},
+				PreviousState: eval.Normal,
+			}}
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		if condition.Condition == "fail" {
+			return failingEvaluator, nil
+		}
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+
+	rules := models.GenerateAlertRules(10, models.AlertRuleGen(models.WithInterval(time.Second)))
+	rules[3].Condition = "fail"
+	from := time.Unix(0, 0)
+	to := from.Add(5 * time.Second)
+
+	summaries := engine.TestRules(context.Background(), nil, rules, from, to, 3)
+
+	require.Len(t, summaries, len(rules))
+	for i, s := range summaries {
+		require.Same(t, rules[i], s.Rule)
+		if i == 3 {
+			require.ErrorIs(t, s.Error, expectedError)
+			continue
+		}
+		require.NoError(t, s.Error)
+		require.Equal(t, 5, s.Evaluations)
+		require.Equal(t, 5, s.Firings)
+		require.Equal(t, map[eval.State]time.Duration{eval.Alerting: 5 * time.Second}, s.StateDurations)
+	}
+
+	t.Run("should not test rules when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		summaries := engine.TestRules(ctx, nil, rules, from, to, 3)
+		for _, s := range summaries {
+			require.ErrorIs(t, s.Error, context.Canceled)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package eval
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -11,7 +12,10 @@ import (
 // AlertingResultsReader provides fingerprints of results that are in alerting state.
 // It is used during the evaluation of queries.
 type AlertingResultsReader interface {
+	// Read returns the fingerprints of the results that are in alerting or pending state.
 	Read() map[data.Fingerprint]struct{}
+	// ReadFiring returns the fingerprints of the results that are in alerting state, without those that are pending.
+	ReadFiring() map[data.Fingerprint]struct{}
 }
 
 // EvaluationContext represents the context in which a condition is evaluated.
@@ -19,6 +23,9 @@ type EvaluationContext struct {
 	Ctx                   context.Context
 	User                  identity.Requester
 	AlertingResultsReader AlertingResultsReader
+	// Timeout is the evaluation timeout of the rule. It is applied only if it is shorter than the evaluation timeout of
+	// the organization.
+	Timeout time.Duration
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -52,6 +53,11 @@ type conditionEvaluator struct {
 	expressionService expressionService
 	condition         models.Condition
 	evalTimeout       time.Duration
+	// maxSeries and maxFrameSize limit the size of the results of the queries of the pipeline. Zero means no limit.
+	maxSeries    int
+	maxFrameSize int
+	// reader provides the alert instances that are firing when the condition has a recovery condition.
+	reader AlertingResultsReader
 }
 
 func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (resp *backend.QueryDataResponse, err error) {
@@ -67,14 +73,25 @@ func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (re
 		}
 	}()
 
-	execCtx := ctx
+	execCtx := expr.WithLimits(expr.WithNumberFields(ctx), expr.Limits{MaxSeries: r.maxSeries, MaxFrameSize: r.maxFrameSize})
 	if r.evalTimeout >= 0 {
-		timeoutCtx, cancel := context.WithTimeout(ctx, r.evalTimeout)
+		timeoutCtx, cancel := context.WithTimeout(execCtx, r.evalTimeout)
 		defer cancel()
 		execCtx = timeoutCtx
 	}
 	logger.FromContext(ctx).Debug("Executing pipeline", "commands", strings.Join(r.pipeline.GetCommandTypes(), ","), "datasources", strings.Join(r.pipeline.GetDatasourceTypes(), ","))
-	return r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	resp, err = r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	// The data sources usually report a timeout as an error of the query rather than an error of the pipeline.
+	if errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil && (err != nil || hasErrors(resp)) {
+		if err == nil {
+			err = execCtx.Err()
+		}
+		return nil, fmt.Errorf("%w after %s: %w", ErrEvaluationTimeout, r.evalTimeout, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // Evaluate evaluates the condition and converts the response to Results
@@ -83,11 +100,45 @@ func (r *conditionEvaluator) Evaluate(ctx context.Context, now time.Time) (Resul
 	if err != nil {
 		return nil, err
 	}
-	return EvaluateAlert(response, r.condition, now), nil
+	results := EvaluateAlert(response, r.condition, now)
+	if r.condition.RecoveryCondition != "" && r.reader != nil {
+		results = applyRecoveryCondition(results, response, r.condition, r.reader.ReadFiring(), now)
+	}
+	return results, nil
+}
+
+// applyRecoveryCondition keeps firing the alert instances that are firing and that would recover, if the recovery
+// condition is met for them. The firing instances are identified by the fingerprint of the labels of their result.
+// Pending instances are not firing, and recover like any other instance when the condition is no longer met.
+func applyRecoveryCondition(results Results, response *backend.QueryDataResponse, condition models.Condition, firing map[data.Fingerprint]struct{}, now time.Time) Results {
+	if len(firing) == 0 {
+		return results
+	}
+	recovery := EvaluateAlert(response, models.Condition{Condition: condition.RecoveryCondition, Data: condition.Data}, now)
+	keepFiring := make(map[data.Fingerprint]struct{}, len(recovery))
+	for _, r := range recovery {
+		if r.State == Alerting {
+			keepFiring[r.Instance.Fingerprint()] = struct{}{}
+		}
+	}
+	for i := range results {
+		// Errors and no data are handled by the rule settings rather than by the recovery condition.
+		if results[i].State != Normal {
+			continue
+		}
+		fp := results[i].Instance.Fingerprint()
+		if _, ok := firing[fp]; !ok {
+			continue
+		}
+		if _, ok := keepFiring[fp]; ok {
+			results[i].State = Alerting
+		}
+	}
+	return results
 }
 
 type evaluatorImpl struct {
-	evaluationTimeout time.Duration
+	cfg               setting.UnifiedAlertingSettings
 	dataSourceCache   datasources.CacheService
 	expressionService *expr.Service
 	pluginsStore      pluginstore.Store
@@ -100,7 +151,7 @@ func NewEvaluatorFactory(
 	pluginsStore pluginstore.Store,
 ) EvaluatorFactory {
 	return &evaluatorImpl{
-		evaluationTimeout: cfg.EvaluationTimeout,
+		cfg:               cfg,
 		dataSourceCache:   datasourceCache,
 		expressionService: expressionService,
 		pluginsStore:      pluginsStore,
@@ -236,6 +287,10 @@ type Result struct {
 	// as EvalMatches (from "classic condition"), and in the future from operations
 	// like SSE "math".
 	EvaluationString string
+
+	// Fields contains the values of the non-numeric fields of the condition frame, such as the hostname or the
+	// error message of a table, indexed by the name of the field. See extractFields for the size limits.
+	Fields map[string]string
 }
 
 func NewResultFromError(err error, evaluatedAt time.Time, duration time.Duration) Result {
@@ -676,7 +731,8 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			continue
 		}
 
-		if len(f.Fields) > 1 {
+		// The value of the condition is the first field. The frame can have other fields as long as they are not numeric.
+		if len(f.Fields) > 1 && slices.ContainsFunc(f.Fields[1:], func(field *data.Field) bool { return field.Type().Numeric() }) {
 			appendErrRes(&invalidEvalResultFormatError{refID: f.RefID, reason: fmt.Sprintf("unexpected field length: %d instead of 1", len(f.Fields))})
 			continue
 		}
@@ -686,6 +742,11 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			continue
 		}
 
+		if rowLen == 0 {
+			appendNoData(f.Fields[0].Labels)
+			continue
+		}
+
 		val := f.Fields[0].At(0).(*float64) // type checked by data.FieldTypeNullableFloat64 above
 
 		r := Result{
@@ -694,6 +755,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 			EvaluationDuration: time.Since(ts),
 			EvaluationString:   extractEvalString(f),
 			Values:             extractValues(f),
+			Fields:             extractFields(f.Fields[1:]),
 		}
 
 		switch {
@@ -797,7 +859,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 		case expr.TypeCMDNode:
 		}
 	}
-	_, err = e.create(condition, req)
+	_, err = e.create(ctx, condition, req)
 	return err
 }
 
@@ -812,25 +874,46 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if err != nil {
 		return nil, err
 	}
-	return e.create(condition, req)
+	return e.create(ctx, condition, req)
 }
 
-func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
+func (e *evaluatorImpl) create(ctx EvaluationContext, condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
 	pipeline, err := e.expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
 	}
 	conditions := make([]string, 0, len(pipeline))
+	hasCondition, hasRecoveryCondition := false, condition.RecoveryCondition == ""
 	for _, node := range pipeline {
-		if node.RefID() == condition.Condition {
-			return &conditionEvaluator{
-				pipeline:          pipeline,
-				expressionService: e.expressionService,
-				condition:         condition,
-				evalTimeout:       e.evaluationTimeout,
-			}, nil
+		switch node.RefID() {
+		case condition.Condition:
+			hasCondition = true
+		case condition.RecoveryCondition:
+			hasRecoveryCondition = true
 		}
 		conditions = append(conditions, node.RefID())
 	}
-	return nil, fmt.Errorf("condition %s does not exist, must be one of %v", condition.Condition, conditions)
+	if !hasCondition {
+		return nil, fmt.Errorf("condition %s does not exist, must be one of %v", condition.Condition, conditions)
+	}
+	if !hasRecoveryCondition {
+		return nil, fmt.Errorf("recovery condition %s does not exist, must be one of %v", condition.RecoveryCondition, conditions)
+	}
+	var orgID int64
+	if ctx.User != nil {
+		orgID = ctx.User.GetOrgID()
+	}
+	limits := e.cfg.GetEvaluationLimits(orgID)
+	if ctx.Timeout > 0 && ctx.Timeout < limits.Timeout {
+		limits.Timeout = ctx.Timeout
+	}
+	return &conditionEvaluator{
+		pipeline:          pipeline,
+		expressionService: e.expressionService,
+		condition:         condition,
+		evalTimeout:       limits.Timeout,
+		maxSeries:         limits.MaxSeries,
+		maxFrameSize:      limits.MaxFrameSize,
+		reader:            ctx.AlertingResultsReader,
+	}, nil
 }
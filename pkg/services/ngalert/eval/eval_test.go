@@ -177,6 +177,44 @@ func TestEvaluateExecutionResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "non-numeric fields are carried into the result",
+			execResults: ExecutionResults{
+				Condition: []*data.Frame{
+					data.NewFrame("",
+						data.NewField("", data.Labels{"instance": "a"}, []*float64{util.Pointer(1.0)}),
+						data.NewField("host", nil, []string{"db-1"}),
+						data.NewField("error", nil, []*string{util.Pointer("connection refused")}),
+					),
+				},
+			},
+			expectResultLength: 1,
+			expectResults: Results{
+				{
+					State:    Alerting,
+					Instance: data.Labels{"instance": "a"},
+					Fields:   map[string]string{"host": "db-1", "error": "connection refused"},
+				},
+			},
+		},
+		{
+			desc: "non-numeric fields without rows produce NoData state result",
+			execResults: ExecutionResults{
+				Condition: []*data.Frame{
+					data.NewFrame("",
+						data.NewField("", data.Labels{"instance": "a"}, []*float64{}),
+						data.NewField("host", nil, []string{}),
+					),
+				},
+			},
+			expectResultLength: 1,
+			expectResults: Results{
+				{
+					State:    NoData,
+					Instance: data.Labels{"instance": "a"},
+				},
+			},
+		},
 		{
 			desc: "more than one row produces Error state result",
 			execResults: ExecutionResults{
@@ -303,6 +341,7 @@ func TestEvaluateExecutionResult(t *testing.T) {
 			for i, r := range res {
 				require.Equal(t, tc.expectResults[i].State, r.State)
 				require.Equal(t, tc.expectResults[i].Instance, r.Instance)
+				require.Equal(t, tc.expectResults[i].Fields, r.Fields)
 				if tc.expectResults[i].State == Error {
 					require.EqualError(t, tc.expectResults[i].Error, r.Error.Error())
 				}
@@ -923,6 +962,81 @@ func TestEvaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluate_RecoveryCondition(t *testing.T) {
+	hosts := []string{"a", "b", "c", "d"}
+	frames := func(refID string, values ...float64) []*data.Frame {
+		result := make([]*data.Frame, 0, len(values))
+		for i, v := range values {
+			result = append(result, &data.Frame{
+				RefID:  refID,
+				Fields: []*data.Field{data.NewField("Value", data.Labels{"host": hosts[i]}, []*float64{util.Pointer(v)})},
+			})
+		}
+		return result
+	}
+	resp := backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"B": {Frames: frames("B", 0, 0, 0, 1)},
+			"C": {Frames: frames("C", 1, 1, 0, 0)},
+		},
+	}
+	firing := map[data.Fingerprint]struct{}{}
+	for _, host := range []string{"a", "c", "d"} {
+		firing[data.Labels{"host": host}.Fingerprint()] = struct{}{}
+	}
+
+	evaluate := func(t *testing.T, cond models.Condition, reader AlertingResultsReader) map[string]State {
+		t.Helper()
+		ev := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					return &resp, nil
+				},
+			},
+			condition: cond,
+			reader:    reader,
+		}
+		results, err := ev.Evaluate(context.Background(), time.Now())
+		require.NoError(t, err)
+		states := make(map[string]State, len(results))
+		for _, r := range results {
+			states[r.Instance["host"]] = r.State
+		}
+		return states
+	}
+
+	t.Run("should keep firing instances firing while the recovery condition is met", func(t *testing.T) {
+		states := evaluate(t, models.Condition{Condition: "B", RecoveryCondition: "C"}, FakeLoadedMetricsReader{fingerprints: firing})
+		require.Equal(t, map[string]State{
+			"a": Alerting, // firing and the recovery condition is met
+			"b": Normal,   // the recovery condition is met but the instance is not firing
+			"c": Normal,   // firing and the recovery condition is not met
+			"d": Alerting, // the condition is met
+		}, states)
+	})
+
+	t.Run("should not keep pending instances pending when the condition is not met", func(t *testing.T) {
+		pending := map[data.Fingerprint]struct{}{data.Labels{"host": "a"}.Fingerprint(): {}}
+		states := evaluate(t, models.Condition{Condition: "B", RecoveryCondition: "C"}, FakeLoadedMetricsReader{fingerprints: firing, pending: pending})
+		require.Equal(t, map[string]State{
+			"a": Normal, // pending, the condition is not met even though the recovery condition is met
+			"b": Normal,
+			"c": Normal,
+			"d": Alerting,
+		}, states)
+	})
+
+	t.Run("should not apply the recovery condition without firing instances", func(t *testing.T) {
+		states := evaluate(t, models.Condition{Condition: "B", RecoveryCondition: "C"}, nil)
+		require.Equal(t, map[string]State{"a": Normal, "b": Normal, "c": Normal, "d": Alerting}, states)
+	})
+
+	t.Run("should not apply the recovery condition if it is not set", func(t *testing.T) {
+		states := evaluate(t, models.Condition{Condition: "B"}, FakeLoadedMetricsReader{fingerprints: firing})
+		require.Equal(t, map[string]State{"a": Normal, "b": Normal, "c": Normal, "d": Alerting}, states)
+	})
+}
+
 func TestEvaluateRaw(t *testing.T) {
 	t.Run("should timeout if request takes too long", func(t *testing.T) {
 		unexpectedResponse := &backend.QueryDataResponse{}
@@ -947,9 +1061,115 @@ func TestEvaluateRaw(t *testing.T) {
 
 		_, err := e.EvaluateRaw(context.Background(), time.Now())
 		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, ErrEvaluationTimeout)
+	})
+
+	t.Run("should timeout if a query fails after the timeout", func(t *testing.T) {
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					<-ctx.Done()
+					return &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("request canceled")}}}, nil
+				},
+			},
+			evalTimeout: 10 * time.Millisecond,
+		}
+
+		_, err := e.EvaluateRaw(context.Background(), time.Now())
+		require.ErrorIs(t, err, ErrEvaluationTimeout)
+	})
+
+	t.Run("should not timeout if the parent context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					return nil, ctx.Err()
+				},
+			},
+			evalTimeout: time.Minute,
+		}
+
+		_, err := e.EvaluateRaw(ctx, time.Now())
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, ErrEvaluationTimeout)
+	})
+
+	t.Run("should execute the pipeline with the query limits", func(t *testing.T) {
+		var limits expr.Limits
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					limits = expr.LimitsFromContext(ctx)
+					return &backend.QueryDataResponse{}, nil
+				},
+			},
+			evalTimeout:  time.Minute,
+			maxSeries:    2,
+			maxFrameSize: 3,
+		}
+
+		_, err := e.EvaluateRaw(context.Background(), time.Now())
+		require.NoError(t, err)
+		require.Equal(t, expr.Limits{MaxSeries: 2, MaxFrameSize: 3}, limits)
 	})
 }
 
+func TestCreate_EvaluationLimits(t *testing.T) {
+	cacheService := &fakes.FakeCacheService{}
+	store := &pluginstore.FakePluginStore{}
+	dsQuery := models.GenerateAlertQuery()
+	ds := &datasources.DataSource{UID: dsQuery.DatasourceUID, Type: util.GenerateShortUID()}
+	cacheService.DataSources = append(cacheService.DataSources, ds)
+	store.PluginList = append(store.PluginList, pluginstore.Plugin{JSONData: plugins.JSONData{ID: ds.Type, Backend: true}})
+	condition := models.Condition{
+		Condition: "B",
+		Data:      []models.AlertQuery{dsQuery, models.CreateReduceExpression("B", dsQuery.RefID, "last")},
+	}
+
+	cfg := setting.UnifiedAlertingSettings{
+		EvaluationTimeout:      30 * time.Second,
+		EvaluationMaxSeries:    100,
+		EvaluationMaxFrameSize: 1000,
+		OrgEvaluationLimits: map[int64]setting.EvaluationLimits{
+			2: {Timeout: 10 * time.Second, MaxSeries: 10},
+		},
+	}
+	factory := NewEvaluatorFactory(cfg, cacheService, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, featuremgmt.WithFeatures(), nil, tracing.InitializeTracerForTest()), store)
+
+	create := func(t *testing.T, orgID int64, timeout time.Duration) *conditionEvaluator {
+		t.Helper()
+		evalCtx := NewContext(context.Background(), &user.SignedInUser{OrgID: orgID})
+		evalCtx.Timeout = timeout
+		ev, err := factory.Create(evalCtx, condition)
+		require.NoError(t, err)
+		return ev.(*conditionEvaluator)
+	}
+
+	testCases := []struct {
+		name            string
+		orgID           int64
+		ruleTimeout     time.Duration
+		expTimeout      time.Duration
+		expMaxSeries    int
+		expMaxFrameSize int
+	}{
+		{name: "default limits", orgID: 1, expTimeout: 30 * time.Second, expMaxSeries: 100, expMaxFrameSize: 1000},
+		{name: "limits of the organization", orgID: 2, expTimeout: 10 * time.Second, expMaxSeries: 10},
+		{name: "shorter rule timeout", orgID: 2, ruleTimeout: 5 * time.Second, expTimeout: 5 * time.Second, expMaxSeries: 10},
+		{name: "longer rule timeout", orgID: 2, ruleTimeout: 20 * time.Second, expTimeout: 10 * time.Second, expMaxSeries: 10},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := create(t, tc.orgID, tc.ruleTimeout)
+			require.Equal(t, tc.expTimeout, ev.evalTimeout)
+			require.Equal(t, tc.expMaxSeries, ev.maxSeries)
+			require.Equal(t, tc.expMaxFrameSize, ev.maxFrameSize)
+		})
+	}
+}
+
 func TestResults_HasNonRetryableErrors(t *testing.T) {
 	tc := []struct {
 		name     string
@@ -1,9 +1,11 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -106,3 +108,55 @@ func extractValues(frame *data.Frame) map[string]NumberValueCapture {
 	}
 	return nil
 }
+
+const (
+	// MaxResultFields is the maximum number of non-numeric fields of the condition frame that are kept in a result.
+	MaxResultFields = 10
+	// MaxResultFieldLength is the maximum length in bytes of the value of a field kept in a result. Longer values are
+	// truncated.
+	MaxResultFieldLength = 1024
+)
+
+// extractFields returns the values of the first row of the fields, indexed by the name of the field. Fields without a
+// name or a value are skipped. It keeps at most MaxResultFields fields, and truncates their values to
+// MaxResultFieldLength bytes. It returns nil if there are no fields.
+func extractFields(fields []*data.Field) map[string]string {
+	var result map[string]string
+	for _, field := range fields {
+		if len(result) == MaxResultFields {
+			break
+		}
+		if field.Name == "" || field.Len() == 0 {
+			continue
+		}
+		v, ok := field.ConcreteAt(0)
+		if !ok {
+			continue
+		}
+		var s string
+		switch value := v.(type) {
+		case string:
+			s = value
+		case json.RawMessage:
+			s = string(value)
+		default:
+			s = fmt.Sprint(value)
+		}
+		if result == nil {
+			result = make(map[string]string, len(fields))
+		}
+		result[field.Name] = truncate(s, MaxResultFieldLength)
+	}
+	return result
+}
+
+// truncate returns the first n bytes of s without splitting a multi-byte character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
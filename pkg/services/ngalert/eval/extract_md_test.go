@@ -1,6 +1,9 @@
 package eval
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -138,3 +141,34 @@ func newMetaFrame(custom any, val *float64, callbacks ...frameCallback) *data.Fr
 
 	return f
 }
+
+func TestExtractFields(t *testing.T) {
+	t.Run("returns nil if there are no fields", func(t *testing.T) {
+		require.Nil(t, extractFields(nil))
+	})
+
+	t.Run("returns the values of the first row", func(t *testing.T) {
+		fields := extractFields([]*data.Field{
+			data.NewField("host", nil, []string{"db-1"}),
+			data.NewField("healthy", nil, []bool{false}),
+			data.NewField("details", nil, []json.RawMessage{json.RawMessage(`{"code":500}`)}),
+			data.NewField("error", nil, []*string{nil}),
+			data.NewField("", nil, []string{"no name"}),
+		})
+		require.Equal(t, map[string]string{"host": "db-1", "healthy": "false", "details": `{"code":500}`}, fields)
+	})
+
+	t.Run("keeps at most MaxResultFields fields", func(t *testing.T) {
+		fields := make([]*data.Field, 0, MaxResultFields+5)
+		for i := 0; i < MaxResultFields+5; i++ {
+			fields = append(fields, data.NewField(fmt.Sprintf("field_%d", i), nil, []string{"value"}))
+		}
+		require.Len(t, extractFields(fields), MaxResultFields)
+	})
+
+	t.Run("truncates long values", func(t *testing.T) {
+		long := strings.Repeat("a", MaxResultFieldLength-1) + "é"
+		fields := extractFields([]*data.Field{data.NewField("error", nil, []string{long})})
+		require.Equal(t, strings.Repeat("a", MaxResultFieldLength-1), fields["error"])
+	})
+}
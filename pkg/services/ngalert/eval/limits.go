@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+var (
+	// ErrEvaluationTimeout is returned when the evaluation of a condition takes longer than its timeout.
+	ErrEvaluationTimeout = errors.New("evaluation timed out")
+	// ErrMaxSeriesExceeded is returned when the queries of a condition return more series than allowed.
+	ErrMaxSeriesExceeded = expr.ErrMaxSeriesExceeded
+	// ErrMaxFrameSizeExceeded is returned when a query of a condition returns a data frame with more rows than allowed.
+	ErrMaxFrameSizeExceeded = expr.ErrMaxFrameSizeExceeded
+)
+
+// hasErrors returns true if any of the queries or expressions of the response failed.
+func hasErrors(resp *backend.QueryDataResponse) bool {
+	if resp == nil {
+		return false
+	}
+	for _, res := range resp.Responses {
+		if res.Error != nil {
+			return true
+		}
+	}
+	return false
+}
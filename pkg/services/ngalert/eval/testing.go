@@ -82,8 +82,20 @@ func WithLabels(labels data.Labels) ResultMutator {
 
 type FakeLoadedMetricsReader struct {
 	fingerprints map[data.Fingerprint]struct{}
+	// pending are the fingerprints that are pending rather than alerting.
+	pending map[data.Fingerprint]struct{}
 }
 
 func (f FakeLoadedMetricsReader) Read() map[data.Fingerprint]struct{} {
 	return f.fingerprints
 }
+
+func (f FakeLoadedMetricsReader) ReadFiring() map[data.Fingerprint]struct{} {
+	firing := make(map[data.Fingerprint]struct{}, len(f.fingerprints))
+	for fp := range f.fingerprints {
+		if _, ok := f.pending[fp]; !ok {
+			firing[fp] = struct{}{}
+		}
+	}
+	return firing
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"github.com/grafana/dskit/instrument"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RemoteWriter contains the metrics of the writer of the series of recording rules.
+type RemoteWriter struct {
+	SeriesTotal        *prometheus.CounterVec
+	SeriesDropped      *prometheus.CounterVec
+	WritesTotal        prometheus.Counter
+	WritesFailed       prometheus.Counter
+	WriteDuration      *instrument.HistogramCollector
+	BytesWritten       prometheus.Counter
+	PendingSeries      prometheus.Gauge
+	LastWriteTimestamp prometheus.Gauge
+}
+
+func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
+	return &RemoteWriter{
+		SeriesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_series_total",
+			Help:      "The total number of series produced by recording rules and queued to be written.",
+		}, []string{"org"}),
+		SeriesDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_series_dropped_total",
+			Help:      "The total number of series produced by recording rules that were dropped, because the queue was full or the write failed.",
+		}, []string{"reason"}),
+		WritesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_writes_total",
+			Help:      "The total number of batches of series that were attempted to be written to the remote-write endpoint.",
+		}),
+		WritesFailed: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_writes_failed_total",
+			Help:      "The total number of failed writes of batches of series to the remote-write endpoint.",
+		}),
+		WriteDuration: instrument.NewHistogramCollector(promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_write_duration_seconds",
+			Help:      "Histogram of request durations to the remote-write endpoint.",
+			Buckets:   instrument.DefBuckets,
+		}, instrument.HistogramCollectorBuckets)),
+		BytesWritten: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_writes_bytes_total",
+			Help:      "The total number of compressed bytes sent to the remote-write endpoint.",
+		}),
+		PendingSeries: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_pending_series",
+			Help:      "The number of series waiting to be written to the remote-write endpoint.",
+		}),
+		LastWriteTimestamp: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "recording_rules_last_successful_write_timestamp_seconds",
+			Help:      "Timestamp of the last successful write to the remote-write endpoint.",
+		}),
+	}
+}
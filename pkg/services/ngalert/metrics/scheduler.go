@@ -31,6 +31,9 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	EvalLimitExceeded                   *prometheus.CounterVec
+	OwnedAlertRules                     prometheus.Gauge
+	ShardMembers                        prometheus.Gauge
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -183,5 +186,30 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		EvalLimitExceeded: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluation_limit_exceeded_total",
+				Help:      "The total number of rule evaluations that exceeded their timeout or query limits.",
+			},
+			[]string{"org", "limit"},
+		),
+		OwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_owned_alert_rules",
+				Help:      "The number of alert rules owned by this instance when scheduling is sharded across the HA cluster.",
+			},
+		),
+		ShardMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_members",
+				Help:      "The number of instances the alert rules are sharded across.",
+			},
+		),
 	}
 }
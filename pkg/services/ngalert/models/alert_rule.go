@@ -145,12 +145,27 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonFlapping      = "Flapping"
+	StateReasonSuppressed    = "Suppressed"
+	// StateReasonEvaluationTimeout is the reason of an Error state when the evaluation took longer than its timeout.
+	StateReasonEvaluationTimeout = "EvaluationTimeout"
+	// StateReasonQueryLimitExceeded is the reason of an Error state when the queries and expressions returned more
+	// series or larger data frames than allowed.
+	StateReasonQueryLimitExceeded = "QueryLimitExceeded"
 )
 
 func ConcatReasons(reasons ...string) string {
 	return strings.Join(reasons, ", ")
 }
 
+// SplitReasons returns the reasons that were concatenated by ConcatReasons.
+func SplitReasons(reason string) []string {
+	if reason == "" {
+		return nil
+	}
+	return strings.Split(reason, ", ")
+}
+
 var (
 	// InternalLabelNameSet are labels that grafana automatically include as part of the labelset.
 	InternalLabelNameSet = map[string]struct{}{
@@ -248,7 +263,19 @@ type AlertRule struct {
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
-	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	NotificationSettings []NotificationSettings  `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	FlapDetection        []FlapDetectionSettings `xorm:"flap_detection"`        // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// RecoveryCondition is the RefID of the query or expression that keeps the firing alert instances firing.
+	// See Condition.RecoveryCondition.
+	RecoveryCondition string `xorm:"recovery_condition"`
+	// Record makes the rule a recording rule that writes the series of its condition as a metric. See RecordSettings.
+	Record []RecordSettings `xorm:"record"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same organization this rule depends on. The instances of the rule
+	// are suppressed while any of these rules is firing.
+	DependsOn []string `xorm:"depends_on"`
+	// EvaluationTimeout is the maximum duration of an evaluation of the rule. It can only shorten the evaluation timeout
+	// of the organization. Zero means the evaluation timeout of the organization is used.
+	EvaluationTimeout time.Duration `xorm:"evaluation_timeout"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -334,8 +361,9 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 
 func (alertRule *AlertRule) GetEvalCondition() Condition {
 	return Condition{
-		Condition: alertRule.Condition,
-		Data:      alertRule.Data,
+		Condition:         alertRule.Condition,
+		RecoveryCondition: alertRule.RecoveryCondition,
+		Data:              alertRule.Data,
 	}
 }
 
@@ -506,6 +534,14 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.EvaluationTimeout < 0 {
+		return fmt.Errorf("%w: field `evaluation_timeout` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.EvaluationTimeout > time.Duration(alertRule.IntervalSeconds)*time.Second {
+		return fmt.Errorf("%w: field `evaluation_timeout` cannot be longer than the evaluation interval", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -522,9 +558,49 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid notification settings: %w", err))
 		}
 	}
+
+	if alertRule.RecoveryCondition != "" {
+		if err := alertRule.validateRecoveryCondition(); err != nil {
+			return err
+		}
+	}
+
+	if len(alertRule.FlapDetection) > 0 {
+		if len(alertRule.FlapDetection) != 1 {
+			return fmt.Errorf("%w: only one flap detection settings entry is allowed", ErrAlertRuleFailedValidation)
+		}
+		if err := alertRule.FlapDetection[0].Validate(); err != nil {
+			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid flap detection settings: %w", err))
+		}
+	}
+
+	if len(alertRule.Record) > 0 {
+		if err := alertRule.validateRecordingRule(cfg); err != nil {
+			return err
+		}
+	}
+
+	if len(alertRule.DependsOn) > 0 {
+		if err := alertRule.validateDependsOn(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// validateRecoveryCondition checks that the recovery condition is a query or expression of the rule other than the condition.
+func (alertRule *AlertRule) validateRecoveryCondition() error {
+	if alertRule.RecoveryCondition == alertRule.Condition {
+		return fmt.Errorf("%w: recovery condition cannot be the same as the condition", ErrAlertRuleFailedValidation)
+	}
+	for _, q := range alertRule.Data {
+		if q.RefID == alertRule.RecoveryCondition {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: recovery condition %s does not exist in the queries and expressions of the rule", ErrAlertRuleFailedValidation, alertRule.RecoveryCondition)
+}
+
 func (alertRule *AlertRule) ResourceType() string {
 	return "alertRule"
 }
@@ -569,7 +645,19 @@ type AlertRuleVersion struct {
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
-	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	NotificationSettings []NotificationSettings  `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	FlapDetection        []FlapDetectionSettings `xorm:"flap_detection"`        // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// RecoveryCondition is the RefID of the query or expression that keeps the firing alert instances firing.
+	// See Condition.RecoveryCondition.
+	RecoveryCondition string `xorm:"recovery_condition"`
+	// Record makes the rule a recording rule that writes the series of its condition as a metric. See RecordSettings.
+	Record []RecordSettings `xorm:"record"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same organization this rule depends on. The instances of the rule
+	// are suppressed while any of these rules is firing.
+	DependsOn []string `xorm:"depends_on"`
+	// EvaluationTimeout is the maximum duration of an evaluation of the rule. It can only shorten the evaluation timeout
+	// of the organization. Zero means the evaluation timeout of the organization is used.
+	EvaluationTimeout time.Duration `xorm:"evaluation_timeout"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -654,6 +742,11 @@ type Condition struct {
 	// the Data property to get the results for.
 	Condition string `json:"condition"`
 
+	// RecoveryCondition is the RefID of the query or expression from the Data property that keeps the firing
+	// alert instances firing. An instance that is firing keeps firing as long as either the condition or the
+	// recovery condition is met, and recovers when neither is. Recovery is not applied when it is empty.
+	RecoveryCondition string `json:"recovery_condition,omitempty"`
+
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
 }
@@ -668,7 +761,7 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//   - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition, AlertRule.RecoveryCondition and AlertRule.Data
 //
 // If either of the pair is specified, neither is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOptionals) {
@@ -677,6 +770,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	}
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
+		ruleToPatch.RecoveryCondition = existingRule.RecoveryCondition
 		ruleToPatch.Data = existingRule.Data
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAlertmanagerStateConflict is returned when an entry of the Alertmanager state was modified
+// concurrently, for example by another replica, while it was being saved.
+var ErrAlertmanagerStateConflict = errors.New("alertmanager state was modified concurrently")
+
+// AlertmanagerStateKind is the kind of the internal Alertmanager state an entry belongs to.
+type AlertmanagerStateKind string
+
+const (
+	AlertmanagerStateSilences        AlertmanagerStateKind = "silences"
+	AlertmanagerStateNotificationLog AlertmanagerStateKind = "nflog"
+)
+
+// AlertmanagerStateEntry is a single silence or notification log entry of the Alertmanager of an organization.
+type AlertmanagerStateEntry struct {
+	// Key uniquely identifies the entry within the organization and the kind.
+	Key string
+	// Data is the binary representation of the entry, as encoded by the Alertmanager.
+	Data []byte
+	// UpdatedAt is the time the entry was last modified in the Alertmanager.
+	// An entry is only overwritten by one that was modified later.
+	UpdatedAt time.Time
+	// ExpiresAt is the time after which the entry is not needed anymore and can be deleted.
+	ExpiresAt time.Time
+	// Version is incremented every time the entry is written to the database.
+	Version int64
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// validateDependsOn checks that the rule depends on distinct rules other than itself.
+func (alertRule *AlertRule) validateDependsOn() error {
+	if alertRule.Type() == RuleTypeRecording {
+		return fmt.Errorf("%w: recording rules cannot depend on other rules", ErrAlertRuleFailedValidation)
+	}
+	seen := make(map[string]struct{}, len(alertRule.DependsOn))
+	for _, uid := range alertRule.DependsOn {
+		if uid == "" {
+			return fmt.Errorf("%w: the UID of a rule dependency cannot be empty", ErrAlertRuleFailedValidation)
+		}
+		if alertRule.UID != "" && uid == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+		}
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("%w: rule depends on rule %s more than once", ErrAlertRuleFailedValidation, uid)
+		}
+		seen[uid] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateRuleDependencies checks the dependency graph of the rules of an organization. The graph maps the UID of every
+// rule to the UIDs of the rules it depends on. It returns an error if a rule depends on a rule that is not in the
+// graph, or if the dependencies form a cycle.
+func ValidateRuleDependencies(graph map[string][]string) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := make(map[string]int, len(graph))
+	var path []string
+	var visit func(uid string) error
+	visit = func(uid string) error {
+		switch status[uid] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, uid):], uid)
+			return fmt.Errorf("%w: rule dependencies form a cycle: %s", ErrAlertRuleFailedValidation, strings.Join(cycle, " -> "))
+		}
+		status[uid] = visiting
+		path = append(path, uid)
+		for _, dep := range graph[uid] {
+			if _, ok := graph[dep]; !ok {
+				return fmt.Errorf("%w: rule %s depends on rule %s that does not exist", ErrAlertRuleFailedValidation, uid, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		status[uid] = visited
+		return nil
+	}
+
+	uids := make([]string, 0, len(graph))
+	for uid := range graph {
+		uids = append(uids, uid)
+	}
+	slices.Sort(uids)
+	for _, uid := range uids {
+		if err := visit(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateRuleDeletion checks that no rule of the dependency graph depends on one of the deleted rules. The graph maps
+// the UID of every rule that remains after the deletion to the UIDs of the rules it depends on.
+func ValidateRuleDeletion(graph map[string][]string, deleted []string) error {
+	if len(deleted) == 0 {
+		return nil
+	}
+	uids := make([]string, 0, len(graph))
+	for uid := range graph {
+		uids = append(uids, uid)
+	}
+	slices.Sort(uids)
+	for _, uid := range uids {
+		for _, dep := range graph[uid] {
+			if slices.Contains(deleted, dep) {
+				return fmt.Errorf("%w: rule %s cannot be deleted because rule %s depends on it", ErrAlertRuleFailedValidation, dep, uid)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValidateRuleDependencies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		graph    map[string][]string
+		expError string
+	}{
+		{
+			name:  "no dependencies",
+			graph: map[string][]string{"a": nil, "b": nil},
+		},
+		{
+			name:  "dependencies without cycle",
+			graph: map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": nil},
+		},
+		{
+			name:     "unknown dependency",
+			graph:    map[string][]string{"a": {"b"}},
+			expError: "rule a depends on rule b that does not exist",
+		},
+		{
+			name:     "direct cycle",
+			graph:    map[string][]string{"a": {"b"}, "b": {"a"}},
+			expError: "rule dependencies form a cycle: a -> b -> a",
+		},
+		{
+			name:     "indirect cycle",
+			graph:    map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"d", "b"}, "d": nil},
+			expError: "rule dependencies form a cycle: b -> c -> b",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRuleDependencies(tc.graph)
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expError)
+		})
+	}
+}
+
+func TestValidateRuleDeletion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		graph    map[string][]string
+		deleted  []string
+		expError string
+	}{
+		{
+			name:    "nothing deleted",
+			graph:   map[string][]string{"a": {"b"}, "b": nil},
+			deleted: nil,
+		},
+		{
+			name:    "deleted rule without dependents",
+			graph:   map[string][]string{"a": {"b"}, "b": nil},
+			deleted: []string{"c"},
+		},
+		{
+			name:     "deleted rule with dependents",
+			graph:    map[string][]string{"a": {"c"}, "b": {"c"}},
+			deleted:  []string{"c"},
+			expError: "rule c cannot be deleted because rule a depends on it",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRuleDeletion(tc.graph, tc.deleted)
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expError)
+		})
+	}
+}
+
+func TestValidateAlertRule_DependsOn(t *testing.T) {
+	cfg := setting.UnifiedAlertingSettings{
+		BaseInterval:   10 * time.Second,
+		RecordingRules: setting.RecordingRuleSettings{Enabled: true},
+	}
+
+	testCases := []struct {
+		name     string
+		mutator  AlertRuleMutator
+		expError string
+	}{
+		{
+			name:    "valid dependencies",
+			mutator: WithDependsOn("upstream-1", "upstream-2"),
+		},
+		{
+			name:     "empty UID",
+			mutator:  WithDependsOn(""),
+			expError: "the UID of a rule dependency cannot be empty",
+		},
+		{
+			name:     "self dependency",
+			mutator:  func(r *AlertRule) { r.DependsOn = []string{r.UID} },
+			expError: "rule cannot depend on itself",
+		},
+		{
+			name:     "duplicated dependency",
+			mutator:  WithDependsOn("upstream", "upstream"),
+			expError: "more than once",
+		},
+		{
+			name: "recording rule",
+			mutator: func(r *AlertRule) {
+				WithRecord("metric")(r)
+				r.DependsOn = []string{"upstream"}
+			},
+			expError: "recording rules cannot depend on other rules",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := AlertRuleGen(WithInterval(time.Minute))()
+			tc.mutator(rule)
+			err := rule.ValidateAlertRule(cfg)
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expError)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// DefaultFlapDetectionWindow is the number of evaluations used by the flap detection of Nagios.
+	DefaultFlapDetectionWindow = 21
+	// MaxFlapDetectionWindow limits the number of evaluations that are kept for each alert instance.
+	MaxFlapDetectionWindow        = 100
+	DefaultFlapDetectionHighValue = 50.0
+	DefaultFlapDetectionLowValue  = 25.0
+)
+
+// FlapDetectionSettings configures the detection of alert instances that change state too often, modelled on the flap
+// detection of Nagios. The percentage of state change of an instance is computed over its latest evaluations, with
+// recent changes weighted more than older ones. An instance starts flapping when the percentage goes above the high
+// threshold, and stops flapping when it goes below the low threshold.
+type FlapDetectionSettings struct {
+	// Window is the number of the latest evaluations used to compute the percentage of state change.
+	Window int `json:"window"`
+	// HighThreshold is the percentage of state change above which an instance starts flapping.
+	HighThreshold float64 `json:"high_threshold"`
+	// LowThreshold is the percentage of state change below which a flapping instance stops flapping.
+	LowThreshold float64 `json:"low_threshold"`
+	// HoldNotifications holds the notifications of an instance while it is flapping.
+	HoldNotifications bool `json:"hold_notifications,omitempty"`
+}
+
+// NewFlapDetectionSettings returns the settings with the default window and thresholds.
+func NewFlapDetectionSettings() FlapDetectionSettings {
+	return FlapDetectionSettings{
+		Window:        DefaultFlapDetectionWindow,
+		HighThreshold: DefaultFlapDetectionHighValue,
+		LowThreshold:  DefaultFlapDetectionLowValue,
+	}
+}
+
+// Validate checks that the window has at least three evaluations so that a change can be followed by another one,
+// and that the thresholds are percentages with the low threshold not above the high threshold.
+func (s *FlapDetectionSettings) Validate() error {
+	if s.Window < 3 || s.Window > MaxFlapDetectionWindow {
+		return fmt.Errorf("window must be between 3 and %d evaluations", MaxFlapDetectionWindow)
+	}
+	if s.HighThreshold <= 0 || s.HighThreshold > 100 {
+		return errors.New("high threshold must be a percentage greater than 0 and at most 100")
+	}
+	if s.LowThreshold < 0 || s.LowThreshold > s.HighThreshold {
+		return errors.New("low threshold must be a percentage between 0 and the high threshold")
+	}
+	return nil
+}
+
+// PercentStateChange returns the percentage of state change of the states, ordered from the oldest to the most recent.
+// Like in Nagios, the weight of a change increases linearly from 0.8 for the oldest change to 1.2 for the most recent.
+func PercentStateChange[T comparable](states []T) float64 {
+	if len(states) < 2 {
+		return 0
+	}
+	changes := len(states) - 1
+	total := 0.0
+	for i := 1; i < len(states); i++ {
+		if states[i] == states[i-1] {
+			continue
+		}
+		weight := 1.0
+		if changes > 1 {
+			weight = 0.8 + 0.4*float64(i-1)/float64(changes-1)
+		}
+		total += weight
+	}
+	return total * 100 / float64(changes)
+}
+
+// GetFlapDetection returns the flap detection settings of the rule, or nil if flap detection is disabled.
+func (alertRule *AlertRule) GetFlapDetection() *FlapDetectionSettings {
+	if len(alertRule.FlapDetection) == 0 {
+		return nil
+	}
+	return &alertRule.FlapDetection[0]
+}
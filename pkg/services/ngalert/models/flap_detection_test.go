@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlapDetectionSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mutator  func(s *FlapDetectionSettings)
+		expError string
+	}{
+		{
+			name:    "default settings are valid",
+			mutator: func(s *FlapDetectionSettings) {},
+		},
+		{
+			name:     "window is too small",
+			mutator:  func(s *FlapDetectionSettings) { s.Window = 2 },
+			expError: "window must be between 3 and 100 evaluations",
+		},
+		{
+			name:     "window is too big",
+			mutator:  func(s *FlapDetectionSettings) { s.Window = MaxFlapDetectionWindow + 1 },
+			expError: "window must be between 3 and 100 evaluations",
+		},
+		{
+			name:     "high threshold is zero",
+			mutator:  func(s *FlapDetectionSettings) { s.HighThreshold = 0 },
+			expError: "high threshold must be a percentage greater than 0 and at most 100",
+		},
+		{
+			name:     "high threshold is above 100",
+			mutator:  func(s *FlapDetectionSettings) { s.HighThreshold = 101 },
+			expError: "high threshold must be a percentage greater than 0 and at most 100",
+		},
+		{
+			name:     "low threshold is negative",
+			mutator:  func(s *FlapDetectionSettings) { s.LowThreshold = -1 },
+			expError: "low threshold must be a percentage between 0 and the high threshold",
+		},
+		{
+			name:     "low threshold is above the high threshold",
+			mutator:  func(s *FlapDetectionSettings) { s.LowThreshold = s.HighThreshold + 1 },
+			expError: "low threshold must be a percentage between 0 and the high threshold",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewFlapDetectionSettings()
+			tc.mutator(&s)
+			err := s.Validate()
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expError)
+		})
+	}
+}
+
+func TestPercentStateChange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		states   []string
+		expected float64
+	}{
+		{
+			name:     "no states",
+			expected: 0,
+		},
+		{
+			name:     "single state",
+			states:   []string{"a"},
+			expected: 0,
+		},
+		{
+			name:     "no change",
+			states:   []string{"a", "a", "a", "a", "a"},
+			expected: 0,
+		},
+		{
+			name:     "every evaluation changes",
+			states:   []string{"a", "b", "a", "b", "a"},
+			expected: 100,
+		},
+		{
+			name:     "oldest change has the lowest weight",
+			states:   []string{"a", "b", "b", "b", "b"},
+			expected: 20,
+		},
+		{
+			name:     "most recent change has the highest weight",
+			states:   []string{"a", "a", "a", "a", "b"},
+			expected: 30,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.InDelta(t, tc.expected, PercentStateChange(tc.states), 1e-9)
+		})
+	}
+}
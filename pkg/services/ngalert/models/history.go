@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
 
@@ -18,3 +19,30 @@ type HistoryQuery struct {
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// StateHistoryEntry is a state transition of an alert instance, as stored by the sql state history backend.
+type StateHistoryEntry struct {
+	ID           int64             `xorm:"pk autoincr 'id'"`
+	OrgID        int64             `xorm:"org_id"`
+	RuleUID      string            `xorm:"rule_uid"`
+	RuleID       int64             `xorm:"rule_id"`
+	RuleTitle    string            `xorm:"rule_title"`
+	NamespaceUID string            `xorm:"namespace_uid"`
+	RuleGroup    string            `xorm:"rule_group"`
+	DashboardUID string            `xorm:"dashboard_uid"`
+	PanelID      int64             `xorm:"panel_id"`
+	Labels       map[string]string `xorm:"labels"`
+	Fingerprint  string            `xorm:"fingerprint"`
+	Previous     string            `xorm:"previous"`
+	Current      string            `xorm:"current"`
+	Error        string            `xorm:"error"`
+	Condition    string            `xorm:"condition_ref_id"`
+	Values       *simplejson.Json  `xorm:"state_values"`
+	// TimeNano is the time of the transition in Unix nanoseconds.
+	TimeNano int64 `xorm:"time_nano"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e StateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// RuleType is the type of the rule, derived from its settings.
+type RuleType string
+
+const (
+	// RuleTypeAlerting is the type of the rules that evaluate their condition to produce alert states.
+	RuleTypeAlerting RuleType = "alerting"
+	// RuleTypeRecording is the type of the rules that write the result of their condition as a metric.
+	RuleTypeRecording RuleType = "recording"
+)
+
+// RecordSettings turns an alert rule into a recording rule. Instead of producing alert states, a recording rule
+// writes the series of its condition, which is the query or expression of the rule that is recorded, to a Prometheus
+// remote-write endpoint under the name of the metric. The labels of the rule are added to the labels of each series.
+type RecordSettings struct {
+	// Metric is the name of the metric the series are written to.
+	Metric string `json:"metric"`
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name.
+func (s *RecordSettings) Validate() error {
+	if s.Metric == "" {
+		return errors.New("metric name must not be empty")
+	}
+	if !model.IsValidMetricName(model.LabelValue(s.Metric)) {
+		return fmt.Errorf("%q is not a valid metric name", s.Metric)
+	}
+	return nil
+}
+
+// Type returns RuleTypeRecording if the rule has record settings, and RuleTypeAlerting otherwise.
+func (alertRule *AlertRule) Type() RuleType {
+	if len(alertRule.Record) > 0 {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
+// GetRecord returns the record settings of the rule, or nil if the rule is an alerting rule.
+func (alertRule *AlertRule) GetRecord() *RecordSettings {
+	if len(alertRule.Record) == 0 {
+		return nil
+	}
+	return &alertRule.Record[0]
+}
+
+// validateRecordingRule checks that recording rules are enabled, the record settings, and that the settings that only
+// apply to alerting rules are not set.
+func (alertRule *AlertRule) validateRecordingRule(cfg setting.UnifiedAlertingSettings) error {
+	if !cfg.RecordingRules.Enabled {
+		return fmt.Errorf("%w: recording rules are not enabled", ErrAlertRuleFailedValidation)
+	}
+	if len(alertRule.Record) != 1 {
+		return fmt.Errorf("%w: only one record settings entry is allowed", ErrAlertRuleFailedValidation)
+	}
+	if err := alertRule.Record[0].Validate(); err != nil {
+		return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid record settings: %w", err))
+	}
+	if alertRule.For != 0 {
+		return fmt.Errorf("%w: recording rules cannot have a pending period", ErrAlertRuleFailedValidation)
+	}
+	if len(alertRule.NotificationSettings) > 0 {
+		return fmt.Errorf("%w: recording rules cannot have notification settings", ErrAlertRuleFailedValidation)
+	}
+	if len(alertRule.FlapDetection) > 0 {
+		return fmt.Errorf("%w: recording rules cannot have flap detection settings", ErrAlertRuleFailedValidation)
+	}
+	if alertRule.RecoveryCondition != "" {
+		return fmt.Errorf("%w: recording rules cannot have a recovery condition", ErrAlertRuleFailedValidation)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRecordSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		metric   string
+		expError string
+	}{
+		{
+			name:   "valid metric name",
+			metric: "job:http_requests:rate5m",
+		},
+		{
+			name:     "empty metric name",
+			expError: "metric name must not be empty",
+		},
+		{
+			name:     "invalid metric name",
+			metric:   "http requests",
+			expError: `"http requests" is not a valid metric name`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := RecordSettings{Metric: tc.metric}
+			err := s.Validate()
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expError)
+		})
+	}
+}
+
+func TestAlertRule_Type(t *testing.T) {
+	rule := AlertRuleGen()()
+	require.Equal(t, RuleTypeAlerting, rule.Type())
+	require.Nil(t, rule.GetRecord())
+
+	WithRecord("metric")(rule)
+	require.Equal(t, RuleTypeRecording, rule.Type())
+	require.Equal(t, &RecordSettings{Metric: "metric"}, rule.GetRecord())
+}
+
+func TestValidateAlertRule_Recording(t *testing.T) {
+	cfg := setting.UnifiedAlertingSettings{
+		BaseInterval:   10 * time.Second,
+		RecordingRules: setting.RecordingRuleSettings{Enabled: true},
+	}
+
+	testCases := []struct {
+		name     string
+		mutator  AlertRuleMutator
+		expError string
+	}{
+		{
+			name:    "recording rule is valid",
+			mutator: func(r *AlertRule) {},
+		},
+		{
+			name:     "more than one record settings",
+			mutator:  func(r *AlertRule) { r.Record = append(r.Record, RecordSettings{Metric: "other"}) },
+			expError: "only one record settings entry is allowed",
+		},
+		{
+			name:     "invalid metric name",
+			mutator:  func(r *AlertRule) { r.Record[0].Metric = "1metric" },
+			expError: "invalid record settings",
+		},
+		{
+			name:     "pending period",
+			mutator:  func(r *AlertRule) { r.For = time.Minute },
+			expError: "recording rules cannot have a pending period",
+		},
+		{
+			name:     "notification settings",
+			mutator:  WithNotificationSettingsGen(NotificationSettingsGen()),
+			expError: "recording rules cannot have notification settings",
+		},
+		{
+			name:     "flap detection",
+			mutator:  WithFlapDetection(NewFlapDetectionSettings()),
+			expError: "recording rules cannot have flap detection settings",
+		},
+	}
+
+	t.Run("recording rules are not enabled", func(t *testing.T) {
+		rule := AlertRuleGen(WithInterval(time.Minute), WithRecord("metric"))()
+		err := rule.ValidateAlertRule(setting.UnifiedAlertingSettings{BaseInterval: cfg.BaseInterval})
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "recording rules are not enabled")
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := AlertRuleGen(WithInterval(time.Minute), WithRecord("metric"))()
+			tc.mutator(rule)
+			err := rule.ValidateAlertRule(cfg)
+			if tc.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.expError)
+		})
+	}
+}
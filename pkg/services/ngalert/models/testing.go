@@ -316,6 +316,34 @@ func WithNoNotificationSettings() AlertRuleMutator {
 	}
 }
 
+func WithFlapDetection(settings FlapDetectionSettings) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.FlapDetection = []FlapDetectionSettings{settings}
+	}
+}
+
+func WithRecord(metric string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = []RecordSettings{{Metric: metric}}
+		rule.For = 0
+		rule.NotificationSettings = nil
+		rule.FlapDetection = nil
+		rule.RecoveryCondition = ""
+	}
+}
+
+func WithDependsOn(uids ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DependsOn = uids
+	}
+}
+
+func WithEvaluationTimeout(timeout time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.EvaluationTimeout = timeout
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -394,20 +422,22 @@ func GenerateGroupKey(orgID int64) AlertRuleGroupKey {
 // CopyRule creates a deep copy of AlertRule
 func CopyRule(r *AlertRule) *AlertRule {
 	result := AlertRule{
-		ID:              r.ID,
-		OrgID:           r.OrgID,
-		Title:           r.Title,
-		Condition:       r.Condition,
-		Updated:         r.Updated,
-		IntervalSeconds: r.IntervalSeconds,
-		Version:         r.Version,
-		UID:             r.UID,
-		NamespaceUID:    r.NamespaceUID,
-		RuleGroup:       r.RuleGroup,
-		RuleGroupIndex:  r.RuleGroupIndex,
-		NoDataState:     r.NoDataState,
-		ExecErrState:    r.ExecErrState,
-		For:             r.For,
+		ID:                r.ID,
+		OrgID:             r.OrgID,
+		Title:             r.Title,
+		Condition:         r.Condition,
+		RecoveryCondition: r.RecoveryCondition,
+		Updated:           r.Updated,
+		IntervalSeconds:   r.IntervalSeconds,
+		Version:           r.Version,
+		UID:               r.UID,
+		NamespaceUID:      r.NamespaceUID,
+		RuleGroup:         r.RuleGroup,
+		RuleGroupIndex:    r.RuleGroupIndex,
+		NoDataState:       r.NoDataState,
+		ExecErrState:      r.ExecErrState,
+		For:               r.For,
+		EvaluationTimeout: r.EvaluationTimeout,
 	}
 
 	if r.DashboardUID != nil {
@@ -449,6 +479,18 @@ func CopyRule(r *AlertRule) *AlertRule {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
 
+	if r.FlapDetection != nil {
+		result.FlapDetection = append(make([]FlapDetectionSettings, 0, len(r.FlapDetection)), r.FlapDetection...)
+	}
+
+	if r.Record != nil {
+		result.Record = append(make([]RecordSettings, 0, len(r.Record)), r.Record...)
+	}
+
+	if r.DependsOn != nil {
+		result.DependsOn = append(make([]string, 0, len(r.DependsOn)), r.DependsOn...)
+	}
+
 	return &result
 }
 
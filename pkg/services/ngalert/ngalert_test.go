@@ -4,20 +4,24 @@ import (
 	"bytes"
 	"context"
 	"math/rand"
+	"net/http"
 	"testing"
 	"time"
 
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -66,7 +70,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "unrecognized")
 	})
@@ -80,7 +84,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiPrimary: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -96,7 +100,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiSecondaries: []string{"annotations", "invalid-backend"},
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -113,12 +117,26 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			LokiWriteURL: "http://gone.invalid",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
 	})
 
+	t.Run("configure sql backend", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled: true,
+			Backend: "sql",
+		}
+
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
+
+		require.NoError(t, err)
+		require.IsType(t, &historian.SQLBackend{}, h)
+	})
+
 	t.Run("emit metric describing chosen backend", func(t *testing.T) {
 		reg := prometheus.NewRegistry()
 		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
@@ -128,7 +146,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "annotations",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
@@ -149,7 +167,7 @@ grafana_alerting_state_history_info{backend="annotations"} 1
 			Enabled: false,
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
@@ -162,3 +180,39 @@ grafana_alerting_state_history_info{backend="noop"} 0
 		require.NoError(t, err)
 	})
 }
+
+type recordingHTTPClientProvider struct {
+	httpclient.Provider
+	opts []sdkhttpclient.Options
+}
+
+func (p *recordingHTTPClientProvider) New(opts ...sdkhttpclient.Options) (*http.Client, error) {
+	p.opts = append(p.opts, opts...)
+	return p.Provider.New(opts...)
+}
+
+func TestConfigureRecordingWriter(t *testing.T) {
+	met := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+	t.Run("creates the client with the provider and the configured timeout", func(t *testing.T) {
+		provider := &recordingHTTPClientProvider{Provider: httpclient.NewProvider()}
+		cfg := setting.RecordingRuleSettings{URL: "http://localhost:9090/api/v1/write", Timeout: 3 * time.Second}
+
+		w, err := configureRecordingWriter(cfg, provider, met)
+
+		require.NoError(t, err)
+		require.NotNil(t, w)
+		require.Len(t, provider.opts, 1)
+		require.Equal(t, 3*time.Second, provider.opts[0].Timeouts.Timeout)
+	})
+
+	t.Run("fails with an invalid URL", func(t *testing.T) {
+		provider := &recordingHTTPClientProvider{Provider: httpclient.NewProvider()}
+		cfg := setting.RecordingRuleSettings{URL: "ftp://localhost", Timeout: time.Second}
+
+		_, err := configureRecordingWriter(cfg, provider, met)
+
+		require.Error(t, err)
+		require.Empty(t, provider.opts)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -29,13 +30,14 @@ const (
 	// How often we flush and garbage collect notifications and silences.
 	maintenanceInterval = 15 * time.Minute
 
-	// How long we keep silences in the kvstore after they've expired.
+	// How long we keep silences in the database after they've expired.
 	silenceRetention = 5 * 24 * time.Hour
 )
 
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	store.AlertmanagerStateStore
 	autogenRuleStore
 }
 
@@ -376,13 +378,13 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
-	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
+	receiverCfg, err := integrations.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
 	if err != nil {
 		return nil, err
 	}
 	s := &sender{am.NotificationService}
 	img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
-	integrations, err := alertingNotify.BuildReceiverIntegrations(
+	receiverIntegrations, err := integrations.BuildReceiverIntegrations(
 		receiverCfg,
 		tmpl,
 		img,
@@ -399,7 +401,7 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	return receiverIntegrations, nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
@@ -1384,6 +1384,412 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "mqtt",
+			Name:        "MQTT",
+			Description: "Publishes notifications to an MQTT broker",
+			Heading:     "MQTT settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Broker URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The URL of the MQTT broker. Supported schemes are tcp, mqtt, ssl, tls and mqtts.",
+					Placeholder:  "tcp://localhost:1883",
+					PropertyName: "brokerUrl",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The topic to which the message will be published.",
+					Placeholder:  "grafana/alerts",
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:        "Client ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The client identifier used to connect to the broker. A random identifier is used if empty.",
+					PropertyName: "clientId",
+				},
+				{
+					Label:        "Message format",
+					Element:      ElementTypeSelect,
+					Description:  "The format of the published message. JSON contains the alerts and the templated message, text contains only the templated message.",
+					PropertyName: "messageFormat",
+					SelectOptions: []SelectOption{
+						{
+							Value: "json",
+							Label: "json",
+						},
+						{
+							Value: "text",
+							Label: "text",
+						},
+					},
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "QoS",
+					Element:      ElementTypeSelect,
+					Description:  "The quality of service level of the published message.",
+					PropertyName: "qos",
+					SelectOptions: []SelectOption{
+						{
+							Value: "0",
+							Label: "At most once (0)",
+						},
+						{
+							Value: "1",
+							Label: "At least once (1)",
+						},
+					},
+				},
+				{
+					Label:        "Retain",
+					Element:      ElementTypeCheckbox,
+					Description:  "Ask the broker to retain the last message of the topic.",
+					PropertyName: "retain",
+				},
+				{
+					Label:        "Disable certificate verification",
+					Element:      ElementTypeCheckbox,
+					Description:  "Do not verify the TLS certificate of the broker.",
+					PropertyName: "insecureSkipVerify",
+				},
+			},
+		},
+		{
+			Type:        "sns",
+			Name:        "AWS SNS",
+			Description: "Sends notifications to Amazon SNS",
+			Heading:     "AWS SNS settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Topic ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The ARN of the topic to publish to. Exactly one of topic ARN, target ARN or phone number must be set.",
+					Placeholder:  "arn:aws:sns:us-east-1:123456789012:alerts",
+					PropertyName: "topic_arn",
+				},
+				{
+					Label:        "Target ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The ARN of the mobile platform endpoint to publish to.",
+					PropertyName: "target_arn",
+				},
+				{
+					Label:        "Phone number",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The phone number in E.164 format to send an SMS to.",
+					Placeholder:  "+15555555555",
+					PropertyName: "phone_number",
+				},
+				{
+					Label:        "Region",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The AWS region. If empty, the region of the topic or target ARN is used.",
+					Placeholder:  "us-east-1",
+					PropertyName: "region",
+				},
+				{
+					Label:        "Access key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "access_key",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Secret key",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "secret_key",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Assume role ARN",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The ARN of a role to assume with the access key.",
+					PropertyName: "assume_role_arn",
+				},
+				{
+					Label:        "API URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the SNS API endpoint.",
+					PropertyName: "api_url",
+				},
+				{
+					Label:        "Subject",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated subject of email notifications. Not used for SMS.",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Attributes",
+					Description:  "Templated message attributes.",
+					Element:      ElementTypeKeyValueMap,
+					InputType:    InputTypeText,
+					PropertyName: "attributes",
+				},
+			},
+		},
+		{
+			Type:        "matrix",
+			Name:        "Matrix",
+			Description: "Sends notifications to a Matrix room",
+			Heading:     "Matrix settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Homeserver URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://matrix.org",
+					PropertyName: "homeserverUrl",
+					Required:     true,
+				},
+				{
+					Label:        "Room ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The ID of the room to send messages to. The user of the access token must be a member of the room.",
+					Placeholder:  "!qporfwt:matrix.org",
+					PropertyName: "roomId",
+					Required:     true,
+				},
+				{
+					Label:        "Access token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "accessToken",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Message type",
+					Element:      ElementTypeSelect,
+					Description:  "Clients usually do not notify users of notices.",
+					PropertyName: "messageType",
+					SelectOptions: []SelectOption{
+						{
+							Value: "m.notice",
+							Label: "Notice",
+						},
+						{
+							Value: "m.text",
+							Label: "Text",
+						},
+					},
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "mattermost",
+			Name:        "Mattermost",
+			Description: "Sends notifications to Mattermost",
+			Heading:     "Mattermost settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Webhook URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The URL of the incoming webhook.",
+					Placeholder:  "https://mattermost.example.com/hooks/xxx",
+					PropertyName: "url",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Channel",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the channel of the incoming webhook.",
+					PropertyName: "channel",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the username of the incoming webhook.",
+					PropertyName: "username",
+				},
+				{
+					Label:        "Icon URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the profile picture of the incoming webhook.",
+					PropertyName: "icon_url",
+				},
+				{
+					Label:        "Icon emoji",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Overrides the profile picture with an emoji. Takes precedence over the icon URL.",
+					PropertyName: "icon_emoji",
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message.",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Text",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated text of the message.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "text",
+				},
+			},
+		},
+		{
+			Type:        "ntfy",
+			Name:        "ntfy",
+			Description: "Publishes notifications to a ntfy topic",
+			Heading:     "ntfy settings",
+			Options: []NotifierOption{
+				{
+					Label:        "Server URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://ntfy.sh",
+					PropertyName: "serverUrl",
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:        "Access token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					Description:  "Cannot be used together with basic authentication.",
+					PropertyName: "token",
+					Secure:       true,
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "Priority",
+					Element:      ElementTypeSelect,
+					PropertyName: "priority",
+					SelectOptions: []SelectOption{
+						{
+							Value: "5",
+							Label: "Max",
+						},
+						{
+							Value: "4",
+							Label: "High",
+						},
+						{
+							Value: "3",
+							Label: "Default",
+						},
+						{
+							Value: "2",
+							Label: "Low",
+						},
+						{
+							Value: "1",
+							Label: "Min",
+						},
+					},
+				},
+				{
+					Label:        "Tags",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated comma-separated list of tags. Tags that match an emoji short code are rendered as emojis.",
+					Placeholder:  `{{ if eq .Status "firing" }}warning{{ else }}white_check_mark{{ end }}`,
+					PropertyName: "tags",
+				},
+				{
+					Label:        "Click action",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated URL that is opened when the notification is clicked.",
+					Placeholder:  "{{ .ExternalURL }}",
+					PropertyName: "click",
+				},
+				{
+					Label:        "Title",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated title of the message.",
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated message.",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
 }
 
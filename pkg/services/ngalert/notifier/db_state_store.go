@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// maxStateConflictRetries is the number of times the state is saved again when another replica wrote it concurrently.
+const maxStateConflictRetries = 3
+
+// DBStateStore is in charge of persisting the silences and the notification log of the Alertmanager to the database.
+// Unlike FileStore, it stores one row per silence and notification log entry, and only writes the entries
+// that were modified since they were last saved.
+type DBStateStore struct {
+	store  store.AlertmanagerStateStore
+	orgID  int64
+	logger log.Logger
+}
+
+func NewDBStateStore(orgID int64, store store.AlertmanagerStateStore) *DBStateStore {
+	return &DBStateStore{
+		orgID:  orgID,
+		store:  store,
+		logger: log.New("ngalert.notifier.alertmanager.db_state_store", "org", orgID),
+	}
+}
+
+// GetSilences returns the silences in the binary format of the Alertmanager.
+func (s *DBStateStore) GetSilences(ctx context.Context) (string, error) {
+	return s.contentFor(ctx, models.AlertmanagerStateSilences)
+}
+
+// GetNotificationLog returns the notification log in the binary format of the Alertmanager.
+func (s *DBStateStore) GetNotificationLog(ctx context.Context) (string, error) {
+	return s.contentFor(ctx, models.AlertmanagerStateNotificationLog)
+}
+
+// contentFor concatenates the stored entries of the given kind. Each entry is length-delimited,
+// so the result can be read by the Alertmanager as a single snapshot.
+func (s *DBStateStore) contentFor(ctx context.Context, kind models.AlertmanagerStateKind) (string, error) {
+	entries, err := s.store.GetAlertmanagerState(ctx, s.orgID, kind)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.Write(e.Data)
+	}
+	return buf.String(), nil
+}
+
+// SaveSilences saves the silences that were modified to the database and returns the size of the unencoded state.
+func (s *DBStateStore) SaveSilences(ctx context.Context, st alertingNotify.State) (int64, error) {
+	return s.persist(ctx, models.AlertmanagerStateSilences, st, silenceEntries)
+}
+
+// SaveNotificationLog saves the notification log entries that were modified to the database and returns the size of the unencoded state.
+func (s *DBStateStore) SaveNotificationLog(ctx context.Context, st alertingNotify.State) (int64, error) {
+	return s.persist(ctx, models.AlertmanagerStateNotificationLog, st, notificationLogEntries)
+}
+
+func (s *DBStateStore) persist(ctx context.Context, kind models.AlertmanagerStateKind, st alertingNotify.State, split func([]byte) ([]models.AlertmanagerStateEntry, error)) (int64, error) {
+	b, err := st.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := split(b)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse alertmanager %s: %w", kind, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = s.store.SaveAlertmanagerState(ctx, s.orgID, kind, entries)
+		if !errors.Is(err, models.ErrAlertmanagerStateConflict) || attempt > maxStateConflictRetries {
+			break
+		}
+		s.logger.Debug("Alertmanager state was modified concurrently, retrying", "kind", kind, "attempt", attempt)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(b)), nil
+}
+
+// silenceEntries splits the binary representation of the silences into one entry per silence.
+func silenceEntries(b []byte) ([]models.AlertmanagerStateEntry, error) {
+	var entries []models.AlertmanagerStateEntry
+	r := bytes.NewReader(b)
+	for {
+		var s silencepb.MeshSilence
+		if _, err := pbutil.ReadDelimited(r, &s); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, err
+		}
+		if s.Silence == nil {
+			return nil, errors.New("invalid silence")
+		}
+		var data bytes.Buffer
+		if _, err := pbutil.WriteDelimited(&data, &s); err != nil {
+			return nil, err
+		}
+		entries = append(entries, models.AlertmanagerStateEntry{
+			Key:       s.Silence.Id,
+			Data:      data.Bytes(),
+			UpdatedAt: s.Silence.UpdatedAt,
+			ExpiresAt: s.ExpiresAt,
+		})
+	}
+}
+
+// notificationLogEntries splits the binary representation of the notification log into one entry per group and receiver.
+// The key of the Alertmanager is hashed, as group keys can be arbitrarily long.
+func notificationLogEntries(b []byte) ([]models.AlertmanagerStateEntry, error) {
+	var entries []models.AlertmanagerStateEntry
+	r := bytes.NewReader(b)
+	for {
+		var e nflogpb.MeshEntry
+		if _, err := pbutil.ReadDelimited(r, &e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, err
+		}
+		if e.Entry == nil || e.Entry.Receiver == nil {
+			return nil, errors.New("invalid notification log entry")
+		}
+		var data bytes.Buffer
+		if _, err := pbutil.WriteDelimited(&data, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, models.AlertmanagerStateEntry{
+			Key:       fmt.Sprintf("%x", sha256.Sum256([]byte(stateKey(string(e.Entry.GroupKey), e.Entry.Receiver)))),
+			Data:      data.Bytes(),
+			UpdatedAt: e.Entry.Timestamp,
+			ExpiresAt: e.ExpiresAt,
+		})
+	}
+}
+
+// receiverKey copied from prometheus-alertmanager/nflog/nflog.go.
+func receiverKey(r *nflogpb.Receiver) string {
+	return fmt.Sprintf("%s/%s/%d", r.GroupName, r.Integration, r.Idx)
+}
+
+// stateKey copied from prometheus-alertmanager/nflog/nflog.go.
+func stateKey(k string, r *nflogpb.Receiver) string {
+	return fmt.Sprintf("%s:%s", k, receiverKey(r))
+}
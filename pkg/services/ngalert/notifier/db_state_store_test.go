@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDBStateStore_Silences(t *testing.T) {
+	store := NewFakeConfigStore(t, nil)
+	ctx := context.Background()
+
+	now := time.Now()
+	oneHour := now.Add(time.Hour)
+	state := silenceState{
+		"1": createSilence("1", now, oneHour),
+		"2": createSilence("2", now, oneHour),
+	}
+	decodedState, err := state.MarshalBinary()
+	require.NoError(t, err)
+
+	s := NewDBStateStore(1, store)
+	size, err := s.SaveSilences(ctx, state)
+	require.NoError(t, err)
+	require.EqualValues(t, len(decodedState), size)
+
+	// One entry per silence.
+	entries, err := store.GetAlertmanagerState(ctx, 1, models.AlertmanagerStateSilences)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		require.Equal(t, oneHour.UnixNano(), e.ExpiresAt.UnixNano())
+	}
+
+	silences, err := s.GetSilences(ctx)
+	require.NoError(t, err)
+	decoded, err := decodeSilenceState(strings.NewReader(silences))
+	require.NoError(t, err)
+	if !cmp.Equal(state, decoded) {
+		t.Errorf("Unexpected Diff: %v", cmp.Diff(state, decoded))
+	}
+
+	// Other orgs are not affected.
+	silences, err = NewDBStateStore(2, store).GetSilences(ctx)
+	require.NoError(t, err)
+	require.Empty(t, silences)
+}
+
+func TestDBStateStore_NotificationLog(t *testing.T) {
+	store := NewFakeConfigStore(t, nil)
+	ctx := context.Background()
+
+	now := time.Now()
+	oneHour := now.Add(time.Hour)
+	k1, v1 := createNotificationLog("group1", "receiver1", now, oneHour)
+	k2, v2 := createNotificationLog(strings.Repeat("group2", 100), "receiver2", now, oneHour)
+	state := nflogState{k1: v1, k2: v2}
+
+	s := NewDBStateStore(1, store)
+	_, err := s.SaveNotificationLog(ctx, state)
+	require.NoError(t, err)
+
+	// Keys are hashed to fit in the database regardless of the length of the group key.
+	entries, err := store.GetAlertmanagerState(ctx, 1, models.AlertmanagerStateNotificationLog)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		require.Len(t, e.Key, 64)
+		require.Equal(t, now.UnixNano(), e.UpdatedAt.UnixNano())
+	}
+
+	nflog, err := s.GetNotificationLog(ctx)
+	require.NoError(t, err)
+	decoded, err := decodeNflogState(strings.NewReader(nflog))
+	require.NoError(t, err)
+	if !cmp.Equal(state, decoded) {
+		t.Errorf("Unexpected Diff: %v", cmp.Diff(state, decoded))
+	}
+}
+
+type conflictingStateStore struct {
+	*fakeConfigStore
+	conflicts int
+	calls     int
+}
+
+func (c *conflictingStateStore) SaveAlertmanagerState(ctx context.Context, orgID int64, kind models.AlertmanagerStateKind, entries []models.AlertmanagerStateEntry) error {
+	c.calls++
+	if c.calls <= c.conflicts {
+		return models.ErrAlertmanagerStateConflict
+	}
+	return c.fakeConfigStore.SaveAlertmanagerState(ctx, orgID, kind, entries)
+}
+
+func TestDBStateStore_Conflicts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	state := silenceState{"1": createSilence("1", now, now.Add(time.Hour))}
+
+	t.Run("retries when the state is modified concurrently", func(t *testing.T) {
+		store := &conflictingStateStore{fakeConfigStore: NewFakeConfigStore(t, nil), conflicts: maxStateConflictRetries}
+		_, err := NewDBStateStore(1, store).SaveSilences(ctx, state)
+		require.NoError(t, err)
+		require.Equal(t, maxStateConflictRetries+1, store.calls)
+	})
+
+	t.Run("returns the conflict after too many retries", func(t *testing.T) {
+		store := &conflictingStateStore{fakeConfigStore: NewFakeConfigStore(t, nil), conflicts: maxStateConflictRetries + 1}
+		_, err := NewDBStateStore(1, store).SaveSilences(ctx, state)
+		require.ErrorIs(t, err, models.ErrAlertmanagerStateConflict)
+	})
+}
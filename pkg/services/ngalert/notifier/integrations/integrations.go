@@ -0,0 +1,210 @@
+// Package integrations contains the contact point integrations that are implemented in Grafana rather than
+// in the module github.com/grafana/alerting. The functions in this package extend the receiver factory of
+// the module, and should be used instead of it wherever receivers are validated or built.
+package integrations
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/mattermost"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/mqtt"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/ntfy"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/sns"
+)
+
+const (
+	MatrixType     = "matrix"
+	MattermostType = "mattermost"
+	MQTTType       = "mqtt"
+	NtfyType       = "ntfy"
+	SNSType        = "sns"
+)
+
+// IsSupported returns true if the integration type is implemented in this package.
+func IsSupported(integrationType string) bool {
+	switch strings.ToLower(integrationType) {
+	case MatrixType, MattermostType, MQTTType, NtfyType, SNSType:
+		return true
+	}
+	return false
+}
+
+// GrafanaReceiverConfig represents a parsed and validated APIReceiver, including the integrations implemented in this package.
+type GrafanaReceiverConfig struct {
+	alertingNotify.GrafanaReceiverConfig
+	MatrixConfigs     []*alertingNotify.NotifierConfig[matrix.Config]
+	MattermostConfigs []*alertingNotify.NotifierConfig[mattermost.Config]
+	MQTTConfigs       []*alertingNotify.NotifierConfig[mqtt.Config]
+	NtfyConfigs       []*alertingNotify.NotifierConfig[ntfy.Config]
+	SNSConfigs        []*alertingNotify.NotifierConfig[sns.Config]
+}
+
+// BuildReceiverConfiguration parses, decrypts and validates the APIReceiver.
+// Integrations of types that are not implemented in this package are passed to the factory of the alerting module.
+func BuildReceiverConfiguration(ctx context.Context, api *alertingNotify.APIReceiver, decrypt alertingNotify.GetDecryptedValueFn) (GrafanaReceiverConfig, error) {
+	result := GrafanaReceiverConfig{}
+	upstream := &alertingNotify.APIReceiver{ConfigReceiver: api.ConfigReceiver}
+	for _, receiver := range api.Integrations {
+		if !IsSupported(receiver.Type) {
+			upstream.Integrations = append(upstream.Integrations, receiver)
+			continue
+		}
+		err := parseNotifier(ctx, &result, receiver, decrypt)
+		if err != nil {
+			return GrafanaReceiverConfig{}, alertingNotify.IntegrationValidationError{
+				Integration: receiver,
+				Err:         err,
+			}
+		}
+	}
+
+	cfg, err := alertingNotify.BuildReceiverConfiguration(ctx, upstream, decrypt)
+	if err != nil {
+		return GrafanaReceiverConfig{}, err
+	}
+	result.GrafanaReceiverConfig = cfg
+	return result, nil
+}
+
+// parseNotifier parses receivers and populates the corresponding field in GrafanaReceiverConfig. Returns an error if the configuration cannot be parsed.
+func parseNotifier(ctx context.Context, result *GrafanaReceiverConfig, receiver *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) error {
+	secureSettings, err := decodeSecretsFromBase64(receiver.SecureSettings)
+	if err != nil {
+		return err
+	}
+
+	decryptFn := func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	}
+
+	switch strings.ToLower(receiver.Type) {
+	case MatrixType:
+		cfg, err := matrix.NewConfig(receiver.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.MatrixConfigs = append(result.MatrixConfigs, newNotifierConfig(receiver, cfg))
+	case MattermostType:
+		cfg, err := mattermost.NewConfig(receiver.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.MattermostConfigs = append(result.MattermostConfigs, newNotifierConfig(receiver, cfg))
+	case MQTTType:
+		cfg, err := mqtt.NewConfig(receiver.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.MQTTConfigs = append(result.MQTTConfigs, newNotifierConfig(receiver, cfg))
+	case NtfyType:
+		cfg, err := ntfy.NewConfig(receiver.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.NtfyConfigs = append(result.NtfyConfigs, newNotifierConfig(receiver, cfg))
+	case SNSType:
+		cfg, err := sns.NewConfig(receiver.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.SNSConfigs = append(result.SNSConfigs, newNotifierConfig(receiver, cfg))
+	default:
+		return fmt.Errorf("notifier %s is not supported", receiver.Type)
+	}
+	return nil
+}
+
+// BuildReceiverIntegrations creates integrations for each configured notification channel in GrafanaReceiverConfig.
+// The integrations supported by the alerting module are built by its factory, and followed by the ones implemented in this package.
+func BuildReceiverIntegrations(
+	receiver GrafanaReceiverConfig,
+	tmpl *templates.Template,
+	img images.Provider,
+	logger logging.LoggerFactory,
+	newWebhookSender func(n receivers.Metadata) (receivers.WebhookSender, error),
+	newEmailSender func(n receivers.Metadata) (receivers.EmailSender, error),
+	orgID int64,
+	version string,
+) ([]*alertingNotify.Integration, error) {
+	integrations, err := alertingNotify.BuildReceiverIntegrations(receiver.GrafanaReceiverConfig, tmpl, img, logger, newWebhookSender, newEmailSender, orgID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	type notificationChannel interface {
+		Notify(ctx context.Context, alerts ...*types.Alert) (bool, error)
+		SendResolved() bool
+	}
+	var (
+		errors types.MultiError
+		nl     = func(meta receivers.Metadata) logging.Logger {
+			return logger("ngalert.notifier."+meta.Type, "notifierUID", meta.UID)
+		}
+		ci = func(idx int, cfg receivers.Metadata, n notificationChannel) {
+			integrations = append(integrations, alertingNotify.NewIntegration(n, n, cfg.Type, idx, cfg.Name))
+		}
+		nw = func(cfg receivers.Metadata) receivers.WebhookSender {
+			w, e := newWebhookSender(cfg)
+			if e != nil {
+				errors.Add(fmt.Errorf("unable to build webhook client for %s notifier %s (UID: %s): %w ", cfg.Type, cfg.Name, cfg.UID, e))
+				return nil
+			}
+			return w
+		}
+	)
+	for i, cfg := range receiver.MatrixConfigs {
+		ci(i, cfg.Metadata, matrix.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.MattermostConfigs {
+		ci(i, cfg.Metadata, mattermost.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), img, nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.MQTTConfigs {
+		ci(i, cfg.Metadata, mqtt.New(cfg.Settings, cfg.Metadata, tmpl, nl(cfg.Metadata), orgID))
+	}
+	for i, cfg := range receiver.NtfyConfigs {
+		ci(i, cfg.Metadata, ntfy.New(cfg.Settings, cfg.Metadata, tmpl, nw(cfg.Metadata), nl(cfg.Metadata)))
+	}
+	for i, cfg := range receiver.SNSConfigs {
+		ci(i, cfg.Metadata, sns.New(cfg.Settings, cfg.Metadata, tmpl, nl(cfg.Metadata)))
+	}
+	if errors.Len() > 0 {
+		return nil, &errors
+	}
+	return integrations, nil
+}
+
+func decodeSecretsFromBase64(secrets map[string]string) (map[string][]byte, error) {
+	secureSettings := make(map[string][]byte, len(secrets))
+	for k, v := range secrets {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secure settings key %s: %w", k, err)
+		}
+		secureSettings[k] = d
+	}
+	return secureSettings, nil
+}
+
+func newNotifierConfig[T interface{}](receiver *alertingNotify.GrafanaIntegrationConfig, settings T) *alertingNotify.NotifierConfig[T] {
+	return &alertingNotify.NotifierConfig[T]{
+		Metadata: receivers.Metadata{
+			UID:                   receiver.UID,
+			Name:                  receiver.Name,
+			Type:                  receiver.Type,
+			DisableResolveMessage: receiver.DisableResolveMessage,
+		},
+		Settings: settings,
+	}
+}
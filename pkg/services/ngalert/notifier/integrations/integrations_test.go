@@ -0,0 +1,102 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func decryptForTesting(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+	return receiversTesting.DecryptForTesting(sjd)(key, fallback)
+}
+
+func TestBuildReceiverConfiguration(t *testing.T) {
+	t.Run("parses all known integrations together with the ones of the alerting module", func(t *testing.T) {
+		recCfg := &alertingNotify.APIReceiver{ConfigReceiver: alertingNotify.ConfigReceiver{Name: "test-receiver"}}
+		for notifierType, cfg := range AllKnownConfigsForTesting {
+			recCfg.Integrations = append(recCfg.Integrations, cfg.GetRawNotifierConfig(notifierType))
+		}
+		recCfg.Integrations = append(recCfg.Integrations, alertingNotify.AllKnownConfigsForTesting["webhook"].GetRawNotifierConfig("webhook"))
+
+		parsed, err := BuildReceiverConfiguration(context.Background(), recCfg, decryptForTesting)
+		require.NoError(t, err)
+		require.Equal(t, "test-receiver", parsed.Name)
+		require.Len(t, parsed.WebhookConfigs, 1)
+		require.Len(t, parsed.MatrixConfigs, 1)
+		require.Len(t, parsed.MattermostConfigs, 1)
+		require.Len(t, parsed.MQTTConfigs, 1)
+		require.Len(t, parsed.NtfyConfigs, 1)
+		require.Len(t, parsed.SNSConfigs, 1)
+
+		// Secrets are decrypted.
+		require.Equal(t, "test-secret-token", parsed.MatrixConfigs[0].Settings.AccessToken)
+		require.Equal(t, "test-secret-password", parsed.MQTTConfigs[0].Settings.Password)
+		require.Equal(t, "matrix-uid", parsed.MatrixConfigs[0].Metadata.UID)
+		require.True(t, parsed.MatrixConfigs[0].Metadata.DisableResolveMessage)
+	})
+
+	t.Run("returns validation error for invalid integration", func(t *testing.T) {
+		integration := &alertingNotify.GrafanaIntegrationConfig{
+			UID:      "test-uid",
+			Name:     "test",
+			Type:     NtfyType,
+			Settings: json.RawMessage(`{}`),
+		}
+		_, err := BuildReceiverConfiguration(context.Background(), &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{integration}},
+		}, decryptForTesting)
+
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, integration, validationErr.Integration)
+		require.ErrorContains(t, err, "could not find topic in settings")
+	})
+
+	t.Run("returns error for unknown integration", func(t *testing.T) {
+		_, err := BuildReceiverConfiguration(context.Background(), &alertingNotify.APIReceiver{
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{Type: "unknown", Settings: json.RawMessage(`{}`)},
+			}},
+		}, decryptForTesting)
+		require.ErrorContains(t, err, "notifier unknown is not supported")
+	})
+}
+
+func TestBuildReceiverIntegrations(t *testing.T) {
+	recCfg := &alertingNotify.APIReceiver{ConfigReceiver: alertingNotify.ConfigReceiver{Name: "test-receiver"}}
+	for notifierType, cfg := range AllKnownConfigsForTesting {
+		recCfg.Integrations = append(recCfg.Integrations, cfg.GetRawNotifierConfig(notifierType))
+	}
+	recCfg.Integrations = append(recCfg.Integrations, alertingNotify.AllKnownConfigsForTesting["webhook"].GetRawNotifierConfig("webhook"))
+
+	parsed, err := BuildReceiverConfiguration(context.Background(), recCfg, decryptForTesting)
+	require.NoError(t, err)
+
+	sender := receivers.MockNotificationService()
+	integrations, err := BuildReceiverIntegrations(
+		parsed,
+		templates.ForTests(t),
+		&images.UnavailableProvider{},
+		func(_ string, _ ...any) logging.Logger { return &logging.FakeLogger{} },
+		func(receivers.Metadata) (receivers.WebhookSender, error) { return sender, nil },
+		func(receivers.Metadata) (receivers.EmailSender, error) { return sender, nil },
+		1,
+		"test",
+	)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(integrations))
+	for _, i := range integrations {
+		names = append(names, i.Name())
+	}
+	require.ElementsMatch(t, []string{"webhook", "matrix", "mattermost", "mqtt", "ntfy", "sns"}, names)
+}
@@ -0,0 +1,65 @@
+package matrix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	MessageTypeText   = "m.text"
+	MessageTypeNotice = "m.notice"
+)
+
+type Config struct {
+	HomeserverURL string `json:"homeserverUrl,omitempty" yaml:"homeserverUrl,omitempty"`
+	RoomID        string `json:"roomId,omitempty" yaml:"roomId,omitempty"`
+	AccessToken   string `json:"accessToken,omitempty" yaml:"accessToken,omitempty"`
+	MessageType   string `json:"messageType,omitempty" yaml:"messageType,omitempty"`
+	Message       string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// NewConfig is the constructor for the Matrix notifier.
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if settings.HomeserverURL == "" {
+		return Config{}, errors.New("could not find homeserver URL in settings")
+	}
+	u, err := url.Parse(settings.HomeserverURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid homeserver URL %q", settings.HomeserverURL)
+	}
+	settings.HomeserverURL = u.String()
+
+	if settings.RoomID == "" {
+		return Config{}, errors.New("could not find room ID in settings")
+	}
+
+	settings.AccessToken = decryptFn("accessToken", settings.AccessToken)
+	if settings.AccessToken == "" {
+		return Config{}, errors.New("could not find access token in settings")
+	}
+
+	switch settings.MessageType {
+	case "":
+		settings.MessageType = MessageTypeNotice
+	case MessageTypeText, MessageTypeNotice:
+	default:
+		return Config{}, fmt.Errorf("invalid message type %q, must be %q or %q", settings.MessageType, MessageTypeText, MessageTypeNotice)
+	}
+
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+
+	return settings, nil
+}
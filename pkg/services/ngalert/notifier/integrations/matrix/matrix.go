@@ -0,0 +1,99 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// maxMessageLenBytes is the size of the message body that is accepted by the default Synapse configuration,
+// leaving some room for the rest of the event.
+const maxMessageLenBytes = 60000
+
+// Notifier is responsible for sending alert notifications as messages to a Matrix room.
+type Notifier struct {
+	*receivers.Base
+	ns       receivers.WebhookSender
+	log      logging.Logger
+	tmpl     *templates.Template
+	settings Config
+	// newTxnID returns the transaction ID of the event. Matrix uses it to deduplicate retried requests.
+	newTxnID func() string
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		ns:       sender,
+		log:      logger,
+		tmpl:     template,
+		settings: cfg,
+		newTxnID: func() string {
+			return uuid.NewString()
+		},
+	}
+}
+
+// matrixMessage is the content of the m.room.message event.
+type matrixMessage struct {
+	MessageType string `json:"msgtype"`
+	Body        string `json:"body"`
+}
+
+// Notify sends the alert notification as a room message event.
+func (mn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	message, truncated := receivers.TruncateInBytes(tmpl(mn.settings.Message), maxMessageLenBytes)
+	if truncated {
+		mn.log.Warn("Matrix message too long, truncating message", "OriginalMessage", mn.settings.Message)
+	}
+	if tmplErr != nil {
+		mn.log.Warn("Failed to template Matrix message", "error", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(matrixMessage{
+		MessageType: mn.settings.MessageType,
+		Body:        message,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &receivers.SendWebhookSettings{
+		URL:        mn.sendURL(),
+		Body:       string(body),
+		HTTPMethod: http.MethodPut,
+		HTTPHeader: map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", mn.settings.AccessToken),
+		},
+	}
+	if err := mn.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sendURL returns the client-server API endpoint that sends a message event to the configured room.
+func (mn *Notifier) sendURL() string {
+	return fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(mn.settings.HomeserverURL, "/"),
+		url.PathEscape(mn.settings.RoomID),
+		url.PathEscape(mn.newTxnID()),
+	)
+}
+
+func (mn *Notifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,185 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if homeserver URL is missing",
+			settings:          `{ "roomId": "!room:localhost", "accessToken": "token" }`,
+			expectedInitError: `could not find homeserver URL in settings`,
+		},
+		{
+			name:              "Error if homeserver URL is not valid",
+			settings:          `{ "homeserverUrl": "localhost", "roomId": "!room:localhost", "accessToken": "token" }`,
+			expectedInitError: `invalid homeserver URL "localhost"`,
+		},
+		{
+			name:              "Error if room ID is missing",
+			settings:          `{ "homeserverUrl": "http://localhost", "accessToken": "token" }`,
+			expectedInitError: `could not find room ID in settings`,
+		},
+		{
+			name:              "Error if access token is missing",
+			settings:          `{ "homeserverUrl": "http://localhost", "roomId": "!room:localhost" }`,
+			expectedInitError: `could not find access token in settings`,
+		},
+		{
+			name:              "Error if message type is not supported",
+			settings:          `{ "homeserverUrl": "http://localhost", "roomId": "!room:localhost", "accessToken": "token", "messageType": "m.emote" }`,
+			expectedInitError: `invalid message type "m.emote"`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "homeserverUrl": "http://localhost", "roomId": "!room:localhost", "accessToken": "token" }`,
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost",
+				RoomID:        "!room:localhost",
+				AccessToken:   "token",
+				MessageType:   MessageTypeNotice,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost:8008",
+				RoomID:        "!test-room:localhost",
+				AccessToken:   "test-token",
+				MessageType:   MessageTypeText,
+				Message:       "test-message",
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost:8008",
+				RoomID:        "!test-room:localhost",
+				AccessToken:   "test-secret-token",
+				MessageType:   MessageTypeText,
+				Message:       "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name        string
+		settings    Config
+		expURL      string
+		expMsg      string
+		expMsgError error
+	}{
+		{
+			name: "Message is sent to the escaped room",
+			settings: Config{
+				HomeserverURL: "https://matrix.example.com/",
+				RoomID:        "!room:example.com",
+				AccessToken:   "token",
+				MessageType:   MessageTypeNotice,
+				Message:       `{{ len .Alerts.Firing }} firing: {{ .CommonLabels.alertname }}`,
+			},
+			expURL: "https://matrix.example.com/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/txn-1",
+			expMsg: `{"msgtype":"m.notice","body":"1 firing: alert1"}`,
+		},
+		{
+			name: "Error is returned if the request fails",
+			settings: Config{
+				HomeserverURL: "https://matrix.example.com",
+				RoomID:        "!room:example.com",
+				AccessToken:   "token",
+				MessageType:   MessageTypeText,
+				Message:       "test",
+			},
+			expURL:      "https://matrix.example.com/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/txn-1",
+			expMsg:      `{"msgtype":"m.text","body":"test"}`,
+			expMsgError: errors.New("send error"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			webhookSender := receivers.MockNotificationService()
+			webhookSender.ShouldError = c.expMsgError
+
+			n := New(c.settings, receivers.Metadata{UID: "test", Name: "matrix", Type: "matrix"}, tmpl, webhookSender, &logging.FakeLogger{})
+			n.newTxnID = func() string { return "txn-1" }
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.ErrorIs(t, err, c.expMsgError)
+			} else {
+				require.True(t, ok)
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, c.expURL, webhookSender.Webhook.URL)
+			require.Equal(t, http.MethodPut, webhookSender.Webhook.HTTPMethod)
+			require.Equal(t, "Bearer token", webhookSender.Webhook.HTTPHeader["Authorization"])
+			require.JSONEq(t, c.expMsg, webhookSender.Webhook.Body)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package matrix
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"homeserverUrl": "http://localhost:8008",
+	"roomId": "!test-room:localhost",
+	"accessToken": "test-token",
+	"messageType": "m.text",
+	"message": "test-message"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"accessToken": "test-secret-token"
+}`
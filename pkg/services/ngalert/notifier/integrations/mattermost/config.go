@@ -0,0 +1,49 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+type Config struct {
+	WebhookURL string `json:"url,omitempty" yaml:"url,omitempty"`
+	Channel    string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+	IconURL    string `json:"icon_url,omitempty" yaml:"icon_url,omitempty"`
+	IconEmoji  string `json:"icon_emoji,omitempty" yaml:"icon_emoji,omitempty"`
+	Title      string `json:"title,omitempty" yaml:"title,omitempty"`
+	Text       string `json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+// NewConfig is the constructor for the Mattermost notifier.
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	settings.WebhookURL = decryptFn("url", settings.WebhookURL)
+	if settings.WebhookURL == "" {
+		return Config{}, errors.New("could not find webhook URL in settings")
+	}
+	u, err := url.Parse(settings.WebhookURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		// Do not include the URL in the error because it contains the webhook key.
+		return Config{}, errors.New("invalid webhook URL")
+	}
+
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Text == "" {
+		settings.Text = templates.DefaultMessageEmbed
+	}
+
+	return settings, nil
+}
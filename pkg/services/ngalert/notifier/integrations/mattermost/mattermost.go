@@ -0,0 +1,117 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// maxTextLenRunes is the maximum length of a post accepted by Mattermost.
+const maxTextLenRunes = 16383
+
+// Notifier is responsible for sending alert notifications to Mattermost incoming webhooks.
+type Notifier struct {
+	*receivers.Base
+	ns       receivers.WebhookSender
+	log      logging.Logger
+	images   images.Provider
+	tmpl     *templates.Template
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		ns:       sender,
+		log:      logger,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// mattermostMessage is the payload of a Mattermost incoming webhook.
+type mattermostMessage struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	IconEmoji   string                 `json:"icon_emoji,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+// mattermostAttachment is a Slack-compatible message attachment rendered by Mattermost.
+type mattermostAttachment struct {
+	Fallback  string `json:"fallback"`
+	Color     string `json:"color"`
+	Title     string `json:"title"`
+	TitleLink string `json:"title_link,omitempty"`
+	Text      string `json:"text"`
+	ImageURL  string `json:"image_url,omitempty"`
+}
+
+// Notify sends the alert notification as a post with a single attachment.
+func (mn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+
+	title := tmpl(mn.settings.Title)
+	text, truncated := receivers.TruncateInRunes(tmpl(mn.settings.Text), maxTextLenRunes)
+	if truncated {
+		mn.log.Warn("Mattermost message too long, truncating message", "OriginalMessage", mn.settings.Text)
+	}
+
+	msg := mattermostMessage{
+		Channel:   tmpl(mn.settings.Channel),
+		Username:  tmpl(mn.settings.Username),
+		IconURL:   tmpl(mn.settings.IconURL),
+		IconEmoji: tmpl(mn.settings.IconEmoji),
+	}
+	if tmplErr != nil {
+		mn.log.Warn("Failed to template Mattermost message", "error", tmplErr.Error())
+	}
+
+	attachment := mattermostAttachment{
+		Fallback:  title,
+		Color:     receivers.GetAlertStatusColor(types.Alerts(as...).Status()),
+		Title:     title,
+		TitleLink: receivers.JoinURLPath(mn.tmpl.ExternalURL.String(), "/alerting/list", mn.log),
+		Text:      text,
+	}
+
+	// Mattermost renders a single image per attachment, use the first one that has a URL.
+	_ = images.WithStoredImages(ctx, mn.log, mn.images, func(_ int, image images.Image) error {
+		if image.HasURL() {
+			attachment.ImageURL = image.URL
+			return images.ErrImagesDone
+		}
+		return nil
+	}, as...)
+
+	msg.Attachments = []mattermostAttachment{attachment}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &receivers.SendWebhookSettings{
+		URL:        mn.settings.WebhookURL,
+		Body:       string(body),
+		HTTPMethod: http.MethodPost,
+	}
+	if err := mn.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (mn *Notifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,185 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	images2 "github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if URL is missing",
+			settings:          `{}`,
+			expectedInitError: `could not find webhook URL in settings`,
+		},
+		{
+			name:              "Error if URL is not valid",
+			settings:          `{ "url": "hooks/test-key" }`,
+			expectedInitError: `invalid webhook URL`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "url": "http://localhost/hooks/key" }`,
+			expectedConfig: Config{
+				WebhookURL: "http://localhost/hooks/key",
+				Title:      templates.DefaultMessageTitleEmbed,
+				Text:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				WebhookURL: "http://localhost/hooks/test-key",
+				Channel:    "test-channel",
+				Username:   "test-username",
+				IconURL:    "http://localhost/icon.png",
+				IconEmoji:  ":grafana:",
+				Title:      "test-title",
+				Text:       "test-text",
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				WebhookURL: "http://localhost/hooks/test-secret-key",
+				Channel:    "test-channel",
+				Username:   "test-username",
+				IconURL:    "http://localhost/icon.png",
+				IconEmoji:  ":grafana:",
+				Title:      "test-title",
+				Text:       "test-text",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	images := images2.NewFakeProviderWithFile(t, 2)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name     string
+		settings Config
+		alerts   []*types.Alert
+		expMsg   string
+	}{
+		{
+			name: "Firing alert with image",
+			settings: Config{
+				WebhookURL: "http://localhost/hooks/key",
+				Channel:    "alerts",
+				Username:   "grafana",
+				Title:      "{{ .Status }}: {{ .CommonLabels.alertname }}",
+				Text:       "{{ len .Alerts.Firing }} firing",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1", "__alertImageToken__": "test-image-1"},
+					},
+				},
+			},
+			expMsg: `{
+				"channel": "alerts",
+				"username": "grafana",
+				"attachments": [{
+					"fallback": "firing: alert1",
+					"color": "#D63232",
+					"title": "firing: alert1",
+					"title_link": "http://localhost/alerting/list",
+					"text": "1 firing",
+					"image_url": "https://www.example.com/test-image-1"
+				}]
+			}`,
+		},
+		{
+			name: "Resolved alert",
+			settings: Config{
+				WebhookURL: "http://localhost/hooks/key",
+				Title:      "{{ .Status }}: {{ .CommonLabels.alertname }}",
+				Text:       "{{ len .Alerts.Resolved }} resolved",
+			},
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+						StartsAt:    time.Now().Add(-time.Hour),
+						EndsAt:      time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: `{
+				"attachments": [{
+					"fallback": "resolved: alert1",
+					"color": "#36a64f",
+					"title": "resolved: alert1",
+					"title_link": "http://localhost/alerting/list",
+					"text": "1 resolved"
+				}]
+			}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			webhookSender := receivers.MockNotificationService()
+			n := New(c.settings, receivers.Metadata{UID: "test", Name: "mattermost", Type: "mattermost"}, tmpl, webhookSender, images, &logging.FakeLogger{})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.settings.WebhookURL, webhookSender.Webhook.URL)
+			require.JSONEq(t, c.expMsg, webhookSender.Webhook.Body)
+		})
+	}
+}
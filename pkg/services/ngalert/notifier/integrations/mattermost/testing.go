@@ -0,0 +1,17 @@
+package mattermost
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"url": "http://localhost/hooks/test-key",
+	"channel": "test-channel",
+	"username": "test-username",
+	"icon_url": "http://localhost/icon.png",
+	"icon_emoji": ":grafana:",
+	"title": "test-title",
+	"text": "test-text"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"url": "http://localhost/hooks/test-secret-key"
+}`
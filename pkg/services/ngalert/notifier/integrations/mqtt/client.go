@@ -0,0 +1,241 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// This file implements the subset of MQTT 3.1.1 needed to publish a single message:
+// http://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+
+const (
+	packetConnect    byte = 0x10
+	packetConnAck    byte = 0x20
+	packetPublish    byte = 0x30
+	packetPubAck     byte = 0x40
+	packetDisconnect byte = 0xE0
+
+	protocolLevel311 byte = 4
+	// keepAliveSeconds is sent in CONNECT. The connection is closed right after publishing, so it is never used.
+	keepAliveSeconds = 30
+	// maxRemainingLength is the largest packet body that can be encoded.
+	maxRemainingLength = 268435455
+
+	defaultTimeout = 10 * time.Second
+)
+
+var connAckErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// publishMessage is a message to be published to the broker.
+type publishMessage struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// publisher connects to a broker, publishes a message and disconnects.
+type publisher interface {
+	Publish(ctx context.Context, cfg Config, clientID string, msg publishMessage) error
+}
+
+type client struct{}
+
+func (client) Publish(ctx context.Context, cfg Config, clientID string, msg publishMessage) (err error) {
+	conn, err := dial(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the broker: %w", err)
+	}
+	defer func() {
+		if cerr := conn.Close(); err == nil && cerr != nil && !errors.Is(cerr, net.ErrClosed) {
+			err = cerr
+		}
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	if _, err := conn.Write(connectPacket(clientID, cfg.Username, cfg.Password)); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+	if err := readConnAck(r); err != nil {
+		return err
+	}
+
+	const packetID uint16 = 1
+	p, err := publishPacket(msg, packetID)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(p); err != nil {
+		return fmt.Errorf("failed to send PUBLISH: %w", err)
+	}
+	if msg.QoS > 0 {
+		if err := readPubAck(r, packetID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := conn.Write([]byte{packetDisconnect, 0}); err != nil {
+		return fmt.Errorf("failed to send DISCONNECT: %w", err)
+	}
+	return nil
+}
+
+func dial(ctx context.Context, cfg Config) (net.Conn, error) {
+	u := cfg.brokerURL()
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	if !isTLSScheme(u.Scheme) {
+		return dialer.DialContext(ctx, "tcp", u.Host)
+	}
+	tlsDialer := &tls.Dialer{
+		NetDialer: dialer,
+		Config: &tls.Config{
+			ServerName: u.Hostname(),
+			MinVersion: tls.VersionTLS12,
+			// nolint:gosec
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		},
+	}
+	return tlsDialer.DialContext(ctx, "tcp", u.Host)
+}
+
+func connectPacket(clientID, username, password string) []byte {
+	var flags byte = 0x02 // clean session
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel311, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAliveSeconds)
+	body = appendString(body, clientID)
+	if username != "" {
+		body = appendString(body, username)
+		if password != "" {
+			body = appendString(body, password)
+		}
+	}
+	return appendPacket(packetConnect, body)
+}
+
+func publishPacket(msg publishMessage, packetID uint16) ([]byte, error) {
+	header := packetPublish | msg.QoS<<1
+	if msg.Retain {
+		header |= 0x01
+	}
+	body := appendString(nil, msg.Topic)
+	if msg.QoS > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	body = append(body, msg.Payload...)
+	if len(body) > maxRemainingLength {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum size of a packet", len(msg.Payload))
+	}
+	return appendPacket(header, body), nil
+}
+
+func readConnAck(r *bufio.Reader) error {
+	header, body, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if header&0xF0 != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("unexpected packet 0x%x while waiting for CONNACK", header)
+	}
+	if rc := body[1]; rc != 0 {
+		if msg, ok := connAckErrors[rc]; ok {
+			return fmt.Errorf("connection refused: %s", msg)
+		}
+		return fmt.Errorf("connection refused with code %d", rc)
+	}
+	return nil
+}
+
+func readPubAck(r *bufio.Reader, packetID uint16) error {
+	header, body, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read PUBACK: %w", err)
+	}
+	if header&0xF0 != packetPubAck || len(body) != 2 {
+		return fmt.Errorf("unexpected packet 0x%x while waiting for PUBACK", header)
+	}
+	if id := binary.BigEndian.Uint16(body); id != packetID {
+		return fmt.Errorf("unexpected packet identifier %d in PUBACK", id)
+	}
+	return nil
+}
+
+// readPacket reads a control packet and returns its fixed header byte and its body.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+		if multiplier > 128*128*128 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// appendPacket appends the fixed header with the variable-length encoded remaining length to the body.
+func appendPacket(header byte, body []byte) []byte {
+	p := make([]byte, 0, len(body)+5)
+	p = append(p, header)
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		p = append(p, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(p, body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,86 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBroker accepts a single connection, answers CONNECT with the given return code and records the published packet.
+func fakeBroker(t *testing.T, connAckCode byte) (string, <-chan [][]byte) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	packets := make(chan [][]byte, 1)
+	go func() {
+		defer close(packets)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		var received [][]byte
+		r := bufio.NewReader(conn)
+		for {
+			header, body, err := readPacket(r)
+			if err != nil {
+				break
+			}
+			received = append(received, append([]byte{header}, body...))
+			switch header & 0xF0 {
+			case packetConnect:
+				_, _ = conn.Write([]byte{packetConnAck, 2, 0, connAckCode})
+			case packetPublish:
+				if qos := (header >> 1) & 0x03; qos > 0 {
+					// The packet identifier follows the topic.
+					topicLen := int(body[0])<<8 | int(body[1])
+					_, _ = conn.Write(append([]byte{packetPubAck, 2}, body[2+topicLen:4+topicLen]...))
+				}
+			}
+		}
+		packets <- received
+	}()
+	return "tcp://" + l.Addr().String(), packets
+}
+
+func TestClientPublish(t *testing.T) {
+	t.Run("publishes with QoS 1 and disconnects", func(t *testing.T) {
+		addr, packets := fakeBroker(t, 0)
+
+		err := client{}.Publish(context.Background(), Config{BrokerURL: addr, Username: "user", Password: "pass"}, "client", publishMessage{
+			Topic:   "alerts",
+			Payload: []byte("test"),
+			QoS:     1,
+			Retain:  true,
+		})
+		require.NoError(t, err)
+
+		received := <-packets
+		require.Len(t, received, 3)
+		require.Equal(t, connectPacket("client", "user", "pass")[0], received[0][0])
+		require.Equal(t, []byte{packetPublish | 1<<1 | 1, 0, 6, 'a', 'l', 'e', 'r', 't', 's', 0, 1, 't', 'e', 's', 't'}, received[1])
+		require.Equal(t, []byte{packetDisconnect}, received[2])
+	})
+
+	t.Run("returns error if connection is refused", func(t *testing.T) {
+		addr, _ := fakeBroker(t, 4)
+
+		err := client{}.Publish(context.Background(), Config{BrokerURL: addr}, "client", publishMessage{Topic: "alerts"})
+		require.ErrorContains(t, err, "connection refused: bad user name or password")
+	})
+}
+
+func TestAppendPacket(t *testing.T) {
+	require.Equal(t, []byte{packetDisconnect, 0}, appendPacket(packetDisconnect, nil))
+
+	// Remaining length above 127 bytes uses a continuation byte.
+	p := appendPacket(packetPublish, make([]byte, 321))
+	require.Equal(t, []byte{packetPublish, 0xC1, 0x02}, p[:3])
+	require.Len(t, p, 324)
+}
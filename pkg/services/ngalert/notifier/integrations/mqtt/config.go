@@ -0,0 +1,124 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	MessageFormatJSON = "json"
+	MessageFormatText = "text"
+)
+
+type Config struct {
+	BrokerURL          string
+	ClientID           string
+	Topic              string
+	MessageFormat      string
+	Message            string
+	Username           string
+	Password           string
+	QoS                byte
+	Retain             bool
+	InsecureSkipVerify bool
+}
+
+// NewConfig is the constructor for the MQTT notifier.
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	rawSettings := struct {
+		BrokerURL          string                   `json:"brokerUrl,omitempty" yaml:"brokerUrl,omitempty"`
+		ClientID           string                   `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+		Topic              string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+		MessageFormat      string                   `json:"messageFormat,omitempty" yaml:"messageFormat,omitempty"`
+		Message            string                   `json:"message,omitempty" yaml:"message,omitempty"`
+		Username           string                   `json:"username,omitempty" yaml:"username,omitempty"`
+		Password           string                   `json:"password,omitempty" yaml:"password,omitempty"`
+		QoS                receivers.OptionalNumber `json:"qos,omitempty" yaml:"qos,omitempty"`
+		Retain             bool                     `json:"retain,omitempty" yaml:"retain,omitempty"`
+		InsecureSkipVerify bool                     `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	}{}
+
+	err := json.Unmarshal(jsonData, &rawSettings)
+	if err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if rawSettings.BrokerURL == "" {
+		return Config{}, errors.New("could not find broker URL in settings")
+	}
+	u, err := url.Parse(rawSettings.BrokerURL)
+	if err != nil || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid broker URL %q", rawSettings.BrokerURL)
+	}
+	if !isTCPScheme(u.Scheme) && !isTLSScheme(u.Scheme) {
+		return Config{}, fmt.Errorf("invalid broker URL scheme %q, must be one of tcp, mqtt, ssl, tls or mqtts", u.Scheme)
+	}
+	settings.BrokerURL = u.String()
+
+	settings.Topic = rawSettings.Topic
+	if settings.Topic == "" {
+		return Config{}, errors.New("could not find topic in settings")
+	}
+	if strings.ContainsAny(settings.Topic, "+#") {
+		return Config{}, fmt.Errorf("topic %q must not contain wildcards", settings.Topic)
+	}
+
+	settings.MessageFormat = rawSettings.MessageFormat
+	switch settings.MessageFormat {
+	case "":
+		settings.MessageFormat = MessageFormatJSON
+	case MessageFormatJSON, MessageFormatText:
+	default:
+		return Config{}, fmt.Errorf("invalid message format %q, must be %q or %q", settings.MessageFormat, MessageFormatJSON, MessageFormatText)
+	}
+	settings.Message = rawSettings.Message
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+
+	qos, err := rawSettings.QoS.Int64()
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to convert QoS to integer: %w", err)
+	}
+	if qos != 0 && qos != 1 {
+		return Config{}, fmt.Errorf("QoS must be 0 or 1, got %d", qos)
+	}
+	settings.QoS = byte(qos)
+
+	settings.ClientID = rawSettings.ClientID
+	settings.Username = rawSettings.Username
+	settings.Password = decryptFn("password", rawSettings.Password)
+	settings.Retain = rawSettings.Retain
+	settings.InsecureSkipVerify = rawSettings.InsecureSkipVerify
+
+	return settings, nil
+}
+
+// brokerURL returns the broker URL with the default port of the scheme if the port is not set.
+func (c Config) brokerURL() *url.URL {
+	u, _ := url.Parse(c.BrokerURL)
+	if u.Port() == "" {
+		port := "1883"
+		if isTLSScheme(u.Scheme) {
+			port = "8883"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return u
+}
+
+func isTCPScheme(scheme string) bool {
+	return scheme == "tcp" || scheme == "mqtt"
+}
+
+func isTLSScheme(scheme string) bool {
+	return scheme == "ssl" || scheme == "tls" || scheme == "mqtts"
+}
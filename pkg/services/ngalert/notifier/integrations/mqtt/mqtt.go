@@ -0,0 +1,102 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Notifier is responsible for publishing alert notifications to an MQTT broker.
+type Notifier struct {
+	*receivers.Base
+	log       logging.Logger
+	tmpl      *templates.Template
+	orgID     int64
+	settings  Config
+	publisher publisher
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, logger logging.Logger, orgID int64) *Notifier {
+	return &Notifier{
+		Base:      receivers.NewBase(meta),
+		log:       logger,
+		tmpl:      template,
+		orgID:     orgID,
+		settings:  cfg,
+		publisher: client{},
+	}
+}
+
+// mqttMessage is the payload published when the message format is JSON. It matches the payload of the webhook integration.
+type mqttMessage struct {
+	*templates.ExtendedData
+
+	GroupKey string `json:"groupKey"`
+	OrgID    int64  `json:"orgId"`
+	State    string `json:"state"`
+	Message  string `json:"message"`
+}
+
+// Notify publishes the alert notification to the configured topic.
+func (mn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var tmplErr error
+	tmpl, data := templates.TmplText(ctx, mn.tmpl, as, mn.log, &tmplErr)
+	message := tmpl(mn.settings.Message)
+	if tmplErr != nil {
+		mn.log.Warn("Failed to template MQTT message", "error", tmplErr.Error())
+	}
+
+	payload := []byte(message)
+	if mn.settings.MessageFormat == MessageFormatJSON {
+		msg := mqttMessage{
+			ExtendedData: data,
+			GroupKey:     groupKey.String(),
+			OrgID:        mn.orgID,
+			Message:      message,
+		}
+		if types.Alerts(as...).Status() == model.AlertFiring {
+			msg.State = string(receivers.AlertStateAlerting)
+		} else {
+			msg.State = string(receivers.AlertStateOK)
+		}
+		payload, err = json.Marshal(msg)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	clientID := mn.settings.ClientID
+	if clientID == "" {
+		// Brokers disconnect clients that reuse an identifier, so each connection gets its own.
+		clientID = fmt.Sprintf("grafana-%s", uuid.NewString()[:8])
+	}
+
+	err = mn.publisher.Publish(ctx, mn.settings, clientID, publishMessage{
+		Topic:   mn.settings.Topic,
+		Payload: payload,
+		QoS:     mn.settings.QoS,
+		Retain:  mn.settings.Retain,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (mn *Notifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,194 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if broker URL is missing",
+			settings:          `{ "topic": "alerts" }`,
+			expectedInitError: `could not find broker URL in settings`,
+		},
+		{
+			name:              "Error if broker URL scheme is not supported",
+			settings:          `{ "brokerUrl": "http://localhost", "topic": "alerts" }`,
+			expectedInitError: `invalid broker URL scheme "http"`,
+		},
+		{
+			name:              "Error if topic is missing",
+			settings:          `{ "brokerUrl": "tcp://localhost" }`,
+			expectedInitError: `could not find topic in settings`,
+		},
+		{
+			name:              "Error if topic has wildcards",
+			settings:          `{ "brokerUrl": "tcp://localhost", "topic": "alerts/#" }`,
+			expectedInitError: `topic "alerts/#" must not contain wildcards`,
+		},
+		{
+			name:              "Error if message format is not supported",
+			settings:          `{ "brokerUrl": "tcp://localhost", "topic": "alerts", "messageFormat": "xml" }`,
+			expectedInitError: `invalid message format "xml"`,
+		},
+		{
+			name:              "Error if QoS is not supported",
+			settings:          `{ "brokerUrl": "tcp://localhost", "topic": "alerts", "qos": 2 }`,
+			expectedInitError: `QoS must be 0 or 1, got 2`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "brokerUrl": "tcp://localhost", "topic": "alerts" }`,
+			expectedConfig: Config{
+				BrokerURL:     "tcp://localhost",
+				Topic:         "alerts",
+				MessageFormat: MessageFormatJSON,
+				Message:       templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				BrokerURL:          "tcp://localhost:1883",
+				ClientID:           "test-client-id",
+				Topic:              "grafana/alerts",
+				MessageFormat:      MessageFormatText,
+				Message:            "test-message",
+				Username:           "test-user",
+				Password:           "test-password",
+				QoS:                1,
+				Retain:             true,
+				InsecureSkipVerify: true,
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				BrokerURL:          "tcp://localhost:1883",
+				ClientID:           "test-client-id",
+				Topic:              "grafana/alerts",
+				MessageFormat:      MessageFormatText,
+				Message:            "test-message",
+				Username:           "test-user",
+				Password:           "test-secret-password",
+				QoS:                1,
+				Retain:             true,
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+type fakePublisher struct {
+	clientID string
+	msg      publishMessage
+}
+
+func (f *fakePublisher) Publish(_ context.Context, _ Config, clientID string, msg publishMessage) error {
+	f.clientID = clientID
+	f.msg = msg
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+
+	t.Run("text message is published as is", func(t *testing.T) {
+		p := &fakePublisher{}
+		n := New(Config{
+			ClientID:      "client",
+			Topic:         "alerts",
+			MessageFormat: MessageFormatText,
+			Message:       "{{ len .Alerts.Firing }} firing",
+			QoS:           1,
+			Retain:        true,
+		}, receivers.Metadata{UID: "test", Name: "mqtt", Type: "mqtt"}, tmpl, &logging.FakeLogger{}, 1)
+		n.publisher = p
+
+		ok, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "client", p.clientID)
+		require.Equal(t, publishMessage{Topic: "alerts", Payload: []byte("1 firing"), QoS: 1, Retain: true}, p.msg)
+	})
+
+	t.Run("JSON message contains the alerts", func(t *testing.T) {
+		p := &fakePublisher{}
+		n := New(Config{
+			Topic:         "alerts",
+			MessageFormat: MessageFormatJSON,
+			Message:       "test",
+		}, receivers.Metadata{UID: "test", Name: "mqtt", Type: "mqtt"}, tmpl, &logging.FakeLogger{}, 1)
+		n.publisher = p
+
+		ok, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Regexp(t, "^grafana-", p.clientID)
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(p.msg.Payload, &payload))
+		require.Equal(t, "alertname", payload["groupKey"])
+		require.Equal(t, float64(1), payload["orgId"])
+		require.Equal(t, "alerting", payload["state"])
+		require.Equal(t, "test", payload["message"])
+		require.Equal(t, "firing", payload["status"])
+		require.Len(t, payload["alerts"], 1)
+	})
+}
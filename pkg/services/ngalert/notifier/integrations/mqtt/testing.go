@@ -0,0 +1,20 @@
+package mqtt
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"brokerUrl": "tcp://localhost:1883",
+	"clientId": "test-client-id",
+	"topic": "grafana/alerts",
+	"messageFormat": "text",
+	"message": "test-message",
+	"username": "test-user",
+	"password": "test-password",
+	"qos": "1",
+	"retain": true,
+	"insecureSkipVerify": true
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"password": "test-secret-password"
+}`
@@ -0,0 +1,102 @@
+package ntfy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	DefaultServerURL = "https://ntfy.sh"
+	DefaultPriority  = 3
+	// DefaultTags renders a warning emoji for firing notifications and a check mark for resolved ones.
+	DefaultTags = `{{ if eq .Status "firing" }}warning{{ else }}white_check_mark{{ end }}`
+)
+
+type Config struct {
+	ServerURL string
+	Topic     string
+	Token     string
+	Username  string
+	Password  string
+	Priority  int64
+	Tags      string
+	Click     string
+	Title     string
+	Message   string
+}
+
+// NewConfig is the constructor for the ntfy notifier.
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	rawSettings := struct {
+		ServerURL string                   `json:"serverUrl,omitempty" yaml:"serverUrl,omitempty"`
+		Topic     string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+		Token     string                   `json:"token,omitempty" yaml:"token,omitempty"`
+		Username  string                   `json:"username,omitempty" yaml:"username,omitempty"`
+		Password  string                   `json:"password,omitempty" yaml:"password,omitempty"`
+		Priority  receivers.OptionalNumber `json:"priority,omitempty" yaml:"priority,omitempty"`
+		Tags      string                   `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Click     string                   `json:"click,omitempty" yaml:"click,omitempty"`
+		Title     string                   `json:"title,omitempty" yaml:"title,omitempty"`
+		Message   string                   `json:"message,omitempty" yaml:"message,omitempty"`
+	}{}
+
+	err := json.Unmarshal(jsonData, &rawSettings)
+	if err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	settings.ServerURL = rawSettings.ServerURL
+	if settings.ServerURL == "" {
+		settings.ServerURL = DefaultServerURL
+	}
+	u, err := url.Parse(settings.ServerURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Config{}, fmt.Errorf("invalid server URL %q", settings.ServerURL)
+	}
+	settings.ServerURL = u.String()
+
+	settings.Topic = rawSettings.Topic
+	if settings.Topic == "" {
+		return Config{}, errors.New("could not find topic in settings")
+	}
+
+	settings.Token = decryptFn("token", rawSettings.Token)
+	settings.Username = rawSettings.Username
+	settings.Password = decryptFn("password", rawSettings.Password)
+	if settings.Token != "" && settings.Username != "" {
+		return Config{}, errors.New("access token and basic authentication cannot be used at the same time")
+	}
+
+	settings.Priority = DefaultPriority
+	if rawSettings.Priority != "" {
+		settings.Priority, err = rawSettings.Priority.Int64()
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to convert priority to integer: %w", err)
+		}
+		if settings.Priority < 1 || settings.Priority > 5 {
+			return Config{}, fmt.Errorf("priority must be between 1 and 5, got %d", settings.Priority)
+		}
+	}
+
+	settings.Tags = rawSettings.Tags
+	if settings.Tags == "" {
+		settings.Tags = DefaultTags
+	}
+	settings.Click = rawSettings.Click
+	settings.Title = rawSettings.Title
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	settings.Message = rawSettings.Message
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,107 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// maxMessageLenBytes is the default size limit of a message accepted by a ntfy server.
+const maxMessageLenBytes = 4096
+
+// Notifier is responsible for publishing alert notifications to a ntfy topic.
+type Notifier struct {
+	*receivers.Base
+	ns       receivers.WebhookSender
+	log      logging.Logger
+	tmpl     *templates.Template
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		ns:       sender,
+		log:      logger,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// ntfyMessage is the body of a message published as JSON to the root of a ntfy server.
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Priority int64    `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+}
+
+// Notify publishes the alert notification to the configured topic.
+func (nn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, nn.tmpl, as, nn.log, &tmplErr)
+
+	message, truncated := receivers.TruncateInBytes(tmpl(nn.settings.Message), maxMessageLenBytes)
+	if truncated {
+		nn.log.Warn("ntfy message too long, truncating message", "OriginalMessage", nn.settings.Message)
+	}
+
+	msg := ntfyMessage{
+		Topic:    nn.settings.Topic,
+		Title:    tmpl(nn.settings.Title),
+		Message:  message,
+		Priority: nn.settings.Priority,
+		Tags:     splitTags(tmpl(nn.settings.Tags)),
+		Click:    tmpl(nn.settings.Click),
+	}
+	if tmplErr != nil {
+		nn.log.Warn("Failed to template ntfy message", "error", tmplErr.Error())
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &receivers.SendWebhookSettings{
+		URL:        nn.settings.ServerURL,
+		Body:       string(body),
+		HTTPMethod: http.MethodPost,
+		User:       nn.settings.Username,
+		Password:   nn.settings.Password,
+	}
+	if nn.settings.Token != "" {
+		cmd.HTTPHeader = map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", nn.settings.Token),
+		}
+	}
+	if err := nn.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (nn *Notifier) SendResolved() bool {
+	return !nn.GetDisableResolveMessage()
+}
+
+// splitTags splits the comma-separated list of tags, dropping empty ones.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,204 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if topic is missing",
+			settings:          `{}`,
+			expectedInitError: `could not find topic in settings`,
+		},
+		{
+			name:              "Error if server URL is not valid",
+			settings:          `{ "serverUrl": "ntfy.sh", "topic": "alerts" }`,
+			expectedInitError: `invalid server URL "ntfy.sh"`,
+		},
+		{
+			name:              "Error if priority is out of range",
+			settings:          `{ "topic": "alerts", "priority": 6 }`,
+			expectedInitError: `priority must be between 1 and 5, got 6`,
+		},
+		{
+			name:              "Error if priority is not a number",
+			settings:          `{ "topic": "alerts", "priority": "high" }`,
+			expectedInitError: `failed to convert priority to integer`,
+		},
+		{
+			name:              "Error if both token and username are set",
+			settings:          `{ "topic": "alerts", "username": "user" }`,
+			secureSettings:    map[string][]byte{"token": []byte("tk_test")},
+			expectedInitError: `access token and basic authentication cannot be used at the same time`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "topic": "alerts" }`,
+			expectedConfig: Config{
+				ServerURL: DefaultServerURL,
+				Topic:     "alerts",
+				Priority:  DefaultPriority,
+				Tags:      DefaultTags,
+				Title:     templates.DefaultMessageTitleEmbed,
+				Message:   templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:           "Extracts token from secrets",
+			settings:       `{ "topic": "alerts" }`,
+			secureSettings: map[string][]byte{"token": []byte("tk_test")},
+			expectedConfig: Config{
+				ServerURL: DefaultServerURL,
+				Topic:     "alerts",
+				Token:     "tk_test",
+				Priority:  DefaultPriority,
+				Tags:      DefaultTags,
+				Title:     templates.DefaultMessageTitleEmbed,
+				Message:   templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				ServerURL: "http://localhost:8080",
+				Topic:     "test-topic",
+				Username:  "test-user",
+				Password:  "test-password",
+				Priority:  4,
+				Tags:      "test-tag1,test-tag2",
+				Click:     "http://localhost/alerting/list",
+				Title:     "test-title",
+				Message:   "test-message",
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				ServerURL: "http://localhost:8080",
+				Topic:     "test-topic",
+				Username:  "test-user",
+				Password:  "test-secret-password",
+				Priority:  4,
+				Tags:      "test-tag1,test-tag2",
+				Click:     "http://localhost/alerting/list",
+				Title:     "test-title",
+				Message:   "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		settings   Config
+		expHeaders map[string]string
+		expUser    string
+		expMsg     string
+	}{
+		{
+			name: "Default tags with access token",
+			settings: Config{
+				ServerURL: DefaultServerURL,
+				Topic:     "alerts",
+				Token:     "tk_test",
+				Priority:  DefaultPriority,
+				Tags:      DefaultTags,
+				Title:     "{{ .CommonLabels.alertname }}",
+				Message:   "{{ len .Alerts.Firing }} firing",
+			},
+			expHeaders: map[string]string{"Authorization": "Bearer tk_test"},
+			expMsg:     `{"topic":"alerts","title":"alert1","message":"1 firing","priority":3,"tags":["warning"]}`,
+		},
+		{
+			name: "Custom tags and click action with basic authentication",
+			settings: Config{
+				ServerURL: "http://localhost:8080",
+				Topic:     "alerts",
+				Username:  "user",
+				Password:  "password",
+				Priority:  5,
+				Tags:      "grafana, {{ .CommonLabels.lbl1 }},",
+				Click:     "{{ .ExternalURL }}/alerting/list",
+				Title:     "test",
+				Message:   "test",
+			},
+			expUser: "user",
+			expMsg:  `{"topic":"alerts","title":"test","message":"test","priority":5,"tags":["grafana","val1"],"click":"http://localhost/alerting/list"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			webhookSender := receivers.MockNotificationService()
+			n := New(c.settings, receivers.Metadata{UID: "test", Name: "ntfy", Type: "ntfy"}, tmpl, webhookSender, &logging.FakeLogger{})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, c.settings.ServerURL, webhookSender.Webhook.URL)
+			require.Equal(t, c.expHeaders, webhookSender.Webhook.HTTPHeader)
+			require.Equal(t, c.expUser, webhookSender.Webhook.User)
+			require.JSONEq(t, c.expMsg, webhookSender.Webhook.Body)
+		})
+	}
+}
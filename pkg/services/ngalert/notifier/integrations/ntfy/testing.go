@@ -0,0 +1,19 @@
+package ntfy
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"serverUrl": "http://localhost:8080",
+	"topic": "test-topic",
+	"username": "test-user",
+	"password": "test-password",
+	"priority": "4",
+	"tags": "test-tag1,test-tag2",
+	"click": "http://localhost/alerting/list",
+	"title": "test-title",
+	"message": "test-message"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"password": "test-secret-password"
+}`
@@ -0,0 +1,86 @@
+package sns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+type Config struct {
+	APIUrl        string            `json:"api_url,omitempty" yaml:"api_url,omitempty"`
+	Region        string            `json:"region,omitempty" yaml:"region,omitempty"`
+	AccessKey     string            `json:"access_key,omitempty" yaml:"access_key,omitempty"`
+	SecretKey     string            `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+	AssumeRoleARN string            `json:"assume_role_arn,omitempty" yaml:"assume_role_arn,omitempty"`
+	TopicARN      string            `json:"topic_arn,omitempty" yaml:"topic_arn,omitempty"`
+	PhoneNumber   string            `json:"phone_number,omitempty" yaml:"phone_number,omitempty"`
+	TargetARN     string            `json:"target_arn,omitempty" yaml:"target_arn,omitempty"`
+	Subject       string            `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Message       string            `json:"message,omitempty" yaml:"message,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+}
+
+// NewConfig is the constructor for the Amazon SNS notifier.
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	destinations := 0
+	for _, d := range []string{settings.TopicARN, settings.PhoneNumber, settings.TargetARN} {
+		if d != "" {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		return Config{}, errors.New("must provide exactly one of topic ARN, target ARN or phone number")
+	}
+
+	if settings.Region == "" {
+		// The region of a topic or an endpoint can be taken from its ARN.
+		if a, err := arn.Parse(settings.TopicARN + settings.TargetARN); err == nil {
+			settings.Region = a.Region
+		}
+	}
+	if settings.Region == "" {
+		return Config{}, errors.New("could not find region in settings")
+	}
+
+	if settings.APIUrl != "" {
+		u, err := url.Parse(settings.APIUrl)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return Config{}, fmt.Errorf("invalid API URL %q", settings.APIUrl)
+		}
+		settings.APIUrl = u.String()
+	}
+
+	settings.AccessKey = decryptFn("access_key", settings.AccessKey)
+	settings.SecretKey = decryptFn("secret_key", settings.SecretKey)
+	// Contact points are editable by users, so the ambient credentials of the Grafana server are never used.
+	if settings.AccessKey == "" || settings.SecretKey == "" {
+		return Config{}, errors.New("could not find access key and secret key in settings")
+	}
+
+	if settings.Subject == "" {
+		settings.Subject = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+
+	return settings, nil
+}
+
+// isFIFOTopic returns true if the configured topic is a FIFO topic.
+func (c Config) isFIFOTopic() bool {
+	return strings.HasSuffix(c.TopicARN, ".fifo")
+}
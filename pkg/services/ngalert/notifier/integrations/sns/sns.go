@@ -0,0 +1,143 @@
+package sns
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// maxMessageLenBytes is the maximum size of a message published to a topic or an endpoint.
+	maxMessageLenBytes = 256 * 1024
+	// maxSMSLenBytes is the maximum size of a message sent as an SMS.
+	maxSMSLenBytes = 1600
+	// maxSubjectLenRunes is the maximum length of the subject of an email notification.
+	maxSubjectLenRunes = 100
+)
+
+// publisher is the subset of the SNS client used by the notifier.
+type publisher interface {
+	PublishWithContext(ctx aws.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error)
+}
+
+// Notifier is responsible for publishing alert notifications to Amazon SNS.
+type Notifier struct {
+	*receivers.Base
+	log       logging.Logger
+	tmpl      *templates.Template
+	settings  Config
+	newClient func(Config) (publisher, error)
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:      receivers.NewBase(meta),
+		log:       logger,
+		tmpl:      template,
+		settings:  cfg,
+		newClient: newSNSClient,
+	}
+}
+
+// Notify publishes the alert notification to the configured topic, endpoint or phone number.
+func (sn *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+
+	input := &sns.PublishInput{}
+	maxLen := maxMessageLenBytes
+	switch {
+	case sn.settings.TopicARN != "":
+		input.SetTopicArn(tmpl(sn.settings.TopicARN))
+	case sn.settings.TargetARN != "":
+		input.SetTargetArn(tmpl(sn.settings.TargetARN))
+	default:
+		input.SetPhoneNumber(tmpl(sn.settings.PhoneNumber))
+		maxLen = maxSMSLenBytes
+	}
+
+	message, truncated := receivers.TruncateInBytes(tmpl(sn.settings.Message), maxLen)
+	if truncated {
+		sn.log.Warn("SNS message too long, truncating message", "OriginalMessage", sn.settings.Message)
+	}
+	input.SetMessage(message)
+
+	// Subjects are used only for email subscriptions and SMS does not support them.
+	if sn.settings.PhoneNumber == "" {
+		subject, _ := receivers.TruncateInRunes(tmpl(sn.settings.Subject), maxSubjectLenRunes)
+		if subject != "" {
+			input.SetSubject(subject)
+		}
+	}
+
+	if len(sn.settings.Attributes) > 0 {
+		attributes := make(map[string]*sns.MessageAttributeValue, len(sn.settings.Attributes))
+		for k, v := range sn.settings.Attributes {
+			attributes[tmpl(k)] = &sns.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(tmpl(v)),
+			}
+		}
+		input.SetMessageAttributes(attributes)
+	}
+
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template SNS message: %w", tmplErr)
+	}
+
+	if sn.settings.isFIFOTopic() {
+		key, err := notify.ExtractGroupKey(ctx)
+		if err != nil {
+			return false, err
+		}
+		// Notifications of the same group are delivered in order, and identical retries are dropped by SNS.
+		input.SetMessageGroupId(key.Hash())
+		input.SetMessageDeduplicationId(fmt.Sprintf("%x", sha256.Sum256([]byte(key.Hash()+message))))
+	}
+
+	client, err := sn.newClient(sn.settings)
+	if err != nil {
+		return false, fmt.Errorf("failed to create SNS client: %w", err)
+	}
+	out, err := client.PublishWithContext(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	sn.log.Debug("Published message to SNS", "messageId", aws.StringValue(out.MessageId))
+	return true, nil
+}
+
+func (sn *Notifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// newSNSClient creates the SNS client that authenticates with the configured keys, optionally assuming a role.
+func newSNSClient(cfg Config) (publisher, error) {
+	awsCfg := aws.NewConfig().
+		WithRegion(cfg.Region).
+		WithCredentials(credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""))
+	if cfg.APIUrl != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.APIUrl)
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AssumeRoleARN != "" {
+		return sns.New(sess, aws.NewConfig().WithCredentials(stscreds.NewCredentials(sess, cfg.AssumeRoleARN))), nil
+	}
+	return sns.New(sess), nil
+}
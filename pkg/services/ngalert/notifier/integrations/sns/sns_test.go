@@ -0,0 +1,232 @@
+package sns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+)
+
+func TestNewConfig(t *testing.T) {
+	const keys = `"access_key": "key", "secret_key": "secret"`
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if destination is missing",
+			settings:          `{ "region": "us-east-1", ` + keys + ` }`,
+			expectedInitError: `must provide exactly one of topic ARN, target ARN or phone number`,
+		},
+		{
+			name:              "Error if several destinations are set",
+			settings:          `{ "region": "us-east-1", "topic_arn": "arn:aws:sns:us-east-1:123456789012:topic", "phone_number": "+15555555555", ` + keys + ` }`,
+			expectedInitError: `must provide exactly one of topic ARN, target ARN or phone number`,
+		},
+		{
+			name:              "Error if region cannot be determined",
+			settings:          `{ "phone_number": "+15555555555", ` + keys + ` }`,
+			expectedInitError: `could not find region in settings`,
+		},
+		{
+			name:              "Error if API URL is not valid",
+			settings:          `{ "api_url": "localhost", "phone_number": "+15555555555", "region": "us-east-1", ` + keys + ` }`,
+			expectedInitError: `invalid API URL "localhost"`,
+		},
+		{
+			name:              "Error if keys are missing",
+			settings:          `{ "topic_arn": "arn:aws:sns:us-east-1:123456789012:topic", "access_key": "key" }`,
+			expectedInitError: `could not find access key and secret key in settings`,
+		},
+		{
+			name:     "Minimal valid configuration takes region from topic ARN",
+			settings: `{ "topic_arn": "arn:aws:sns:eu-west-1:123456789012:topic", ` + keys + ` }`,
+			expectedConfig: Config{
+				Region:    "eu-west-1",
+				AccessKey: "key",
+				SecretKey: "secret",
+				TopicARN:  "arn:aws:sns:eu-west-1:123456789012:topic",
+				Subject:   templates.DefaultMessageTitleEmbed,
+				Message:   templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: FullValidConfigForTesting,
+			expectedConfig: Config{
+				APIUrl:        "http://localhost:4566",
+				Region:        "us-east-1",
+				AccessKey:     "test-access-key",
+				SecretKey:     "test-secret-key",
+				AssumeRoleARN: "arn:aws:iam::123456789012:role/test-role",
+				TopicARN:      "arn:aws:sns:us-east-1:123456789012:test-topic",
+				Subject:       "test-subject",
+				Message:       "test-message",
+				Attributes:    map[string]string{"test-attribute": "test-value"},
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				APIUrl:        "http://localhost:4566",
+				Region:        "us-east-1",
+				AccessKey:     "test-secret-access-key",
+				SecretKey:     "test-secret-secret-key",
+				AssumeRoleARN: "arn:aws:iam::123456789012:role/test-role",
+				TopicARN:      "arn:aws:sns:us-east-1:123456789012:test-topic",
+				Subject:       "test-subject",
+				Message:       "test-message",
+				Attributes:    map[string]string{"test-attribute": "test-value"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+type fakePublisher struct {
+	input *sns.PublishInput
+	err   error
+}
+
+func (f *fakePublisher) PublishWithContext(_ aws.Context, input *sns.PublishInput, _ ...request.Option) (*sns.PublishOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sns.PublishOutput{MessageId: aws.String("test-id")}, nil
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		settings   Config
+		publishErr error
+		expInput   *sns.PublishInput
+		expError   string
+	}{
+		{
+			name: "Topic with subject and attributes",
+			settings: Config{
+				TopicARN:   "arn:aws:sns:us-east-1:123456789012:topic",
+				Subject:    "{{ .CommonLabels.alertname }}",
+				Message:    "{{ len .Alerts.Firing }} firing",
+				Attributes: map[string]string{"severity": "{{ .CommonLabels.lbl1 }}"},
+			},
+			expInput: &sns.PublishInput{
+				TopicArn: aws.String("arn:aws:sns:us-east-1:123456789012:topic"),
+				Subject:  aws.String("alert1"),
+				Message:  aws.String("1 firing"),
+				MessageAttributes: map[string]*sns.MessageAttributeValue{
+					"severity": {DataType: aws.String("String"), StringValue: aws.String("val1")},
+				},
+			},
+		},
+		{
+			name: "Phone number does not get a subject",
+			settings: Config{
+				PhoneNumber: "+15555555555",
+				Subject:     "{{ .CommonLabels.alertname }}",
+				Message:     "test",
+			},
+			expInput: &sns.PublishInput{
+				PhoneNumber: aws.String("+15555555555"),
+				Message:     aws.String("test"),
+			},
+		},
+		{
+			name: "FIFO topic gets group and deduplication IDs",
+			settings: Config{
+				TopicARN: "arn:aws:sns:us-east-1:123456789012:topic.fifo",
+				Message:  "test",
+			},
+			expInput: &sns.PublishInput{
+				TopicArn:               aws.String("arn:aws:sns:us-east-1:123456789012:topic.fifo"),
+				Message:                aws.String("test"),
+				MessageGroupId:         aws.String(notify.Key("alertname").Hash()),
+				MessageDeduplicationId: aws.String(fmt.Sprintf("%x", sha256.Sum256([]byte(notify.Key("alertname").Hash()+"test")))),
+			},
+		},
+		{
+			name: "Error from SNS is returned",
+			settings: Config{
+				TargetARN: "arn:aws:sns:us-east-1:123456789012:endpoint/GCM/app/id",
+				Message:   "test",
+			},
+			publishErr: errors.New("publish error"),
+			expError:   "publish error",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &fakePublisher{err: c.publishErr}
+			n := New(c.settings, receivers.Metadata{UID: "test", Name: "sns", Type: "sns"}, tmpl, &logging.FakeLogger{})
+			n.newClient = func(Config) (publisher, error) { return client, nil }
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := n.Notify(ctx, alerts...)
+			if c.expError != "" {
+				require.False(t, ok)
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, c.expInput, client.input)
+		})
+	}
+}
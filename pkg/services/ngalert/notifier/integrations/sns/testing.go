@@ -0,0 +1,22 @@
+package sns
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"api_url": "http://localhost:4566",
+	"region": "us-east-1",
+	"access_key": "test-access-key",
+	"secret_key": "test-secret-key",
+	"assume_role_arn": "arn:aws:iam::123456789012:role/test-role",
+	"topic_arn": "arn:aws:sns:us-east-1:123456789012:test-topic",
+	"subject": "test-subject",
+	"message": "test-message",
+	"attributes": {
+		"test-attribute": "test-value"
+	}
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"access_key": "test-secret-access-key",
+	"secret_key": "test-secret-secret-key"
+}`
@@ -0,0 +1,35 @@
+package integrations
+
+import (
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/mattermost"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/mqtt"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/ntfy"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/sns"
+)
+
+// AllKnownConfigsForTesting contains the full valid configurations of the integrations implemented in this package.
+var AllKnownConfigsForTesting = map[string]alertingNotify.NotifierConfigTest{
+	MatrixType: {NotifierType: MatrixType,
+		Config:  matrix.FullValidConfigForTesting,
+		Secrets: matrix.FullValidSecretsForTesting,
+	},
+	MattermostType: {NotifierType: MattermostType,
+		Config:  mattermost.FullValidConfigForTesting,
+		Secrets: mattermost.FullValidSecretsForTesting,
+	},
+	MQTTType: {NotifierType: MQTTType,
+		Config:  mqtt.FullValidConfigForTesting,
+		Secrets: mqtt.FullValidSecretsForTesting,
+	},
+	NtfyType: {NotifierType: NtfyType,
+		Config:  ntfy.FullValidConfigForTesting,
+		Secrets: ntfy.FullValidSecretsForTesting,
+	},
+	SNSType: {NotifierType: SNSType,
+		Config:  sns.FullValidConfigForTesting,
+		Secrets: sns.FullValidSecretsForTesting,
+	},
+}
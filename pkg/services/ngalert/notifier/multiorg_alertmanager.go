@@ -44,7 +44,7 @@ type Alertmanager interface {
 	ListSilences(context.Context, []string) (apimodels.GettableSilences, error)
 
 	// SilenceState returns the current state of silences in the Alertmanager. This is used to persist the state
-	// to the kvstore.
+	// to the database.
 	SilenceState(context.Context) (alertingNotify.SilenceState, error)
 
 	// Alerts
@@ -136,7 +136,7 @@ func NewMultiOrgAlertmanager(
 	// Set up the default per tenant Alertmanager factory.
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
-		stateStore := NewFileStore(orgID, kvStore)
+		stateStore := NewDBStateStore(orgID, moa.configStore)
 		return NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting))
 	}
 
@@ -334,11 +334,20 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 	moa.cleanupOrphanLocalOrgState(ctx, orgsFound)
 }
 
-// cleanupOrphanLocalOrgState will remove all orphaned nflog and silence states in kvstore by existing to currently
-// active organizations. The original intention for this was the cleanup deleted orgs, that have had their states
-// saved to the kvstore after deletion on instance shutdown.
+// cleanupOrphanLocalOrgState will remove all orphaned nflog and silence states in the database and the kvstore by existing
+// to currently active organizations. The original intention for this was the cleanup deleted orgs, that have had their states
+// saved after deletion on instance shutdown.
 func (moa *MultiOrgAlertmanager) cleanupOrphanLocalOrgState(ctx context.Context,
 	activeOrganizations map[int64]struct{}) {
+	activeOrgIDs := make([]int64, 0, len(activeOrganizations))
+	for orgID := range activeOrganizations {
+		activeOrgIDs = append(activeOrgIDs, orgID)
+	}
+	if _, err := moa.configStore.DeleteOrphanedAlertmanagerState(ctx, activeOrgIDs); err != nil {
+		moa.logger.Error("Failed to delete orphaned Alertmanager state", "error", err)
+	}
+
+	// The kvstore only contains the state persisted before it was moved to the database.
 	storedFiles := []string{NotificationLogFilename, SilencesFilename}
 	for _, fileName := range storedFiles {
 		keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, fileName)
@@ -381,6 +390,24 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance in the HA cluster and the names of all live members of the
+// cluster, including this instance. It returns an empty name and no members if HA is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string) {
+	switch p := moa.peer.(type) {
+	case *alertingCluster.Peer:
+		peers := p.Peers()
+		members := make([]string, 0, len(peers))
+		for _, peer := range peers {
+			members = append(members, peer.Name())
+		}
+		return p.Name(), members
+	case *redisPeer:
+		return p.Name(), p.Members()
+	default:
+		return "", nil
+	}
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
@@ -401,7 +428,7 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (Alertmanager, err
 }
 
 // CreateSilence creates a silence in the Alertmanager for the organization provided, returning the silence ID. It will
-// also persist the silence state to the kvstore immediately after creating the silence.
+// also persist the silence state to the database immediately after creating the silence.
 func (moa *MultiOrgAlertmanager) CreateSilence(ctx context.Context, orgID int64, ps *alertingNotify.PostableSilence) (string, error) {
 	moa.alertmanagersMtx.RLock()
 	defer moa.alertmanagersMtx.RUnlock()
@@ -457,7 +484,7 @@ func (moa *MultiOrgAlertmanager) DeleteSilence(ctx context.Context, orgID int64,
 	return nil
 }
 
-// updateSilenceState persists the silence state to the kvstore immediately instead of waiting for the next maintenance
+// updateSilenceState persists the silence state to the database immediately instead of waiting for the next maintenance
 // run. This is used after Create/Delete to prevent silences from being lost when a new Alertmanager is started before
 // the state has persisted. This can happen, for example, in a rolling deployment scenario.
 func (moa *MultiOrgAlertmanager) updateSilenceState(ctx context.Context, orgAM Alertmanager, orgID int64) error {
@@ -471,9 +498,8 @@ func (moa *MultiOrgAlertmanager) updateSilenceState(ctx context.Context, orgAM A
 		return err
 	}
 
-	// Persist to kvstore.
-	fs := NewFileStore(orgID, moa.kvStore)
-	_, err = fs.SaveSilences(ctx, silences)
+	// Persist to the database.
+	_, err = NewDBStateStore(orgID, moa.configStore).SaveSilences(ctx, silences)
 	return err
 }
 
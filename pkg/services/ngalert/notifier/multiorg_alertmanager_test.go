@@ -306,10 +306,10 @@ func TestMultiOrgAlertmanager_Silences(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, state, 0)
 
-	// Confirm empty kvstore.
-	v, ok, err := mam.kvStore.Get(ctx, 1, KVNamespace, SilencesFilename)
+	// Confirm empty database.
+	stateStore := NewDBStateStore(1, mam.configStore)
+	v, err := stateStore.GetSilences(ctx)
 	require.NoError(t, err)
-	require.False(t, ok)
 	require.Empty(t, v)
 
 	// Create 2 silences.
@@ -330,13 +330,10 @@ func TestMultiOrgAlertmanager_Silences(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, state, 2)
 
-	// Confirm 2 silences in the kvstore.
-	v, ok, err = mam.kvStore.Get(ctx, 1, KVNamespace, SilencesFilename)
+	// Confirm 2 silences in the database.
+	v, err = stateStore.GetSilences(ctx)
 	require.NoError(t, err)
-	require.True(t, ok)
-	decoded, err := decode(v)
-	require.NoError(t, err)
-	state, err = alertingNotify.DecodeState(bytes.NewReader(decoded))
+	state, err = alertingNotify.DecodeState(bytes.NewReader([]byte(v)))
 	require.NoError(t, err)
 	require.Len(t, state, 2)
 
@@ -349,13 +346,10 @@ func TestMultiOrgAlertmanager_Silences(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, types.SilenceStateExpired, *silence.Status.State)
 
-	// Confirm silence is expired in kvstore.
-	v, ok, err = mam.kvStore.Get(ctx, 1, KVNamespace, SilencesFilename)
-	require.NoError(t, err)
-	require.True(t, ok)
-	decoded, err = decode(v)
+	// Confirm silence is expired in the database.
+	v, err = stateStore.GetSilences(ctx)
 	require.NoError(t, err)
-	state, err = alertingNotify.DecodeState(bytes.NewReader(decoded))
+	state, err = alertingNotify.DecodeState(bytes.NewReader([]byte(v)))
 	require.NoError(t, err)
 	require.True(t, time.Now().After(state[sid].Silence.EndsAt)) // Expired.
 }
@@ -340,7 +340,7 @@ func (p *redisPeer) filterUnhealthyMembers(members []string, values []any) []str
 
 func (p *redisPeer) Position() int {
 	for i, peer := range p.Members() {
-		if peer == p.withPrefix(p.name) {
+		if peer == p.Name() {
 			p.logger.Debug("Cluster position found", "name", p.name, "position", i)
 			return i
 		}
@@ -349,6 +349,11 @@ func (p *redisPeer) Position() int {
 	return 0
 }
 
+// Name returns the name of this peer in the cluster, as it appears in Members.
+func (p *redisPeer) Name() string {
+	return p.withPrefix(p.name)
+}
+
 // Returns the known size of the Cluster. This also includes dead nodes that
 // haven't timeout yet.
 func (p *redisPeer) ClusterSize() int {
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +35,10 @@ type fakeConfigStore struct {
 
 	// notificationSettings stores notification settings by orgID.
 	notificationSettings map[int64]map[models.AlertRuleKey][]models.NotificationSettings
+
+	// state stores the entries of the Alertmanager state by orgID and kind.
+	stateMtx sync.Mutex
+	state    map[int64]map[models.AlertmanagerStateKind][]models.AlertmanagerStateEntry
 }
 
 func (f *fakeConfigStore) ListNotificationSettings(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error) {
@@ -82,6 +88,38 @@ func (f *fakeConfigStore) GetImages(ctx context.Context, tokens []string) ([]mod
 	return nil, nil, alertingImages.ErrImageNotFound
 }
 
+func (f *fakeConfigStore) GetAlertmanagerState(_ context.Context, orgID int64, kind models.AlertmanagerStateKind) ([]models.AlertmanagerStateEntry, error) {
+	f.stateMtx.Lock()
+	defer f.stateMtx.Unlock()
+	return f.state[orgID][kind], nil
+}
+
+func (f *fakeConfigStore) SaveAlertmanagerState(_ context.Context, orgID int64, kind models.AlertmanagerStateKind, entries []models.AlertmanagerStateEntry) error {
+	f.stateMtx.Lock()
+	defer f.stateMtx.Unlock()
+	if f.state == nil {
+		f.state = make(map[int64]map[models.AlertmanagerStateKind][]models.AlertmanagerStateEntry)
+	}
+	if f.state[orgID] == nil {
+		f.state[orgID] = make(map[models.AlertmanagerStateKind][]models.AlertmanagerStateEntry)
+	}
+	f.state[orgID][kind] = entries
+	return nil
+}
+
+func (f *fakeConfigStore) DeleteOrphanedAlertmanagerState(_ context.Context, activeOrgIDs []int64) (int64, error) {
+	f.stateMtx.Lock()
+	defer f.stateMtx.Unlock()
+	var deleted int64
+	for orgID := range f.state {
+		if !slices.Contains(activeOrgIDs, orgID) {
+			delete(f.state, orgID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfiguration) *fakeConfigStore {
 	t.Helper()
 
@@ -303,16 +341,6 @@ func createSilence(id string, startsAt, expiresAt time.Time) *silencepb.MeshSile
 	}
 }
 
-// receiverKey copied from prometheus-alertmanager/nflog/nflog.go.
-func receiverKey(r *nflogpb.Receiver) string {
-	return fmt.Sprintf("%s/%s/%d", r.GroupName, r.Integration, r.Idx)
-}
-
-// stateKey copied from prometheus-alertmanager/nflog/nflog.go.
-func stateKey(k string, r *nflogpb.Receiver) string {
-	return fmt.Sprintf("%s:%s", k, receiverKey(r))
-}
-
 // nflogState copied from state in prometheus-alertmanager/nflog/nflog.go.
 type nflogState map[string]*nflogpb.MeshEntry
 
@@ -261,6 +261,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 		}
 	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := store.ValidateRuleDependencies(ctx, service.ruleStore, rule.OrgID, []*models.AlertRule{&rule}, nil); err != nil {
+			return err
+		}
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
 		})
@@ -507,6 +510,10 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, user identity.Re
 
 func (service *AlertRuleService) persistDelta(ctx context.Context, user identity.Requester, delta *store.GroupDelta, provenance models.Provenance) error {
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := store.ValidateGroupDeltaDependencies(ctx, service.ruleStore, delta); err != nil {
+			return err
+		}
+
 		// Delete first as this could prevent future unique constraint violations.
 		if len(delta.Delete) > 0 {
 			for _, del := range delta.Delete {
@@ -632,6 +639,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 		return models.AlertRule{}, err
 	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := store.ValidateRuleDependencies(ctx, service.ruleStore, rule.OrgID, []*models.AlertRule{&rule}, nil); err != nil {
+			return err
+		}
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
 				Existing: storedRule,
@@ -681,6 +691,9 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, user ident
 	// This is different from deleting groups. We delete the rules directly rather than persisting a delta here to keep the semantics the same.
 	// TODO: Either persist a delta here as a breaking change, or deprecate this endpoint in favor of the group endpoint.
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := store.ValidateRuleDependencies(ctx, service.ruleStore, rule.OrgID, nil, []string{rule.UID}); err != nil {
+			return err
+		}
 		return service.deleteRules(ctx, user.GetOrgID(), rule)
 	})
 }
@@ -522,6 +522,28 @@ func TestAlertRuleService(t *testing.T) {
 		}
 	})
 
+	t.Run("group with rules that depend on each other should be rejected", func(t *testing.T) {
+		group := createDummyGroup("dependency-cycle", orgID)
+		group.Rules = append(group.Rules, dummyRule("dependency-cycle-rule-2", orgID))
+		err := ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceFile)
+		require.NoError(t, err)
+		group, err = ruleService.GetRuleGroup(context.Background(), u, "my-namespace", "dependency-cycle")
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 2)
+
+		group.Rules[0].DependsOn = []string{group.Rules[1].UID}
+		group.Rules[1].DependsOn = []string{group.Rules[0].UID}
+		err = ruleService.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceFile)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "rule dependencies form a cycle")
+
+		group, err = ruleService.GetRuleGroup(context.Background(), u, "my-namespace", "dependency-cycle")
+		require.NoError(t, err)
+		for _, rule := range group.Rules {
+			require.Empty(t, rule.DependsOn)
+		}
+	})
+
 	t.Run("quota met causes create to be rejected", func(t *testing.T) {
 		ruleService := createAlertRuleService(t)
 		checker := &MockQuotaChecker{}
@@ -757,6 +779,23 @@ func TestCreateAlertRule(t *testing.T) {
 	})
 
 	ruleService := createAlertRuleService(t)
+	t.Run("when the rule depends on a rule that does not exist", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+		rule := models.AlertRuleGen(models.WithOrgID(orgID), models.WithDependsOn(rules[0].UID, "unknown"))()
+
+		_, err := service.CreateAlertRule(context.Background(), u, *rule, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "depends on rule unknown that does not exist")
+		inserts := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			a, ok := cmd.([]models.AlertRule)
+			return a, ok
+		})
+		require.Empty(t, inserts)
+	})
+
 	t.Run("should return the created id", func(t *testing.T) {
 		rule, err := ruleService.CreateAlertRule(context.Background(), u, dummyRule("test#1", orgID), models.ProvenanceNone)
 		require.NoError(t, err)
@@ -833,6 +872,25 @@ func TestUpdateAlertRule(t *testing.T) {
 		})
 		require.Len(t, updates, 1)
 	})
+	t.Run("when the update creates a dependency cycle", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+		dependent := models.AlertRuleGen(models.WithOrgID(orgID), models.WithDependsOn(rules[0].UID))()
+		ruleStore.PutRule(context.Background(), dependent)
+		rule := models.CopyRule(rules[0])
+		rule.DependsOn = []string{dependent.UID}
+
+		_, err := service.UpdateAlertRule(context.Background(), u, *rule, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "rule dependencies form a cycle")
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			a, ok := cmd.([]models.UpdateRule)
+			return a, ok
+		})
+		require.Empty(t, updates)
+	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		rule := models.CopyRule(rules[0])
 		rule.Title = rule.Title + "_new"
@@ -930,6 +988,19 @@ func TestDeleteAlertRule(t *testing.T) {
 		deletes := getDeleteQueries(ruleStore)
 		require.Len(t, deletes, 1)
 	})
+	t.Run("when other rules depend on the rule", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		dependent := models.AlertRuleGen(models.WithOrgID(orgID), models.WithDependsOn(rules[0].UID))()
+		ruleStore.PutRule(context.Background(), dependent)
+
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+
+		err := service.DeleteAlertRule(context.Background(), u, rules[0].UID, groupProvenance)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, dependent.UID)
+	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		rule := models.CopyRule(rules[0])
 		rule.Title = rule.Title + "_new"
@@ -1468,6 +1539,19 @@ func TestDeleteRuleGroup(t *testing.T) {
 		deletes := getDeleteQueries(ruleStore)
 		require.Len(t, deletes, 1)
 	})
+	t.Run("when rules of other groups depend on the group", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		dependent := models.AlertRuleGen(models.WithOrgID(orgID), models.WithDependsOn(rules[1].UID))()
+		ruleStore.PutRule(context.Background(), dependent)
+
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+
+		err := service.DeleteRuleGroup(context.Background(), u, groupKey.NamespaceUID, groupKey.RuleGroup, groupProvenance)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, dependent.UID)
+	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		t.Run("it should not update if not authorized", func(t *testing.T) {
 			service, ruleStore, _, ac := initServiceWithData(t)
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -34,12 +35,14 @@ type Rule interface {
 	Eval(eval *Evaluation) (bool, *Evaluation)
 	// Update sends a singal to change the definition of the rule.
 	Update(lastVersion RuleVersionAndPauseStatus) bool
+	// Type returns the type of the rule the routine evaluates.
+	Type() ngmodels.RuleType
 }
 
-type ruleFactoryFunc func(context.Context) Rule
+type ruleFactoryFunc func(context.Context, *ngmodels.AlertRule) Rule
 
-func (f ruleFactoryFunc) new(ctx context.Context) Rule {
-	return f(ctx)
+func (f ruleFactoryFunc) new(ctx context.Context, rule *ngmodels.AlertRule) Rule {
+	return f(ctx, rule)
 }
 
 func newRuleFactory(
@@ -50,6 +53,7 @@ func newRuleFactory(
 	stateManager *state.Manager,
 	evalFactory eval.EvaluatorFactory,
 	ruleProvider ruleProvider,
+	recordingWriter writer.Writer,
 	clock clock.Clock,
 	met *metrics.Scheduler,
 	logger log.Logger,
@@ -57,7 +61,21 @@ func newRuleFactory(
 	evalAppliedHook evalAppliedFunc,
 	stopAppliedHook stopAppliedFunc,
 ) ruleFactoryFunc {
-	return func(ctx context.Context) Rule {
+	return func(ctx context.Context, rule *ngmodels.AlertRule) Rule {
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			return newRecordingRule(
+				ctx,
+				maxAttempts,
+				evalFactory,
+				recordingWriter,
+				clock,
+				met,
+				logger,
+				tracer,
+				evalAppliedHook,
+				stopAppliedHook,
+			)
+		}
 		return newAlertRule(
 			ctx,
 			appURL,
@@ -147,6 +165,10 @@ func newAlertRule(
 	}
 }
 
+func (a *alertRule) Type() ngmodels.RuleType {
+	return ngmodels.RuleTypeAlerting
+}
+
 // eval signals the rule evaluation routine to perform the evaluation of the rule. Does nothing if the loop is stopped.
 // Before sending a message into the channel, it does non-blocking read to make sure that there is no concurrent send operation.
 // Returns a tuple where first element is
@@ -305,8 +327,9 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 			}()
 
 		case <-grafanaCtx.Done():
-			// clean up the state only if the reason for stopping the evaluation loop is that the rule was deleted
-			if errors.Is(grafanaCtx.Err(), errRuleDeleted) {
+			// clean up the state only if the reason for stopping the evaluation loop is that the rule was deleted,
+			// or that it was changed to a recording rule.
+			if errors.Is(grafanaCtx.Err(), errRuleDeleted) || errors.Is(grafanaCtx.Err(), errRuleTypeChanged) {
 				// We do not want a context to be unbounded which could potentially cause a go routine running
 				// indefinitely. 1 minute is an almost randomly chosen timeout, big enough to cover the majority of the
 				// cases.
@@ -315,6 +338,10 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, ngmodels.StateReasonRuleDeleted)
 				a.notify(grafanaCtx, key, states)
 			}
+			// keep the state in the database if the rule was handed off to another instance, which continues from it.
+			if errors.Is(grafanaCtx.Err(), errRuleNotOwned) {
+				a.stateManager.ForgetStateByRuleUID(ngmodels.WithRuleKey(context.Background(), key), key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
@@ -333,6 +360,7 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 	start := a.clock.Now()
 
 	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
+	evalCtx.Timeout = e.rule.EvaluationTimeout
 	ruleEval, err := a.evalFactory.Create(evalCtx, e.rule.GetEvalCondition())
 	var results eval.Results
 	var dur time.Duration
@@ -358,8 +386,14 @@ func (a *alertRule) evaluate(ctx context.Context, key ngmodels.AlertRuleKey, f f
 	if err != nil || results.HasErrors() {
 		evalAttemptFailures.Inc()
 
+		// An evaluation that exceeded its limits is not retried because it would most likely exceed them again.
+		limit := evaluationLimit(err)
+		if limit != "" {
+			a.metrics.EvalLimitExceeded.WithLabelValues(orgID, limit).Inc()
+		}
+
 		// Only retry (return errors) if this isn't the last attempt, otherwise skip these return operations.
-		if retry {
+		if retry && limit == "" {
 			// The only thing that can return non-nil `err` from ruleEval.Evaluate is the server side expression pipeline.
 			// This includes transport errors such as transient network errors.
 			if err != nil {
@@ -472,3 +506,19 @@ func SchedulerUserFor(orgID int64) *user.SignedInUser {
 		},
 	}
 }
+
+// evaluationLimit returns the name of the evaluation limit that the error reports as exceeded, or an empty string if
+// the error is not caused by an evaluation limit.
+func evaluationLimit(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, eval.ErrEvaluationTimeout):
+		return "timeout"
+	case errors.Is(err, eval.ErrMaxSeriesExceeded):
+		return "max_series"
+	case errors.Is(err, eval.ErrMaxFrameSizeExceeded):
+		return "max_frame_size"
+	}
+	return ""
+}
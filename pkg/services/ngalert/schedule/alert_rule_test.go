@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	definitions "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
@@ -276,7 +277,7 @@ func TestRuleRoutine(t *testing.T) {
 			factory := ruleFactoryFromScheduler(sch)
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			ruleInfo := factory.new(ctx)
+			ruleInfo := factory.new(ctx, rule)
 			go func() {
 				_ = ruleInfo.Run(rule.GetKey())
 			}()
@@ -439,7 +440,7 @@ func TestRuleRoutine(t *testing.T) {
 
 			factory := ruleFactoryFromScheduler(sch)
 			ctx, cancel := context.WithCancel(context.Background())
-			ruleInfo := factory.new(ctx)
+			ruleInfo := factory.new(ctx, rule)
 			go func() {
 				err := ruleInfo.Run(models.AlertRuleKey{})
 				stoppedChan <- err
@@ -459,7 +460,7 @@ func TestRuleRoutine(t *testing.T) {
 			require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 
 			factory := ruleFactoryFromScheduler(sch)
-			ruleInfo := factory.new(context.Background())
+			ruleInfo := factory.new(context.Background(), rule)
 			go func() {
 				err := ruleInfo.Run(rule.GetKey())
 				stoppedChan <- err
@@ -489,7 +490,7 @@ func TestRuleRoutine(t *testing.T) {
 		factory := ruleFactoryFromScheduler(sch)
 		ctx, cancel := context.WithCancel(context.Background())
 		t.Cleanup(cancel)
-		ruleInfo := factory.new(ctx)
+		ruleInfo := factory.new(ctx, rule)
 
 		go func() {
 			_ = ruleInfo.Run(rule.GetKey())
@@ -571,7 +572,7 @@ func TestRuleRoutine(t *testing.T) {
 		factory := ruleFactoryFromScheduler(sch)
 		ctx, cancel := context.WithCancel(context.Background())
 		t.Cleanup(cancel)
-		ruleInfo := factory.new(ctx)
+		ruleInfo := factory.new(ctx, rule)
 
 		go func() {
 			_ = ruleInfo.Run(rule.GetKey())
@@ -675,6 +676,49 @@ func TestRuleRoutine(t *testing.T) {
 		})
 	})
 
+	t.Run("when evaluation exceeds its limits", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithEvaluationTimeout(time.Second))()
+		rule.ExecErrState = models.ErrorErrState
+
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w after 1s: %w", eval.ErrEvaluationTimeout, context.DeadlineExceeded))
+
+		evalAppliedChan := make(chan time.Time)
+		sender := NewSyncAlertsSenderMock()
+		sender.EXPECT().Send(mock.Anything, rule.GetKey(), mock.Anything).Return()
+
+		reg := prometheus.NewPedanticRegistry()
+		sch := setupScheduler(t, newFakeRulesStore(), &state.FakeInstanceStore{}, reg, sender, eval_mocks.NewEvaluatorFactory(evaluator))
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		sch.maxAttempts = 3
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		ruleInfo := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = ruleInfo.Run(rule.GetKey())
+		}()
+
+		ruleInfo.Eval(&Evaluation{scheduledAt: sch.clock.Now(), rule: rule})
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should not retry the evaluation and count the exceeded limit", func(t *testing.T) {
+			evaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+			orgID := fmt.Sprint(rule.OrgID)
+			require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalLimitExceeded.WithLabelValues(orgID, "timeout")))
+			require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalFailures.WithLabelValues(orgID)))
+			require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalAttemptFailures.WithLabelValues(orgID)))
+		})
+
+		t.Run("it should set the state to Error with a distinct reason", func(t *testing.T) {
+			states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			require.Len(t, states, 1)
+			require.Equal(t, eval.Error, states[0].State)
+			require.Equal(t, models.StateReasonEvaluationTimeout, states[0].StateReason)
+		})
+	})
+
 	t.Run("when there are alerts that should be firing", func(t *testing.T) {
 		t.Run("it should call sender", func(t *testing.T) {
 			// eval.Alerting makes state manager to create notifications for alertmanagers
@@ -690,7 +734,7 @@ func TestRuleRoutine(t *testing.T) {
 			factory := ruleFactoryFromScheduler(sch)
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
-			ruleInfo := factory.new(ctx)
+			ruleInfo := factory.new(ctx, rule)
 
 			go func() {
 				_ = ruleInfo.Run(rule.GetKey())
@@ -724,7 +768,7 @@ func TestRuleRoutine(t *testing.T) {
 		factory := ruleFactoryFromScheduler(sch)
 		ctx, cancel := context.WithCancel(context.Background())
 		t.Cleanup(cancel)
-		ruleInfo := factory.new(ctx)
+		ruleInfo := factory.new(ctx, rule)
 
 		go func() {
 			_ = ruleInfo.Run(rule.GetKey())
@@ -744,5 +788,5 @@ func TestRuleRoutine(t *testing.T) {
 }
 
 func ruleFactoryFromScheduler(sch *schedule) ruleFactory {
-	return newRuleFactory(sch.appURL, sch.disableGrafanaFolder, sch.maxAttempts, sch.alertsSender, sch.stateManager, sch.evaluatorFactory, &sch.schedulableAlertRules, sch.clock, sch.metrics, sch.log, sch.tracer, sch.evalAppliedFunc, sch.stopAppliedFunc)
+	return newRuleFactory(sch.appURL, sch.disableGrafanaFolder, sch.maxAttempts, sch.alertsSender, sch.stateManager, sch.evaluatorFactory, &sch.schedulableAlertRules, sch.recordingWriter, sch.clock, sch.metrics, sch.log, sch.tracer, sch.evalAppliedFunc, sch.stopAppliedFunc)
 }
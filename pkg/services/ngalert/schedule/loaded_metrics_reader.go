@@ -1,6 +1,8 @@
 package schedule
 
 import (
+	"slices"
+
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -22,23 +24,32 @@ type RuleStateProvider interface {
 }
 
 // AlertingResultsFromRuleState implements eval.AlertingResultsReader that gets the data from state manager.
-// It returns results fingerprints only for Alerting and Pending states that have empty StateReason.
+// It returns results fingerprints only for Alerting and Pending states, or only for Alerting states when reading
+// the firing results, that have empty StateReason, or that only have the reasons that they are flapping or suppressed.
 type AlertingResultsFromRuleState struct {
 	Manager RuleStateProvider
 	Rule    *ngmodels.AlertRule
 }
 
 func (n AlertingResultsFromRuleState) Read() map[data.Fingerprint]struct{} {
+	return n.read(eval.Alerting, eval.Pending)
+}
+
+func (n AlertingResultsFromRuleState) ReadFiring() map[data.Fingerprint]struct{} {
+	return n.read(eval.Alerting)
+}
+
+func (n AlertingResultsFromRuleState) read(active ...eval.State) map[data.Fingerprint]struct{} {
 	states := n.Manager.GetStatesForRuleUID(n.Rule.OrgID, n.Rule.UID)
 
-	active := map[data.Fingerprint]struct{}{}
+	result := map[data.Fingerprint]struct{}{}
 	for _, st := range states {
-		if st.StateReason != "" {
+		if st.HasReasonOtherThanFlags() {
 			continue
 		}
-		if st.State == eval.Alerting || st.State == eval.Pending {
-			active[st.ResultFingerprint] = struct{}{}
+		if slices.Contains(active, st.State) {
+			result[st.ResultFingerprint] = struct{}{}
 		}
 	}
-	return active
+	return result
 }
@@ -38,12 +38,36 @@ func TestLoadedResultsFromRuleState(t *testing.T) {
 		require.Contains(t, loaded, data.Fingerprint(2))
 	})
 
+	t.Run("should return only alerting states when reading firing states", func(t *testing.T) {
+		loaded := reader.ReadFiring()
+		require.Len(t, loaded, 1)
+		require.Contains(t, loaded, data.Fingerprint(1))
+	})
+
+	t.Run("should return states whose only reasons are that they are flapping or suppressed", func(t *testing.T) {
+		p := &FakeRuleStateProvider{
+			map[ngmodels.AlertRuleKey][]*state.State{
+				rule.GetKey(): {
+					{State: eval.Alerting, ResultFingerprint: data.Fingerprint(1), StateReason: ngmodels.StateReasonFlapping, Flapping: true},
+					{State: eval.Alerting, ResultFingerprint: data.Fingerprint(2), StateReason: ngmodels.ConcatReasons(ngmodels.StateReasonSuppressed, ngmodels.StateReasonFlapping), Flapping: true, Suppressed: true},
+					{State: eval.Pending, ResultFingerprint: data.Fingerprint(3), StateReason: ngmodels.StateReasonSuppressed, Suppressed: true},
+					{State: eval.Alerting, ResultFingerprint: data.Fingerprint(4), StateReason: ngmodels.StateReasonFlapping},
+					{State: eval.Alerting, ResultFingerprint: data.Fingerprint(5), StateReason: ngmodels.ConcatReasons(ngmodels.StateReasonNoData, ngmodels.StateReasonFlapping), Flapping: true},
+				},
+			},
+		}
+		reader := AlertingResultsFromRuleState{Manager: p, Rule: rule}
+		require.Equal(t, map[data.Fingerprint]struct{}{1: {}, 2: {}, 3: {}}, reader.Read())
+		require.Equal(t, map[data.Fingerprint]struct{}{1: {}, 2: {}}, reader.ReadFiring())
+	})
+
 	t.Run("should not return any states with reason", func(t *testing.T) {
 		for _, s := range p.states[rule.GetKey()] {
 			s.StateReason = uuid.NewString()
 		}
 		loaded := reader.Read()
 		require.Empty(t, loaded)
+		require.Empty(t, reader.ReadFiring())
 	})
 
 	t.Run("empty if no states", func(t *testing.T) {
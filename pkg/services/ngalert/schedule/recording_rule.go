@@ -0,0 +1,229 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// recordingRule is a Rule that evaluates the queries and expressions of a recording rule, and writes the series of
+// its condition as a metric. It has no state, so updates of the rule take effect on the next evaluation.
+type recordingRule struct {
+	evalCh chan *Evaluation
+	ctx    context.Context
+	stopFn util.CancelCauseFunc
+
+	maxAttempts int64
+
+	clock       clock.Clock
+	evalFactory eval.EvaluatorFactory
+	writer      writer.Writer
+
+	// Event hooks that are only used in tests.
+	evalAppliedHook evalAppliedFunc
+	stopAppliedHook stopAppliedFunc
+
+	metrics *metrics.Scheduler
+	logger  log.Logger
+	tracer  tracing.Tracer
+}
+
+func newRecordingRule(
+	parent context.Context,
+	maxAttempts int64,
+	evalFactory eval.EvaluatorFactory,
+	writer writer.Writer,
+	clock clock.Clock,
+	met *metrics.Scheduler,
+	logger log.Logger,
+	tracer tracing.Tracer,
+	evalAppliedHook evalAppliedFunc,
+	stopAppliedHook stopAppliedFunc,
+) *recordingRule {
+	ctx, stop := util.WithCancelCause(parent)
+	return &recordingRule{
+		evalCh:          make(chan *Evaluation),
+		ctx:             ctx,
+		stopFn:          stop,
+		maxAttempts:     maxAttempts,
+		clock:           clock,
+		evalFactory:     evalFactory,
+		writer:          writer,
+		evalAppliedHook: evalAppliedHook,
+		stopAppliedHook: stopAppliedHook,
+		metrics:         met,
+		logger:          logger,
+		tracer:          tracer,
+	}
+}
+
+func (r *recordingRule) Type() ngmodels.RuleType {
+	return ngmodels.RuleTypeRecording
+}
+
+// Eval signals the routine to evaluate the rule. See alertRule.Eval.
+func (r *recordingRule) Eval(eval *Evaluation) (bool, *Evaluation) {
+	var droppedMsg *Evaluation
+	select {
+	case droppedMsg = <-r.evalCh:
+	default:
+	}
+
+	select {
+	case r.evalCh <- eval:
+		return true, droppedMsg
+	case <-r.ctx.Done():
+		return false, droppedMsg
+	}
+}
+
+// Update does nothing because recording rules have no state to reset. It returns false if the rule is stopped.
+func (r *recordingRule) Update(_ RuleVersionAndPauseStatus) bool {
+	return r.ctx.Err() == nil
+}
+
+func (r *recordingRule) Stop(reason error) {
+	if r.stopFn != nil {
+		r.stopFn(reason)
+	}
+}
+
+func (r *recordingRule) Run(key ngmodels.AlertRuleKey) error {
+	ctx := ngmodels.WithRuleKey(r.ctx, key)
+	logger := r.logger.FromContext(ctx)
+	logger.Debug("Recording rule routine started")
+
+	defer r.stopApplied(key)
+	for {
+		select {
+		case e, ok := <-r.evalCh:
+			if !ok {
+				logger.Debug("Evaluation channel has been closed. Exiting")
+				return nil
+			}
+			r.doEvaluate(ctx, key, e)
+		case <-ctx.Done():
+			logger.Debug("Stopping recording rule routine")
+			return nil
+		}
+	}
+}
+
+func (r *recordingRule) doEvaluate(ctx context.Context, key ngmodels.AlertRuleKey, e *Evaluation) {
+	orgID := fmt.Sprint(key.OrgID)
+	evalStart := r.clock.Now()
+	defer func() {
+		r.evalApplied(key, e.scheduledAt)
+		r.metrics.EvalDuration.WithLabelValues(orgID).Observe(r.clock.Now().Sub(evalStart).Seconds())
+	}()
+
+	if e.rule.IsPaused {
+		r.logger.FromContext(ctx).Debug("Skip recording rule evaluation because it is paused")
+		return
+	}
+	r.metrics.EvalTotal.WithLabelValues(orgID).Inc()
+
+	for attempt := int64(1); attempt <= r.maxAttempts; attempt++ {
+		logger := r.logger.FromContext(ctx).New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
+		tracingCtx, span := r.tracer.Start(ctx, "recording rule execution", trace.WithAttributes(
+			attribute.String("rule_uid", e.rule.UID),
+			attribute.Int64("org_id", e.rule.OrgID),
+			attribute.Int64("rule_version", e.rule.Version),
+			attribute.String("tick", e.scheduledAt.UTC().Format(time.RFC3339Nano)),
+		))
+		if tracingCtx.Err() != nil {
+			span.SetStatus(codes.Error, "rule evaluation cancelled")
+			span.End()
+			logger.Error("Skip evaluation because the context has been cancelled")
+			return
+		}
+
+		r.metrics.EvalAttemptTotal.WithLabelValues(orgID).Inc()
+		err := r.evaluate(tracingCtx, e)
+		if err == nil {
+			span.End()
+			return
+		}
+		span.SetStatus(codes.Error, "rule evaluation failed")
+		span.RecordError(err)
+		span.End()
+		r.metrics.EvalAttemptFailures.WithLabelValues(orgID).Inc()
+		logger.Error("Failed to evaluate recording rule", "error", err)
+
+		limit := evaluationLimit(err)
+		if limit != "" {
+			r.metrics.EvalLimitExceeded.WithLabelValues(orgID, limit).Inc()
+		}
+		if attempt == r.maxAttempts || limit != "" {
+			r.metrics.EvalFailures.WithLabelValues(orgID).Inc()
+			return
+		}
+		select {
+		case <-tracingCtx.Done():
+			logger.Error("Context has been cancelled while backing off")
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// evaluate executes the queries and expressions of the rule, and writes the series of the condition.
+func (r *recordingRule) evaluate(ctx context.Context, e *Evaluation) error {
+	record := e.rule.GetRecord()
+	if record == nil {
+		return errors.New("the rule has no record settings")
+	}
+
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+	evalCtx.Timeout = e.rule.EvaluationTimeout
+	ruleEval, err := r.evalFactory.Create(evalCtx, e.rule.GetEvalCondition())
+	if err != nil {
+		return fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	resp, err := ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+	if err != nil {
+		return fmt.Errorf("server side expressions pipeline returned an error: %w", err)
+	}
+	res, ok := resp.Responses[e.rule.Condition]
+	if !ok {
+		return fmt.Errorf("no result for condition %s", e.rule.Condition)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("condition %s returned an error: %w", e.rule.Condition, res.Error)
+	}
+
+	if err := r.writer.Write(ctx, record.Metric, e.scheduledAt, res.Frames, e.rule.OrgID, e.rule.Labels); err != nil {
+		return fmt.Errorf("failed to write the series: %w", err)
+	}
+	return nil
+}
+
+// evalApplied is only used on tests.
+func (r *recordingRule) evalApplied(key ngmodels.AlertRuleKey, now time.Time) {
+	if r.evalAppliedHook == nil {
+		return
+	}
+	r.evalAppliedHook(key, now)
+}
+
+// stopApplied is only used on tests.
+func (r *recordingRule) stopApplied(key ngmodels.AlertRuleKey) {
+	if r.stopAppliedHook == nil {
+		return
+	}
+	r.stopAppliedHook(key)
+}
@@ -0,0 +1,167 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	models "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeWriteCall struct {
+	name        string
+	t           time.Time
+	frames      data.Frames
+	orgID       int64
+	extraLabels map[string]string
+}
+
+type fakeWriter struct {
+	mtx   sync.Mutex
+	calls []fakeWriteCall
+	err   error
+}
+
+func (w *fakeWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.calls = append(w.calls, fakeWriteCall{name: name, t: t, frames: frames, orgID: orgID, extraLabels: extraLabels})
+	return w.err
+}
+
+func (w *fakeWriter) Calls() []fakeWriteCall {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]fakeWriteCall(nil), w.calls...)
+}
+
+func TestRecordingRule(t *testing.T) {
+	createSchedule := func(t *testing.T, evalAppliedChan chan time.Time, w *fakeWriter) *schedule {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		sch.recordingWriter = w
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+		return sch
+	}
+
+	t.Run("factory should create a recording rule routine for recording rules", func(t *testing.T) {
+		sch := createSchedule(t, make(chan time.Time), &fakeWriter{})
+		factory := ruleFactoryFromScheduler(sch)
+
+		require.IsType(t, &recordingRule{}, factory.new(context.Background(), models.AlertRuleGen(models.WithRecord("metric"))()))
+		require.IsType(t, &alertRule{}, factory.new(context.Background(), models.AlertRuleGen()()))
+	})
+
+	t.Run("should write the series of the condition", func(t *testing.T) {
+		evalAppliedChan := make(chan time.Time)
+		w := &fakeWriter{}
+		sch := createSchedule(t, evalAppliedChan, w)
+
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("grafana:test:value"))()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		ruleInfo := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = ruleInfo.Run(rule.GetKey())
+		}()
+
+		expectedTime := time.UnixMicro(rand.Int63())
+		ruleInfo.Eval(&Evaluation{scheduledAt: expectedTime, rule: rule})
+		require.Equal(t, expectedTime, waitForTimeChannel(t, evalAppliedChan))
+
+		calls := w.Calls()
+		require.Len(t, calls, 1)
+		require.Equal(t, "grafana:test:value", calls[0].name)
+		require.Equal(t, expectedTime, calls[0].t)
+		require.Equal(t, rule.OrgID, calls[0].orgID)
+		require.Equal(t, rule.Labels, calls[0].extraLabels)
+		require.Len(t, calls[0].frames, 1)
+
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	})
+
+	t.Run("should retry and count the failure when the write fails", func(t *testing.T) {
+		evalAppliedChan := make(chan time.Time)
+		w := &fakeWriter{err: errors.New("write failed")}
+		sch := createSchedule(t, evalAppliedChan, w)
+		sch.maxAttempts = 2
+
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("grafana:test:value"))()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		ruleInfo := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = ruleInfo.Run(rule.GetKey())
+		}()
+
+		ruleInfo.Eval(&Evaluation{scheduledAt: sch.clock.Now(), rule: rule})
+		waitForTimeChannel(t, evalAppliedChan)
+
+		require.Len(t, w.Calls(), 2)
+		orgID := fmt.Sprint(rule.OrgID)
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalTotal.WithLabelValues(orgID)))
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalFailures.WithLabelValues(orgID)))
+		require.Equal(t, 2.0, testutil.ToFloat64(sch.metrics.EvalAttemptTotal.WithLabelValues(orgID)))
+		require.Equal(t, 2.0, testutil.ToFloat64(sch.metrics.EvalAttemptFailures.WithLabelValues(orgID)))
+	})
+
+	t.Run("should not evaluate paused rules", func(t *testing.T) {
+		evalAppliedChan := make(chan time.Time)
+		w := &fakeWriter{}
+		sch := createSchedule(t, evalAppliedChan, w)
+
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("grafana:test:value"))()
+		rule.IsPaused = true
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		ruleInfo := ruleFactoryFromScheduler(sch).new(ctx, rule)
+		go func() {
+			_ = ruleInfo.Run(rule.GetKey())
+		}()
+
+		ruleInfo.Eval(&Evaluation{scheduledAt: sch.clock.Now(), rule: rule})
+		waitForTimeChannel(t, evalAppliedChan)
+		require.Empty(t, w.Calls())
+	})
+}
+
+func TestRuleRegistry_getOrCreateTypeChange(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	sch.recordingWriter = &fakeWriter{}
+	factory := ruleFactoryFromScheduler(sch)
+
+	rule := models.AlertRuleGen()()
+	_ = sch.stateManager.ProcessEvalResults(context.Background(), sch.clock.Now(), rule, eval.GenerateResults(rand.Intn(5)+1, eval.ResultGen(eval.WithEvaluatedAt(sch.clock.Now()))), nil)
+	require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+	alertRoutine, isNew := sch.registry.getOrCreate(context.Background(), rule, factory)
+	require.True(t, isNew)
+	stoppedChan := make(chan error)
+	go func() {
+		stoppedChan <- alertRoutine.Run(rule.GetKey())
+	}()
+
+	recording := models.CopyRule(rule)
+	models.WithRecord("grafana:test:value")(recording)
+	recordingRoutine, isNew := sch.registry.getOrCreate(context.Background(), recording, factory)
+	require.True(t, isNew)
+	require.Equal(t, models.RuleTypeRecording, recordingRoutine.Type())
+
+	require.NoError(t, waitForErrChannel(t, stoppedChan))
+	require.ErrorIs(t, alertRoutine.(*alertRule).ctx.Err(), errRuleTypeChanged)
+	require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID), "the state of the alert rule should be removed")
+
+	same, isNew := sch.registry.getOrCreate(context.Background(), recording, factory)
+	require.False(t, isNew)
+	require.Same(t, recordingRoutine, same)
+}
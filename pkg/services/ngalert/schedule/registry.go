@@ -16,9 +16,11 @@ import (
 )
 
 var errRuleDeleted = errors.New("rule deleted")
+var errRuleTypeChanged = errors.New("rule type changed")
+var errRuleNotOwned = errors.New("rule is owned by another instance")
 
 type ruleFactory interface {
-	new(context.Context) Rule
+	new(context.Context, *models.AlertRule) Rule
 }
 
 type ruleRegistry struct {
@@ -30,15 +32,21 @@ func newRuleRegistry() ruleRegistry {
 	return ruleRegistry{rules: make(map[models.AlertRuleKey]Rule)}
 }
 
-// getOrCreate gets rule routine from registry by the key. If it does not exist, it creates a new one.
+// getOrCreate gets rule routine from registry by the key of the rule. If it does not exist, it creates a new one.
+// If the routine exists but evaluates another type of rule, it is stopped and replaced by a new one.
 // Returns a pointer to the rule routine and a flag that indicates whether it is a new struct or not.
-func (r *ruleRegistry) getOrCreate(context context.Context, key models.AlertRuleKey, factory ruleFactory) (Rule, bool) {
+func (r *ruleRegistry) getOrCreate(context context.Context, item *models.AlertRule, factory ruleFactory) (Rule, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	key := item.GetKey()
 	rule, ok := r.rules[key]
+	if ok && rule.Type() != item.Type() {
+		rule.Stop(errRuleTypeChanged)
+		ok = false
+	}
 	if !ok {
-		rule = factory.new(context)
+		rule = factory.new(context, item)
 		r.rules[key] = rule
 	}
 	return rule, !ok
@@ -311,5 +319,23 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	writeString(rule.RecoveryCondition)
+	for _, setting := range rule.FlapDetection {
+		writeInt(int64(setting.Window))
+		writeInt(int64(math.Float64bits(setting.HighThreshold)))
+		writeInt(int64(math.Float64bits(setting.LowThreshold)))
+		if setting.HoldNotifications {
+			writeInt(1)
+		} else {
+			writeInt(0)
+		}
+	}
+	for _, record := range rule.Record {
+		writeString(record.Metric)
+	}
+	for _, uid := range rule.DependsOn {
+		writeString(uid)
+	}
+	writeInt(int64(rule.EvaluationTimeout))
 	return fingerprint(sum.Sum64())
 }
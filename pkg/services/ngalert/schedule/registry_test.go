@@ -202,6 +202,13 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			FlapDetection: []models.FlapDetectionSettings{
+				models.NewFlapDetectionSettings(),
+			},
+			RecoveryCondition: "C",
+			Record:            []models.RecordSettings{{Metric: "metric_1"}},
+			DependsOn:         []string{"upstream-1"},
+			EvaluationTimeout: 10 * time.Second,
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -240,6 +247,13 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			FlapDetection: []models.FlapDetectionSettings{
+				{Window: 10, HighThreshold: 60, LowThreshold: 30, HoldNotifications: true},
+			},
+			RecoveryCondition: "D",
+			Record:            []models.RecordSettings{{Metric: "metric_2"}},
+			DependsOn:         []string{"upstream-2"},
+			EvaluationTimeout: 20 * time.Second,
 		}
 
 		excludedFields := map[string]struct{}{
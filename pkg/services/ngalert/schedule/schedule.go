@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/util/ticker"
 )
 
@@ -88,6 +89,12 @@ type schedule struct {
 	schedulableAlertRules alertRulesRegistry
 
 	tracer tracing.Tracer
+
+	// recordingWriter writes the series of the recording rules.
+	recordingWriter writer.Writer
+
+	// sharder decides which rules are evaluated by this instance. It is nil if scheduling is not sharded.
+	sharder *ruleSharder
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -105,6 +112,12 @@ type SchedulerCfg struct {
 	AlertSender          AlertsSender
 	Tracer               tracing.Tracer
 	Log                  log.Logger
+	// RecordingWriter writes the series of the recording rules. Recording rules are evaluated but their series
+	// are discarded if it is nil.
+	RecordingWriter writer.Writer
+	// ClusterMembers provides the members of the HA cluster. If it is not nil, the scheduler evaluates only the
+	// rules owned by this instance.
+	ClusterMembers ClusterMembers
 }
 
 // NewScheduler returns a new scheduler.
@@ -132,6 +145,13 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
+		recordingWriter:       cfg.RecordingWriter,
+	}
+	if sch.recordingWriter == nil {
+		sch.recordingWriter = writer.NoopWriter{}
+	}
+	if cfg.ClusterMembers != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembers)
 	}
 
 	return &sch
@@ -201,6 +221,36 @@ func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	}
 }
 
+// shardRules returns the rules owned by this instance. It stops the routines of the rules that were handed off to
+// another instance and loads the state of the rules that were taken over from another instance, so that their
+// pending periods are not reset. All rules are returned if scheduling is not sharded.
+//
+// The states of the rules owned by other instances are not kept in the cache. The state manager reads them from the
+// database when the API or a rule of this instance that depends on them requests them.
+func (sch *schedule) shardRules(ctx context.Context, alertRules []*ngmodels.AlertRule) []*ngmodels.AlertRule {
+	if sch.sharder == nil {
+		return alertRules
+	}
+	assignment := sch.sharder.assign(alertRules)
+	for _, key := range assignment.released {
+		// the routine removes the state from the cache when it stops, it is kept in the database for the new owner
+		if ruleRoutine, ok := sch.registry.del(key); ok {
+			sch.log.Debug("Alert rule was handed off to another instance", key.LogContext()...)
+			ruleRoutine.Stop(errRuleNotOwned)
+			continue
+		}
+		sch.stateManager.ForgetStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key)
+	}
+	if len(assignment.acquired) > 0 {
+		sch.log.Debug("Alert rules were taken over from another instance", "rules", len(assignment.acquired))
+		sch.stateManager.LoadStateForRules(ctx, assignment.acquired)
+	}
+	sch.stateManager.SetRemoteRules(assignment.others)
+	sch.metrics.OwnedAlertRules.Set(float64(len(assignment.owned)))
+	sch.metrics.ShardMembers.Set(float64(sch.sharder.size()))
+	return assignment.owned
+}
+
 type readyToRunItem struct {
 	ruleRoutine Rule
 	Evaluation
@@ -224,14 +274,17 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	// this is the new current state. rulesDiff contains the previously existing rules that were different between this state and the previous state.
 	alertRules, folderTitles := sch.schedulableAlertRules.all()
 
+	sch.updateRulesMetrics(alertRules)
+
+	// when scheduling is sharded, only the rules owned by this instance are evaluated
+	alertRules = sch.shardRules(ctx, alertRules)
+
 	// registeredDefinitions is a map used for finding deleted alert rules
 	// initially it is assigned to all known alert rules from the previous cycle
 	// each alert rule found also in this cycle is removed
 	// so, at the end, the remaining registered alert rules are the deleted ones
 	registeredDefinitions := sch.registry.keyMap()
 
-	sch.updateRulesMetrics(alertRules)
-
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
@@ -243,6 +296,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.stateManager,
 		sch.evaluatorFactory,
 		&sch.schedulableAlertRules,
+		sch.recordingWriter,
 		sch.clock,
 		sch.metrics,
 		sch.log,
@@ -252,7 +306,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	)
 	for _, item := range alertRules {
 		key := item.GetKey()
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, key, ruleFactory)
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -356,6 +358,125 @@ func TestProcessTicks(t *testing.T) {
 	})
 }
 
+func TestProcessTicks_Sharding(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	cluster := &fakeClusterMembers{self: "grafana-0", members: []string{"grafana-0", "grafana-1"}}
+	sch.sharder = newRuleSharder(cluster)
+
+	// find a rule group for every member of the cluster
+	groups := make(map[string]models.AlertRuleGroupKey)
+	ring := newHashRing("", cluster.members)
+	for _, key := range genGroupKeys(100) {
+		groups[ring.owner(key)] = key
+	}
+	require.Len(t, groups, 2)
+	rule0 := models.AlertRuleGen(models.WithGroupKey(groups["grafana-0"]), models.WithInterval(time.Second), withQueryForState(t, eval.Normal))()
+	rule1 := models.AlertRuleGen(models.WithGroupKey(groups["grafana-1"]), models.WithInterval(time.Second), withQueryForState(t, eval.Normal))()
+	ruleStore.PutRule(context.Background(), rule0, rule1)
+
+	dispatcherGroup, ctx := errgroup.WithContext(context.Background())
+	tick := time.Time{}
+
+	t.Run("should evaluate only the rules owned by this instance", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, rule0.GetKey(), scheduled[0].rule.GetKey())
+		require.True(t, sch.registry.exists(rule0.GetKey()))
+		require.False(t, sch.registry.exists(rule1.GetKey()))
+	})
+
+	t.Run("should hand off and take over rules when ownership changes", func(t *testing.T) {
+		routine, isNew := sch.registry.getOrCreate(ctx, rule0, ruleFactoryFromScheduler(sch))
+		require.False(t, isNew)
+
+		cluster.self = "grafana-1"
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, rule1.GetKey(), scheduled[0].rule.GetKey())
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(rule0.GetKey()))
+		require.ErrorIs(t, routine.(*alertRule).ctx.Err(), errRuleNotOwned)
+		require.Contains(t, instanceStore.RecordedOps(), models.ListAlertInstancesQuery{RuleOrgID: rule1.OrgID})
+	})
+}
+
+func TestProcessTicks_ShardedRuleDependencies(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	instanceStore := newMemoryInstanceStore()
+	members := []string{"grafana-0", "grafana-1"}
+
+	// find a rule group in the same organization for every member of the cluster
+	groups := make(map[string]models.AlertRuleGroupKey)
+	ring := newHashRing("", members)
+	for _, key := range genGroupKeys(100) {
+		key.OrgID = 1
+		groups[ring.owner(key)] = key
+	}
+	require.Len(t, groups, 2)
+	upstream := models.AlertRuleGen(models.WithGroupKey(groups["grafana-1"]), models.WithInterval(time.Second), withQueryForState(t, eval.Alerting))()
+	downstream := models.AlertRuleGen(models.WithGroupKey(groups["grafana-0"]), models.WithInterval(time.Second), withQueryForState(t, eval.Alerting), models.WithDependsOn(upstream.UID))()
+	ruleStore.PutRule(context.Background(), upstream, downstream)
+
+	replica := func(self string) (*schedule, chan evalAppliedInfo) {
+		sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+		sch.sharder = newRuleSharder(&fakeClusterMembers{self: self, members: members})
+		evalAppliedCh := make(chan evalAppliedInfo, 1)
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, now time.Time) {
+			evalAppliedCh <- evalAppliedInfo{alertDefKey: key, now: now}
+		}
+		return sch, evalAppliedCh
+	}
+	sch0, evalApplied0 := replica("grafana-0")
+	sch1, evalApplied1 := replica("grafana-1")
+
+	dispatcherGroup, ctx := errgroup.WithContext(context.Background())
+	tick := time.Time{}.Add(time.Second)
+
+	// the upstream rule is evaluated by the other replica first, which stores its state
+	scheduled, _, _ := sch1.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, 1)
+	assertEvalRun(t, evalApplied1, tick, upstream.GetKey())
+
+	scheduled, _, _ = sch0.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, 1)
+	assertEvalRun(t, evalApplied0, tick, downstream.GetKey())
+
+	t.Run("should read the state of the rules owned by other replicas from the database", func(t *testing.T) {
+		states := sch0.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+	})
+
+	t.Run("should suppress the rules that depend on a firing rule of another replica", func(t *testing.T) {
+		states := sch0.stateManager.GetStatesForRuleUID(downstream.OrgID, downstream.UID)
+		require.Len(t, states, 1)
+		require.True(t, states[0].Suppressed)
+		require.Equal(t, models.StateReasonSuppressed, states[0].StateReason)
+	})
+
+	t.Run("should not read the state of the rules owned by other replicas at every tick", func(t *testing.T) {
+		lists := instanceStore.listCount()
+		tick = tick.Add(time.Second)
+		_, _, _ = sch1.processTick(ctx, dispatcherGroup, tick)
+		assertEvalRun(t, evalApplied1, tick, upstream.GetKey())
+		require.Equal(t, lists, instanceStore.listCount())
+	})
+
+	t.Run("should forget the state of a deleted rule of another replica", func(t *testing.T) {
+		ruleStore.DeleteRule(upstream)
+		tick = tick.Add(time.Second)
+		_, _, _ = sch0.processTick(ctx, dispatcherGroup, tick)
+		assertEvalRun(t, evalApplied0, tick, downstream.GetKey())
+		require.Empty(t, sch0.stateManager.GetStatesForRuleUID(upstream.OrgID, upstream.UID))
+	})
+}
+
 func TestSchedule_deleteAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should stop evaluation loop and remove the controller from registry", func(t *testing.T) {
@@ -363,7 +484,7 @@ func TestSchedule_deleteAlertRule(t *testing.T) {
 			ruleFactory := ruleFactoryFromScheduler(sch)
 			rule := models.AlertRuleGen()()
 			key := rule.GetKey()
-			info, _ := sch.registry.getOrCreate(context.Background(), key, ruleFactory)
+			info, _ := sch.registry.getOrCreate(context.Background(), rule, ruleFactory)
 			sch.deleteAlertRule(key)
 			require.ErrorIs(t, info.(*alertRule).ctx.Err(), errRuleDeleted)
 			require.False(t, sch.registry.exists(key))
@@ -378,7 +499,7 @@ func TestSchedule_deleteAlertRule(t *testing.T) {
 	})
 }
 
-func setupScheduler(t *testing.T, rs *fakeRulesStore, is *state.FakeInstanceStore, registry *prometheus.Registry, senderMock *SyncAlertsSenderMock, evalMock eval.EvaluatorFactory) *schedule {
+func setupScheduler(t *testing.T, rs *fakeRulesStore, is state.InstanceStore, registry *prometheus.Registry, senderMock *SyncAlertsSenderMock, evalMock eval.EvaluatorFactory) *schedule {
 	t.Helper()
 	testTracer := tracing.InitializeTracerForTest()
 
@@ -548,3 +669,85 @@ func assertStopRun(t *testing.T, ch <-chan models.AlertRuleKey, keys ...models.A
 		}
 	}
 }
+
+// memoryInstanceStore keeps the alert instances in memory, so that the schedulers of several replicas can share it.
+type memoryInstanceStore struct {
+	mtx       sync.Mutex
+	instances map[models.AlertInstanceKey]models.AlertInstance
+	// lists is the number of calls of ListAlertInstances.
+	lists int
+}
+
+func (m *memoryInstanceStore) listCount() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.lists
+}
+
+func newMemoryInstanceStore() *memoryInstanceStore {
+	return &memoryInstanceStore{instances: make(map[models.AlertInstanceKey]models.AlertInstance)}
+}
+
+func (m *memoryInstanceStore) FetchOrgIds(_ context.Context) ([]int64, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	var orgIDs []int64
+	for key := range m.instances {
+		if !slices.Contains(orgIDs, key.RuleOrgID) {
+			orgIDs = append(orgIDs, key.RuleOrgID)
+		}
+	}
+	return orgIDs, nil
+}
+
+func (m *memoryInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.lists++
+	var result []*models.AlertInstance
+	for key, instance := range m.instances {
+		if key.RuleOrgID != q.RuleOrgID || q.RuleUID != "" && key.RuleUID != q.RuleUID {
+			continue
+		}
+		instance := instance
+		result = append(result, &instance)
+	}
+	return result, nil
+}
+
+func (m *memoryInstanceStore) SaveAlertInstance(_ context.Context, instance models.AlertInstance) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.instances[instance.AlertInstanceKey] = instance
+	return nil
+}
+
+func (m *memoryInstanceStore) DeleteAlertInstances(_ context.Context, keys ...models.AlertInstanceKey) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for _, key := range keys {
+		delete(m.instances, key)
+	}
+	return nil
+}
+
+func (m *memoryInstanceStore) DeleteAlertInstancesByRule(_ context.Context, ruleKey models.AlertRuleKey) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for key := range m.instances {
+		if key.RuleOrgID == ruleKey.OrgID && key.RuleUID == ruleKey.UID {
+			delete(m.instances, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryInstanceStore) FullSync(_ context.Context, instances []models.AlertInstance) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.instances = make(map[models.AlertInstanceKey]models.AlertInstance, len(instances))
+	for _, instance := range instances {
+		m.instances[instance.AlertInstanceKey] = instance
+	}
+	return nil
+}
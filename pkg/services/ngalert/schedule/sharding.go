@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// tokensPerMember is the number of tokens every member of the cluster has in the hash ring. More tokens spread the
+// rule groups more evenly across the members at the cost of a bigger ring.
+const tokensPerMember = 128
+
+// ClusterMembers provides the members of the HA cluster the evaluation of alert rules is sharded across.
+type ClusterMembers interface {
+	// ClusterMembers returns the name of this instance and the names of all live members of the cluster,
+	// including this instance.
+	ClusterMembers() (string, []string)
+}
+
+type ringToken struct {
+	hash   uint64
+	member string
+}
+
+// hashRing assigns rule groups to the members of the cluster using consistent hashing, so that only the rule groups
+// of a member that joins or leaves the cluster change owners.
+type hashRing struct {
+	self    string
+	members []string
+	tokens  []ringToken
+}
+
+func newHashRing(self string, members []string) *hashRing {
+	r := &hashRing{
+		self:    self,
+		members: members,
+		tokens:  make([]ringToken, 0, len(members)*tokensPerMember),
+	}
+	for _, member := range members {
+		for i := 0; i < tokensPerMember; i++ {
+			r.tokens = append(r.tokens, ringToken{hash: hashString(member + "/" + strconv.Itoa(i)), member: member})
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool {
+		if r.tokens[i].hash == r.tokens[j].hash {
+			return r.tokens[i].member < r.tokens[j].member
+		}
+		return r.tokens[i].hash < r.tokens[j].hash
+	})
+	return r
+}
+
+// owner returns the member that owns the rule group.
+func (r *hashRing) owner(key ngmodels.AlertRuleGroupKey) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hashGroupKey(key)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i].hash >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.tokens[i].member
+}
+
+// owns returns true if this instance owns the rule group. This instance owns all rule groups if it is not a member of
+// the cluster yet, so that rules are never left without an owner.
+func (r *hashRing) owns(key ngmodels.AlertRuleGroupKey) bool {
+	if r.self == "" || !slices.Contains(r.members, r.self) {
+		return true
+	}
+	return r.owner(key) == r.self
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func hashGroupKey(key ngmodels.AlertRuleGroupKey) uint64 {
+	h := fnv.New64a()
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(key.OrgID))
+	_, _ = h.Write(b)
+	_, _ = h.Write([]byte(key.NamespaceUID))
+	_, _ = h.Write([]byte{'\xff'})
+	_, _ = h.Write([]byte(key.RuleGroup))
+	return h.Sum64()
+}
+
+// ruleSharder decides which rules are evaluated by this instance of Grafana when scheduling is sharded across the
+// HA cluster. Rules are sharded by rule group, so all rules of a group are evaluated by the same instance.
+type ruleSharder struct {
+	members ClusterMembers
+	ring    *hashRing
+	// owned contains the rules owned by this instance at the previous tick. It is nil before the first tick.
+	owned map[ngmodels.AlertRuleKey]struct{}
+}
+
+// shardAssignment is the result of the assignment of the rules to the members of the cluster at a tick.
+type shardAssignment struct {
+	// owned are the rules owned by this instance.
+	owned []*ngmodels.AlertRule
+	// acquired are the rules this instance took over from another member since the previous tick.
+	acquired []*ngmodels.AlertRule
+	// released are the keys of the rules this instance handed off to another member since the previous tick.
+	// All rules that are not owned are handed off at the first tick.
+	released []ngmodels.AlertRuleKey
+	// others are the rules owned by the other members.
+	others []*ngmodels.AlertRule
+}
+
+func newRuleSharder(members ClusterMembers) *ruleSharder {
+	return &ruleSharder{members: members}
+}
+
+// assign splits the rules by ownership, and compares the result with the assignment of the previous tick.
+func (s *ruleSharder) assign(rules []*ngmodels.AlertRule) shardAssignment {
+	self, members := s.members.ClusterMembers()
+	members = slices.Clone(members)
+	slices.Sort(members)
+	if s.ring == nil || s.ring.self != self || !slices.Equal(s.ring.members, members) {
+		s.ring = newHashRing(self, members)
+	}
+
+	owned := make(map[ngmodels.AlertRuleKey]struct{}, len(rules))
+	result := shardAssignment{owned: make([]*ngmodels.AlertRule, 0, len(rules))}
+	for _, rule := range rules {
+		key := rule.GetKey()
+		_, wasOwned := s.owned[key]
+		if !s.ring.owns(rule.GetGroupKey()) {
+			if s.owned == nil || wasOwned {
+				result.released = append(result.released, key)
+			}
+			result.others = append(result.others, rule)
+			continue
+		}
+		owned[key] = struct{}{}
+		result.owned = append(result.owned, rule)
+		if s.owned != nil && !wasOwned {
+			result.acquired = append(result.acquired, rule)
+		}
+	}
+	s.owned = owned
+	return result
+}
+
+// size returns the number of members the rules are sharded across.
+func (s *ruleSharder) size() int {
+	if s.ring == nil {
+		return 0
+	}
+	return len(s.ring.members)
+}
@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeClusterMembers struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembers) ClusterMembers() (string, []string) {
+	return f.self, f.members
+}
+
+func genGroupKeys(n int) []models.AlertRuleGroupKey {
+	keys := make([]models.AlertRuleGroupKey, 0, n)
+	for i := 0; i < n; i++ {
+		keys = append(keys, models.AlertRuleGroupKey{OrgID: int64(i%3 + 1), NamespaceUID: fmt.Sprintf("folder-%d", i%7), RuleGroup: fmt.Sprintf("group-%d", i)})
+	}
+	return keys
+}
+
+func TestHashRing(t *testing.T) {
+	members := []string{"grafana-0", "grafana-1", "grafana-2"}
+	keys := genGroupKeys(1000)
+
+	t.Run("should own all groups if it is not a member of the cluster", func(t *testing.T) {
+		for _, r := range []*hashRing{newHashRing("", members), newHashRing("grafana-3", members), newHashRing("grafana-0", nil)} {
+			for _, key := range keys {
+				require.True(t, r.owns(key))
+			}
+		}
+	})
+
+	t.Run("every group should be owned by exactly one member", func(t *testing.T) {
+		owners := make(map[string]int)
+		for _, key := range keys {
+			count := 0
+			for _, member := range members {
+				if newHashRing(member, members).owns(key) {
+					owners[member]++
+					count++
+				}
+			}
+			require.Equalf(t, 1, count, "group %v is owned by %d members", key, count)
+		}
+		for _, member := range members {
+			assert.Greaterf(t, owners[member], len(keys)/10, "member %s owns too few groups", member)
+		}
+	})
+
+	t.Run("only groups of a joining member should change owner", func(t *testing.T) {
+		before := newHashRing("", members)
+		after := newHashRing("", append([]string{"grafana-3"}, members...))
+		moved := 0
+		for _, key := range keys {
+			if before.owner(key) == after.owner(key) {
+				continue
+			}
+			require.Equal(t, "grafana-3", after.owner(key))
+			moved++
+		}
+		assert.Positive(t, moved)
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	cluster := &fakeClusterMembers{self: "grafana-0", members: []string{"grafana-0", "grafana-1"}}
+	sharder := newRuleSharder(cluster)
+
+	rules := make([]*models.AlertRule, 0, 100)
+	for _, key := range genGroupKeys(100) {
+		rules = append(rules, models.AlertRuleGen(models.WithGroupKey(key))())
+	}
+
+	t.Run("should release rules owned by other members on the first tick", func(t *testing.T) {
+		assignment := sharder.assign(rules)
+		require.NotEmpty(t, assignment.owned)
+		require.Empty(t, assignment.acquired)
+		require.Len(t, assignment.released, len(rules)-len(assignment.owned))
+		require.Len(t, assignment.others, len(rules)-len(assignment.owned))
+		require.Equal(t, 2, sharder.size())
+	})
+
+	t.Run("should not change ownership if members do not change", func(t *testing.T) {
+		cluster.members = []string{"grafana-1", "grafana-0"}
+		assignment := sharder.assign(rules)
+		require.Empty(t, assignment.acquired)
+		require.Empty(t, assignment.released)
+		require.Len(t, assignment.others, len(rules)-len(assignment.owned))
+	})
+
+	t.Run("should acquire rules of a member that left", func(t *testing.T) {
+		previous := sharder.assign(rules)
+		cluster.members = []string{"grafana-0"}
+		assignment := sharder.assign(rules)
+		require.Len(t, assignment.owned, len(rules))
+		require.Len(t, assignment.acquired, len(rules)-len(previous.owned))
+		require.Empty(t, assignment.released)
+		require.Empty(t, assignment.others)
+	})
+
+	t.Run("should release rules to a member that joined", func(t *testing.T) {
+		cluster.members = []string{"grafana-0", "grafana-1", "grafana-2"}
+		assignment := sharder.assign(rules)
+		require.Empty(t, assignment.acquired)
+		require.Len(t, assignment.released, len(rules)-len(assignment.owned))
+		ownedKeys := make(map[models.AlertRuleKey]struct{}, len(assignment.owned))
+		for _, rule := range assignment.owned {
+			ownedKeys[rule.GetKey()] = struct{}{}
+		}
+		for _, key := range assignment.released {
+			require.NotContains(t, ownedKeys, key)
+		}
+	})
+}
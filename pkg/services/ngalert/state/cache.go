@@ -284,6 +284,16 @@ func (c *cache) set(entry *State) {
 	c.states[entry.OrgID][entry.AlertRuleUID].states[entry.CacheID] = entry
 }
 
+// setRuleStates replaces all states of the rule with the given states.
+func (c *cache) setRuleStates(orgID int64, alertRuleUID string, states map[string]*State) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][alertRuleUID] = &ruleStates{states: states}
+}
+
 func (c *cache) get(orgID int64, alertRuleUID, stateId string) *State {
 	c.mtxStates.RLock()
 	defer c.mtxStates.RUnlock()
@@ -0,0 +1,204 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type SQLStore interface {
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error)
+}
+
+// SQLBackend is a state.Historian that records state history to a table of the Grafana database.
+// Unlike annotations, it keeps the labels and values of the alert instances, and can be queried by labels.
+type SQLBackend struct {
+	store   SQLStore
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewSQLBackend(store SQLStore, metrics *metrics.Historian) *SQLBackend {
+	return &SQLBackend{
+		store:   store,
+		clock:   clock.New(),
+		metrics: metrics,
+		log:     log.New("ngalert.state.historian", "backend", "sql"),
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	entries := statesToEntries(rule, states)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.store.SaveStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe.
+// The dataframe has the same shape as the one of the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+
+	entries, err := h.store.GetStateHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	lbls := data.Labels(map[string]string{})
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+
+	// Entries are returned newest first, while the history is represented oldest first.
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		line, err := json.Marshal(LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.Previous,
+			Current:        e.Current,
+			Error:          e.Error,
+			Values:         e.Values,
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: e.Labels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+		lblsJson, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history labels: %w", err)
+		}
+
+		times = append(times, time.Unix(0, e.TimeNano))
+		lines = append(lines, line)
+		labels = append(labels, lblsJson)
+	}
+
+	frame := data.NewFrame("states")
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, t := range states {
+		if !shouldRecord(t) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(t.Labels)
+		entry := models.StateHistoryEntry{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			RuleID:       rule.ID,
+			RuleTitle:    rule.Title,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroup:    rule.Group,
+			DashboardUID: rule.DashboardUID,
+			PanelID:      rule.PanelID,
+			Labels:       sanitizedLabels,
+			Fingerprint:  labelFingerprint(sanitizedLabels),
+			Previous:     t.PreviousFormatted(),
+			Current:      t.Formatted(),
+			Condition:    rule.Condition,
+			Values:       valuesAsDataBlob(t.State),
+			TimeNano:     t.State.LastEvaluationTime.UnixNano(),
+		}
+		if t.State.State == eval.Error && t.Error != nil {
+			entry.Error = t.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// DeleteExpiredService deletes the state history of the sql backend that is older than the configured retention.
+type DeleteExpiredService struct {
+	store  deleteStore
+	maxAge time.Duration
+	clock  clock.Clock
+}
+
+type deleteStore interface {
+	DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+func ProvideDeleteExpiredService(cfg *setting.Cfg, store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{store: store, maxAge: cfg.UnifiedAlerting.StateHistory.SQLMaxAge, clock: clock.New()}
+}
+
+// DeleteExpired deletes the expired state history, and returns the number of deleted entries.
+// It does nothing if the retention is not configured.
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.maxAge <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteStateHistoryBefore(ctx, s.clock.Now().Add(-s.maxAge))
+}
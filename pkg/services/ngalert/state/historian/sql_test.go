@@ -0,0 +1,189 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestSQLBackend(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		t.Run("saves state transitions", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			sql := createTestSQLBackend(store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+			rule := createTestRule()
+			rule.Condition = "B"
+			now := time.Now()
+			states := singleFromNormal(&state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"a": "b", "__private__": "c"},
+				Values:             map[string]float64{"A": 1.5},
+				LastEvaluationTime: now,
+			})
+
+			err := <-sql.Record(context.Background(), rule, states)
+
+			require.NoError(t, err)
+			require.Len(t, store.entries, 1)
+			e := store.entries[0]
+			require.Equal(t, rule.OrgID, e.OrgID)
+			require.Equal(t, rule.UID, e.RuleUID)
+			require.Equal(t, rule.Group, e.RuleGroup)
+			require.Equal(t, rule.NamespaceUID, e.NamespaceUID)
+			require.Equal(t, "B", e.Condition)
+			require.Equal(t, "Normal", e.Previous)
+			require.Equal(t, "Alerting", e.Current)
+			require.Equal(t, map[string]string{"a": "b"}, e.Labels)
+			require.NotEmpty(t, e.Fingerprint)
+			require.Equal(t, 1.5, e.Values.Get("A").MustFloat64())
+			require.Equal(t, now.UnixNano(), e.TimeNano)
+		})
+
+		t.Run("skips transitions that should not be recorded", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			sql := createTestSQLBackend(store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+			states := []state.StateTransition{{PreviousState: eval.Normal, State: &state.State{State: eval.Normal}}}
+
+			err := <-sql.Record(context.Background(), createTestRule(), states)
+
+			require.NoError(t, err)
+			require.Zero(t, store.calls)
+		})
+
+		t.Run("emits expected write metrics", func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
+			sql := createTestSQLBackend(&fakeStateHistoryStore{}, met)
+			errSQL := createTestSQLBackend(&fakeStateHistoryStore{err: errors.New("boom")}, met)
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{
+				State:  eval.Alerting,
+				Labels: data.Labels{"a": "b"},
+			})
+
+			<-sql.Record(context.Background(), rule, states)
+			err := <-errSQL.Record(context.Background(), rule, states)
+			require.ErrorContains(t, err, "boom")
+
+			exp := bytes.NewBufferString(`
+# HELP grafana_alerting_state_history_writes_failed_total The total number of failed writes of state history batches.
+# TYPE grafana_alerting_state_history_writes_failed_total counter
+grafana_alerting_state_history_writes_failed_total{backend="sql",org="1"} 1
+# HELP grafana_alerting_state_history_writes_total The total number of state history batches that were attempted to be written.
+# TYPE grafana_alerting_state_history_writes_total counter
+grafana_alerting_state_history_writes_total{backend="sql",org="1"} 2
+`)
+			err = testutil.GatherAndCompare(reg, exp,
+				"grafana_alerting_state_history_writes_total",
+				"grafana_alerting_state_history_writes_failed_total",
+			)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		t.Run("defaults to a recent time range", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			sql := createTestSQLBackend(store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+			mock := clock.NewMock()
+			sql.clock = mock
+
+			_, err := sql.Query(context.Background(), models.HistoryQuery{OrgID: 1})
+
+			require.NoError(t, err)
+			require.Equal(t, mock.Now().UTC(), store.lastQuery.To)
+			require.Equal(t, mock.Now().UTC().Add(-defaultQueryRange), store.lastQuery.From)
+		})
+
+		t.Run("returns a frame in the same format as loki, oldest first", func(t *testing.T) {
+			now := time.Now()
+			store := &fakeStateHistoryStore{entries: []models.StateHistoryEntry{
+				{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "my-group", NamespaceUID: "my-folder", Labels: map[string]string{"a": "2"}, Current: "Normal", Previous: "Alerting", TimeNano: now.UnixNano()},
+				{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "my-group", NamespaceUID: "my-folder", Labels: map[string]string{"a": "1"}, Current: "Alerting", Previous: "Normal", TimeNano: now.Add(-time.Minute).UnixNano()},
+			}}
+			sql := createTestSQLBackend(store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+			frame, err := sql.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule-uid"})
+
+			require.NoError(t, err)
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, 2, frame.Rows())
+			require.Equal(t, now.Add(-time.Minute).UnixNano(), frame.Fields[0].At(0).(time.Time).UnixNano())
+
+			var entry LokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+			require.Equal(t, "Alerting", entry.Current)
+			require.Equal(t, map[string]string{"a": "1"}, entry.InstanceLabels)
+			require.Equal(t, "rule-uid", entry.RuleUID)
+
+			var lbls map[string]string
+			require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+			require.Equal(t, map[string]string{
+				StateHistoryLabelKey: StateHistoryLabelValue,
+				OrgIDLabel:           "1",
+				GroupLabel:           "my-group",
+				FolderUIDLabel:       "my-folder",
+			}, lbls)
+		})
+	})
+}
+
+func TestDeleteExpiredService(t *testing.T) {
+	mock := clock.NewMock()
+	store := &fakeStateHistoryStore{}
+
+	s := &DeleteExpiredService{store: store, clock: mock}
+	_, err := s.DeleteExpired(context.Background())
+	require.NoError(t, err)
+	require.True(t, store.deletedBefore.IsZero(), "history should be kept forever without retention")
+
+	s.maxAge = time.Hour
+	_, err = s.DeleteExpired(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, mock.Now().Add(-time.Hour), store.deletedBefore)
+}
+
+func createTestSQLBackend(store SQLStore, met *metrics.Historian) *SQLBackend {
+	return NewSQLBackend(store, met)
+}
+
+type fakeStateHistoryStore struct {
+	entries       []models.StateHistoryEntry
+	calls         int
+	err           error
+	lastQuery     models.HistoryQuery
+	deletedBefore time.Time
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	f.calls++
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetStateHistory(_ context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	f.lastQuery = query
+	return f.entries, f.err
+}
+
+func (f *fakeStateHistoryStore) DeleteStateHistoryBefore(_ context.Context, before time.Time) (int64, error) {
+	f.deletedBefore = before
+	return 0, f.err
+}
@@ -2,8 +2,10 @@ package state
 
 import (
 	"context"
+	"errors"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -53,6 +55,12 @@ type Manager struct {
 	rulesPerRuleGroupLimit         int64
 
 	persister StatePersister
+
+	// remoteRules contains the rules evaluated by other instances of Grafana when scheduling is sharded across the
+	// HA cluster, by organization and UID. Their states are not kept in the cache, but read from the database when
+	// they are requested.
+	remoteRules    map[int64]map[string]*ngModels.AlertRule
+	remoteRulesMtx sync.RWMutex
 }
 
 type ManagerCfg struct {
@@ -184,33 +192,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-				ResultFingerprint:    resultFp,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -218,6 +201,141 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// LoadStateForRules replaces the states of the rules in the cache with the alert instances stored in the database.
+// It is used when the rules are taken over from another instance of Grafana, so that their states, including the
+// pending periods, carry on from the last evaluation of the previous owner.
+func (st *Manager) LoadStateForRules(ctx context.Context, rules []*ngModels.AlertRule) {
+	if st.instanceStore == nil || len(rules) == 0 {
+		return
+	}
+	logger := st.log.FromContext(ctx)
+
+	rulesByOrg := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		if _, ok := rulesByOrg[rule.OrgID]; !ok {
+			rulesByOrg[rule.OrgID] = make(map[string]*ngModels.AlertRule)
+		}
+		rulesByOrg[rule.OrgID][rule.UID] = rule
+	}
+
+	statesCount := 0
+	for orgID, ruleByUID := range rulesByOrg {
+		alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID})
+		if err != nil {
+			logger.Error("Unable to fetch the state of the rules", "org", orgID, "error", err)
+			continue
+		}
+
+		statesByRule := make(map[string]map[string]*State, len(ruleByUID))
+		for uid := range ruleByUID {
+			statesByRule[uid] = make(map[string]*State)
+		}
+		for _, entry := range alertInstances {
+			rule, ok := ruleByUID[entry.RuleUID]
+			if !ok {
+				continue
+			}
+			s := st.stateFromInstance(entry, rule)
+			statesByRule[entry.RuleUID][s.CacheID] = s
+			statesCount++
+		}
+		for uid, states := range statesByRule {
+			st.cache.setRuleStates(orgID, uid, states)
+		}
+	}
+	logger.Debug("State of the rules has been loaded", "rules", len(rules), "states", statesCount)
+}
+
+// ForgetStateByRuleUID removes the rule instances from the cache but keeps them in the database. It is used when the
+// rule is handed off to another instance of Grafana that continues from the stored state.
+func (st *Manager) ForgetStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.log.FromContext(ctx).Debug("Rules state was removed from the cache", "states", len(states))
+}
+
+// SetRemoteRules replaces the rules that are evaluated by other instances of Grafana. The states of these rules are
+// read from the database instead of the cache.
+func (st *Manager) SetRemoteRules(rules []*ngModels.AlertRule) {
+	remoteRules := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		if _, ok := remoteRules[rule.OrgID]; !ok {
+			remoteRules[rule.OrgID] = make(map[string]*ngModels.AlertRule)
+		}
+		remoteRules[rule.OrgID][rule.UID] = rule
+	}
+	st.remoteRulesMtx.Lock()
+	defer st.remoteRulesMtx.Unlock()
+	st.remoteRules = remoteRules
+}
+
+// getRemoteRule returns the rule if it is evaluated by another instance of Grafana, or nil otherwise.
+func (st *Manager) getRemoteRule(orgID int64, ruleUID string) *ngModels.AlertRule {
+	st.remoteRulesMtx.RLock()
+	defer st.remoteRulesMtx.RUnlock()
+	return st.remoteRules[orgID][ruleUID]
+}
+
+// getRemoteStates reads from the database the states of the rules of the organization that are evaluated by other
+// instances of Grafana. If ruleUID is not empty, only the states of that rule are read.
+func (st *Manager) getRemoteStates(ctx context.Context, orgID int64, ruleUID string) []*State {
+	st.remoteRulesMtx.RLock()
+	rules := st.remoteRules[orgID]
+	st.remoteRulesMtx.RUnlock()
+	if st.instanceStore == nil || len(rules) == 0 {
+		return nil
+	}
+	if ruleUID != "" {
+		if _, ok := rules[ruleUID]; !ok {
+			return nil
+		}
+	}
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUID: ruleUID})
+	if err != nil {
+		st.log.FromContext(ctx).Error("Unable to fetch the state of the rules evaluated by other instances", "org", orgID, "error", err)
+		return nil
+	}
+	states := make([]*State, 0, len(alertInstances))
+	for _, entry := range alertInstances {
+		rule, ok := rules[entry.RuleUID]
+		if !ok {
+			continue
+		}
+		states = append(states, st.stateFromInstance(entry, rule))
+	}
+	return states
+}
+
+// stateFromInstance converts the alert instance stored in the database to a state of the rule.
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	lbs := map[string]string(entry.Labels)
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               lbs,
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -319,16 +437,20 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 }
 
 func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, logger log.Logger) []StateTransition {
+	suppressed := st.isSuppressed(ctx, alertRule)
+	if suppressed {
+		logger.Debug("Alert rule is suppressed because a rule it depends on is firing")
+	}
 	if st.applyNoDataAndErrorToAllStates && results.IsNoData() && (alertRule.NoDataState == ngModels.Alerting || alertRule.NoDataState == ngModels.OK || alertRule.NoDataState == ngModels.KeepLast) { // If it is no data, check the mapping and switch all results to the new state
 		// TODO aggregate UID of datasources that returned NoData into one and provide as auxiliary info, probably annotation
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], suppressed, logger)
 		if len(transitions) > 0 {
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
 	}
 	if st.applyNoDataAndErrorToAllStates && results.IsError() && (alertRule.ExecErrState == ngModels.AlertingErrState || alertRule.ExecErrState == ngModels.OkErrState || alertRule.ExecErrState == ngModels.KeepLastErrState) {
 		// TODO squash all errors into one, and provide as annotation
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], suppressed, logger)
 		if len(transitions) > 0 {
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
@@ -336,24 +458,41 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
 		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
-		s := st.setNextState(ctx, alertRule, currentState, result, logger)
+		s := st.setNextState(ctx, alertRule, currentState, result, suppressed, logger)
 		transitions = append(transitions, s)
 	}
 	return transitions
 }
 
-func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, logger log.Logger) []StateTransition {
+func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, suppressed bool, logger log.Logger) []StateTransition {
 	currentStates := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false)
 	transitions := make([]StateTransition, 0, len(currentStates))
 	for _, currentState := range currentStates {
-		t := st.setNextState(ctx, alertRule, currentState, result, logger)
+		t := st.setNextState(ctx, alertRule, currentState, result, suppressed, logger)
 		transitions = append(transitions, t)
 	}
 	return transitions
 }
 
+// isSuppressed returns true if any of the rules the alert rule depends on has a firing state. The states of the rules
+// that are evaluated by other instances of Grafana are read from the database.
+func (st *Manager) isSuppressed(ctx context.Context, alertRule *ngModels.AlertRule) bool {
+	for _, uid := range alertRule.DependsOn {
+		states := st.cache.getStatesForRuleUID(alertRule.OrgID, uid, false)
+		if st.getRemoteRule(alertRule.OrgID, uid) != nil {
+			states = st.getRemoteStates(ctx, alertRule.OrgID, uid)
+		}
+		for _, s := range states {
+			if s.State == eval.Alerting {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, logger log.Logger) StateTransition {
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, suppressed bool, logger log.Logger) StateTransition {
 	start := st.clock.Now()
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -419,9 +558,38 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	// An evaluation that exceeded its limits has a distinct reason, even if the state is Error.
+	if reason := limitStateReason(result); reason != "" {
+		if currentState.StateReason == "" {
+			currentState.StateReason = reason
+		} else {
+			currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, reason)
+		}
+	}
+
+	held := currentState.HoldNotifications || currentState.Suppressed
+	currentState.updateFlapping(alertRule.GetFlapDetection())
+	if currentState.Flapping {
+		if currentState.StateReason == "" {
+			currentState.StateReason = ngModels.StateReasonFlapping
+		} else {
+			currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonFlapping)
+		}
+	}
+	currentState.Suppressed = suppressed
+	if currentState.Suppressed {
+		if currentState.StateReason == "" {
+			currentState.StateReason = ngModels.StateReasonSuppressed
+		} else {
+			currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonSuppressed)
+		}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
-	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	// to Alertmanager. If the notifications were held while the state was flapping
+	// or suppressed, the state is resolved when it stops flapping or being suppressed in Normal.
+	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal ||
+		held && !currentState.HoldNotifications && !currentState.Suppressed && currentState.State == eval.Normal
 
 	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
 		image, err := takeImage(ctx, st.images, alertRule)
@@ -458,11 +626,29 @@ func resultStateReason(result eval.Result, rule *ngModels.AlertRule) string {
 	return result.State.String()
 }
 
+// limitStateReason returns the state reason of an Error result caused by the evaluation exceeding its limits, or an
+// empty string if the result is not caused by an evaluation limit.
+func limitStateReason(result eval.Result) string {
+	if result.State != eval.Error || result.Error == nil {
+		return ""
+	}
+	switch {
+	case errors.Is(result.Error, eval.ErrEvaluationTimeout):
+		return ngModels.StateReasonEvaluationTimeout
+	case errors.Is(result.Error, eval.ErrMaxSeriesExceeded), errors.Is(result.Error, eval.ErrMaxFrameSizeExceeded):
+		return ngModels.StateReasonQueryLimitExceeded
+	}
+	return ""
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	allStates := st.cache.getAll(orgID, st.doNotSaveNormalState)
-	return allStates
+	return append(allStates, st.getRemoteStates(context.Background(), orgID, "")...)
 }
 func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	if st.getRemoteRule(orgID, alertRuleUID) != nil {
+		return st.getRemoteStates(context.Background(), orgID, alertRuleUID)
+	}
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
 }
 
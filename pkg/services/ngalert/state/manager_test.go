@@ -3,6 +3,7 @@ package state_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -15,6 +16,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -25,15 +27,25 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -1821,6 +1833,183 @@ func TestStaleResults(t *testing.T) {
 	})
 }
 
+func TestFlapDetection(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithFlapDetection(models.FlapDetectionSettings{
+		Window:            5,
+		HighThreshold:     50,
+		LowThreshold:      25,
+		HoldNotifications: true,
+	}))()
+	labels := data.Labels{"instance": "test"}
+	process := func(s eval.State) *state.State {
+		t.Helper()
+		clk.Add(time.Duration(rule.IntervalSeconds) * time.Second)
+		result := eval.ResultGen(eval.WithState(s), eval.WithLabels(labels), eval.WithEvaluatedAt(clk.Now()))()
+		transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0].State
+	}
+
+	// The state does not flap until the window is full.
+	for _, s := range []eval.State{eval.Alerting, eval.Normal, eval.Alerting, eval.Normal} {
+		require.False(t, process(s).Flapping)
+	}
+
+	current := process(eval.Alerting)
+	require.True(t, current.Flapping)
+	require.Equal(t, models.StateReasonFlapping, current.StateReason)
+	require.False(t, current.NeedsSending(st.ResendDelay))
+
+	current = process(eval.Normal)
+	require.True(t, current.Flapping)
+	require.False(t, current.NeedsSending(st.ResendDelay))
+
+	// The state keeps flapping until the percentage of state change goes below the low threshold.
+	for i := 0; i < 2; i++ {
+		current = process(eval.Normal)
+		require.True(t, current.Flapping)
+		require.False(t, current.NeedsSending(st.ResendDelay))
+	}
+
+	current = process(eval.Normal)
+	require.False(t, current.Flapping)
+	require.Empty(t, current.StateReason)
+	require.True(t, current.Resolved, "the state should be resolved when the held notifications are released in Normal")
+	require.True(t, current.NeedsSending(st.ResendDelay))
+}
+
+func TestRuleDependencies(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	upstream := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1))()
+	downstream := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithDependsOn(upstream.UID))()
+	process := func(rule *models.AlertRule, s eval.State) state.StateTransition {
+		t.Helper()
+		result := eval.ResultGen(eval.WithState(s), eval.WithLabels(data.Labels{"instance": "test"}), eval.WithEvaluatedAt(clk.Now()))()
+		transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0]
+	}
+
+	clk.Add(time.Minute)
+	process(upstream, eval.Normal)
+	current := process(downstream, eval.Alerting)
+	require.False(t, current.Suppressed)
+	require.Empty(t, current.StateReason)
+	require.True(t, current.NeedsSending(st.ResendDelay))
+
+	clk.Add(time.Minute)
+	process(upstream, eval.Alerting)
+	current = process(downstream, eval.Alerting)
+	require.True(t, current.Suppressed)
+	require.Equal(t, models.StateReasonSuppressed, current.StateReason)
+	require.False(t, current.NeedsSending(st.ResendDelay))
+	require.True(t, current.Changed(), "the transition to suppressed should be recorded in the state history")
+
+	clk.Add(time.Minute)
+	process(upstream, eval.Alerting)
+	current = process(downstream, eval.Normal)
+	require.True(t, current.Suppressed)
+	require.Equal(t, models.StateReasonSuppressed, current.StateReason)
+	require.False(t, current.NeedsSending(st.ResendDelay))
+
+	clk.Add(time.Minute)
+	process(upstream, eval.Normal)
+	current = process(downstream, eval.Normal)
+	require.False(t, current.Suppressed)
+	require.Empty(t, current.StateReason)
+	require.True(t, current.Resolved, "the state should be resolved when it stops being suppressed in Normal")
+	require.True(t, current.NeedsSending(st.ResendDelay))
+}
+
+func TestEvaluationLimitStateReason(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	testCases := []struct {
+		name         string
+		execErrState models.ExecutionErrorState
+		err          error
+		expState     eval.State
+		expReason    string
+	}{
+		{
+			name:         "timeout in Error state",
+			execErrState: models.ErrorErrState,
+			err:          fmt.Errorf("%w after 10s: %w", eval.ErrEvaluationTimeout, context.DeadlineExceeded),
+			expState:     eval.Error,
+			expReason:    models.StateReasonEvaluationTimeout,
+		},
+		{
+			name:         "too many series in Error state",
+			execErrState: models.ErrorErrState,
+			err:          fmt.Errorf("%w: 10 series were returned, the limit is 5", eval.ErrMaxSeriesExceeded),
+			expState:     eval.Error,
+			expReason:    models.StateReasonQueryLimitExceeded,
+		},
+		{
+			name:         "frame too large in Alerting state",
+			execErrState: models.AlertingErrState,
+			err:          fmt.Errorf("%w: a frame of A has 10 rows, the limit is 5", eval.ErrMaxFrameSizeExceeded),
+			expState:     eval.Alerting,
+			expReason:    models.ConcatReasons(models.StateReasonError, models.StateReasonQueryLimitExceeded),
+		},
+		{
+			name:         "other errors",
+			execErrState: models.ErrorErrState,
+			err:          errors.New("query failed"),
+			expState:     eval.Error,
+			expReason:    "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1), models.WithErrorExecAs(tc.execErrState))()
+			result := eval.NewResultFromError(tc.err, clk.Now(), time.Second)
+
+			transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+			require.Len(t, transitions, 1)
+			require.Equal(t, tc.expState, transitions[0].State.State)
+			require.Equal(t, tc.expReason, transitions[0].StateReason)
+		})
+	}
+}
+
 func TestDeleteStateByRuleUID(t *testing.T) {
 	interval := time.Minute
 	ctx := context.Background()
@@ -2139,3 +2328,136 @@ func mergeLabels(a, b data.Labels) data.Labels {
 	}
 	return result
 }
+
+func TestLoadStateForRules(t *testing.T) {
+	evaluationTime := time.Date(2021, 3, 25, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState:      models.InstanceStatePending,
+		LastEvalTime:      evaluationTime,
+		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
+		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		Labels:            labels,
+	}))
+
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: dbstore,
+		Images:        &state.NoopImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+	require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+	t.Run("loads the state of the rules from the database", func(t *testing.T) {
+		st.LoadStateForRules(ctx, []*models.AlertRule{rule})
+
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Pending, states[0].State)
+		require.Equal(t, data.Labels{"test1": "testValue1"}, states[0].Labels)
+		require.Equal(t, evaluationTime.Add(-1*time.Minute), states[0].StartsAt)
+		require.Equal(t, evaluationTime, states[0].LastEvaluationTime)
+	})
+
+	t.Run("forgets the state of the rule but keeps it in the database", func(t *testing.T) {
+		st.ForgetStateByRuleUID(ctx, rule.GetKey())
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+		instances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+	})
+
+	t.Run("reads the state of the rules evaluated by other instances from the database", func(t *testing.T) {
+		st.SetRemoteRules([]*models.AlertRule{rule})
+
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Pending, states[0].State)
+		require.Len(t, st.GetAll(rule.OrgID), 1)
+
+		st.SetRemoteRules(nil)
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		require.Empty(t, st.GetAll(rule.OrgID))
+	})
+}
+
+func TestProcessEvalResults_FieldsOfTableQuery(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	clk.Set(time.Now())
+
+	// The data source returns a table with a numeric column and string columns, like a SQL query.
+	dsQuery := models.GenerateAlertQuery()
+	dsQuery.RefID = "A"
+	ds := &datasources.DataSource{UID: dsQuery.DatasourceUID, Type: datasources.DS_MYSQL}
+	client := &clienttest.TestClient{
+		QueryDataFunc: func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("",
+				data.NewField("host", nil, []string{"db-1"}),
+				data.NewField("error", nil, []string{"connection refused"}),
+				data.NewField("failures", nil, []float64{3}),
+			)}}
+			return resp, nil
+		},
+	}
+	pluginStore := &pluginstore.FakePluginStore{PluginList: []pluginstore.Plugin{
+		{JSONData: plugins.JSONData{ID: ds.Type, Backend: true}},
+	}}
+	dsCache := &datafakes.FakeCacheService{DataSources: []*datasources.DataSource{ds}}
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, pluginStore, dsCache, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+	exprService := expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, client, pCtxProvider, featuremgmt.WithFeatures(), nil, tracing.InitializeTracerForTest())
+	factory := eval.NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, dsCache, exprService, pluginStore)
+
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithOrgID(1))()
+	rule.Condition = "C"
+	rule.Data = []models.AlertQuery{
+		dsQuery,
+		models.CreateReduceExpression("B", "A", "last"),
+		{
+			RefID:         "C",
+			QueryType:     expr.DatasourceType,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"refId": "C", "type": "math", "expression": "$B > 0"}`),
+		},
+	}
+	rule.Annotations = map[string]string{"summary": "{{ $labels.host }} failed: {{ $fields.error }}"}
+
+	evaluator, err := factory.Create(eval.NewContext(ctx, &user.SignedInUser{OrgID: rule.OrgID}), rule.GetEvalCondition())
+	require.NoError(t, err)
+	results, err := evaluator.Evaluate(ctx, clk.Now())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, eval.Alerting, results[0].State)
+	require.Equal(t, map[string]string{"host": "db-1", "error": "connection refused"}, results[0].Fields)
+
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}, state.NewNoopPersister())
+	transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil)
+	require.Len(t, transitions, 1)
+	require.Equal(t, "db-1 failed: connection refused", transitions[0].Annotations["summary"])
+}
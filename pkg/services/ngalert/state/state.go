@@ -49,6 +49,17 @@ type State struct {
 	// All subsequent states will be false until the next transition from Firing to Normal.
 	Resolved bool
 
+	// Flapping is set to true if the state changes too often according to the flap detection settings of the alert rule.
+	Flapping bool
+
+	// HoldNotifications is set to true while the state is flapping and the alert rule holds the notifications of
+	// flapping states.
+	HoldNotifications bool
+
+	// Suppressed is set to true while any of the rules the alert rule depends on is firing. The notifications of
+	// suppressed states are not sent.
+	Suppressed bool
+
 	// Image contains an optional image for the state. It tends to be included in notifications
 	// as a visualization to show why the alert fired.
 	Image *models.Image
@@ -401,6 +412,9 @@ func resultKeepLast(state *State, rule *models.AlertRule, result eval.Result, lo
 }
 
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
+	if a.HoldNotifications || a.Suppressed {
+		return false
+	}
 	switch a.State {
 	case eval.Pending:
 		// We do not send notifications for pending states
@@ -432,6 +446,9 @@ func (a *State) TrimResults(alertRule *models.AlertRule) {
 	if numBuckets == 0 {
 		numBuckets = 10 // keep at least 10 evaluations in the event For is set to 0
 	}
+	if fd := alertRule.GetFlapDetection(); fd != nil && int64(fd.Window) > numBuckets {
+		numBuckets = int64(fd.Window) // keep enough evaluations to detect flapping
+	}
 
 	if len(a.Results) < int(numBuckets) {
 		return
@@ -441,6 +458,44 @@ func (a *State) TrimResults(alertRule *models.AlertRule) {
 	a.Results = newResults
 }
 
+// updateFlapping updates whether the state is flapping from the percentage of state change of its latest evaluations.
+// The state starts flapping when the percentage goes above the high threshold, and stops flapping when it goes below
+// the low threshold. The state does not flap if the flap detection is disabled.
+func (a *State) updateFlapping(fd *models.FlapDetectionSettings) {
+	if fd == nil {
+		a.Flapping = false
+		a.HoldNotifications = false
+		return
+	}
+	if len(a.Results) >= fd.Window {
+		states := make([]eval.State, 0, fd.Window)
+		for _, r := range a.Results[len(a.Results)-fd.Window:] {
+			states = append(states, r.EvaluationState)
+		}
+		change := models.PercentStateChange(states)
+		if !a.Flapping && change > fd.HighThreshold {
+			a.Flapping = true
+		} else if a.Flapping && change < fd.LowThreshold {
+			a.Flapping = false
+		}
+	}
+	a.HoldNotifications = a.Flapping && fd.HoldNotifications
+}
+
+// HasReasonOtherThanFlags returns true if the state has a reason other than those that are added because it is
+// flapping or suppressed, such as the state being set by the NoData or Error settings of the rule.
+func (a *State) HasReasonOtherThanFlags() bool {
+	for _, reason := range models.SplitReasons(a.StateReason) {
+		switch {
+		case reason == models.StateReasonFlapping && a.Flapping:
+		case reason == models.StateReasonSuppressed && a.Suppressed:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 func nextEndsTime(interval int64, evaluatedAt time.Time) time.Time {
 	ends := ResendDelay
 	intv := time.Second * time.Duration(interval)
@@ -74,6 +74,9 @@ type Data struct {
 	Labels Labels
 	Values map[string]Value
 	Value  string
+	// Fields contains the values of the non-numeric fields of the condition, such as the hostname or the error
+	// message of a table. See eval.Result.Fields.
+	Fields map[string]string
 }
 
 func NewData(labels map[string]string, res eval.Result) Data {
@@ -81,6 +84,7 @@ func NewData(labels map[string]string, res eval.Result) Data {
 		Labels: labels,
 		Values: NewValues(res.Values),
 		Value:  res.EvaluationString,
+		Fields: res.Fields,
 	}
 }
 
@@ -102,8 +106,8 @@ func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.
 
 	// add __alert_ to avoid possible conflicts with other templates
 	name = "__alert_" + name
-	// add variables for the labels and values to the beginning of the template
-	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + tmpl
+	// add variables for the labels, values and fields to the beginning of the template
+	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $fields := .Fields -}}" + tmpl
 	// ctx and queryFunc are no-ops as `query()` is not supported in Grafana
 	queryFunc := func(context.Context, string, time.Time) (promql.Vector, error) {
 		return nil, nil
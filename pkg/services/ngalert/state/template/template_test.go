@@ -145,6 +145,24 @@ func TestExpandTemplate(t *testing.T) {
 			},
 		},
 		expected: "foo has value NaN",
+	}, {
+		name: "fields are expanded into $fields",
+		text: "{{ $fields.host }} failed with {{ $fields.error }}",
+		alertInstance: eval.Result{
+			Fields: map[string]string{"host": "db-1", "error": "connection refused"},
+		},
+		expected: "db-1 failed with connection refused",
+	}, {
+		name: "missing field in $fields returns [no value]",
+		text: "{{ $fields.host }} is down",
+		alertInstance: eval.Result{
+			Fields: map[string]string{},
+		},
+		expected: "[no value] is down",
+	}, {
+		name:     "$fields is empty if the condition has no fields",
+		text:     "{{ len $fields }} fields",
+		expected: "0 fields",
 	}, {
 		name: "assert value string is expanded into $value",
 		text: "{{ $value }}",
@@ -172,7 +190,7 @@ func TestExpandTemplate(t *testing.T) {
 		alertInstance: eval.Result{
 			EvaluationString: "invalid",
 		},
-		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{ humanize $value }}': error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <humanize $value>: error calling humanize: strconv.ParseFloat: parsing "invalid": invalid syntax`),
+		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $fields := .Fields -}}{{ humanize $value }}': error executing template __alert_test: template: __alert_test:1:105: executing "__alert_test" at <humanize $value>: error calling humanize: strconv.ParseFloat: parsing "invalid": invalid syntax`),
 	}, {
 		name: "humanize1024 float64",
 		text: "{{ range $key, $val := $values }}{{ humanize1024 .Value }}:{{ end }}",
@@ -207,7 +225,7 @@ func TestExpandTemplate(t *testing.T) {
 		alertInstance: eval.Result{
 			EvaluationString: "invalid",
 		},
-		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{ humanize1024 $value }}': error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <humanize1024 $value>: error calling humanize1024: strconv.ParseFloat: parsing "invalid": invalid syntax`),
+		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $fields := .Fields -}}{{ humanize1024 $value }}': error executing template __alert_test: template: __alert_test:1:105: executing "__alert_test" at <humanize1024 $value>: error calling humanize1024: strconv.ParseFloat: parsing "invalid": invalid syntax`),
 	}, {
 		name: "humanizeDuration - seconds - float64",
 		text: "{{ range $key, $val := $values }}{{ humanizeDuration .Value }}:{{ end }}",
@@ -326,7 +344,7 @@ func TestExpandTemplate(t *testing.T) {
 		alertInstance: eval.Result{
 			EvaluationString: "invalid",
 		},
-		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{ humanizeDuration $value }}': error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <humanizeDuration $value>: error calling humanizeDuration: strconv.ParseFloat: parsing "invalid": invalid syntax`),
+		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $fields := .Fields -}}{{ humanizeDuration $value }}': error executing template __alert_test: template: __alert_test:1:105: executing "__alert_test" at <humanizeDuration $value>: error calling humanizeDuration: strconv.ParseFloat: parsing "invalid": invalid syntax`),
 	}, {
 		name:     "humanizePercentage - float64",
 		text:     "{{ -0.22222 | humanizePercentage }}:{{ 0.0 | humanizePercentage }}:{{ 0.1234567 | humanizePercentage }}:{{ 1.23456 | humanizePercentage }}",
@@ -338,7 +356,7 @@ func TestExpandTemplate(t *testing.T) {
 	}, {
 		name:          "humanizePercentage - string with error",
 		text:          `{{ "invalid" | humanizePercentage }}`,
-		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{ "invalid" | humanizePercentage }}': error executing template __alert_test: template: __alert_test:1:91: executing "__alert_test" at <humanizePercentage>: error calling humanizePercentage: strconv.ParseFloat: parsing "invalid": invalid syntax`),
+		expectedError: errors.New(`failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $fields := .Fields -}}{{ "invalid" | humanizePercentage }}': error executing template __alert_test: template: __alert_test:1:117: executing "__alert_test" at <humanizePercentage>: error calling humanizePercentage: strconv.ParseFloat: parsing "invalid": invalid syntax`),
 	}, {
 		name:     "humanizeTimestamp - float64",
 		text:     "{{ 1435065584.128 | humanizeTimestamp }}",
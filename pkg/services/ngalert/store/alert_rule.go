@@ -161,6 +161,11 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				NotificationSettings: r.NotificationSettings,
+				FlapDetection:        r.FlapDetection,
+				RecoveryCondition:    r.RecoveryCondition,
+				Record:               r.Record,
+				DependsOn:            r.DependsOn,
+				EvaluationTimeout:    r.EvaluationTimeout,
 			})
 		}
 		if len(newRules) > 0 {
@@ -241,6 +246,11 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				FlapDetection:        r.New.FlapDetection,
+				RecoveryCondition:    r.New.RecoveryCondition,
+				Record:               r.New.Record,
+				DependsOn:            r.New.DependsOn,
+				EvaluationTimeout:    r.New.EvaluationTimeout,
 			})
 		}
 		if len(ruleVersions) > 0 {
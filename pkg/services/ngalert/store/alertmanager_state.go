@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertmanagerStateStore persists the silences and the notification log of the Alertmanager with one row per entry.
+type AlertmanagerStateStore interface {
+	// GetAlertmanagerState returns the entries of the given kind that have not expired yet.
+	GetAlertmanagerState(ctx context.Context, orgID int64, kind models.AlertmanagerStateKind) ([]models.AlertmanagerStateEntry, error)
+
+	// SaveAlertmanagerState writes the entries that were modified since they were last saved, and deletes the expired ones.
+	// Entries that are stored but not in the list are kept until they expire, as they might have been written by another replica.
+	// It returns ErrAlertmanagerStateConflict if an entry is written concurrently, in which case nothing is saved.
+	SaveAlertmanagerState(ctx context.Context, orgID int64, kind models.AlertmanagerStateKind, entries []models.AlertmanagerStateEntry) error
+
+	// DeleteOrphanedAlertmanagerState deletes the entries of all organizations that are not in the list.
+	DeleteOrphanedAlertmanagerState(ctx context.Context, activeOrgIDs []int64) (int64, error)
+}
+
+// alertmanagerStateRow is the database representation of models.AlertmanagerStateEntry.
+// Timestamps are stored as Unix nanoseconds to keep the precision of the Alertmanager, and Version is used
+// by xorm for optimistic locking.
+type alertmanagerStateRow struct {
+	ID        int64                        `xorm:"pk autoincr 'id'"`
+	OrgID     int64                        `xorm:"org_id"`
+	Kind      models.AlertmanagerStateKind `xorm:"kind"`
+	EntryKey  string                       `xorm:"entry_key"`
+	Data      []byte                       `xorm:"data"`
+	UpdatedAt int64                        `xorm:"updated_at"`
+	ExpiresAt int64                        `xorm:"expires_at"`
+	Version   int64                        `xorm:"version 'version'"`
+}
+
+func (r alertmanagerStateRow) TableName() string {
+	return "alertmanager_state"
+}
+
+func (st DBstore) GetAlertmanagerState(ctx context.Context, orgID int64, kind models.AlertmanagerStateKind) ([]models.AlertmanagerStateEntry, error) {
+	var rows []alertmanagerStateRow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND kind = ? AND expires_at > ?", orgID, kind, TimeNow().UnixNano()).Asc("entry_key").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alertmanager %s: %w", kind, err)
+	}
+
+	result := make([]models.AlertmanagerStateEntry, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, models.AlertmanagerStateEntry{
+			Key:       row.EntryKey,
+			Data:      row.Data,
+			UpdatedAt: time.Unix(0, row.UpdatedAt).UTC(),
+			ExpiresAt: time.Unix(0, row.ExpiresAt).UTC(),
+			Version:   row.Version,
+		})
+	}
+	return result, nil
+}
+
+func (st DBstore) SaveAlertmanagerState(ctx context.Context, orgID int64, kind models.AlertmanagerStateKind, entries []models.AlertmanagerStateEntry) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := TimeNow().UnixNano()
+
+		var existing []alertmanagerStateRow
+		if err := sess.Cols("id", "entry_key", "updated_at", "version").Where("org_id = ? AND kind = ?", orgID, kind).Find(&existing); err != nil {
+			return fmt.Errorf("failed to get alertmanager %s: %w", kind, err)
+		}
+		stored := make(map[string]alertmanagerStateRow, len(existing))
+		for _, row := range existing {
+			stored[row.EntryKey] = row
+		}
+
+		for _, entry := range entries {
+			row := alertmanagerStateRow{
+				OrgID:     orgID,
+				Kind:      kind,
+				EntryKey:  entry.Key,
+				Data:      entry.Data,
+				UpdatedAt: entry.UpdatedAt.UnixNano(),
+				ExpiresAt: entry.ExpiresAt.UnixNano(),
+				Version:   1,
+			}
+			prev, ok := stored[entry.Key]
+			if !ok {
+				if row.ExpiresAt <= now {
+					continue
+				}
+				if _, err := sess.Insert(&row); err != nil {
+					if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+						return models.ErrAlertmanagerStateConflict
+					}
+					return fmt.Errorf("failed to insert alertmanager %s entry: %w", kind, err)
+				}
+				continue
+			}
+
+			// Entries that have not been modified since they were saved, or that were modified more recently
+			// by another replica, are left as they are.
+			if row.UpdatedAt <= prev.UpdatedAt {
+				continue
+			}
+			// The version column makes the update conditional on the version that was read, and increments it.
+			row.Version = prev.Version
+			affected, err := sess.ID(prev.ID).Cols("data", "updated_at", "expires_at").Update(&row)
+			if err != nil {
+				return fmt.Errorf("failed to update alertmanager %s entry: %w", kind, err)
+			}
+			if affected == 0 {
+				return models.ErrAlertmanagerStateConflict
+			}
+		}
+
+		if _, err := sess.Where("org_id = ? AND kind = ? AND expires_at <= ?", orgID, kind, now).Delete(&alertmanagerStateRow{}); err != nil {
+			return fmt.Errorf("failed to delete expired alertmanager %s entries: %w", kind, err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteOrphanedAlertmanagerState(ctx context.Context, activeOrgIDs []int64) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("1 = 1")
+		if len(activeOrgIDs) > 0 {
+			q = sess.NotIn("org_id", activeOrgIDs)
+		}
+		n, err := q.Delete(&alertmanagerStateRow{})
+		deleted = n
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertmanagerState(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().UTC()
+	entry := func(key, data string, updatedAt time.Time, ttl time.Duration) models.AlertmanagerStateEntry {
+		return models.AlertmanagerStateEntry{Key: key, Data: []byte(data), UpdatedAt: updatedAt, ExpiresAt: now.Add(ttl)}
+	}
+	get := func(t *testing.T, orgID int64, kind models.AlertmanagerStateKind) map[string]models.AlertmanagerStateEntry {
+		t.Helper()
+		entries, err := dbstore.GetAlertmanagerState(ctx, orgID, kind)
+		require.NoError(t, err)
+		result := make(map[string]models.AlertmanagerStateEntry, len(entries))
+		for _, e := range entries {
+			result[e.Key] = e
+		}
+		return result
+	}
+
+	t.Run("saves one row per entry", func(t *testing.T) {
+		err := dbstore.SaveAlertmanagerState(ctx, 1, models.AlertmanagerStateSilences, []models.AlertmanagerStateEntry{
+			entry("a", "silence-a", now, time.Hour),
+			entry("b", "silence-b", now, time.Hour),
+		})
+		require.NoError(t, err)
+
+		stored := get(t, 1, models.AlertmanagerStateSilences)
+		require.Len(t, stored, 2)
+		require.Equal(t, []byte("silence-a"), stored["a"].Data)
+		require.Equal(t, now, stored["a"].UpdatedAt)
+		require.EqualValues(t, 1, stored["a"].Version)
+
+		require.Empty(t, get(t, 1, models.AlertmanagerStateNotificationLog))
+		require.Empty(t, get(t, 2, models.AlertmanagerStateSilences))
+	})
+
+	t.Run("only writes entries that were modified later", func(t *testing.T) {
+		err := dbstore.SaveAlertmanagerState(ctx, 1, models.AlertmanagerStateSilences, []models.AlertmanagerStateEntry{
+			entry("a", "silence-a-updated", now.Add(time.Second), time.Hour),
+			entry("b", "silence-b-older", now.Add(-time.Second), time.Hour),
+		})
+		require.NoError(t, err)
+
+		stored := get(t, 1, models.AlertmanagerStateSilences)
+		require.Equal(t, []byte("silence-a-updated"), stored["a"].Data)
+		require.EqualValues(t, 2, stored["a"].Version)
+		require.Equal(t, []byte("silence-b"), stored["b"].Data)
+		require.EqualValues(t, 1, stored["b"].Version)
+	})
+
+	t.Run("keeps entries that are not in the list until they expire", func(t *testing.T) {
+		err := dbstore.SaveAlertmanagerState(ctx, 1, models.AlertmanagerStateSilences, []models.AlertmanagerStateEntry{
+			entry("c", "silence-c", now, -time.Minute),
+		})
+		require.NoError(t, err)
+
+		stored := get(t, 1, models.AlertmanagerStateSilences)
+		require.Len(t, stored, 2)
+		require.NotContains(t, stored, "c")
+	})
+
+	t.Run("deletes expired entries", func(t *testing.T) {
+		err := dbstore.SaveAlertmanagerState(ctx, 1, models.AlertmanagerStateNotificationLog, []models.AlertmanagerStateEntry{
+			entry("x", "nflog-x", now, time.Hour),
+			entry("y", "nflog-y", now, time.Hour),
+		})
+		require.NoError(t, err)
+
+		// Entries that were expired by a later modification are deleted.
+		err = dbstore.SaveAlertmanagerState(ctx, 1, models.AlertmanagerStateNotificationLog, []models.AlertmanagerStateEntry{
+			entry("x", "nflog-x", now.Add(time.Second), -time.Second),
+		})
+		require.NoError(t, err)
+		stored := get(t, 1, models.AlertmanagerStateNotificationLog)
+		require.Len(t, stored, 1)
+		require.Contains(t, stored, "y")
+	})
+
+	t.Run("deletes the state of orphaned organizations", func(t *testing.T) {
+		err := dbstore.SaveAlertmanagerState(ctx, 2, models.AlertmanagerStateSilences, []models.AlertmanagerStateEntry{
+			entry("a", "silence-a", now, time.Hour),
+		})
+		require.NoError(t, err)
+
+		deleted, err := dbstore.DeleteOrphanedAlertmanagerState(ctx, []int64{1})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+		require.Empty(t, get(t, 2, models.AlertmanagerStateSilences))
+		require.Len(t, get(t, 1, models.AlertmanagerStateSilences), 2)
+	})
+}
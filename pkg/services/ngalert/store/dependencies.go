@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ValidateRuleDependencies checks that the dependencies of the rules of an organization remain valid after the changed
+// rules are created or updated and the deleted rules are removed: every changed rule must depend on rules that exist,
+// no remaining rule can depend on a deleted rule, and the dependencies cannot form a cycle. It must be called before
+// the changes are stored, in the same transaction.
+func ValidateRuleDependencies(ctx context.Context, ruleReader RuleReader, orgID int64, changed []*models.AlertRule, deleted []string) error {
+	hasDependencies := slices.ContainsFunc(changed, func(r *models.AlertRule) bool { return len(r.DependsOn) > 0 })
+	if len(deleted) == 0 && !hasDependencies {
+		// Removing dependencies cannot create a cycle.
+		return nil
+	}
+
+	rules, err := ruleReader.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to list the alert rules of the organization: %w", err)
+	}
+	graph := make(map[string][]string, len(rules)+len(changed))
+	for _, r := range rules {
+		graph[r.UID] = r.DependsOn
+	}
+	for _, uid := range deleted {
+		delete(graph, uid)
+	}
+	for _, r := range changed {
+		uid := r.UID
+		if uid == "" {
+			// The UID of a new rule is generated when it is inserted, so no rule can depend on it yet.
+			uid = fmt.Sprintf("%q", r.Title)
+		}
+		graph[uid] = r.DependsOn
+	}
+	if err := models.ValidateRuleDeletion(graph, deleted); err != nil {
+		return err
+	}
+	if !hasDependencies {
+		return nil
+	}
+	// The rules that are not changed can depend on rules that were deleted earlier. Such dependencies are ignored
+	// during evaluation, and should not fail the validation of unrelated changes.
+	for _, r := range rules {
+		if _, ok := graph[r.UID]; !ok || slices.ContainsFunc(changed, func(c *models.AlertRule) bool { return c.UID == r.UID }) {
+			continue
+		}
+		graph[r.UID] = slices.DeleteFunc(slices.Clone(r.DependsOn), func(dep string) bool {
+			_, ok := graph[dep]
+			return !ok
+		})
+	}
+	return models.ValidateRuleDependencies(graph)
+}
+
+// ValidateGroupDeltaDependencies is like ValidateRuleDependencies for the rules created, updated and deleted by the delta.
+func ValidateGroupDeltaDependencies(ctx context.Context, ruleReader RuleReader, delta *GroupDelta) error {
+	changed := make([]*models.AlertRule, 0, len(delta.New)+len(delta.Update))
+	for _, r := range delta.New {
+		if r != nil {
+			changed = append(changed, r)
+		}
+	}
+	for _, update := range delta.Update {
+		changed = append(changed, update.New)
+	}
+	deleted := make([]string, 0, len(delta.Delete))
+	for _, r := range delta.Delete {
+		if r != nil {
+			deleted = append(deleted, r.UID)
+		}
+	}
+	return ValidateRuleDependencies(ctx, ruleReader, delta.GroupKey.OrgID, changed, deleted)
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/rand"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestValidateGroupDeltaDependencies(t *testing.T) {
+	orgID := rand.Int63()
+	gen := func(dependsOn ...string) *models.AlertRule {
+		return models.AlertRuleGen(models.WithOrgID(orgID), models.WithDependsOn(dependsOn...))()
+	}
+	upstream := gen()
+	downstream := gen(upstream.UID)
+
+	setup := func(t *testing.T) *fakes.RuleStore {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.PutRule(context.Background(), upstream, downstream)
+		return ruleStore
+	}
+	groupKey := models.AlertRuleGroupKey{OrgID: orgID}
+
+	t.Run("accepts changes without dependencies", func(t *testing.T) {
+		ruleStore := setup(t)
+		changes := &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{gen()}}
+		require.NoError(t, ValidateGroupDeltaDependencies(context.Background(), ruleStore, changes))
+		require.Empty(t, ruleStore.RecordedOps)
+	})
+
+	t.Run("accepts new rule that depends on existing rules", func(t *testing.T) {
+		changes := &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{
+			gen(upstream.UID, downstream.UID),
+		}}
+		require.NoError(t, ValidateGroupDeltaDependencies(context.Background(), setup(t), changes))
+	})
+
+	t.Run("rejects rule that depends on unknown rule", func(t *testing.T) {
+		changes := &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{
+			gen("unknown"),
+		}}
+		err := ValidateGroupDeltaDependencies(context.Background(), setup(t), changes)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "depends on rule unknown that does not exist")
+	})
+
+	t.Run("rejects rule that depends on a deleted rule", func(t *testing.T) {
+		changes := &GroupDelta{
+			GroupKey: groupKey,
+			New:      []*models.AlertRule{gen(upstream.UID)},
+			Delete:   []*models.AlertRule{upstream},
+		}
+		err := ValidateGroupDeltaDependencies(context.Background(), setup(t), changes)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects deleting a rule that other rules depend on", func(t *testing.T) {
+		changes := &GroupDelta{GroupKey: groupKey, Delete: []*models.AlertRule{upstream}}
+		err := ValidateGroupDeltaDependencies(context.Background(), setup(t), changes)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, fmt.Sprintf("rule %s cannot be deleted because rule %s depends on it", upstream.UID, downstream.UID))
+	})
+
+	t.Run("accepts deleting a rule together with the rules that depend on it", func(t *testing.T) {
+		changes := &GroupDelta{GroupKey: groupKey, Delete: []*models.AlertRule{upstream, downstream}}
+		require.NoError(t, ValidateGroupDeltaDependencies(context.Background(), setup(t), changes))
+	})
+
+	t.Run("accepts deleting a rule when the rules that depend on it drop the dependency", func(t *testing.T) {
+		updated := models.CopyRule(downstream)
+		updated.DependsOn = nil
+		changes := &GroupDelta{
+			GroupKey: groupKey,
+			Update:   []RuleDelta{{Existing: downstream, New: updated}},
+			Delete:   []*models.AlertRule{upstream},
+		}
+		require.NoError(t, ValidateGroupDeltaDependencies(context.Background(), setup(t), changes))
+	})
+
+	t.Run("rejects update that creates a cycle", func(t *testing.T) {
+		updated := models.CopyRule(upstream)
+		updated.DependsOn = []string{downstream.UID}
+		changes := &GroupDelta{GroupKey: groupKey, Update: []RuleDelta{{Existing: upstream, New: updated}}}
+		err := ValidateGroupDeltaDependencies(context.Background(), setup(t), changes)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "rule dependencies form a cycle")
+	})
+
+	t.Run("ignores dependencies of unchanged rules on rules that do not exist", func(t *testing.T) {
+		ruleStore := setup(t)
+		ruleStore.PutRule(context.Background(), gen("deleted"))
+		changes := &GroupDelta{GroupKey: groupKey, New: []*models.AlertRule{gen(upstream.UID)}}
+		require.NoError(t, ValidateGroupDeltaDependencies(context.Background(), ruleStore, changes))
+	})
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// stateHistoryBatchSize is the number of entries read at once when the state history is filtered by labels.
+	stateHistoryBatchSize = 1000
+	// stateHistoryInsertBatchSize is the number of entries inserted by a single statement.
+	stateHistoryInsertBatchSize = 100
+	// stateHistoryDeleteBatchSize is the number of entries deleted by a single statement. It is below the parameter
+	// limit of SQLite.
+	stateHistoryDeleteBatchSize = 500
+	// StateHistoryMaxLimit is the maximum number of entries returned by a query, which is also the limit of queries
+	// without a limit.
+	StateHistoryMaxLimit = 5000
+)
+
+// StateHistoryStore is the database interface used by the sql state history backend.
+type StateHistoryStore interface {
+	// SaveStateHistory inserts the state transitions.
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+
+	// GetStateHistory returns the most recent state transitions that match the query, newest first.
+	// Labels must all be present in the labels of the alert instance with the same value. At most
+	// StateHistoryMaxLimit entries are returned, also when the query has no limit.
+	GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error)
+
+	// DeleteStateHistoryBefore deletes the state transitions that happened before the given time, and returns
+	// the number of deleted entries.
+	DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(entries); start += stateHistoryInsertBatchSize {
+			batch := entries[start:min(start+stateHistoryInsertBatchSize, len(entries))]
+			if _, err := sess.InsertMulti(&batch); err != nil {
+				return fmt.Errorf("failed to insert state history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	limit := query.Limit
+	if limit <= 0 || limit > StateHistoryMaxLimit {
+		limit = StateHistoryMaxLimit
+	}
+
+	var result []models.StateHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Where("org_id = ?", query.OrgID)
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if !query.From.IsZero() {
+				q = q.And("time_nano >= ?", query.From.UnixNano())
+			}
+			if !query.To.IsZero() {
+				q = q.And("time_nano <= ?", query.To.UnixNano())
+			}
+			return q
+		}
+
+		if len(query.Labels) == 0 {
+			return filter().Desc("time_nano", "id").Limit(limit).Find(&result)
+		}
+
+		// Labels are stored as a JSON object, so they are matched here rather than in the database. The entries are
+		// read in batches that continue after the last entry of the previous batch, so that entries inserted in the
+		// meantime neither shift the batches nor are read twice.
+		var last *models.StateHistoryEntry
+		for {
+			q := filter()
+			if last != nil {
+				q = q.And("(time_nano < ? OR (time_nano = ? AND id < ?))", last.TimeNano, last.TimeNano, last.ID)
+			}
+			var batch []models.StateHistoryEntry
+			if err := q.Desc("time_nano", "id").Limit(stateHistoryBatchSize).Find(&batch); err != nil {
+				return err
+			}
+			for _, e := range batch {
+				if !matchLabels(e.Labels, query.Labels) {
+					continue
+				}
+				result = append(result, e)
+				if len(result) == limit {
+					return nil
+				}
+			}
+			if len(batch) < stateHistoryBatchSize {
+				return nil
+			}
+			last = &batch[len(batch)-1]
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteStateHistoryBefore deletes the entries in batches, like the cleanup of annotations, so that a large
+// backlog of expired entries does not hold locks on the table for long.
+func (st DBstore) DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		var ids []int64
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(models.StateHistoryEntry{}.TableName()).Where("time_nano < ?", before.UnixNano()).
+				Asc("id").Limit(stateHistoryDeleteBatchSize).Cols("id").Find(&ids)
+		})
+		if err != nil {
+			return deleted, err
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+
+		err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			n, err := sess.In("id", ids).Delete(&models.StateHistoryEntry{})
+			deleted += n
+			return err
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+}
+
+func matchLabels(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
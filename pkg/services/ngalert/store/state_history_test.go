@@ -0,0 +1,140 @@
+package store_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().UTC()
+	entry := func(orgID int64, ruleUID string, labels map[string]string, at time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:    orgID,
+			RuleUID:  ruleUID,
+			Labels:   labels,
+			Previous: "Normal",
+			Current:  "Alerting",
+			Values:   simplejson.NewFromAny(map[string]any{"A": 1}),
+			TimeNano: at.UnixNano(),
+		}
+	}
+	err := dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{
+		entry(1, "rule-1", map[string]string{"a": "1"}, now.Add(-3*time.Hour)),
+		entry(1, "rule-1", map[string]string{"a": "2"}, now.Add(-2*time.Hour)),
+		entry(1, "rule-1", map[string]string{"a": "1", "b": "1"}, now.Add(-time.Hour)),
+		entry(1, "rule-2", map[string]string{"a": "1"}, now),
+		entry(2, "rule-1", map[string]string{"a": "1"}, now),
+	})
+	require.NoError(t, err)
+
+	t.Run("filters by organization and rule, newest first", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		require.Equal(t, now.Add(-time.Hour).UnixNano(), res[0].TimeNano)
+		require.Equal(t, now.Add(-3*time.Hour).UnixNano(), res[2].TimeNano)
+		require.Equal(t, "Alerting", res[0].Current)
+		require.Equal(t, 1, res[0].Values.Get("A").MustInt())
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-30 * time.Minute)})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+	})
+
+	t.Run("filters by labels", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1", Labels: map[string]string{"a": "1"}})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, map[string]string{"a": "1", "b": "1"}, res[0].Labels)
+
+		res, err = dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, Labels: map[string]string{"a": "1"}, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "rule-2", res[0].RuleUID)
+	})
+
+	t.Run("limits the number of entries", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, now.Add(-time.Hour).UnixNano(), res[0].TimeNano)
+	})
+
+	t.Run("deletes entries older than the given time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteStateHistoryBefore(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+}
+
+func TestIntegrationStateHistoryBatches(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	// more entries than are read or deleted at once, many of them at the same time
+	now := time.Now().UTC()
+	count := 2500
+	entries := make([]models.StateHistoryEntry, 0, count)
+	for i := 0; i < count; i++ {
+		entries = append(entries, models.StateHistoryEntry{
+			OrgID:    1,
+			RuleUID:  "rule-1",
+			Labels:   map[string]string{"i": strconv.Itoa(i), "even": strconv.FormatBool(i%2 == 0)},
+			Previous: "Normal",
+			Current:  "Alerting",
+			TimeNano: now.Add(time.Duration(i/10) * time.Second).UnixNano(),
+		})
+	}
+	require.NoError(t, dbstore.SaveStateHistory(ctx, entries))
+
+	t.Run("matches labels across batches without duplicates", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, Labels: map[string]string{"even": "true"}})
+		require.NoError(t, err)
+		require.Len(t, res, count/2)
+		seen := map[int64]struct{}{}
+		for i, e := range res {
+			require.NotContains(t, seen, e.ID)
+			seen[e.ID] = struct{}{}
+			if i > 0 {
+				require.LessOrEqual(t, e.TimeNano, res[i-1].TimeNano)
+			}
+		}
+
+		res, err = dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1, Labels: map[string]string{"i": "0"}})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("deletes in batches", func(t *testing.T) {
+		deleted, err := dbstore.DeleteStateHistoryBefore(ctx, now.Add(time.Hour))
+		require.NoError(t, err)
+		require.EqualValues(t, count, deleted)
+
+		res, err := dbstore.GetStateHistory(ctx, models.HistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+}
@@ -0,0 +1,202 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxPendingBatches is the number of batches that can wait to be sent before new series are dropped.
+const maxPendingBatches = 10
+
+type PrometheusWriterConfig struct {
+	URL               *url.URL
+	BasicAuthUsername string
+	BasicAuthPassword string
+	CustomHeaders     map[string]string
+	Timeout           time.Duration
+	MaxBatchSize      int
+	FlushInterval     time.Duration
+}
+
+func NewPrometheusWriterConfig(cfg setting.RecordingRuleSettings) (PrometheusWriterConfig, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return PrometheusWriterConfig{}, fmt.Errorf("failed to parse remote-write URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return PrometheusWriterConfig{}, fmt.Errorf("remote-write URL %q must use http or https", cfg.URL)
+	}
+	return PrometheusWriterConfig{
+		URL:               u,
+		BasicAuthUsername: cfg.BasicAuthUsername,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		CustomHeaders:     cfg.CustomHeaders,
+		Timeout:           cfg.Timeout,
+		MaxBatchSize:      cfg.MaxBatchSize,
+		FlushInterval:     cfg.FlushInterval,
+	}, nil
+}
+
+// PrometheusWriter writes the series of recording rules to a Prometheus remote-write endpoint. The series are queued
+// and sent in batches, either when a batch is full or when the flush interval elapses, so that the rules evaluated at
+// the same time share the requests. The series of a batch that cannot be written are dropped.
+type PrometheusWriter struct {
+	cfg     PrometheusWriterConfig
+	client  client.Requester
+	metrics *metrics.RemoteWriter
+	logger  log.Logger
+
+	mtx     sync.Mutex
+	pending []prompb.TimeSeries
+	flushCh chan struct{}
+}
+
+func NewPrometheusWriter(cfg PrometheusWriterConfig, req client.Requester, met *metrics.RemoteWriter, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		cfg:     cfg,
+		client:  client.NewTimedClient(req, met.WriteDuration),
+		metrics: met,
+		logger:  logger,
+		flushCh: make(chan struct{}, 1),
+	}
+}
+
+// Write queues the series of the frames. It returns ErrQueueFull if the series cannot be queued.
+func (w *PrometheusWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	series, err := TimeSeriesFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		return nil
+	}
+
+	w.mtx.Lock()
+	if len(w.pending)+len(series) > maxPendingBatches*w.cfg.MaxBatchSize {
+		w.mtx.Unlock()
+		w.metrics.SeriesDropped.WithLabelValues("queue_full").Add(float64(len(series)))
+		return ErrQueueFull
+	}
+	w.pending = append(w.pending, series...)
+	full := len(w.pending) >= w.cfg.MaxBatchSize
+	w.metrics.PendingSeries.Set(float64(len(w.pending)))
+	w.mtx.Unlock()
+
+	w.metrics.SeriesTotal.WithLabelValues(strconv.FormatInt(orgID, 10)).Add(float64(len(series)))
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run sends the queued series until the context is cancelled. The remaining series are sent before it returns.
+func (w *PrometheusWriter) Run(ctx context.Context) error {
+	w.logger.Info("Starting remote writer", "url", w.cfg.URL.Redacted(), "maxBatchSize", w.cfg.MaxBatchSize, "flushInterval", w.cfg.FlushInterval)
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush(ctx)
+		case <-w.flushCh:
+			w.flush(ctx)
+		case <-ctx.Done():
+			// Use a fresh context so that the series queued before the shutdown are not lost.
+			flushCtx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+			w.flush(flushCtx)
+			cancel()
+			w.logger.Info("Remote writer stopped")
+			return nil
+		}
+	}
+}
+
+// flush sends all the queued series in batches of at most MaxBatchSize series.
+func (w *PrometheusWriter) flush(ctx context.Context) {
+	w.mtx.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.metrics.PendingSeries.Set(0)
+	w.mtx.Unlock()
+
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), w.cfg.MaxBatchSize)]
+		pending = pending[len(batch):]
+		if err := w.send(ctx, batch); err != nil {
+			w.logger.Error("Failed to write series to the remote-write endpoint", "series", len(batch), "error", err)
+			w.metrics.SeriesDropped.WithLabelValues("write_failed").Add(float64(len(batch)))
+		}
+	}
+}
+
+func (w *PrometheusWriter) send(ctx context.Context, series []prompb.TimeSeries) error {
+	w.metrics.WritesTotal.Inc()
+	err := w.doSend(ctx, series)
+	if err != nil {
+		w.metrics.WritesFailed.Inc()
+		return err
+	}
+	w.metrics.LastWriteTimestamp.SetToCurrentTime()
+	return nil
+}
+
+func (w *PrometheusWriter) doSend(ctx context.Context, series []prompb.TimeSeries) error {
+	raw, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return fmt.Errorf("failed to encode the series: %w", err)
+	}
+	body := snappy.Encode(nil, raw)
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	for k, v := range w.cfg.CustomHeaders {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.cfg.BasicAuthUsername != "" || w.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(w.cfg.BasicAuthUsername, w.cfg.BasicAuthPassword)
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	w.metrics.BytesWritten.Add(float64(len(body)))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("remote-write endpoint returned status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package writer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestTimeSeriesFromFrames(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("should write the numbers and the last value of time series", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("value", data.Labels{"job": "a"}, []float64{1})),
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+				data.NewField("value", data.Labels{"job": "b"}, []*float64{util.Pointer(2.0), nil}),
+			),
+		}
+
+		series, err := TimeSeriesFromFrames("metric", now, frames, map[string]string{"rule": "r"})
+		require.NoError(t, err)
+
+		require.Equal(t, []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "metric"}, {Name: "job", Value: "a"}, {Name: "rule", Value: "r"}},
+				Samples: []prompb.Sample{{Timestamp: now.UnixMilli(), Value: 1}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "metric"}, {Name: "job", Value: "b"}, {Name: "rule", Value: "r"}},
+				Samples: []prompb.Sample{{Timestamp: now.UnixMilli(), Value: 2}},
+			},
+		}, series)
+	})
+
+	t.Run("should skip series without value", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("", data.NewField("value", nil, []*float64{nil}))}
+
+		series, err := TimeSeriesFromFrames("metric", now, frames, nil)
+		require.NoError(t, err)
+		require.Empty(t, series)
+	})
+
+	t.Run("should fail if labels are duplicated", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("value", data.Labels{"job": "a"}, []float64{1})),
+			data.NewFrame("", data.NewField("value", data.Labels{"job": "a"}, []float64{2})),
+		}
+
+		_, err := TimeSeriesFromFrames("metric", now, frames, nil)
+		require.ErrorContains(t, err, "more than one series")
+	})
+
+	t.Run("should fail if metric name is invalid", func(t *testing.T) {
+		_, err := TimeSeriesFromFrames("1metric", now, nil, nil)
+		require.ErrorContains(t, err, "not a valid metric name")
+	})
+}
+
+func TestPrometheusWriter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	frame := func(job string, v float64) *data.Frame {
+		return data.NewFrame("", data.NewField("value", data.Labels{"job": job}, []float64{v}))
+	}
+
+	setup := func(t *testing.T, target *TestRemoteWriteTarget, batchSize int, flushInterval time.Duration) *PrometheusWriter {
+		cfg, err := NewPrometheusWriterConfig(setting.RecordingRuleSettings{
+			URL:               target.URL(),
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			CustomHeaders:     map[string]string{"X-Scope-OrgID": "tenant"},
+			Timeout:           time.Second,
+			MaxBatchSize:      batchSize,
+			FlushInterval:     flushInterval,
+		})
+		require.NoError(t, err)
+		w := NewPrometheusWriter(cfg, &http.Client{}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			_ = w.Run(ctx)
+			close(done)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+		return w
+	}
+
+	t.Run("should send the series in batches when the batch is full", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t)
+		w := setup(t, target, 2, time.Hour)
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", 1)}, 1, nil))
+		require.Never(t, func() bool { return len(target.Requests()) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("b", 2), frame("c", 3)}, 1, nil))
+		require.Eventually(t, func() bool { return len(target.Series()) == 3 }, time.Second, 10*time.Millisecond)
+		require.Len(t, target.Requests(), 2)
+
+		req := target.Requests()[0]
+		require.Equal(t, "snappy", req.Header.Get("Content-Encoding"))
+		require.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		require.Equal(t, "tenant", req.Header.Get("X-Scope-OrgID"))
+		user, password, ok := req.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+
+		jobs := make([]string, 0, 3)
+		for _, s := range target.Series() {
+			for _, l := range s.Labels {
+				if l.Name == "job" {
+					jobs = append(jobs, l.Value)
+				}
+			}
+		}
+		sort.Strings(jobs)
+		require.Equal(t, []string{"a", "b", "c"}, jobs)
+	})
+
+	t.Run("should send the series when the flush interval elapses", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t)
+		w := setup(t, target, 100, 50*time.Millisecond)
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", 1)}, 1, nil))
+		require.Eventually(t, func() bool { return len(target.Series()) == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should drop the series if the write fails", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t).WithStatus(http.StatusInternalServerError)
+		w := setup(t, target, 1, time.Hour)
+
+		require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", 1)}, 1, nil))
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(w.metrics.SeriesDropped.WithLabelValues("write_failed")) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, 1.0, testutil.ToFloat64(w.metrics.WritesFailed))
+		require.Empty(t, target.Series())
+	})
+
+	t.Run("should reject the series if the queue is full", func(t *testing.T) {
+		cfg := PrometheusWriterConfig{URL: &url.URL{Scheme: "http", Host: "localhost"}, MaxBatchSize: 1, FlushInterval: time.Hour}
+		w := NewPrometheusWriter(cfg, &http.Client{}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+
+		for i := 0; i < maxPendingBatches; i++ {
+			require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", float64(i))}, 1, nil))
+		}
+		require.ErrorIs(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", 1)}, 1, nil), ErrQueueFull)
+	})
+
+	t.Run("should send the queued series when stopped", func(t *testing.T) {
+		target := NewTestRemoteWriteTarget(t)
+		cfg, err := NewPrometheusWriterConfig(setting.RecordingRuleSettings{URL: target.URL(), Timeout: time.Second, MaxBatchSize: 100, FlushInterval: time.Hour})
+		require.NoError(t, err)
+		w := NewPrometheusWriter(cfg, &http.Client{}, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, w.Write(context.Background(), "metric", now, data.Frames{frame("a", 1)}, 1, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, w.Run(ctx))
+		require.Len(t, target.Series(), 1)
+	})
+}
+
+func TestNewPrometheusWriterConfig(t *testing.T) {
+	_, err := NewPrometheusWriterConfig(setting.RecordingRuleSettings{URL: "localhost:9090"})
+	require.ErrorContains(t, err, "must use http or https")
+}
@@ -0,0 +1,86 @@
+package writer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// TestRemoteWriteTarget is a remote-write receiver stub that records the series it receives.
+type TestRemoteWriteTarget struct {
+	srv *httptest.Server
+
+	mtx      sync.Mutex
+	requests []*http.Request
+	series   []prompb.TimeSeries
+	status   int
+}
+
+func NewTestRemoteWriteTarget(t *testing.T) *TestRemoteWriteTarget {
+	t.Helper()
+	target := &TestRemoteWriteTarget{status: http.StatusNoContent}
+	target.srv = httptest.NewServer(http.HandlerFunc(target.handle))
+	t.Cleanup(target.srv.Close)
+	return target
+}
+
+func (target *TestRemoteWriteTarget) handle(w http.ResponseWriter, r *http.Request) {
+	compressed, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	raw, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(raw, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target.mtx.Lock()
+	defer target.mtx.Unlock()
+	target.requests = append(target.requests, r)
+	if target.status/100 != 2 {
+		http.Error(w, "write failed", target.status)
+		return
+	}
+	target.series = append(target.series, req.Timeseries...)
+	w.WriteHeader(target.status)
+}
+
+// URL returns the URL of the remote-write endpoint.
+func (target *TestRemoteWriteTarget) URL() string {
+	return target.srv.URL + "/api/v1/write"
+}
+
+// WithStatus sets the status code of the responses.
+func (target *TestRemoteWriteTarget) WithStatus(status int) *TestRemoteWriteTarget {
+	target.mtx.Lock()
+	defer target.mtx.Unlock()
+	target.status = status
+	return target
+}
+
+// Requests returns the requests that were received.
+func (target *TestRemoteWriteTarget) Requests() []*http.Request {
+	target.mtx.Lock()
+	defer target.mtx.Unlock()
+	return append([]*http.Request(nil), target.requests...)
+}
+
+// Series returns the series that were written.
+func (target *TestRemoteWriteTarget) Series() []prompb.TimeSeries {
+	target.mtx.Lock()
+	defer target.mtx.Unlock()
+	return append([]prompb.TimeSeries(nil), target.series...)
+}
@@ -0,0 +1,104 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// ErrQueueFull is returned when the series cannot be written because too many series are waiting to be sent.
+var ErrQueueFull = errors.New("the queue of series to write is full")
+
+// Writer writes the series of recording rules.
+type Writer interface {
+	// Write writes the last value of every numeric series of the frames as a sample of the metric at time t.
+	// The extra labels are added to the labels of every series.
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+}
+
+// NoopWriter is a Writer that discards the series.
+type NoopWriter struct{}
+
+func (w NoopWriter) Write(context.Context, string, time.Time, data.Frames, int64, map[string]string) error {
+	return nil
+}
+
+// TimeSeriesFromFrames converts the frames to Prometheus time series with a single sample at time t. A series is
+// created for every numeric field of the frames, with the last non-null value of the field. Time series frames
+// produced by queries are therefore reduced to their last value, and number frames produced by expressions are
+// written as is. The labels of a series are the labels of the field, the extra labels and the name of the metric.
+func TimeSeriesFromFrames(name string, t time.Time, frames data.Frames, extraLabels map[string]string) ([]prompb.TimeSeries, error) {
+	if !model.IsValidMetricName(model.LabelValue(name)) {
+		return nil, fmt.Errorf("%q is not a valid metric name", name)
+	}
+	timestamp := t.UnixMilli()
+	result := make([]prompb.TimeSeries, 0, len(frames))
+	seen := make(map[data.Fingerprint]struct{}, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, ok, err := lastValue(field)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the value of field %s: %w", field.Name, err)
+			}
+			if !ok {
+				continue
+			}
+
+			lbls := make(data.Labels, len(field.Labels)+len(extraLabels)+1)
+			for k, v := range field.Labels {
+				lbls[k] = v
+			}
+			for k, v := range extraLabels {
+				lbls[k] = v
+			}
+			lbls[model.MetricNameLabel] = name
+			fp := lbls.Fingerprint()
+			if _, ok := seen[fp]; ok {
+				return nil, fmt.Errorf("the frames have more than one series with labels %s", lbls.String())
+			}
+			seen[fp] = struct{}{}
+
+			result = append(result, prompb.TimeSeries{
+				Labels:  promLabels(lbls),
+				Samples: []prompb.Sample{{Timestamp: timestamp, Value: value}},
+			})
+		}
+	}
+	return result, nil
+}
+
+func lastValue(field *data.Field) (float64, bool, error) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		v, err := field.NullableFloatAt(i)
+		if err != nil {
+			return 0, false, err
+		}
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		return *v, true, nil
+	}
+	return 0, false, nil
+}
+
+// promLabels converts the labels to Prometheus labels sorted by name, as required by the remote-write protocol.
+func promLabels(lbls data.Labels) []prompb.Label {
+	result := make([]prompb.Label, 0, len(lbls))
+	for k, v := range lbls {
+		result = append(result, prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
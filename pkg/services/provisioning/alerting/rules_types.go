@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -65,6 +66,7 @@ type AlertRuleV1 struct {
 	UID                  values.StringValue      `json:"uid" yaml:"uid"`
 	Title                values.StringValue      `json:"title" yaml:"title"`
 	Condition            values.StringValue      `json:"condition" yaml:"condition"`
+	RecoveryCondition    values.StringValue      `json:"recovery_condition" yaml:"recovery_condition"`
 	Data                 []QueryV1               `json:"data" yaml:"data"`
 	DashboardUID         values.StringValue      `json:"dasboardUid" yaml:"dashboardUid"`
 	PanelID              values.Int64Value       `json:"panelId" yaml:"panelId"`
@@ -75,6 +77,10 @@ type AlertRuleV1 struct {
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+	FlapDetection        *FlapDetectionV1        `json:"flap_detection" yaml:"flap_detection"`
+	Record               *RecordV1               `json:"record" yaml:"record"`
+	DependsOn            []values.StringValue    `json:"depends_on" yaml:"depends_on"`
+	EvaluationTimeout    values.StringValue      `json:"evaluation_timeout" yaml:"evaluation_timeout"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -119,6 +125,7 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
+	alertRule.RecoveryCondition = rule.RecoveryCondition.Value()
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
 	for _, queryV1 := range rule.Data {
@@ -139,6 +146,32 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.NotificationSettings = append(alertRule.NotificationSettings, ns)
 	}
+	if rule.FlapDetection != nil {
+		fd, err := rule.FlapDetection.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.FlapDetection = append(alertRule.FlapDetection, fd)
+	}
+	for _, uid := range rule.DependsOn {
+		if uid.Value() != "" {
+			alertRule.DependsOn = append(alertRule.DependsOn, uid.Value())
+		}
+	}
+	if rule.EvaluationTimeout.Value() != "" {
+		timeout, err := model.ParseDuration(rule.EvaluationTimeout.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse evaluation timeout: %w", alertRule.Title, err)
+		}
+		alertRule.EvaluationTimeout = time.Duration(timeout)
+	}
+	if rule.Record != nil {
+		record, err := rule.Record.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.Record = append(alertRule.Record, record)
+	}
 	return alertRule, nil
 }
 
@@ -246,3 +279,48 @@ func (nsV1 *NotificationSettingsV1) mapToModel() (models.NotificationSettings, e
 		MuteTimeIntervals: mute,
 	}, nil
 }
+
+type FlapDetectionV1 struct {
+	Window            values.IntValue    `json:"window,omitempty" yaml:"window"`
+	HighThreshold     values.StringValue `json:"high_threshold,omitempty" yaml:"high_threshold"`
+	LowThreshold      values.StringValue `json:"low_threshold,omitempty" yaml:"low_threshold"`
+	HoldNotifications values.BoolValue   `json:"hold_notifications,omitempty" yaml:"hold_notifications"`
+}
+
+func (fdV1 *FlapDetectionV1) mapToModel() (models.FlapDetectionSettings, error) {
+	fd := models.NewFlapDetectionSettings()
+	if fdV1.Window.Value() != 0 {
+		fd.Window = fdV1.Window.Value()
+	}
+	if v := fdV1.HighThreshold.Value(); v != "" {
+		high, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return models.FlapDetectionSettings{}, fmt.Errorf("failed to parse high threshold: %w", err)
+		}
+		fd.HighThreshold = high
+	}
+	if v := fdV1.LowThreshold.Value(); v != "" {
+		low, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return models.FlapDetectionSettings{}, fmt.Errorf("failed to parse low threshold: %w", err)
+		}
+		fd.LowThreshold = low
+	}
+	fd.HoldNotifications = fdV1.HoldNotifications.Value()
+	if err := fd.Validate(); err != nil {
+		return models.FlapDetectionSettings{}, fmt.Errorf("invalid flap detection: %w", err)
+	}
+	return fd, nil
+}
+
+type RecordV1 struct {
+	Metric values.StringValue `json:"metric" yaml:"metric"`
+}
+
+func (rV1 *RecordV1) mapToModel() (models.RecordSettings, error) {
+	record := models.RecordSettings{Metric: rV1.Metric.Value()}
+	if err := record.Validate(); err != nil {
+		return models.RecordSettings{}, fmt.Errorf("invalid record: %w", err)
+	}
+	return record, nil
+}
@@ -199,6 +199,39 @@ func TestRules(t *testing.T) {
 		require.Len(t, ruleMapped.NotificationSettings, 1)
 		require.Equal(t, models.NotificationSettings{Receiver: "test-receiver"}, ruleMapped.NotificationSettings[0])
 	})
+	t.Run("a rule with record should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Record = &RecordV1{Metric: stringToStringValue("grafana:test:value")}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []models.RecordSettings{{Metric: "grafana:test:value"}}, ruleMapped.Record)
+	})
+	t.Run("a rule with dependencies should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.DependsOn = []values.StringValue{stringToStringValue("upstream"), stringToStringValue("")}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"upstream"}, ruleMapped.DependsOn)
+	})
+	t.Run("a rule with an evaluation timeout should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.EvaluationTimeout = stringToStringValue("15s")
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Second, ruleMapped.EvaluationTimeout)
+	})
+	t.Run("a rule with an invalid evaluation timeout should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.EvaluationTimeout = stringToStringValue("soon")
+		_, err := rule.mapToModel(1)
+		require.ErrorContains(t, err, "failed to parse evaluation timeout")
+	})
+	t.Run("a rule with an invalid record metric should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Record = &RecordV1{Metric: stringToStringValue("1invalid")}
+		_, err := rule.mapToModel(1)
+		require.ErrorContains(t, err, "invalid record")
+	})
 }
 
 func TestNotificationsSettingsV1MapToModel(t *testing.T) {
@@ -121,6 +121,20 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAlertingScopeRemovalMigration(mg)
 
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
+
+	ualert.AddRuleFlapDetectionColumns(mg)
+
+	ualert.AddRuleRecoveryConditionColumns(mg)
+
+	ualert.AddAlertmanagerStateMigrations(mg)
+
+	ualert.AddStateHistoryMigrations(mg)
+
+	ualert.AddRuleRecordColumns(mg)
+
+	ualert.AddRuleDependsOnColumns(mg)
+
+	ualert.AddRuleEvaluationTimeoutColumns(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,181 @@
+package ualert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddAlertmanagerStateMigrations creates the alertmanager_state table, which stores the silences and the
+// notification log of the Alertmanager of each organization with one row per entry, and copies the existing
+// state from the kvstore into it.
+func AddAlertmanagerStateMigrations(mg *migrator.Migrator) {
+	stateTable := migrator.Table{
+		Name: "alertmanager_state",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "entry_key", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "data", Type: migrator.DB_Blob, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "kind", "entry_key"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"expires_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alertmanager_state table", migrator.NewAddTableMigration(stateTable))
+	mg.AddMigration("add unique index on org_id, kind and entry_key to alertmanager_state table", migrator.NewAddIndexMigration(stateTable, stateTable.Indices[0]))
+	mg.AddMigration("add index on expires_at to alertmanager_state table", migrator.NewAddIndexMigration(stateTable, stateTable.Indices[1]))
+	mg.AddMigration("copy alertmanager state from kvstore to alertmanager_state table", &copyAlertmanagerStateFromKVStore{})
+}
+
+// alertmanagerKVNamespace, silencesKVKey and notificationLogKVKey are vendored from notifier.FileStore.
+const (
+	alertmanagerKVNamespace = "alertmanager"
+	silencesKVKey           = "silences"
+	notificationLogKVKey    = "notifications"
+)
+
+// alertmanagerStateRow is the model of an alertmanager_state row, at the time that the copyAlertmanagerStateFromKVStore migration was run.
+// This is not to be used outside of the copyAlertmanagerStateFromKVStore migration.
+type alertmanagerStateRow struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	Kind      string `xorm:"kind"`
+	EntryKey  string `xorm:"entry_key"`
+	Data      []byte `xorm:"data"`
+	UpdatedAt int64  `xorm:"updated_at"`
+	ExpiresAt int64  `xorm:"expires_at"`
+	Version   int64  `xorm:"version"`
+}
+
+// copyAlertmanagerStateFromKVStore splits the silences and notification log snapshots that are stored in the kvstore into
+// one row per entry. The kvstore entries are left in place, so that the state is not lost if Grafana is downgraded.
+type copyAlertmanagerStateFromKVStore struct {
+	migrator.MigrationBase
+}
+
+func (c copyAlertmanagerStateFromKVStore) SQL(migrator.Dialect) string {
+	return codeMigration
+}
+
+func (c copyAlertmanagerStateFromKVStore) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
+	var entries []kvStoreV1Entry
+	if err := sess.Table("kv_store").Where("namespace = ?", alertmanagerKVNamespace).In("key", silencesKVKey, notificationLogKVKey).Find(&entries); err != nil {
+		return fmt.Errorf("failed to read alertmanager state from kvstore: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.OrgID == nil || entry.Key == nil {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(entry.Value)
+		if err != nil {
+			// The state is rebuilt by the Alertmanager, so a corrupted snapshot must not block the migration.
+			mg.Logger.Warn("Failed to decode alertmanager state, skipping", "org", *entry.OrgID, "key", *entry.Key, "error", err)
+			continue
+		}
+
+		var rows []alertmanagerStateRow
+		switch *entry.Key {
+		case silencesKVKey:
+			rows, err = silenceRows(b)
+		case notificationLogKVKey:
+			rows, err = notificationLogRows(b)
+		}
+		if err != nil {
+			mg.Logger.Warn("Failed to parse alertmanager state, skipping", "org", *entry.OrgID, "key", *entry.Key, "error", err)
+			continue
+		}
+
+		for _, row := range rows {
+			row.OrgID = *entry.OrgID
+			row.Version = 1
+			exists, err := sess.Table("alertmanager_state").Where("org_id = ? AND kind = ? AND entry_key = ?", row.OrgID, row.Kind, row.EntryKey).Exist()
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+			if _, err := sess.Table("alertmanager_state").Insert(&row); err != nil {
+				return fmt.Errorf("failed to insert alertmanager state for org %d: %w", row.OrgID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func silenceRows(b []byte) ([]alertmanagerStateRow, error) {
+	var rows []alertmanagerStateRow
+	r := bytes.NewReader(b)
+	for {
+		var s silencepb.MeshSilence
+		if _, err := pbutil.ReadDelimited(r, &s); err != nil {
+			if errors.Is(err, io.EOF) {
+				return rows, nil
+			}
+			return nil, err
+		}
+		if s.Silence == nil {
+			return nil, errors.New("invalid silence")
+		}
+		var data bytes.Buffer
+		if _, err := pbutil.WriteDelimited(&data, &s); err != nil {
+			return nil, err
+		}
+		rows = append(rows, alertmanagerStateRow{
+			Kind:      "silences",
+			EntryKey:  s.Silence.Id,
+			Data:      data.Bytes(),
+			UpdatedAt: s.Silence.UpdatedAt.UnixNano(),
+			ExpiresAt: s.ExpiresAt.UnixNano(),
+		})
+	}
+}
+
+func notificationLogRows(b []byte) ([]alertmanagerStateRow, error) {
+	var rows []alertmanagerStateRow
+	r := bytes.NewReader(b)
+	for {
+		var e nflogpb.MeshEntry
+		if _, err := pbutil.ReadDelimited(r, &e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return rows, nil
+			}
+			return nil, err
+		}
+		if e.Entry == nil || e.Entry.Receiver == nil {
+			return nil, errors.New("invalid notification log entry")
+		}
+		var data bytes.Buffer
+		if _, err := pbutil.WriteDelimited(&data, &e); err != nil {
+			return nil, err
+		}
+		// Group keys can be longer than the column, so the key is hashed.
+		receiver := fmt.Sprintf("%s/%s/%d", e.Entry.Receiver.GroupName, e.Entry.Receiver.Integration, e.Entry.Receiver.Idx)
+		key := fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s:%s", e.Entry.GroupKey, receiver))))
+		rows = append(rows, alertmanagerStateRow{
+			Kind:      "nflog",
+			EntryKey:  key,
+			Data:      data.Bytes(),
+			UpdatedAt: e.Entry.Timestamp.UnixNano(),
+			ExpiresAt: e.ExpiresAt.UnixNano(),
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleEvaluationTimeoutColumns creates a column for the evaluation timeout of an alert rule in the alert_rule and alert_rule_version tables.
+func AddRuleEvaluationTimeoutColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add evaluation_timeout column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "evaluation_timeout",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add evaluation_timeout column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "evaluation_timeout",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+}
@@ -0,0 +1,24 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleRecoveryConditionColumns creates a column for the recovery condition in the alert_rule and alert_rule_version tables.
+func AddRuleRecoveryConditionColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add recovery_condition column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "recovery_condition",
+		Type:     migrator.DB_NVarchar,
+		Length:   190,
+		Nullable: false,
+		Default:  "''",
+	}))
+
+	mg.AddMigration("add recovery_condition column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "recovery_condition",
+		Type:     migrator.DB_NVarchar,
+		Length:   190,
+		Nullable: false,
+		Default:  "''",
+	}))
+}
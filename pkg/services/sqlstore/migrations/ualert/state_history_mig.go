@@ -0,0 +1,43 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddStateHistoryMigrations creates the alert_state_history table used by the sql state history backend.
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "previous", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "current", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "condition_ref_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "time_nano", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "time_nano"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "time_nano"}, Type: migrator.IndexType},
+			{Cols: []string{"time_nano"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "time_nano"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index on org_id, rule_uid and time_nano to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index on org_id, dashboard_uid, panel_id and time_nano to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index on time_nano to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+	mg.AddMigration("add index on org_id and time_nano to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[3]))
+}
@@ -336,6 +336,8 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	// SQLiteDatasourceAllowedPaths are the glob patterns of the database files that SQLite data sources can open.
+	SQLiteDatasourceAllowedPaths []string
 
 	// Snapshots
 	SnapshotEnabled      bool
@@ -408,6 +410,14 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// SQLExpressionsEngine is the engine that runs SQL expressions, either "duckdb" or "embedded".
+	SQLExpressionsEngine string
+	// SQLExpressionsMaxRows is the maximum number of rows of each step of a SQL expression run by the
+	// embedded engine. 0 means no limit.
+	SQLExpressionsMaxRows int64
+	// SQLExpressionsMaxMemoryBytes is the maximum approximate memory used by a SQL expression run by the
+	// embedded engine. 0 means no limit.
+	SQLExpressionsMaxMemoryBytes int64
 
 	ImageUploadProvider string
 
@@ -727,9 +737,22 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	return nil
 }
 
-func (cfg *Cfg) readExpressionsSettings() {
+func (cfg *Cfg) readExpressionsSettings() error {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+
+	cfg.SQLExpressionsEngine = expressions.Key("sql_engine").MustString("duckdb")
+	switch cfg.SQLExpressionsEngine {
+	case "duckdb", "embedded":
+	default:
+		return fmt.Errorf("unsupported [expressions] sql_engine: %s", cfg.SQLExpressionsEngine)
+	}
+	cfg.SQLExpressionsMaxRows = expressions.Key("sql_max_rows").MustInt64(100000)
+	cfg.SQLExpressionsMaxMemoryBytes = expressions.Key("sql_max_memory_mb").MustInt64(64) * 1024 * 1024
+	if cfg.SQLExpressionsMaxRows < 0 || cfg.SQLExpressionsMaxMemoryBytes < 0 {
+		return fmt.Errorf("[expressions] sql_max_rows and sql_max_memory_mb must not be negative")
+	}
+	return nil
 }
 
 type AnnotationCleanupSettings struct {
@@ -1207,7 +1230,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.readQuotaSettings()
 
-	cfg.readExpressionsSettings()
+	if err := cfg.readExpressionsSettings(); err != nil {
+		return err
+	}
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -1944,6 +1969,7 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SQLiteDatasourceAllowedPaths = util.SplitString(sqlDatasources.Key("sqlite_allowed_paths").String())
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -60,6 +60,10 @@ const (
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
 	lokiDefaultMaxQueryLength     = 721 * time.Hour // 30d1h, matches the default value in Loki
+
+	recordingRulesDefaultTimeout       = 10 * time.Second
+	recordingRulesDefaultMaxBatchSize  = 1000
+	recordingRulesDefaultFlushInterval = 5 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -95,6 +99,7 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                RecordingRuleSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
@@ -103,6 +108,19 @@ type UnifiedAlertingSettings struct {
 
 	// Retention period for Alertmanager notification log entries.
 	NotificationLogRetention time.Duration
+
+	// EvaluationMaxSeries is the maximum number of series the queries of a rule can return in a single evaluation. It
+	// is checked before the expressions of the rule are evaluated. Zero means no limit.
+	EvaluationMaxSeries int
+	// EvaluationMaxFrameSize is the maximum number of rows of a data frame returned by a query of a rule. Zero means no
+	// limit.
+	EvaluationMaxFrameSize int
+	// OrgEvaluationLimits overrides the evaluation limits of specific organizations.
+	OrgEvaluationLimits map[int64]EvaluationLimits
+
+	// HAShardedScheduling makes every instance of the HA cluster evaluate only the rule groups it owns instead of
+	// evaluating all rules.
+	HAShardedScheduling bool
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -141,6 +159,41 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLMaxAge is the retention of the state history stored by the sql backend. Zero keeps it forever.
+	SQLMaxAge time.Duration
+}
+
+// RecordingRuleSettings configures the remote-write endpoint the recording rules write their series to.
+type RecordingRuleSettings struct {
+	Enabled           bool
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	CustomHeaders     map[string]string
+	Timeout           time.Duration
+	// MaxBatchSize is the maximum number of series sent in a single remote-write request.
+	MaxBatchSize int
+	// FlushInterval is the maximum time the series are buffered before they are sent.
+	FlushInterval time.Duration
+}
+
+// EvaluationLimits are the limits enforced on a single evaluation of an alert rule.
+type EvaluationLimits struct {
+	Timeout      time.Duration
+	MaxSeries    int
+	MaxFrameSize int
+}
+
+// GetEvaluationLimits returns the evaluation limits of the organization.
+func (u *UnifiedAlertingSettings) GetEvaluationLimits(orgID int64) EvaluationLimits {
+	if limits, ok := u.OrgEvaluationLimits[orgID]; ok {
+		return limits
+	}
+	return EvaluationLimits{
+		Timeout:      u.EvaluationTimeout,
+		MaxSeries:    u.EvaluationMaxSeries,
+		MaxFrameSize: u.EvaluationMaxFrameSize,
+	}
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -226,6 +279,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HAShardedScheduling = ua.Key("ha_sharded_scheduling").MustBool(false)
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -261,6 +315,19 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.EvaluationTimeout = uaEvaluationTimeout
 
+	uaCfg.EvaluationMaxSeries = ua.Key("evaluation_max_series").MustInt(0)
+	if uaCfg.EvaluationMaxSeries < 0 {
+		return fmt.Errorf("setting 'evaluation_max_series' in section [unified_alerting] cannot be negative")
+	}
+	uaCfg.EvaluationMaxFrameSize = ua.Key("evaluation_max_frame_size").MustInt(0)
+	if uaCfg.EvaluationMaxFrameSize < 0 {
+		return fmt.Errorf("setting 'evaluation_max_frame_size' in section [unified_alerting] cannot be negative")
+	}
+	uaCfg.OrgEvaluationLimits, err = readOrgEvaluationLimits(iniFile.Section("unified_alerting.evaluation_limits"), uaCfg.GetEvaluationLimits(0))
+	if err != nil {
+		return err
+	}
+
 	uaCfg.MaxAttempts = ua.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
 
 	uaCfg.BaseInterval = SchedulerBaseInterval
@@ -373,8 +440,44 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	stateHistorySQL := iniFile.Section("unified_alerting.state_history.sql")
+	uaCfgStateHistory.SQLMaxAge, err = gtime.ParseDuration(valueAsString(stateHistorySQL, "max_age", "0"))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'max_age' in section [unified_alerting.state_history.sql]: %w", err)
+	}
+	if uaCfgStateHistory.SQLMaxAge < 0 {
+		return fmt.Errorf("setting 'max_age' in section [unified_alerting.state_history.sql] must not be negative")
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("recording_rules")
+	uaCfgRecordingRules := RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		CustomHeaders:     iniFile.Section("recording_rules.custom_headers").KeysHash(),
+		MaxBatchSize:      recordingRules.Key("max_batch_size").MustInt(recordingRulesDefaultMaxBatchSize),
+	}
+	uaCfgRecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'timeout' in section [recording_rules]: %w", err)
+	}
+	uaCfgRecordingRules.FlushInterval, err = gtime.ParseDuration(valueAsString(recordingRules, "flush_interval", recordingRulesDefaultFlushInterval.String()))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'flush_interval' in section [recording_rules]: %w", err)
+	}
+	if uaCfgRecordingRules.Enabled && uaCfgRecordingRules.URL == "" {
+		return fmt.Errorf("setting 'url' in section [recording_rules] is required when recording rules are enabled")
+	}
+	if uaCfgRecordingRules.MaxBatchSize <= 0 {
+		return fmt.Errorf("setting 'max_batch_size' in section [recording_rules] must be greater than 0")
+	}
+	if uaCfgRecordingRules.FlushInterval <= 0 {
+		return fmt.Errorf("setting 'flush_interval' in section [recording_rules] must be greater than 0")
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
@@ -402,3 +505,40 @@ func splitTrim(s string, sep string) []string {
 	}
 	return spl
 }
+
+// readOrgEvaluationLimits reads the evaluation limits of the organizations from the child sections of the given section.
+// The child sections are named after the ID of the organization, e.g. [unified_alerting.evaluation_limits.org_2]. The
+// limits that are not set in a child section default to the ones of the [unified_alerting] section.
+func readOrgEvaluationLimits(section *ini.Section, defaults EvaluationLimits) (map[int64]EvaluationLimits, error) {
+	prefix := section.Name() + ".org_"
+	result := make(map[int64]EvaluationLimits)
+	for _, child := range section.ChildSections() {
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(child.Name(), prefix), 10, 64)
+		if !strings.HasPrefix(child.Name(), prefix) || err != nil {
+			return nil, fmt.Errorf("invalid section [%s]: the name must be [%s<org ID>]", child.Name(), prefix)
+		}
+		limits := defaults
+		// The keys of a child section fall back to the keys of its parents, so only the keys of the section itself are read.
+		keys := child.KeysHash()
+		if v, ok := keys["evaluation_timeout"]; ok {
+			limits.Timeout, err = gtime.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse setting 'evaluation_timeout' in section [%s]: %w", child.Name(), err)
+			}
+		}
+		if v, ok := keys["evaluation_max_series"]; ok {
+			limits.MaxSeries, err = strconv.Atoi(v)
+			if err != nil || limits.MaxSeries < 0 {
+				return nil, fmt.Errorf("setting 'evaluation_max_series' in section [%s] must be a non-negative integer", child.Name())
+			}
+		}
+		if v, ok := keys["evaluation_max_frame_size"]; ok {
+			limits.MaxFrameSize, err = strconv.Atoi(v)
+			if err != nil || limits.MaxFrameSize < 0 {
+				return nil, fmt.Errorf("setting 'evaluation_max_frame_size' in section [%s] must be a non-negative integer", child.Name())
+			}
+		}
+		result[orgID] = limits
+	}
+	return result, nil
+}
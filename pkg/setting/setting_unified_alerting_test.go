@@ -25,6 +25,7 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, time.Minute, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.False(t, cfg.UnifiedAlerting.HAShardedScheduling)
 	}
 
 	// With peers set, it correctly parses them.
@@ -34,10 +35,13 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.NoError(t, err)
 		_, err = s.NewKey("ha_peers", "hostname1:9090,hostname2:9090,hostname3:9090")
 		require.NoError(t, err)
+		_, err = s.NewKey("ha_sharded_scheduling", "true")
+		require.NoError(t, err)
 
 		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 3)
 		require.ElementsMatch(t, []string{"hostname1:9090", "hostname2:9090", "hostname3:9090"}, cfg.UnifiedAlerting.HAPeers)
+		require.True(t, cfg.UnifiedAlerting.HAShardedScheduling)
 	}
 
 	t.Run("should read 'scheduler_tick_interval'", func(t *testing.T) {
@@ -298,3 +302,110 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordingRuleSettings(t *testing.T) {
+	read := func(t *testing.T, options map[string]string) (*Cfg, error) {
+		f := ini.Empty()
+		section, err := f.NewSection("recording_rules")
+		require.NoError(t, err)
+		for k, v := range options {
+			_, err = section.NewKey(k, v)
+			require.NoError(t, err)
+		}
+		cfg := NewCfg()
+		cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("should use defaults", func(t *testing.T) {
+		cfg, err := read(t, nil)
+		require.NoError(t, err)
+		require.False(t, cfg.UnifiedAlerting.RecordingRules.Enabled)
+		require.Equal(t, recordingRulesDefaultTimeout, cfg.UnifiedAlerting.RecordingRules.Timeout)
+		require.Equal(t, recordingRulesDefaultMaxBatchSize, cfg.UnifiedAlerting.RecordingRules.MaxBatchSize)
+		require.Equal(t, recordingRulesDefaultFlushInterval, cfg.UnifiedAlerting.RecordingRules.FlushInterval)
+	})
+
+	t.Run("should read the settings", func(t *testing.T) {
+		cfg, err := read(t, map[string]string{
+			"enabled":             "true",
+			"url":                 "http://localhost:9090/api/v1/write",
+			"basic_auth_username": "user",
+			"basic_auth_password": "password",
+			"timeout":             "30s",
+			"max_batch_size":      "100",
+			"flush_interval":      "1s",
+		})
+		require.NoError(t, err)
+		require.Equal(t, RecordingRuleSettings{
+			Enabled:           true,
+			URL:               "http://localhost:9090/api/v1/write",
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			CustomHeaders:     map[string]string{},
+			Timeout:           30 * time.Second,
+			MaxBatchSize:      100,
+			FlushInterval:     time.Second,
+		}, cfg.UnifiedAlerting.RecordingRules)
+	})
+
+	t.Run("should fail if enabled without url", func(t *testing.T) {
+		_, err := read(t, map[string]string{"enabled": "true"})
+		require.ErrorContains(t, err, "setting 'url' in section [recording_rules] is required")
+	})
+
+	t.Run("should fail if batch size is not positive", func(t *testing.T) {
+		_, err := read(t, map[string]string{"max_batch_size": "0"})
+		require.ErrorContains(t, err, "max_batch_size")
+	})
+}
+
+func TestEvaluationLimitsSettings(t *testing.T) {
+	read := func(t *testing.T, content string) (*Cfg, error) {
+		f, err := ini.Load([]byte(content))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+		return cfg, cfg.ReadUnifiedAlertingSettings(f)
+	}
+
+	t.Run("should use defaults", func(t *testing.T) {
+		cfg, err := read(t, "")
+		require.NoError(t, err)
+		require.Equal(t, EvaluationLimits{Timeout: evaluatorDefaultEvaluationTimeout}, cfg.UnifiedAlerting.GetEvaluationLimits(1))
+		require.Empty(t, cfg.UnifiedAlerting.OrgEvaluationLimits)
+	})
+
+	t.Run("should read the limits of the organizations", func(t *testing.T) {
+		cfg, err := read(t, `
+[unified_alerting]
+evaluation_timeout = 20s
+evaluation_max_series = 1000
+evaluation_max_frame_size = 5000
+
+[unified_alerting.evaluation_limits.org_2]
+evaluation_timeout = 5s
+
+[unified_alerting.evaluation_limits.org_3]
+evaluation_max_series = 10
+evaluation_max_frame_size = 0
+`)
+		require.NoError(t, err)
+		require.Equal(t, EvaluationLimits{Timeout: 20 * time.Second, MaxSeries: 1000, MaxFrameSize: 5000}, cfg.UnifiedAlerting.GetEvaluationLimits(1))
+		require.Equal(t, EvaluationLimits{Timeout: 5 * time.Second, MaxSeries: 1000, MaxFrameSize: 5000}, cfg.UnifiedAlerting.GetEvaluationLimits(2))
+		require.Equal(t, EvaluationLimits{Timeout: 20 * time.Second, MaxSeries: 10, MaxFrameSize: 0}, cfg.UnifiedAlerting.GetEvaluationLimits(3))
+	})
+
+	t.Run("should fail if a limit is negative", func(t *testing.T) {
+		_, err := read(t, "[unified_alerting]\nevaluation_max_series = -1")
+		require.ErrorContains(t, err, "evaluation_max_series")
+
+		_, err = read(t, "[unified_alerting.evaluation_limits.org_2]\nevaluation_max_frame_size = -1")
+		require.ErrorContains(t, err, "evaluation_max_frame_size")
+	})
+
+	t.Run("should fail if the section is not named after an organization", func(t *testing.T) {
+		_, err := read(t, "[unified_alerting.evaluation_limits.main]\nevaluation_timeout = 5s")
+		require.ErrorContains(t, err, "invalid section [unified_alerting.evaluation_limits.main]")
+	})
+}
@@ -8,8 +8,9 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/lib/pq"
 
-	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
@@ -73,12 +74,12 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
 		}
-		return fmt.Sprintf("%s AS \"time\"", args[0]), nil
+		return fmt.Sprintf("%s AS %s", args[0], pq.QuoteIdentifier("time")), nil
 	case "__timeEpoch":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
 		}
-		return fmt.Sprintf("extract(epoch from %s) as \"time\"", args[0]), nil
+		return fmt.Sprintf("extract(epoch from %s) as %s", args[0], pq.QuoteIdentifier("time")), nil
 	case "__timeFilter":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
@@ -116,7 +117,7 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 	case "__timeGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
 		if err == nil {
-			return tg + " AS \"time\"", nil
+			return tg + " AS " + pq.QuoteIdentifier("time"), nil
 		}
 		return "", err
 	case "__unixEpochFilter":
@@ -151,7 +152,7 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 	case "__unixEpochGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
 		if err == nil {
-			return tg + " AS \"time\"", nil
+			return tg + " AS " + pq.QuoteIdentifier("time"), nil
 		}
 		return "", err
 	default:
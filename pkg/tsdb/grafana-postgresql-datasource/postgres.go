@@ -3,12 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -19,7 +17,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana/pkg/setting"
-	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 func ProvideService(cfg *setting.Cfg) *Service {
@@ -56,45 +54,25 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*sql.DB, *sqleng.DataSourceHandler, error) {
-	connector, err := pq.NewConnector(cnnstr)
-	if err != nil {
-		logger.Error("postgres connector creation failed", "error", err)
-		return nil, nil, fmt.Errorf("postgres connector creation failed")
-	}
-
-	proxyClient, err := settings.ProxyClient(ctx)
-	if err != nil {
-		logger.Error("postgres proxy creation failed", "error", err)
-		return nil, nil, fmt.Errorf("postgres proxy creation failed")
-	}
-
-	if proxyClient.SecureSocksProxyEnabled() {
-		dialer, err := proxyClient.NewSecureSocksProxyContextDialer()
-		if err != nil {
-			logger.Error("postgres proxy creation failed", "error", err)
-			return nil, nil, fmt.Errorf("postgres proxy creation failed")
-		}
-		postgresDialer := newPostgresProxyDialer(dialer)
-		// update the postgres dialer with the proxy dialer
-		connector.Dialer(postgresDialer)
-	}
-
 	config := sqleng.DataPluginConfiguration{
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 		RowLimit:          rowLimit,
 	}
 
-	queryResultTransformer := postgresQueryResultTransformer{}
-
-	db := sql.OpenDB(connector)
+	dialect := &postgresDialect{
+		SQLMacroEngine:                 newPostgresMacroEngine(dsInfo.JsonData.Timescaledb),
+		postgresQueryResultTransformer: &postgresQueryResultTransformer{},
+		cnnstr:                         cnnstr,
+		logger:                         logger,
+	}
 
-	db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
-	db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
-	db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+	db, err := sqleng.OpenDB(ctx, settings, dsInfo, dialect)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &queryResultTransformer, newPostgresMacroEngine(dsInfo.JsonData.Timescaledb),
-		logger)
+	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, dialect, logger)
 	if err != nil {
 		logger.Error("Failed connecting to Postgres", "err", err)
 		return nil, nil, err
@@ -113,34 +91,16 @@ func (s *Service) newInstanceSettings() datasource.InstanceFactoryFunc {
 			return nil, err
 		}
 
-		jsonData := sqleng.JsonData{
+		dsInfo, err := sqleng.NewDataSourceInfo(settings, sqleng.JsonData{
 			MaxOpenConns:        sqlCfg.DefaultMaxOpenConns,
 			MaxIdleConns:        sqlCfg.DefaultMaxIdleConns,
 			ConnMaxLifetime:     sqlCfg.DefaultMaxConnLifetimeSeconds,
 			Timescaledb:         false,
 			ConfigurationMethod: "file-path",
 			SecureDSProxy:       false,
-		}
-
-		err = json.Unmarshal(settings.JSONData, &jsonData)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("error reading settings: %w", err)
-		}
-
-		database := jsonData.Database
-		if database == "" {
-			database = settings.Database
-		}
-
-		dsInfo := sqleng.DataSourceInfo{
-			JsonData:                jsonData,
-			URL:                     settings.URL,
-			User:                    settings.User,
-			Database:                database,
-			ID:                      settings.ID,
-			Updated:                 settings.Updated,
-			UID:                     settings.UID,
-			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+			return nil, err
 		}
 
 		cnnstr, err := s.generateConnectionString(dsInfo)
@@ -165,6 +125,48 @@ func (s *Service) newInstanceSettings() datasource.InstanceFactoryFunc {
 	}
 }
 
+// postgresDialect is the PostgreSQL dialect of the SQL engine. It connects with the connection string that was
+// generated from the settings of its data source instance when the instance was created.
+type postgresDialect struct {
+	sqleng.SQLMacroEngine
+	*postgresQueryResultTransformer
+
+	cnnstr string
+	logger log.Logger
+}
+
+// QuoteIdentifier quotes an identifier with double quotes.
+func (d *postgresDialect) QuoteIdentifier(identifier string) string {
+	return pq.QuoteIdentifier(identifier)
+}
+
+func (d *postgresDialect) Connect(ctx context.Context, settings backend.DataSourceInstanceSettings, _ sqleng.DataSourceInfo) (*sql.DB, error) {
+	connector, err := pq.NewConnector(d.cnnstr)
+	if err != nil {
+		d.logger.Error("postgres connector creation failed", "error", err)
+		return nil, fmt.Errorf("postgres connector creation failed")
+	}
+
+	proxyClient, err := settings.ProxyClient(ctx)
+	if err != nil {
+		d.logger.Error("postgres proxy creation failed", "error", err)
+		return nil, fmt.Errorf("postgres proxy creation failed")
+	}
+
+	if proxyClient.SecureSocksProxyEnabled() {
+		dialer, err := proxyClient.NewSecureSocksProxyContextDialer()
+		if err != nil {
+			d.logger.Error("postgres proxy creation failed", "error", err)
+			return nil, fmt.Errorf("postgres proxy creation failed")
+		}
+		postgresDialer := newPostgresProxyDialer(dialer)
+		// update the postgres dialer with the proxy dialer
+		connector.Dialer(postgresDialer)
+	}
+
+	return sql.OpenDB(connector), nil
+}
+
 // escape single quotes and backslashes in Postgres connection string parameters.
 func escape(input string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(input, `\`, `\\`), "'", `\'`)
@@ -263,14 +265,7 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 		return nil, err
 	}
 
-	err = dsHandler.Ping()
-
-	if err != nil {
-		s.logger.Error("Check health failed", "error", err)
-		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, err).Error()}, nil
-	}
-
-	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+	return dsHandler.CheckHealth(ctx), nil
 }
 
 func (t *postgresQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
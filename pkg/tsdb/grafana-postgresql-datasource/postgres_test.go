@@ -15,7 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/setting"
-	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	_ "github.com/lib/pq"
 )
@@ -1414,3 +1414,10 @@ func postgresTestDBConnString() string {
 	return fmt.Sprintf("user=grafanatest password=grafanatest host=%s port=%s dbname=grafanadstest sslmode=disable",
 		host, port)
 }
+
+func TestQuoteIdentifier(t *testing.T) {
+	dialect := &postgresDialect{}
+	require.Equal(t, `"metric"`, dialect.QuoteIdentifier("metric"))
+	require.Equal(t, `"my table"`, dialect.QuoteIdentifier("my table"))
+	require.Equal(t, `"a""b"`, dialect.QuoteIdentifier(`a"b`))
+}
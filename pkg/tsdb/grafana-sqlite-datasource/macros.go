@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const rsIdentifier = `([_a-zA-Z0-9]+)`
+const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+
+var macroRegExp = regexp.MustCompile(sExpr)
+
+// restrictedRegExp matches the statements that begin with VACUUM, after any whitespace and comments. VACUUM can write
+// a copy of the database to a file even though the database is opened read-only, and the authorizer cannot deny it.
+// ATTACH and DETACH are denied by the authorizer.
+var restrictedRegExp = regexp.MustCompile(`(?is)(^|;)(\s|--[^\n]*|/\*.*?\*/)*vacuum\b`)
+
+type sqliteMacroEngine struct {
+	*sqleng.SQLMacroEngineBase
+	logger    log.Logger
+	userError string
+}
+
+func newSQLiteMacroEngine(logger log.Logger, userFacingDefaultError string) sqleng.SQLMacroEngine {
+	return &sqliteMacroEngine{
+		SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase(),
+		logger:             logger,
+		userError:          userFacingDefaultError,
+	}
+}
+
+func (m *sqliteMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	if restrictedRegExp.MatchString(sql) {
+		m.logger.Error("VACUUM not allowed in query")
+		return "", fmt.Errorf("invalid query - %s", m.userError)
+	}
+
+	var macroError error
+
+	sql = m.ReplaceAllStringSubmatchFunc(macroRegExp, sql, func(groups []string) string {
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.Trim(arg, " ")
+		}
+		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
+		if err != nil && macroError == nil {
+			macroError = err
+			return "macro_error()"
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
+// evaluateMacro evaluates a macro. SQLite has no date and time types, the time macros expect the time column to
+// contain text in a format of the SQLite date and time functions, such as "2006-01-02 15:04:05" in UTC, and the unix
+// epoch macros expect it to contain a unix timestamp.
+func (m *sqliteMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+	switch name {
+	case "__timeEpoch", "__time":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER) AS time_sec", args[0]), nil
+	case "__timeFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s BETWEEN datetime(%d, 'unixepoch') AND datetime(%d, 'unixepoch')", args[0], timeRange.From.UTC().Unix(), timeRange.To.UTC().Unix()), nil
+	case "__timeFrom":
+		return fmt.Sprintf("datetime(%d, 'unixepoch')", timeRange.From.UTC().Unix()), nil
+	case "__timeTo":
+		return fmt.Sprintf("datetime(%d, 'unixepoch')", timeRange.To.UTC().Unix()), nil
+	case "__timeGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval", name)
+		}
+		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+		if err != nil {
+			return "", fmt.Errorf("error parsing interval %v", args[1])
+		}
+		if len(args) == 3 {
+			err := sqleng.SetupFillmode(query, interval, args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("CAST(strftime('%%s', %s) / %.0f AS INTEGER) * %.0f", args[0], interval.Seconds(), interval.Seconds()), nil
+	case "__timeGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		if err == nil {
+			return tg + " AS " + quoteIdentifier("time"), nil
+		}
+		return "", err
+	case "__unixEpochFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().Unix(), args[0], timeRange.To.UTC().Unix()), nil
+	case "__unixEpochNanoFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().UnixNano(), args[0], timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochNanoFrom":
+		return fmt.Sprintf("%d", timeRange.From.UTC().UnixNano()), nil
+	case "__unixEpochNanoTo":
+		return fmt.Sprintf("%d", timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochGroup":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+		}
+		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
+		if err != nil {
+			return "", fmt.Errorf("error parsing interval %v", args[1])
+		}
+		if len(args) == 3 {
+			err := sqleng.SetupFillmode(query, interval, args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("CAST(%s / %v AS INTEGER) * %v", args[0], interval.Seconds(), interval.Seconds()), nil
+	case "__unixEpochGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		if err == nil {
+			return tg + " AS " + quoteIdentifier("time"), nil
+		}
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown macro %v", name)
+	}
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSQLiteMacroEngine(backend.NewLoggerWith("logger", "test"), "inspect Grafana server log for details")
+	query := &backend.DataQuery{}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("interpolate __time function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+		require.NoError(t, err)
+		require.Equal(t, "select CAST(strftime('%s', time_column) AS INTEGER) AS time_sec", sql)
+	})
+
+	t.Run("interpolate __timeFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("WHERE time_column BETWEEN datetime(%d, 'unixepoch') AND datetime(%d, 'unixepoch')", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __timeFrom and __timeTo functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__timeFrom(), $__timeTo()")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("select datetime(%d, 'unixepoch'), datetime(%d, 'unixepoch')", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __timeGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY CAST(strftime('%s', time_column) / 300 AS INTEGER) * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("interpolate __timeGroup function with fill mode", func(t *testing.T) {
+		q := &backend.DataQuery{JSON: []byte("{}")}
+		_, err := engine.Interpolate(q, timeRange, "GROUP BY $__timeGroup(time_column, '5m', previous)")
+		require.NoError(t, err)
+		require.Contains(t, string(q.JSON), `"fillMode":"previous"`)
+	})
+
+	t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochFilter(time)")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __unixEpochNanoFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochNanoFilter(time)")
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.UnixNano(), to.UnixNano()), sql)
+	})
+
+	t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroup(time_column,'5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "SELECT CAST(time_column / 300 AS INTEGER) * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("return an error for an unknown macro", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "select $__unknown(time)")
+		require.EqualError(t, err, "unknown macro __unknown")
+	})
+
+	t.Run("reject vacuum", func(t *testing.T) {
+		for _, sql := range []string{
+			"VACUUM INTO '/tmp/copy.db'",
+			"select 1; vacuum",
+			"  -- copy\n /* the database */ vacuum main into '/tmp/copy.db'",
+		} {
+			_, err := engine.Interpolate(query, timeRange, sql)
+			require.EqualError(t, err, "invalid query - inspect Grafana server log for details")
+		}
+
+		for _, sql := range []string{
+			"select attached_at from vacuums",
+			"select * from logs where action = 'vacuum'",
+			"select vacuum_count from stats -- vacuum",
+			"select * from logs where action = 'attach' or action = 'detach'",
+		} {
+			interpolated, err := engine.Interpolate(query, timeRange, sql)
+			require.NoError(t, err)
+			require.Equal(t, sql, interpolated)
+		}
+	})
+}
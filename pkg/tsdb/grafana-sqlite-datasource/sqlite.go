@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// driverName is the name of the SQLite driver of the data source, which only authorizes reading the database.
+const driverName = "sqlite3_grafana_datasource"
+
+// sqliteRecursive is the authorizer action of recursive common table expressions, which the driver does not export.
+const sqliteRecursive = 33
+
+// errPathNotAllowed is returned when the database file of a data source is not in the allowed paths.
+var errPathNotAllowed = errors.New("database file is not in the allowed paths")
+
+// allowedPragmas are the pragmas that queries can use, to read the schema of the database.
+var allowedPragmas = map[string]bool{
+	"table_info":       true,
+	"table_xinfo":      true,
+	"table_list":       true,
+	"index_list":       true,
+	"index_info":       true,
+	"foreign_key_list": true,
+}
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(authorize)
+			return nil
+		},
+	})
+}
+
+// authorize only authorizes the actions of queries that read the database.
+func authorize(action int, arg1, arg2, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	case sqlite3.SQLITE_FUNCTION:
+		if arg2 == "load_extension" {
+			return sqlite3.SQLITE_DENY
+		}
+		return sqlite3.SQLITE_OK
+	case sqlite3.SQLITE_PRAGMA:
+		if allowedPragmas[arg1] {
+			return sqlite3.SQLITE_OK
+		}
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(newInstanceSettings(cfg, logger)),
+		logger: logger,
+	}
+}
+
+func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		dsInfo, err := sqleng.NewDataSourceInfo(settings, sqleng.JsonData{
+			MaxOpenConns:    cfg.SqlDatasourceMaxOpenConnsDefault,
+			MaxIdleConns:    cfg.SqlDatasourceMaxIdleConnsDefault,
+			ConnMaxLifetime: cfg.SqlDatasourceMaxConnLifetimeDefault,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		dialect := &sqliteDialect{
+			SQLMacroEngine:               newSQLiteMacroEngine(logger, cfg.UserFacingDefaultError),
+			sqliteQueryResultTransformer: &sqliteQueryResultTransformer{},
+			allowedPaths:                 cfg.SQLiteDatasourceAllowedPaths,
+		}
+
+		db, err := sqleng.OpenDB(ctx, settings, dsInfo, dialect)
+		if err != nil {
+			logger.Error("Failed connecting to SQLite", "err", err)
+			return nil, err
+		}
+
+		logger.Debug("Successfully connected to SQLite")
+		return sqleng.NewQueryDataHandler(cfg.UserFacingDefaultError, db, config, dialect, logger)
+	}
+}
+
+// sqliteDialect is the SQLite dialect of the SQL engine. The database of a data source is the path of its file, which
+// must match one of the allowed paths. It is opened read-only.
+type sqliteDialect struct {
+	sqleng.SQLMacroEngine
+	*sqliteQueryResultTransformer
+
+	allowedPaths []string
+}
+
+// QuoteIdentifier quotes an identifier with double quotes.
+func (d *sqliteDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifier(identifier)
+}
+
+// quoteIdentifier quotes an identifier with double quotes, so that the macros can quote the aliases they add.
+func quoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (d *sqliteDialect) Connect(_ context.Context, _ backend.DataSourceInstanceSettings, dsInfo sqleng.DataSourceInfo) (*sql.DB, error) {
+	path, err := resolvePath(dsInfo.Database, d.allowedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Path:     path,
+		RawQuery: "mode=ro&_query_only=true",
+	}).String()
+	return sql.Open(driverName, dsn)
+}
+
+// resolvePath returns the absolute path of a database file, with its symbolic links resolved. Both the path and its
+// resolved path must match one of the allowed glob patterns. The path is checked before its symbolic links are
+// resolved, so that nothing outside the allowed paths is accessed, and every failure returns errPathNotAllowed, so
+// that the error does not tell whether a file exists.
+func resolvePath(path string, allowedPaths []string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", errPathNotAllowed
+	}
+	path = filepath.Clean(path)
+	if !isAllowedPath(path, allowedPaths) {
+		return "", errPathNotAllowed
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil || !isAllowedPath(resolved, allowedPaths) {
+		return "", errPathNotAllowed
+	}
+	return resolved, nil
+}
+
+// isAllowedPath returns whether a path matches one of the allowed glob patterns. Invalid patterns match no path.
+func isAllowedPath(path string, allowedPaths []string) bool {
+	for _, pattern := range allowedPaths {
+		if matched, err := filepath.Match(filepath.Clean(pattern), path); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// CheckHealth pings the connected SQL database
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.CheckHealth(ctx), nil
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// DynamicTypes returns true, since the columns of SQLite can contain values of any type, and the columns of
+// expressions have no declared type.
+func (t *sqliteQueryResultTransformer) DynamicTypes() bool {
+	return true
+}
@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResolvePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.db")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	// The temporary directory can itself be behind a symbolic link, such as on macOS.
+	resolved, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	resolvedDir := filepath.Dir(resolved)
+
+	allowed := []string{dir + "/*.db", resolvedDir + "/*.db"}
+
+	t.Run("allows a path matching a pattern", func(t *testing.T) {
+		p, err := resolvePath(path, append([]string{"/other/*.db"}, allowed...))
+		require.NoError(t, err)
+		require.Equal(t, resolved, p)
+	})
+
+	t.Run("cleans the path before matching it", func(t *testing.T) {
+		p, err := resolvePath(filepath.Join(dir, "sub", "..", "metrics.db"), allowed)
+		require.NoError(t, err)
+		require.Equal(t, resolved, p)
+
+		_, err = resolvePath(filepath.Join(dir, "..", "metrics.db"), []string{dir + "/*.db", dir + "/../../*.db"})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("rejects a path matching no pattern", func(t *testing.T) {
+		_, err := resolvePath(path, []string{dir + "/*.sqlite", resolvedDir + "/*.sqlite"})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("rejects any path without patterns", func(t *testing.T) {
+		_, err := resolvePath(path, nil)
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("matches a symbolic link and its target", func(t *testing.T) {
+		linkDir := t.TempDir()
+		link := filepath.Join(linkDir, "link.db")
+		require.NoError(t, os.Symlink(path, link))
+
+		_, err := resolvePath(link, []string{linkDir + "/*.db"})
+		require.ErrorIs(t, err, errPathNotAllowed)
+
+		_, err = resolvePath(link, allowed)
+		require.ErrorIs(t, err, errPathNotAllowed)
+
+		p, err := resolvePath(link, append([]string{linkDir + "/*.db"}, allowed...))
+		require.NoError(t, err)
+		require.Equal(t, resolved, p)
+	})
+
+	t.Run("rejects a relative path", func(t *testing.T) {
+		_, err := resolvePath("metrics.db", []string{"*.db"})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("rejects an empty path", func(t *testing.T) {
+		_, err := resolvePath("", []string{"*"})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+
+	t.Run("rejects a missing file", func(t *testing.T) {
+		_, err := resolvePath(filepath.Join(dir, "missing.db"), allowed)
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE metric (ts TEXT, epoch INTEGER, host TEXT, value REAL);
+		INSERT INTO metric VALUES
+			('2024-01-01 00:00:00', 1704067200, 'a', 1.5),
+			('2024-01-01 00:01:00', 1704067260, 'b', 2.5),
+			('2024-01-01 00:02:00', 1704067320, 'a', 3.5);`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	resolved, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.DataProxyRowLimit = 1000
+	cfg.UserFacingDefaultError = "query failed"
+	cfg.SQLiteDatasourceAllowedPaths = []string{filepath.Dir(path) + "/*.db", filepath.Dir(resolved) + "/*.db"}
+	s := ProvideService(cfg)
+
+	pluginCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			ID:       1,
+			JSONData: []byte(`{"database": "` + path + `"}`),
+		},
+	}
+	from := time.Unix(1704067200, 0)
+	query := func(t *testing.T, rawQuery string) backend.DataResponse {
+		t.Helper()
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{
+				{
+					RefID:         "A",
+					JSON:          []byte(rawQuery),
+					TimeRange:     backend.TimeRange{From: from, To: from.Add(time.Hour)},
+					Interval:      time.Minute,
+					MaxDataPoints: 100,
+				},
+			},
+		})
+		require.NoError(t, err)
+		return res.Responses["A"]
+	}
+
+	t.Run("time series of an aggregation grouped by time", func(t *testing.T) {
+		res := query(t, `{"format": "time_series", "rawSql": "SELECT $__timeGroupAlias(ts, '2m'), avg(value) AS value FROM metric WHERE $__timeFilter(ts) GROUP BY 1 ORDER BY 1"}`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Unix(1704067200, 0).UTC(), frame.Fields[0].At(0).(*time.Time).UTC())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 3.5, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("time series with a metric column", func(t *testing.T) {
+		res := query(t, `{"format": "time_series", "rawSql": "SELECT epoch AS time, host AS metric, value FROM metric WHERE $__unixEpochFilter(epoch) ORDER BY 1"}`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "a", frame.Fields[1].Name)
+		assert.Equal(t, "b", frame.Fields[2].Name)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		res := query(t, `{"format": "table", "rawSql": "SELECT host, value FROM metric ORDER BY epoch"}`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "a", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("columns of a table", func(t *testing.T) {
+		res := query(t, `{"format": "table", "rawSql": "SELECT name FROM pragma_table_info('metric')"}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 4, res.Frames[0].Rows())
+	})
+
+	t.Run("rejects statements that do not read the database", func(t *testing.T) {
+		for _, rawSQL := range []string{
+			"DELETE FROM metric",
+			"CREATE TABLE other (id INTEGER)",
+			"PRAGMA query_only = false",
+			"SELECT load_extension('/tmp/ext.so')",
+			"ATTACH DATABASE '/tmp/other.db' AS other",
+			"DETACH DATABASE main",
+		} {
+			res := query(t, `{"format": "table", "rawSql": "`+rawSQL+`"}`)
+			require.ErrorContains(t, res.Error, "not authorized", rawSQL)
+		}
+
+		res := query(t, `{"format": "table", "rawSql": "SELECT count(*) AS count FROM metric WHERE host != 'attach'"}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 3.0, *res.Frames[0].Fields[0].At(0).(*float64))
+	})
+
+	t.Run("check health", func(t *testing.T) {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginCtx})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("rejects a database file that is not allowed", func(t *testing.T) {
+		_, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					ID:       2,
+					JSONData: []byte(`{"database": "/etc/passwd"}`),
+				},
+			},
+		})
+		require.ErrorIs(t, err, errPathNotAllowed)
+	})
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	dialect := &sqliteDialect{}
+	assert.Equal(t, `"metric"`, dialect.QuoteIdentifier("metric"))
+	assert.Equal(t, `"my table"`, dialect.QuoteIdentifier("my table"))
+	assert.Equal(t, `"a""b"`, dialect.QuoteIdentifier(`a"b`))
+}
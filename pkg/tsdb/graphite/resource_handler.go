@@ -0,0 +1,249 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// infinityDefaultRegex matches the Infinity default values of function parameters, that Graphite 1.1.7 returns in
+// the function list although they are not valid JSON. See https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefaultRegex = regexp.MustCompile(`"default": ?Infinity`)
+
+// errMissingParameter is returned when a required parameter of a resource request is missing.
+var errMissingParameter = errors.New("missing parameter")
+
+// graphiteError is returned when Graphite responds to a resource request with an unsuccessful status code.
+type graphiteError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *graphiteError) Error() string {
+	return fmt.Sprintf("request failed, status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type resourceHandlerFunc func(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleResourceReq(s.handleMetricsFind, "query", "from", "until"))
+	mux.HandleFunc("/metrics/expand", s.handleResourceReq(s.handleMetricsExpand, "query", "from", "until"))
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResourceReq(s.handleTagsAutoComplete, "expr", "tagPrefix", "limit", "from", "until"))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResourceReq(s.handleTagValuesAutoComplete, "expr", "tag", "valuePrefix", "limit", "from", "until"))
+	mux.HandleFunc("/functions", s.handleResourceReq(s.handleFunctions))
+	return mux
+}
+
+// handleResourceReq returns an HTTP handler that calls the resource handler with the given parameters of the
+// request, which can be passed in the query string or in a form body, and writes its result as JSON.
+func (s *Service) handleResourceReq(handler resourceHandlerFunc, paramNames ...string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			writeErrorResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse request: %v", err))
+			return
+		}
+		params := url.Values{}
+		for _, name := range paramNames {
+			if values, ok := req.Form[name]; ok {
+				params[name] = values
+			}
+		}
+
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to get data source: %v", err))
+			return
+		}
+
+		result, err := handler(ctx, dsInfo, params)
+		if err != nil {
+			var gErr *graphiteError
+			switch {
+			case errors.Is(err, errMissingParameter):
+				writeErrorResponse(rw, http.StatusBadRequest, err.Error())
+			case errors.As(err, &gErr):
+				writeErrorResponse(rw, gErr.StatusCode, err.Error())
+			default:
+				writeErrorResponse(rw, http.StatusBadGateway, err.Error())
+			}
+			return
+		}
+
+		body, err := json.Marshal(result)
+		if err != nil {
+			writeErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response: %v", err))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		writeResponse(rw, http.StatusOK, body)
+	}
+}
+
+func (s *Service) handleMetricsFind(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	if params.Get("query") == "" {
+		return nil, fmt.Errorf("%w: query", errMissingParameter)
+	}
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodPost, "metrics/find", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []metricFindDTO
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graphite response: %w", err)
+	}
+	result := make([]MetricFindResult, 0, len(metrics))
+	for _, metric := range metrics {
+		result = append(result, MetricFindResult{
+			Text:          metric.Text,
+			ID:            metric.ID,
+			Expandable:    bool(metric.Expandable),
+			Leaf:          bool(metric.Leaf),
+			AllowChildren: bool(metric.AllowChildren),
+		})
+	}
+	return result, nil
+}
+
+func (s *Service) handleMetricsExpand(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	if params.Get("query") == "" {
+		return nil, fmt.Errorf("%w: query", errMissingParameter)
+	}
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "metrics/expand", params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := MetricsExpandResult{Results: []string{}}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graphite response: %w", err)
+	}
+	return result, nil
+}
+
+func (s *Service) handleTagsAutoComplete(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "tags/autoComplete/tags", params)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTags(body)
+}
+
+func (s *Service) handleTagValuesAutoComplete(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	if params.Get("tag") == "" {
+		return nil, fmt.Errorf("%w: tag", errMissingParameter)
+	}
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "tags/autoComplete/values", params)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTags(body)
+}
+
+func (s *Service) handleFunctions(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "functions", params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1e9999 is valid JSON and is parsed as Infinity by the browser
+	body = infinityDefaultRegex.ReplaceAll(body, []byte(`"default": 1e9999`))
+	var functions map[string]FuncDef
+	if err := json.Unmarshal(body, &functions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graphite response: %w", err)
+	}
+	return functions, nil
+}
+
+func unmarshalTags(body []byte) ([]string, error) {
+	tags := []string{}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal graphite response: %w", err)
+	}
+	return tags, nil
+}
+
+// doResourceRequest sends a request to an endpoint of the Graphite API and returns the body of the response.
+// The parameters are sent in a form body for POST requests and in the query string otherwise.
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, method string, endpoint string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = strings.NewReader(params.Encode())
+	} else {
+		u.RawQuery = params.Encode()
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("endpoint", endpoint),
+		attribute.Int64("datasource_id", dsInfo.Id),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		logger.FromContext(ctx).Info("Resource request failed", "endpoint", endpoint, "status", res.Status, "body", string(body))
+		err := &graphiteError{StatusCode: res.StatusCode, Body: string(body)}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return body, nil
+}
+
+func writeResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeErrorResponse(rw http.ResponseWriter, code int, msg string) {
+	body, _ := json.Marshal(map[string]string{"message": msg})
+	rw.Header().Set("Content-Type", "application/json")
+	writeResponse(rw, code, body)
+}
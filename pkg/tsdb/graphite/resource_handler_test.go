@@ -0,0 +1,207 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+// stubGraphite is a Graphite server that records the requests it receives and responds with fixed bodies.
+type stubGraphite struct {
+	*httptest.Server
+	requests  []*http.Request
+	forms     []url.Values
+	responses map[string]string
+}
+
+func newStubGraphite(t *testing.T, responses map[string]string) *stubGraphite {
+	t.Helper()
+	stub := &stubGraphite{responses: responses}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		stub.requests = append(stub.requests, req)
+		stub.forms = append(stub.forms, req.Form)
+		body, ok := stub.responses[req.URL.Path]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func newResourceService(stub *stubGraphite) *Service {
+	s := &Service{
+		im: datasource.NewInstanceManager(func(_ context.Context, _ backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return datasourceInfo{HTTPClient: stub.Client(), URL: stub.URL + "/graphite"}, nil
+		}),
+		tracer: tracing.InitializeTracerForTest(),
+	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func callResource(t *testing.T, s *Service, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+	t.Helper()
+	req.PluginContext = backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}}
+	sender := &fakeSender{}
+	require.NoError(t, s.CallResource(context.Background(), req, sender))
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+func TestCallResource(t *testing.T) {
+	stub := newStubGraphite(t, map[string]string{
+		"/graphite/metrics/find": `[
+			{"text": "cpu", "id": "servers.web1.cpu", "leaf": 0, "expandable": 1, "allowChildren": 1},
+			{"text": "uptime", "id": "servers.web1.uptime", "leaf": true, "expandable": false, "allowChildren": false}
+		]`,
+		"/graphite/metrics/expand":           `{"results": ["servers.web1.cpu", "servers.web2.cpu"]}`,
+		"/graphite/tags/autoComplete/tags":   `["datacenter", "server"]`,
+		"/graphite/tags/autoComplete/values": `["web1", "web2"]`,
+		"/graphite/functions": `{
+			"removeAboveValue": {
+				"name": "removeAboveValue",
+				"function": "removeAboveValue(seriesList, n)",
+				"description": "Removes data above the given threshold",
+				"module": "graphite.render.functions",
+				"group": "Filter Data",
+				"params": [
+					{"name": "seriesList", "type": "seriesList", "required": true},
+					{"name": "n", "type": "integer", "default": Infinity}
+				]
+			}
+		}`,
+	})
+	s := newResourceService(stub)
+
+	t.Run("metrics/find", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "metrics/find",
+			URL:     "metrics/find?from=1700000000&until=1700003600",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte(`query=servers.web1.*`),
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result []MetricFindResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		assert.Equal(t, []MetricFindResult{
+			{Text: "cpu", ID: "servers.web1.cpu", Expandable: true, AllowChildren: true},
+			{Text: "uptime", ID: "servers.web1.uptime", Leaf: true},
+		}, result)
+
+		req := stub.requests[len(stub.requests)-1]
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, url.Values{"query": {"servers.web1.*"}, "from": {"1700000000"}, "until": {"1700003600"}}, stub.forms[len(stub.forms)-1])
+	})
+
+	t.Run("metrics/expand", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "metrics/expand",
+			URL:    "metrics/expand?query=servers.*.cpu",
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result MetricsExpandResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		assert.Equal(t, MetricsExpandResult{Results: []string{"servers.web1.cpu", "servers.web2.cpu"}}, result)
+		assert.Equal(t, url.Values{"query": {"servers.*.cpu"}}, stub.forms[len(stub.forms)-1])
+	})
+
+	t.Run("tags/autoComplete/tags", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/tags",
+			URL:    "tags/autoComplete/tags?expr=name%3Dcpu&expr=server%3Dweb1&tagPrefix=data&limit=10&unknown=1",
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `["datacenter", "server"]`, string(resp.Body))
+		assert.Equal(t, url.Values{"expr": {"name=cpu", "server=web1"}, "tagPrefix": {"data"}, "limit": {"10"}}, stub.forms[len(stub.forms)-1])
+	})
+
+	t.Run("tags/autoComplete/values", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/values",
+			URL:    "tags/autoComplete/values?expr=name%3Dcpu&tag=server&valuePrefix=web",
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `["web1", "web2"]`, string(resp.Body))
+		assert.Equal(t, url.Values{"expr": {"name=cpu"}, "tag": {"server"}, "valuePrefix": {"web"}}, stub.forms[len(stub.forms)-1])
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "functions",
+			URL:    "functions",
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result map[string]FuncDef
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Contains(t, result, "removeAboveValue")
+		fn := result["removeAboveValue"]
+		assert.Equal(t, "Filter Data", fn.Group)
+		require.Len(t, fn.Params, 2)
+		assert.Equal(t, FuncDefParam{Name: "seriesList", Type: "seriesList", Required: true}, fn.Params[0])
+		assert.Contains(t, string(resp.Body), `"default":1e9999`)
+	})
+
+	t.Run("fails if a required parameter is missing", func(t *testing.T) {
+		for _, path := range []string{"metrics/find", "metrics/expand", "tags/autoComplete/values"} {
+			resp := callResource(t, s, &backend.CallResourceRequest{
+				Method: http.MethodGet,
+				Path:   path,
+				URL:    path,
+			})
+			assert.Equal(t, http.StatusBadRequest, resp.Status, path)
+		}
+	})
+
+	t.Run("returns the status of failed Graphite requests", func(t *testing.T) {
+		delete(stub.responses, "/graphite/metrics/expand")
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "metrics/expand",
+			URL:    "metrics/expand?query=servers.*",
+		})
+		assert.Equal(t, http.StatusNotFound, resp.Status)
+		assert.JSONEq(t, `{"message": "request failed, status: 404 Not Found"}`, string(resp.Body))
+	})
+
+	t.Run("returns not found for unknown resources", func(t *testing.T) {
+		resp := callResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "render",
+			URL:    "render",
+		})
+		assert.Equal(t, http.StatusNotFound, resp.Status)
+	})
+}
@@ -1,6 +1,11 @@
 package graphite
 
-import "github.com/grafana/grafana/pkg/tsdb/legacydata"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
 
 type TargetResponseDTO struct {
 	Target     string                          `json:"target"`
@@ -8,3 +13,69 @@ type TargetResponseDTO struct {
 	// Graphite <=1.1.7 may return some tags as numbers requiring extra conversion. See https://github.com/grafana/grafana/issues/37614
 	Tags map[string]any `json:"tags"`
 }
+
+// MetricFindResult is a node of the metric tree returned by the metrics/find resource.
+type MetricFindResult struct {
+	Text          string `json:"text"`
+	ID            string `json:"id"`
+	Expandable    bool   `json:"expandable"`
+	Leaf          bool   `json:"leaf"`
+	AllowChildren bool   `json:"allowChildren"`
+}
+
+// MetricsExpandResult is the list of metrics returned by the metrics/expand resource.
+type MetricsExpandResult struct {
+	Results []string `json:"results"`
+}
+
+// FuncDef is a function returned by the functions resource.
+type FuncDef struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Function    string         `json:"function"`
+	Module      string         `json:"module"`
+	Group       string         `json:"group"`
+	Params      []FuncDefParam `json:"params"`
+}
+
+// FuncDefParam is a parameter of a function returned by the functions resource. Defaults, options and suggestions
+// can be strings, numbers or booleans, so they are returned as they are.
+type FuncDefParam struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Required    bool              `json:"required,omitempty"`
+	Multiple    bool              `json:"multiple,omitempty"`
+	Default     json.RawMessage   `json:"default,omitempty"`
+	Options     []json.RawMessage `json:"options,omitempty"`
+	Suggestions []json.RawMessage `json:"suggestions,omitempty"`
+}
+
+// metricFindDTO is a node of the metric tree as returned by Graphite.
+type metricFindDTO struct {
+	Text          string       `json:"text"`
+	ID            string       `json:"id"`
+	Expandable    graphiteFlag `json:"expandable"`
+	Leaf          graphiteFlag `json:"leaf"`
+	AllowChildren graphiteFlag `json:"allowChildren"`
+}
+
+// graphiteFlag is a flag that Graphite returns as 0 or 1. Some Graphite compatible backends return booleans instead.
+type graphiteFlag bool
+
+func (b *graphiteFlag) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = graphiteFlag(v)
+	case float64:
+		*b = v != 0
+	case nil:
+		*b = false
+	default:
+		return fmt.Errorf("invalid flag %s", string(data))
+	}
+	return nil
+}
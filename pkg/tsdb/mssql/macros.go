@@ -8,7 +8,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
-	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
@@ -88,7 +88,7 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 	case "__timeGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
 		if err == nil {
-			return tg + " AS [time]", nil
+			return tg + " AS " + quoteIdentifier("time"), nil
 		}
 		return "", err
 	case "__unixEpochFilter":
@@ -123,7 +123,7 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 	case "__unixEpochGroupAlias":
 		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
 		if err == nil {
-			return tg + " AS [time]", nil
+			return tg + " AS " + quoteIdentifier("time"), nil
 		}
 		return "", err
 	default:
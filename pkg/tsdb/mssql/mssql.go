@@ -3,14 +3,12 @@ package mssql
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/grafana/grafana-azure-sdk-go/v2/azcredentials"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -25,8 +23,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/mssql/kerberos"
-	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
 	"github.com/grafana/grafana/pkg/tsdb/mssql/utils"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -71,81 +69,101 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func newMSSQL(ctx context.Context, driverName string, userFacingDefaultError string, rowLimit int64, dsInfo sqleng.DataSourceInfo, cnnstr string, logger log.Logger, settings backend.DataSourceInstanceSettings) (*sql.DB, *sqleng.DataSourceHandler, error) {
+	config := sqleng.DataPluginConfiguration{
+		DSInfo:            dsInfo,
+		MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
+		RowLimit:          rowLimit,
+	}
+
+	dialect := &mssqlDialect{
+		SQLMacroEngine:              newMssqlMacroEngine(),
+		mssqlQueryResultTransformer: &mssqlQueryResultTransformer{userError: userFacingDefaultError},
+		driverName:                  driverName,
+		cnnstr:                      cnnstr,
+		logger:                      logger,
+	}
+
+	db, err := sqleng.OpenDB(ctx, settings, dsInfo, dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, dialect, logger)
+	if err != nil {
+		logger.Error("Failed connecting to MSSQL", "err", err)
+		return nil, nil, err
+	}
+
+	logger.Debug("Successfully connected to MSSQL")
+	return db, handler, nil
+}
+
+// mssqlDialect is the Microsoft SQL Server dialect of the SQL engine. It connects with the connection string that was
+// generated from the settings of its data source instance when the instance was created, with the Azure AD driver for
+// Azure authentication.
+type mssqlDialect struct {
+	sqleng.SQLMacroEngine
+	*mssqlQueryResultTransformer
+
+	driverName string
+	cnnstr     string
+	logger     log.Logger
+}
+
+// QuoteIdentifier quotes an identifier with square brackets.
+func (d *mssqlDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifier(identifier)
+}
+
+// quoteIdentifier quotes an identifier with square brackets, so that the macros can quote the aliases they add.
+func quoteIdentifier(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
+
+func (d *mssqlDialect) Connect(ctx context.Context, settings backend.DataSourceInstanceSettings, dsInfo sqleng.DataSourceInfo) (*sql.DB, error) {
 	var connector *mssql.Connector
 	var err error
-	if driverName == "azuresql" {
-		connector, err = azuread.NewConnector(cnnstr)
+	if d.driverName == "azuresql" {
+		connector, err = azuread.NewConnector(d.cnnstr)
 	} else {
-		connector, err = mssql.NewConnector(cnnstr)
+		connector, err = mssql.NewConnector(d.cnnstr)
 	}
 
 	if err != nil {
-		logger.Error("mssql connector creation failed", "error", err)
-		return nil, nil, fmt.Errorf("mssql connector creation failed")
+		d.logger.Error("mssql connector creation failed", "error", err)
+		return nil, fmt.Errorf("mssql connector creation failed")
 	}
 
 	proxyClient, err := settings.ProxyClient(ctx)
 	if err != nil {
-		logger.Error("mssql proxy creation failed", "error", err)
-		return nil, nil, fmt.Errorf("mssql proxy creation failed")
+		d.logger.Error("mssql proxy creation failed", "error", err)
+		return nil, fmt.Errorf("mssql proxy creation failed")
 	}
 
 	if proxyClient.SecureSocksProxyEnabled() {
 		dialer, err := proxyClient.NewSecureSocksProxyContextDialer()
 		if err != nil {
-			logger.Error("mssql proxy creation failed", "error", err)
-			return nil, nil, fmt.Errorf("mssql proxy creation failed")
+			d.logger.Error("mssql proxy creation failed", "error", err)
+			return nil, fmt.Errorf("mssql proxy creation failed")
 		}
-		URL, err := ParseURL(dsInfo.URL, logger)
+		URL, err := ParseURL(dsInfo.URL, d.logger)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		mssqlDialer, err := newMSSQLProxyDialer(URL.Hostname(), dialer)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		// update the mssql dialer with the proxy dialer
 		connector.Dialer = (mssqlDialer)
 	}
 
-	config := sqleng.DataPluginConfiguration{
-		DSInfo:            dsInfo,
-		MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
-		RowLimit:          rowLimit,
-	}
-
-	queryResultTransformer := mssqlQueryResultTransformer{
-		userError: userFacingDefaultError,
-	}
-
-	db := sql.OpenDB(connector)
-
-	db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
-	db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
-	db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
-
-	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &queryResultTransformer, newMssqlMacroEngine(),
-		logger)
-	if err != nil {
-		logger.Error("Failed connecting to Postgres", "err", err)
-		return nil, nil, err
-	}
-
-	logger.Debug("Successfully connected to Postgres")
-	return db, handler, nil
+	return sql.OpenDB(connector), nil
 }
 
 func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
-		jsonData := sqleng.JsonData{
-			MaxOpenConns:      cfg.SqlDatasourceMaxOpenConnsDefault,
-			MaxIdleConns:      cfg.SqlDatasourceMaxIdleConnsDefault,
-			ConnMaxLifetime:   cfg.SqlDatasourceMaxConnLifetimeDefault,
-			Encrypt:           "false",
-			ConnectionTimeout: 0,
-			SecureDSProxy:     false,
-		}
 		azureCredentials, err := utils.GetAzureCredentials(settings)
 		if err != nil {
 			return nil, fmt.Errorf("error reading azure credentials")
@@ -156,25 +174,16 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			return nil, fmt.Errorf("error getting kerberos settings: %w", err)
 		}
 
-		err = json.Unmarshal(settings.JSONData, &jsonData)
+		dsInfo, err := sqleng.NewDataSourceInfo(settings, sqleng.JsonData{
+			MaxOpenConns:      cfg.SqlDatasourceMaxOpenConnsDefault,
+			MaxIdleConns:      cfg.SqlDatasourceMaxIdleConnsDefault,
+			ConnMaxLifetime:   cfg.SqlDatasourceMaxConnLifetimeDefault,
+			Encrypt:           "false",
+			ConnectionTimeout: 0,
+			SecureDSProxy:     false,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("error reading settings: %w", err)
-		}
-
-		database := jsonData.Database
-		if database == "" {
-			database = settings.Database
-		}
-
-		dsInfo := sqleng.DataSourceInfo{
-			JsonData:                jsonData,
-			URL:                     settings.URL,
-			User:                    settings.User,
-			Database:                database,
-			ID:                      settings.ID,
-			Updated:                 settings.Updated,
-			UID:                     settings.UID,
-			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+			return nil, err
 		}
 		cnnstr, err := generateConnectionString(dsInfo, cfg, azureCredentials, kerberosAuth, logger)
 		if err != nil {
@@ -185,7 +194,7 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			logger.Debug("GetEngine", "connection", cnnstr)
 		}
 		driverName := "mssql"
-		if jsonData.AuthenticationType == azureAuthentication {
+		if dsInfo.JsonData.AuthenticationType == azureAuthentication {
 			driverName = "azuresql"
 		}
 
@@ -349,13 +358,7 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 		return nil, err
 	}
 
-	err = dsHandler.Ping()
-
-	if err != nil {
-		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, err).Error()}, nil
-	}
-
-	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+	return dsHandler.CheckHealth(ctx), nil
 }
 
 func (t *mssqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
@@ -17,7 +17,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/tsdb/mssql/kerberos"
-	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 // To run this test, set runMssqlTests=true
@@ -51,7 +51,7 @@ func TestMSSQL(t *testing.T) {
 
 	db := initMSSQLTestDB(t, config.DSInfo.JsonData)
 
-	endpoint, err := sqleng.NewQueryDataHandler("", db, config, &queryResultTransformer, newMssqlMacroEngine(), logger)
+	endpoint, err := sqleng.NewQueryDataHandler("", db, config, &mssqlDialect{SQLMacroEngine: newMssqlMacroEngine(), mssqlQueryResultTransformer: &queryResultTransformer}, logger)
 	require.NoError(t, err)
 
 	fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC).In(time.Local)
@@ -806,7 +806,7 @@ func TestMSSQL(t *testing.T) {
 					MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
 					RowLimit:          1000000,
 				}
-				endpoint, err := sqleng.NewQueryDataHandler("", db, config, &queryResultTransformer, newMssqlMacroEngine(), logger)
+				endpoint, err := sqleng.NewQueryDataHandler("", db, config, &mssqlDialect{SQLMacroEngine: newMssqlMacroEngine(), mssqlQueryResultTransformer: &queryResultTransformer}, logger)
 				require.NoError(t, err)
 				query := &backend.QueryDataRequest{
 					Queries: []backend.DataQuery{
@@ -1210,7 +1210,7 @@ func TestMSSQL(t *testing.T) {
 				RowLimit:          1,
 			}
 
-			handler, err := sqleng.NewQueryDataHandler("", db, config, &queryResultTransformer, newMssqlMacroEngine(), logger)
+			handler, err := sqleng.NewQueryDataHandler("", db, config, &mssqlDialect{SQLMacroEngine: newMssqlMacroEngine(), mssqlQueryResultTransformer: &queryResultTransformer}, logger)
 			require.NoError(t, err)
 
 			t.Run("When doing a table query that returns 2 rows should limit the result to 1 row", func(t *testing.T) {
@@ -1334,6 +1334,13 @@ func TestTransformQueryError(t *testing.T) {
 	})
 }
 
+func TestQuoteIdentifier(t *testing.T) {
+	dialect := &mssqlDialect{}
+	assert.Equal(t, "[metric]", dialect.QuoteIdentifier("metric"))
+	assert.Equal(t, "[my table]", dialect.QuoteIdentifier("my table"))
+	assert.Equal(t, "[a]]b]", dialect.QuoteIdentifier("a]b"))
+}
+
 func TestGenerateConnectionString(t *testing.T) {
 	kerberosLookup := []kerberos.KerberosLookup{
 		{
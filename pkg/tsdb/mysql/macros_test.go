@@ -44,7 +44,7 @@ func TestMacroEngine(t *testing.T) {
 			require.Nil(t, err)
 
 			require.Equal(t, "GROUP BY UNIX_TIMESTAMP(time_column) DIV 300 * 300", sql)
-			require.Equal(t, sql+" AS \"time\"", sql2)
+			require.Equal(t, sql+" AS `time`", sql2)
 		})
 
 		t.Run("interpolate __timeGroup function with spaces around arguments", func(t *testing.T) {
@@ -54,7 +54,7 @@ func TestMacroEngine(t *testing.T) {
 			require.Nil(t, err)
 
 			require.Equal(t, "GROUP BY UNIX_TIMESTAMP(time_column) DIV 300 * 300", sql)
-			require.Equal(t, sql+" AS \"time\"", sql2)
+			require.Equal(t, sql+" AS `time`", sql2)
 		})
 
 		t.Run("interpolate __timeFilter function", func(t *testing.T) {
@@ -113,7 +113,7 @@ func TestMacroEngine(t *testing.T) {
 			require.Nil(t, err)
 
 			require.Equal(t, "SELECT time_column DIV 300 * 300", sql)
-			require.Equal(t, sql+" AS \"time\"", sql2)
+			require.Equal(t, sql+" AS `time`", sql2)
 		})
 	})
 
@@ -3,7 +3,6 @@ package mysql
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -22,7 +21,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
-	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 const (
@@ -55,120 +54,119 @@ func newInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 		if err != nil {
 			return nil, err
 		}
-		jsonData := sqleng.JsonData{
+		dsInfo, err := sqleng.NewDataSourceInfo(settings, sqleng.JsonData{
 			MaxOpenConns:            sqlCfg.DefaultMaxOpenConns,
 			MaxIdleConns:            sqlCfg.DefaultMaxIdleConns,
 			ConnMaxLifetime:         sqlCfg.DefaultMaxConnLifetimeSeconds,
 			SecureDSProxy:           false,
 			AllowCleartextPasswords: false,
-		}
-
-		err = json.Unmarshal(settings.JSONData, &jsonData)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("error reading settings: %w", err)
+			return nil, err
 		}
 
-		database := jsonData.Database
-		if database == "" {
-			database = settings.Database
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+			RowLimit:          sqlCfg.RowLimit,
 		}
 
-		dsInfo := sqleng.DataSourceInfo{
-			JsonData:                jsonData,
-			URL:                     settings.URL,
-			User:                    settings.User,
-			Database:                database,
-			ID:                      settings.ID,
-			Updated:                 settings.Updated,
-			UID:                     settings.UID,
-			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		userFacingDefaultError, err := cfg.UserFacingDefaultError()
+		if err != nil {
+			return nil, err
 		}
 
-		protocol := "tcp"
-		if strings.HasPrefix(dsInfo.URL, "/") {
-			protocol = "unix"
+		dialect := &mysqlDialect{
+			SQLMacroEngine:              newMysqlMacroEngine(logger, userFacingDefaultError),
+			mysqlQueryResultTransformer: &mysqlQueryResultTransformer{userError: userFacingDefaultError},
 		}
 
-		proxyClient, err := settings.ProxyClient(ctx)
+		db, err := sqleng.OpenDB(ctx, settings, dsInfo, dialect)
 		if err != nil {
 			return nil, err
 		}
 
-		// register the secure socks proxy dialer context, if enabled
-		if proxyClient.SecureSocksProxyEnabled() {
-			dialer, err := proxyClient.NewSecureSocksProxyContextDialer()
-			if err != nil {
-				return nil, err
-			}
-			// UID is only unique per org, the only way to ensure uniqueness is to do it by connection information
-			uniqueIdentifier := dsInfo.User + dsInfo.DecryptedSecureJSONData["password"] + dsInfo.URL + dsInfo.Database
-			protocol, err = registerProxyDialerContext(protocol, uniqueIdentifier, dialer)
-			if err != nil {
-				return nil, err
-			}
-		}
+		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, dialect, logger)
+	}
+}
 
-		cnnstr := fmt.Sprintf("%s:%s@%s(%s)/%s?collation=utf8mb4_unicode_ci&parseTime=true&loc=UTC&allowNativePasswords=true",
-			characterEscape(dsInfo.User, ":"),
-			dsInfo.DecryptedSecureJSONData["password"],
-			protocol,
-			characterEscape(dsInfo.URL, ")"),
-			characterEscape(dsInfo.Database, "?"),
-		)
+// mysqlDialect is the MySQL dialect of the SQL engine.
+type mysqlDialect struct {
+	sqleng.SQLMacroEngine
+	*mysqlQueryResultTransformer
+}
 
-		if dsInfo.JsonData.AllowCleartextPasswords {
-			cnnstr += "&allowCleartextPasswords=true"
-		}
+// QuoteIdentifier quotes an identifier with backticks.
+func (d *mysqlDialect) QuoteIdentifier(identifier string) string {
+	return quoteIdentifier(identifier)
+}
+
+// quoteIdentifier quotes an identifier with backticks, so that the macros can quote the aliases they add.
+func quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func (d *mysqlDialect) Connect(ctx context.Context, settings backend.DataSourceInstanceSettings, dsInfo sqleng.DataSourceInfo) (*sql.DB, error) {
+	protocol := "tcp"
+	if strings.HasPrefix(dsInfo.URL, "/") {
+		protocol = "unix"
+	}
+
+	proxyClient, err := settings.ProxyClient(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		opts, err := settings.HTTPClientOptions(ctx)
+	// register the secure socks proxy dialer context, if enabled
+	if proxyClient.SecureSocksProxyEnabled() {
+		dialer, err := proxyClient.NewSecureSocksProxyContextDialer()
 		if err != nil {
 			return nil, err
 		}
-
-		tlsConfig, err := sdkhttpclient.GetTLSConfig(opts)
+		// UID is only unique per org, the only way to ensure uniqueness is to do it by connection information
+		uniqueIdentifier := dsInfo.User + dsInfo.DecryptedSecureJSONData["password"] + dsInfo.URL + dsInfo.Database
+		protocol, err = registerProxyDialerContext(protocol, uniqueIdentifier, dialer)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		if tlsConfig.RootCAs != nil || len(tlsConfig.Certificates) > 0 {
-			tlsConfigString := fmt.Sprintf("ds%d", settings.ID)
-			if err := mysql.RegisterTLSConfig(tlsConfigString, tlsConfig); err != nil {
-				return nil, err
-			}
-			cnnstr += "&tls=" + tlsConfigString
-		}
+	cnnstr := fmt.Sprintf("%s:%s@%s(%s)/%s?collation=utf8mb4_unicode_ci&parseTime=true&loc=UTC&allowNativePasswords=true",
+		characterEscape(dsInfo.User, ":"),
+		dsInfo.DecryptedSecureJSONData["password"],
+		protocol,
+		characterEscape(dsInfo.URL, ")"),
+		characterEscape(dsInfo.Database, "?"),
+	)
 
-		if dsInfo.JsonData.Timezone != "" {
-			cnnstr += fmt.Sprintf("&time_zone='%s'", url.QueryEscape(dsInfo.JsonData.Timezone))
-		}
-
-		config := sqleng.DataPluginConfiguration{
-			DSInfo:            dsInfo,
-			TimeColumnNames:   []string{"time", "time_sec"},
-			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
-			RowLimit:          sqlCfg.RowLimit,
-		}
+	if dsInfo.JsonData.AllowCleartextPasswords {
+		cnnstr += "&allowCleartextPasswords=true"
+	}
 
-		userFacingDefaultError, err := cfg.UserFacingDefaultError()
-		if err != nil {
-			return nil, err
-		}
+	opts, err := settings.HTTPClientOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		rowTransformer := mysqlQueryResultTransformer{
-			userError: userFacingDefaultError,
-		}
+	tlsConfig, err := sdkhttpclient.GetTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 
-		db, err := sql.Open("mysql", cnnstr)
-		if err != nil {
+	if tlsConfig.RootCAs != nil || len(tlsConfig.Certificates) > 0 {
+		tlsConfigString := fmt.Sprintf("ds%d", settings.ID)
+		if err := mysql.RegisterTLSConfig(tlsConfigString, tlsConfig); err != nil {
 			return nil, err
 		}
+		cnnstr += "&tls=" + tlsConfigString
+	}
 
-		db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
-		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
-		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
-
-		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &rowTransformer, newMysqlMacroEngine(logger, userFacingDefaultError), logger)
+	if dsInfo.JsonData.Timezone != "" {
+		cnnstr += fmt.Sprintf("&time_zone='%s'", url.QueryEscape(dsInfo.JsonData.Timezone))
 	}
+
+	return sql.Open("mysql", cnnstr)
 }
 
 func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
@@ -186,17 +184,7 @@ func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthReque
 	if err != nil {
 		return nil, err
 	}
-
-	err = dsHandler.Ping()
-
-	if err != nil {
-		var driverErr *mysql.MySQLError
-		if errors.As(err, &driverErr) {
-			return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, driverErr).Error()}, nil
-		}
-		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, err).Error()}, nil
-	}
-	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+	return dsHandler.CheckHealth(ctx), nil
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -16,7 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/require"
 
-	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -175,7 +175,7 @@ func TestIntegrationMySQLSnapshots(t *testing.T) {
 				RowLimit:          1000000,
 			}
 
-			handler, err := sqleng.NewQueryDataHandler("", db, config, &rowTransformer, newMysqlMacroEngine(logger, ""), logger)
+			handler, err := sqleng.NewQueryDataHandler("", db, config, &mysqlDialect{SQLMacroEngine: newMysqlMacroEngine(logger, ""), mysqlQueryResultTransformer: &rowTransformer}, logger)
 
 			require.NoError(t, err)
 
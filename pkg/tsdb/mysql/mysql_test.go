@@ -13,7 +13,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
-	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
 // To run this test, set runMySqlTests=true
@@ -66,7 +66,7 @@ func TestIntegrationMySQL(t *testing.T) {
 
 	db := InitMySQLTestDB(t, config.DSInfo.JsonData)
 
-	exe, err := sqleng.NewQueryDataHandler("", db, config, &rowTransformer, newMysqlMacroEngine(logger, ""), logger)
+	exe, err := sqleng.NewQueryDataHandler("", db, config, &mysqlDialect{SQLMacroEngine: newMysqlMacroEngine(logger, ""), mysqlQueryResultTransformer: &rowTransformer}, logger)
 
 	require.NoError(t, err)
 
@@ -1178,7 +1178,7 @@ func TestIntegrationMySQL(t *testing.T) {
 
 			queryResultTransformer := mysqlQueryResultTransformer{}
 
-			handler, err := sqleng.NewQueryDataHandler("", db, config, &queryResultTransformer, newMysqlMacroEngine(logger, ""), logger)
+			handler, err := sqleng.NewQueryDataHandler("", db, config, &mysqlDialect{SQLMacroEngine: newMysqlMacroEngine(logger, ""), mysqlQueryResultTransformer: &queryResultTransformer}, logger)
 			require.NoError(t, err)
 
 			t.Run("When doing a table query that returns 2 rows should limit the result to 1 row", func(t *testing.T) {
@@ -1326,3 +1326,10 @@ func mySQLTestDBConnStr() string {
 	}
 	return fmt.Sprintf("grafana:password@tcp(%s:%s)/grafana_ds_tests?collation=utf8mb4_unicode_ci&sql_mode='ANSI_QUOTES'&parseTime=true&loc=UTC", host, port)
 }
+
+func TestQuoteIdentifier(t *testing.T) {
+	dialect := &mysqlDialect{}
+	require.Equal(t, "`metric`", dialect.QuoteIdentifier("metric"))
+	require.Equal(t, "`my table`", dialect.QuoteIdentifier("my table"))
+	require.Equal(t, "`a``b`", dialect.QuoteIdentifier("a`b"))
+}
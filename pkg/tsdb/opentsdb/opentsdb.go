@@ -8,16 +8,19 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
-	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -26,22 +29,34 @@ import (
 var logger = log.New("tsdb.opentsdb")
 
 type Service struct {
-	im instancemgmt.InstanceManager
+	im              instancemgmt.InstanceManager
+	resourceHandler backend.CallResourceHandler
 }
 
 func ProvideService(httpClientProvider httpclient.Provider) *Service {
-	return &Service{
+	s := &Service{
 		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
 }
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient  *http.Client
+	URL         string
+	LookupLimit int
 }
 
 type DsAccess string
 
+// defaultLookupLimit is the maximum number of suggestions and tag lookup results, unless the data source
+// configures another limit.
+const defaultLookupLimit = 1000
+
+// defaultConcurrentQueryCount is the number of queries that are sent to OpenTSDB at the same time, unless Grafana
+// configures another number.
+const defaultConcurrentQueryCount = 10
+
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
 	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions(ctx)
@@ -54,65 +69,182 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		var jsonData datasourceSettings
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("failed to parse data source settings: %w", err)
+			}
+		}
+		lookupLimit := defaultLookupLimit
+		if jsonData.LookupLimit.Valid && jsonData.LookupLimit.Value > 0 {
+			lookupLimit = int(jsonData.LookupLimit.Value)
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:  client,
+			URL:         settings.URL,
+			LookupLimit: lookupLimit,
 		}
 
 		return model, nil
 	}
 }
 
-func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
 
+// QueryData sends a request to OpenTSDB for each query, so that the results and errors of each query are returned
+// with its own refID. At most the concurrent query count of the Grafana configuration is sent at the same time.
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrentQueryCount, err := backend.GrafanaConfigFromContext(ctx).ConcurrentQueryCount()
+	if err != nil || concurrentQueryCount <= 0 {
+		concurrentQueryCount = defaultConcurrentQueryCount
+	}
+
+	result := backend.NewQueryDataResponse()
+	resultLock := sync.Mutex{}
+	err = concurrency.ForEachJob(ctx, len(req.Queries), concurrentQueryCount, func(ctx context.Context, idx int) error {
+		q := req.Queries[idx]
+		res := s.query(ctx, logger, dsInfo, q)
+
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		result.Responses[q.RefID] = res
+		return nil // errors are saved per-query, always return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	myRefID := q.RefID
+	return result, nil
+}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+func (s *Service) query(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q backend.DataQuery) backend.DataResponse {
+	model, err := parseQuery(q)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("failed to parse query: %v", err))
+	}
+	if model.FromAnnotations {
+		return s.annotationQuery(ctx, logger, dsInfo, q, model)
+	}
+	return s.metricQuery(ctx, logger, dsInfo, q, model)
+}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+func (s *Service) metricQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q backend.DataQuery, model *QueryModel) backend.DataResponse {
+	// queries without a metric are not sent, like in the query editor
+	if model.Metric == "" {
+		return backend.DataResponse{}
 	}
 
-	// TODO: Don't use global variable
-	if setting.Env == setting.Dev {
-		logger.Debug("OpenTsdb request", "params", tsdbQuery)
+	tsdbQuery := OpenTsdbQuery{
+		Start:   q.TimeRange.From.UnixMilli(),
+		End:     q.TimeRange.To.UnixMilli(),
+		Queries: []OpenTsdbMetric{s.buildMetric(model)},
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	res, err := s.doQuery(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return nil, err
+		return backend.DataResponse{Error: err}
 	}
 
-	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	result, err := s.parseResponse(logger, res, q.RefID)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: err}
+	}
+	return result.Responses[q.RefID]
+}
+
+// annotationQuery returns the annotations of the metric of an annotation query, or the global annotations, in the
+// time range of the query.
+//
+// The annotations are read from /api/query rather than /api/annotation: /api/annotation only looks up a single
+// annotation by its exact start time and time series, and cannot list the annotations of a metric in a time range.
+// /api/query returns the annotations of the matched time series, and the global annotations when
+// globalAnnotations is set, for the whole time range.
+func (s *Service) annotationQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q backend.DataQuery, model *QueryModel) backend.DataResponse {
+	if model.Target == "" {
+		return backend.ErrDataResponse(backend.StatusBadRequest, "annotation query has no metric")
 	}
 
-	res, err := dsInfo.HTTPClient.Do(request)
+	tsdbQuery := OpenTsdbQuery{
+		Start:             q.TimeRange.From.UnixMilli(),
+		End:               q.TimeRange.To.UnixMilli(),
+		Queries:           []OpenTsdbMetric{{Metric: model.Target, Aggregator: "sum"}},
+		GlobalAnnotations: model.IsGlobal,
+	}
+
+	res, err := s.doQuery(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: err}
 	}
 
-	defer func() {
-		err := res.Body.Close()
-		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
+	responseData, err := s.readResponse(logger, res)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	// every matched time series has its own annotations, and repeats the global annotations
+	var annotations []OpenTsdbAnnotation
+	seen := make(map[OpenTsdbAnnotation]bool)
+	for _, series := range responseData {
+		seriesAnnotations := series.Annotations
+		if model.IsGlobal {
+			seriesAnnotations = series.GlobalAnnotations
 		}
-	}()
+		for _, annotation := range seriesAnnotations {
+			if !seen[annotation] {
+				seen[annotation] = true
+				annotations = append(annotations, annotation)
+			}
+		}
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].StartTime < annotations[j].StartTime
+	})
+
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]*time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	for _, annotation := range annotations {
+		times = append(times, time.Unix(annotation.StartTime, 0).UTC())
+		var timeEnd *time.Time
+		if annotation.EndTime > 0 {
+			t := time.Unix(annotation.EndTime, 0).UTC()
+			timeEnd = &t
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, annotation.Description)
+	}
 
-	result, err := s.parseResponse(logger, res, myRefID)
+	return backend.DataResponse{
+		Frames: data.Frames{data.NewFrame(q.RefID,
+			data.NewField("time", nil, times),
+			data.NewField("timeEnd", nil, timeEnds),
+			data.NewField("text", nil, texts),
+		)},
+	}
+}
+
+func (s *Service) doQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, tsdbQuery OpenTsdbQuery) (*http.Response, error) {
+	// TODO: Don't use global variable
+	if setting.Env == setting.Dev {
+		logger.Debug("OpenTsdb request", "params", tsdbQuery)
+	}
+
+	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
-	return result, nil
+	return dsInfo.HTTPClient.Do(request)
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -138,9 +270,8 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
-	resp := backend.NewQueryDataResponse()
-
+// readResponse reads the results of an OpenTSDB query and closes the body of the response.
+func (s *Service) readResponse(logger log.Logger, res *http.Response) ([]OpenTsdbResponse, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -162,6 +293,16 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
 		return nil, err
 	}
+	return responseData, nil
+}
+
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	responseData, err := s.readResponse(logger, res)
+	if err != nil {
+		return nil, err
+	}
 
 	frames := data.Frames{}
 	for _, val := range responseData {
@@ -189,66 +330,59 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
-	metric := make(map[string]any)
-
-	model, err := simplejson.NewJson(query.JSON)
-	if err != nil {
-		return nil
+func parseQuery(query backend.DataQuery) (*QueryModel, error) {
+	model := &QueryModel{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		return nil, err
 	}
+	return model, nil
+}
 
-	// Setting metric and aggregator
-	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+func (s *Service) buildMetric(model *QueryModel) OpenTsdbMetric {
+	metric := OpenTsdbMetric{
+		Metric:       model.Metric,
+		Aggregator:   model.Aggregator,
+		ExplicitTags: model.ExplicitTags,
+	}
 
 	// Setting downsampling options
-	disableDownsampling := model.Get("disableDownsampling").MustBool()
-	if !disableDownsampling {
-		downsampleInterval := model.Get("downsampleInterval").MustString()
+	if !model.DisableDownsampling {
+		downsampleInterval := model.DownsampleInterval
 		if downsampleInterval == "" {
 			downsampleInterval = "1m" // default value for blank
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
-		} else {
-			metric["downsample"] = downsample
+		metric.Downsample = downsampleInterval + "-" + model.DownsampleAggregator
+		if model.DownsampleFillPolicy != "" && model.DownsampleFillPolicy != "none" {
+			metric.Downsample += "-" + model.DownsampleFillPolicy
 		}
 	}
 
 	// Setting rate options
-	if model.Get("shouldComputeRate").MustBool() {
-		metric["rate"] = true
-		rateOptions := make(map[string]any)
-		rateOptions["counter"] = model.Get("isCounter").MustBool()
-
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
-		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
-		}
+	if model.ShouldComputeRate {
+		metric.Rate = true
+		metric.RateOptions = &RateOptions{Counter: model.IsCounter}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
-		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+		if model.CounterMax.Valid {
+			metric.RateOptions.CounterMax = &model.CounterMax.Value
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
-			rateOptions["dropResets"] = true
+		if model.CounterResetValue.Valid {
+			metric.RateOptions.ResetValue = &model.CounterResetValue.Value
 		}
 
-		metric["rateOptions"] = rateOptions
+		if !model.CounterMax.Valid && (!model.CounterResetValue.Valid || model.CounterResetValue.Value == 0) {
+			metric.RateOptions.DropResets = true
+		}
 	}
 
 	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
+	if len(model.Tags) > 0 {
+		metric.Tags = model.Tags
 	}
 
 	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	if len(model.Filters) > 0 {
+		metric.Filters = model.Filters
 	}
 
 	return metric
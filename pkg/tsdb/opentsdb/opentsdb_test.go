@@ -2,14 +2,22 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -109,8 +117,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	})
 
 	t.Run("Build metric with downsampling enabled", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -119,20 +126,19 @@ func TestOpenTsdbExecutor(t *testing.T) {
 						"downsampleAggregator": "avg",
 						"downsampleFillPolicy": "none"
 					}`,
-			),
-		}
+		)
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(model)
 
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Equal(t, "1m-avg", metric["downsample"])
+		require.Equal(t, OpenTsdbMetric{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Downsample: "1m-avg",
+		}, metric)
 	})
 
 	t.Run("Build metric with downsampling disabled", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -141,19 +147,18 @@ func TestOpenTsdbExecutor(t *testing.T) {
 						"downsampleAggregator": "avg",
 						"downsampleFillPolicy": "none"
 					}`,
-			),
-		}
+		)
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(model)
 
-		require.Len(t, metric, 2)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, OpenTsdbMetric{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+		}, metric)
 	})
 
 	t.Run("Build metric with downsampling enabled with params", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -162,20 +167,19 @@ func TestOpenTsdbExecutor(t *testing.T) {
 						"downsampleAggregator": "sum",
 						"downsampleFillPolicy": "null"
 					}`,
-			),
-		}
+		)
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(model)
 
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Equal(t, "5m-sum-null", metric["downsample"])
+		require.Equal(t, OpenTsdbMetric{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Downsample: "5m-sum-null",
+		}, metric)
 	})
 
 	t.Run("Build metric with tags with downsampling disabled", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -188,26 +192,47 @@ func TestOpenTsdbExecutor(t *testing.T) {
 							"app": "grafana"
 						}
 					}`,
-			),
-		}
+		)
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(model)
+
+		require.Equal(t, OpenTsdbMetric{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Tags:       map[string]string{"env": "prod", "app": "grafana"},
+		}, metric)
+	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		model := parseTestQuery(t, `
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"filters": [
+							{
+								"type": "wildcard",
+								"tagk": "host",
+								"filter": "web*",
+								"groupBy": true
+							}
+						]
+					}`,
+		)
 
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Nil(t, metric["downsample"])
+		metric := service.buildMetric(model)
 
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
+		require.Equal(t, OpenTsdbMetric{
+			Metric:       "cpu.average.percent",
+			Aggregator:   "avg",
+			Filters:      []Filter{{Type: "wildcard", Tagk: "host", Filter: "web*", GroupBy: true}},
+			ExplicitTags: true,
+		}, metric)
 	})
 
 	t.Run("Build metric with rate enabled but counter disabled", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -219,28 +244,21 @@ func TestOpenTsdbExecutor(t *testing.T) {
 							"app": "grafana"
 						}
 					}`,
-			),
-		}
-
-		metric := service.buildMetric(query)
-
-		require.Len(t, metric, 5)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
+		)
 
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
+		metric := service.buildMetric(model)
 
-		require.True(t, metric["rate"].(bool))
-		require.False(t, metric["rateOptions"].(map[string]any)["counter"].(bool))
+		require.Equal(t, OpenTsdbMetric{
+			Metric:      "cpu.average.percent",
+			Aggregator:  "avg",
+			Rate:        true,
+			RateOptions: &RateOptions{Counter: false, DropResets: true},
+			Tags:        map[string]string{"env": "prod", "app": "grafana"},
+		}, metric)
 	})
 
 	t.Run("Build metric with rate and counter enabled", func(t *testing.T) {
-		query := backend.DataQuery{
-			JSON: []byte(`
+		model := parseTestQuery(t, `
 					{
 						"metric": "cpu.average.percent",
 						"aggregator": "avg",
@@ -254,26 +272,223 @@ func TestOpenTsdbExecutor(t *testing.T) {
 							"app": "grafana"
 						}
 					}`,
-			),
+		)
+
+		metric := service.buildMetric(model)
+
+		counterMax, resetValue := float64(45), float64(60)
+		require.Equal(t, OpenTsdbMetric{
+			Metric:      "cpu.average.percent",
+			Aggregator:  "avg",
+			Rate:        true,
+			RateOptions: &RateOptions{Counter: true, CounterMax: &counterMax, ResetValue: &resetValue},
+			Tags:        map[string]string{"env": "prod", "app": "grafana"},
+		}, metric)
+	})
+
+	t.Run("Build metric with counter options from the query editor", func(t *testing.T) {
+		model := parseTestQuery(t, `
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "45",
+						"counterResetValue": ""
+					}`,
+		)
+
+		metric := service.buildMetric(model)
+
+		counterMax := float64(45)
+		require.Equal(t, &RateOptions{Counter: true, CounterMax: &counterMax}, metric.RateOptions)
+	})
+
+	t.Run("Parse query should fail if the counter options are invalid", func(t *testing.T) {
+		_, err := parseQuery(backend.DataQuery{JSON: []byte(`{"metric": "cpu", "counterMax": "a lot"}`)})
+		require.Error(t, err)
+	})
+}
+
+func parseTestQuery(t *testing.T, query string) *QueryModel {
+	t.Helper()
+	model, err := parseQuery(backend.DataQuery{JSON: []byte(query)})
+	require.NoError(t, err)
+	return model
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	s := &Service{
+		im: datasource.NewInstanceManager(func(_ context.Context, _ backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, LookupLimit: 50}, nil
+		}),
+	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+	return s
+}
+
+func TestQueryData(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1405544100, 0), To: time.Unix(1405547700, 0)}
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}}
+
+	// the queries are sent concurrently, so the requests are sorted by metric to compare them
+	var (
+		requests   []OpenTsdbQuery
+		requestsMu sync.Mutex
+	)
+	sortedRequests := func() []OpenTsdbQuery {
+		requestsMu.Lock()
+		defer requestsMu.Unlock()
+		sort.SliceStable(requests, func(i, j int) bool {
+			if requests[i].Queries[0].Metric != requests[j].Queries[0].Metric {
+				return requests[i].Queries[0].Metric < requests[j].Queries[0].Metric
+			}
+			return !requests[i].GlobalAnnotations && requests[j].GlobalAnnotations
+		})
+		return requests
+	}
+	service := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/api/query", req.URL.Path)
+		var query OpenTsdbQuery
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&query))
+		requestsMu.Lock()
+		requests = append(requests, query)
+		requestsMu.Unlock()
+
+		switch query.Queries[0].Metric {
+		case "cpu":
+			_, _ = rw.Write([]byte(`[{"metric": "cpu", "tags": {"host": "web01"}, "dps": {"1405544146": 50}}]`))
+		case "mem":
+			_, _ = rw.Write([]byte(`[{"metric": "mem", "tags": {}, "dps": {"1405544146": 1024}}]`))
+		case "deploys":
+			_, _ = rw.Write([]byte(`[{
+				"metric": "deploys",
+				"tags": {"host": "web01"},
+				"dps": {},
+				"annotations": [{"description": "deployed web01", "startTime": 1405544146, "endTime": 1405544246}],
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405545000}]
+			}, {
+				"metric": "deploys",
+				"tags": {"host": "web02"},
+				"dps": {},
+				"annotations": [{"description": "deployed web02", "startTime": 1405544100}],
+				"globalAnnotations": [{"description": "maintenance", "startTime": 1405545000}]
+			}]`))
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"error": {"code": 400, "message": "No such name for 'metrics'"}}`))
 		}
+	})
 
-		metric := service.buildMetric(query)
+	t.Run("returns the results of each query with its refID", func(t *testing.T) {
+		requests = nil
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "avg", "disableDownsampling": true}`)},
+				{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "mem", "aggregator": "sum", "downsampleInterval": "5m", "downsampleAggregator": "max"}`)},
+				{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"metric": "unknown", "aggregator": "sum"}`)},
+				{RefID: "D", TimeRange: timeRange, JSON: []byte(`{"aggregator": "sum"}`)},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Responses, 4)
+
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, "cpu", resp.Responses["A"].Frames[0].Name)
+		require.Equal(t, data.Labels{"host": "web01"}, resp.Responses["A"].Frames[0].Fields[1].Labels)
+
+		require.NoError(t, resp.Responses["B"].Error)
+		require.Len(t, resp.Responses["B"].Frames, 1)
+		require.Equal(t, "mem", resp.Responses["B"].Frames[0].Name)
+
+		require.ErrorContains(t, resp.Responses["C"].Error, "400 Bad Request")
+
+		require.NoError(t, resp.Responses["D"].Error)
+		require.Empty(t, resp.Responses["D"].Frames)
+
+		requests := sortedRequests()
+		require.Len(t, requests, 3)
+		require.Equal(t, OpenTsdbQuery{
+			Start:   1405544100000,
+			End:     1405547700000,
+			Queries: []OpenTsdbMetric{{Metric: "cpu", Aggregator: "avg"}},
+		}, requests[0])
+		require.Equal(t, []OpenTsdbMetric{{Metric: "mem", Aggregator: "sum", Downsample: "5m-max"}}, requests[1].Queries)
+	})
+
+	t.Run("returns the annotations of annotation queries", func(t *testing.T) {
+		requests = nil
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{
+				{RefID: "Anno", TimeRange: timeRange, JSON: []byte(`{"fromAnnotations": true, "target": "deploys"}`)},
+				{RefID: "Global", TimeRange: timeRange, JSON: []byte(`{"fromAnnotations": true, "target": "deploys", "isGlobal": true}`)},
+			},
+		})
+		require.NoError(t, err)
+
+		end := time.Unix(1405544246, 0).UTC()
+		expected := data.NewFrame("Anno",
+			data.NewField("time", nil, []time.Time{time.Unix(1405544100, 0).UTC(), time.Unix(1405544146, 0).UTC()}),
+			data.NewField("timeEnd", nil, []*time.Time{nil, &end}),
+			data.NewField("text", nil, []string{"deployed web02", "deployed web01"}),
+		)
+		if diff := cmp.Diff(expected, resp.Responses["Anno"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
 
-		require.Len(t, metric, 5)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
+		expected = data.NewFrame("Global",
+			data.NewField("time", nil, []time.Time{time.Unix(1405545000, 0).UTC()}),
+			data.NewField("timeEnd", nil, []*time.Time{nil}),
+			data.NewField("text", nil, []string{"maintenance"}),
+		)
+		if diff := cmp.Diff(expected, resp.Responses["Global"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
 
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
+		requests := sortedRequests()
+		require.Len(t, requests, 2)
+		require.Equal(t, []OpenTsdbMetric{{Metric: "deploys", Aggregator: "sum"}}, requests[0].Queries)
+		require.False(t, requests[0].GlobalAnnotations)
+		require.True(t, requests[1].GlobalAnnotations)
+	})
+}
 
-		require.True(t, metric["rate"].(bool))
-		metricRateOptions := metric["rateOptions"].(map[string]any)
-		require.Len(t, metricRateOptions, 3)
-		require.True(t, metricRateOptions["counter"].(bool))
-		require.Equal(t, float64(45), metricRateOptions["counterMax"])
-		require.Equal(t, float64(60), metricRateOptions["resetValue"])
+func TestQueryData_ConcurrentQueryCount(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1405544100, 0), To: time.Unix(1405547700, 0)}
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}}
+
+	var inFlight, maxInFlight atomic.Int32
+	service := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		_, _ = rw.Write([]byte(`[{"metric": "cpu", "tags": {}, "dps": {"1405544146": 50}}]`))
 	})
+
+	queries := make([]backend.DataQuery, 0, 6)
+	for _, refID := range []string{"A", "B", "C", "D", "E", "F"} {
+		queries = append(queries, backend.DataQuery{RefID: refID, TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "avg"}`)})
+	}
+	ctx := backend.WithGrafanaConfig(context.Background(), backend.NewGrafanaCfg(map[string]string{backend.ConcurrentQueryCount: "2"}))
+	resp, err := service.QueryData(ctx, &backend.QueryDataRequest{PluginContext: pluginCtx, Queries: queries})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses, 6)
+	for _, q := range queries {
+		require.NoError(t, resp.Responses[q.RefID].Error)
+		require.Len(t, resp.Responses[q.RefID].Frames, 1)
+	}
+	require.Equal(t, int32(2), maxInFlight.Load())
 }
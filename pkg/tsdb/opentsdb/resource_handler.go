@@ -0,0 +1,220 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+)
+
+// errMissingParameter is returned when a required parameter of a resource request is missing or invalid.
+var errMissingParameter = errors.New("missing parameter")
+
+// suggestTypes are the types of suggestions of the OpenTSDB suggest API.
+var suggestTypes = map[string]bool{"metrics": true, "tagk": true, "tagv": true}
+
+// lookupResponse is the response of the OpenTSDB search lookup API.
+type lookupResponse struct {
+	Results []struct {
+		Metric string            `json:"metric"`
+		Tags   map[string]string `json:"tags"`
+	} `json:"results"`
+}
+
+type resourceHandlerFunc func(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/suggest", s.handleResourceReq(s.handleSuggest))
+	mux.HandleFunc("/tag-keys", s.handleResourceReq(s.handleTagKeys))
+	mux.HandleFunc("/tag-values", s.handleResourceReq(s.handleTagValues))
+	return mux
+}
+
+func (s *Service) handleResourceReq(handler resourceHandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to get data source: %v", err))
+			return
+		}
+
+		result, err := handler(ctx, dsInfo, req.URL.Query())
+		if err != nil {
+			if errors.Is(err, errMissingParameter) {
+				writeErrorResponse(rw, http.StatusBadRequest, err.Error())
+				return
+			}
+			logger.Info("Resource request failed", "path", req.URL.Path, "error", err)
+			writeErrorResponse(rw, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		body, err := json.Marshal(result)
+		if err != nil {
+			writeErrorResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response: %v", err))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		writeResponse(rw, http.StatusOK, body)
+	}
+}
+
+// handleSuggest returns the metric names, tag keys or tag values that start with the query, depending on the type.
+func (s *Service) handleSuggest(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	suggestType := params.Get("type")
+	if !suggestTypes[suggestType] {
+		return nil, fmt.Errorf("%w: type must be one of metrics, tagk or tagv", errMissingParameter)
+	}
+
+	body, err := s.doResourceRequest(ctx, dsInfo, "api/suggest", url.Values{
+		"type": {suggestType},
+		"q":    {params.Get("q")},
+		"max":  {strconv.Itoa(dsInfo.LookupLimit)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []string{}
+	if err := json.Unmarshal(body, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal opentsdb response: %w", err)
+	}
+	return suggestions, nil
+}
+
+// handleTagKeys returns the tag keys of the time series of a metric.
+func (s *Service) handleTagKeys(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	metric := params.Get("metric")
+	if metric == "" {
+		return nil, fmt.Errorf("%w: metric", errMissingParameter)
+	}
+
+	lookup, err := s.lookup(ctx, dsInfo, metric, dsInfo.LookupLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, r := range lookup.Results {
+		for key := range r.Tags {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// handleTagValues returns the values of the first of the comma separated tag keys, for the time series of a metric
+// that have all the keys. The other keys can also be given with a value, for example "host,env=prod".
+func (s *Service) handleTagValues(ctx context.Context, dsInfo *datasourceInfo, params url.Values) (any, error) {
+	metric := params.Get("metric")
+	if metric == "" {
+		return nil, fmt.Errorf("%w: metric", errMissingParameter)
+	}
+	keys := strings.Split(params.Get("keys"), ",")
+	for i := range keys {
+		keys[i] = strings.TrimSpace(keys[i])
+	}
+	key := keys[0]
+	if key == "" {
+		return nil, fmt.Errorf("%w: keys", errMissingParameter)
+	}
+
+	keysQuery := key + "=*"
+	if len(keys) > 1 {
+		keysQuery += "," + strings.Join(keys[1:], ",")
+	}
+	lookup, err := s.lookup(ctx, dsInfo, metric+"{"+keysQuery+"}", dsInfo.LookupLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, r := range lookup.Results {
+		value, ok := r.Tags[key]
+		if ok && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func (s *Service) lookup(ctx context.Context, dsInfo *datasourceInfo, m string, limit int) (*lookupResponse, error) {
+	body, err := s.doResourceRequest(ctx, dsInfo, "api/search/lookup", url.Values{
+		"m":     {m},
+		"limit": {strconv.Itoa(limit)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var lookup lookupResponse
+	if err := json.Unmarshal(body, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal opentsdb response: %w", err)
+	}
+	return &lookup, nil
+}
+
+// doResourceRequest sends a GET request to an endpoint of the OpenTSDB API and returns the body of the response.
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, endpoint string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	return body, nil
+}
+
+func writeResponse(rw http.ResponseWriter, code int, body []byte) {
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeErrorResponse(rw http.ResponseWriter, code int, msg string) {
+	body, _ := json.Marshal(map[string]string{"message": msg})
+	rw.Header().Set("Content-Type", "application/json")
+	writeResponse(rw, code, body)
+}
@@ -0,0 +1,92 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	var params url.Values
+	service := newTestService(t, func(rw http.ResponseWriter, req *http.Request) {
+		params = req.URL.Query()
+		switch req.URL.Path {
+		case "/api/suggest":
+			_, _ = rw.Write([]byte(`["cpu.idle", "cpu.user"]`))
+		case "/api/search/lookup":
+			_, _ = rw.Write([]byte(`{"results": [
+				{"metric": "cpu", "tags": {"host": "web01", "env": "prod"}},
+				{"metric": "cpu", "tags": {"host": "web02", "env": "prod"}},
+				{"metric": "cpu", "tags": {"host": "web01", "env": "dev", "dc": "eu"}}
+			]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	callResource := func(t *testing.T, resourceURL string) *backend.CallResourceResponse {
+		t.Helper()
+		path, _, _ := strings.Cut(resourceURL, "?")
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}},
+			Method:        http.MethodGet,
+			Path:          path,
+			URL:           resourceURL,
+		}, sender)
+		require.NoError(t, err)
+		require.NotNil(t, sender.resp)
+		return sender.resp
+	}
+
+	t.Run("suggest", func(t *testing.T) {
+		resp := callResource(t, "suggest?type=metrics&q=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `["cpu.idle", "cpu.user"]`, string(resp.Body))
+		assert.Equal(t, url.Values{"type": {"metrics"}, "q": {"cpu"}, "max": {"50"}}, params)
+	})
+
+	t.Run("suggest fails for unknown types", func(t *testing.T) {
+		resp := callResource(t, "suggest?type=dashboards&q=cpu")
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("tag keys", func(t *testing.T) {
+		resp := callResource(t, "tag-keys?metric=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var keys []string
+		require.NoError(t, json.Unmarshal(resp.Body, &keys))
+		assert.ElementsMatch(t, []string{"host", "env", "dc"}, keys)
+		assert.Equal(t, url.Values{"m": {"cpu"}, "limit": {"50"}}, params)
+	})
+
+	t.Run("tag values", func(t *testing.T) {
+		resp := callResource(t, "tag-values?metric=cpu&keys=host,%20env%3Dprod")
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `["web01", "web02"]`, string(resp.Body))
+		assert.Equal(t, url.Values{"m": {"cpu{host=*,env=prod}"}, "limit": {"50"}}, params)
+	})
+
+	t.Run("tag lookups fail without a metric", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, callResource(t, "tag-keys").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, "tag-values?keys=host").Status)
+		require.Equal(t, http.StatusBadRequest, callResource(t, "tag-values?metric=cpu").Status)
+	})
+}
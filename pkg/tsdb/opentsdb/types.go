@@ -1,13 +1,119 @@
 package opentsdb
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start             int64            `json:"start"`
+	End               int64            `json:"end"`
+	Queries           []OpenTsdbMetric `json:"queries"`
+	GlobalAnnotations bool             `json:"globalAnnotations,omitempty"`
+}
+
+// OpenTsdbMetric is a sub query of an OpenTSDB query. See http://opentsdb.net/docs/build/html/api_http/query/index.html
+type OpenTsdbMetric struct {
+	Metric       string            `json:"metric"`
+	Aggregator   string            `json:"aggregator"`
+	Downsample   string            `json:"downsample,omitempty"`
+	Rate         bool              `json:"rate,omitempty"`
+	RateOptions  *RateOptions      `json:"rateOptions,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Filters      []Filter          `json:"filters,omitempty"`
+	ExplicitTags bool              `json:"explicitTags,omitempty"`
+}
+
+type RateOptions struct {
+	Counter    bool     `json:"counter"`
+	CounterMax *float64 `json:"counterMax,omitempty"`
+	ResetValue *float64 `json:"resetValue,omitempty"`
+	DropResets bool     `json:"dropResets,omitempty"`
+}
+
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	DataPoints        map[string]float64   `json:"dps"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations"`
+}
+
+type OpenTsdbAnnotation struct {
+	Description string `json:"description"`
+	StartTime   int64  `json:"startTime"`
+	EndTime     int64  `json:"endTime"`
+}
+
+// QueryModel is the model of a query as it is sent by the query and annotation editors.
+type QueryModel struct {
+	Metric     string `json:"metric"`
+	Aggregator string `json:"aggregator"`
+
+	DisableDownsampling  bool   `json:"disableDownsampling"`
+	DownsampleInterval   string `json:"downsampleInterval"`
+	DownsampleAggregator string `json:"downsampleAggregator"`
+	DownsampleFillPolicy string `json:"downsampleFillPolicy"`
+
+	ShouldComputeRate bool          `json:"shouldComputeRate"`
+	IsCounter         bool          `json:"isCounter"`
+	CounterMax        NullableFloat `json:"counterMax"`
+	CounterResetValue NullableFloat `json:"counterResetValue"`
+	ExplicitTags      bool          `json:"explicitTags"`
+
+	Tags    map[string]string `json:"tags"`
+	Filters []Filter          `json:"filters"`
+
+	// FromAnnotations is set for annotation queries, which return the annotations of Target, or the global
+	// annotations if IsGlobal is set.
+	FromAnnotations bool   `json:"fromAnnotations"`
+	IsGlobal        bool   `json:"isGlobal"`
+	Target          string `json:"target"`
+}
+
+// NullableFloat is a number that the query editor sends as a string. Older queries may contain a number instead.
+// It is not valid if it is empty.
+type NullableFloat struct {
+	Value float64
+	Valid bool
+}
+
+func (f *NullableFloat) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		f.Value, f.Valid = v, true
+	case string:
+		if strings.TrimSpace(v) == "" {
+			f.Value, f.Valid = 0, false
+			return nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		f.Value, f.Valid = value, true
+	case nil:
+		f.Value, f.Valid = 0, false
+	default:
+		return fmt.Errorf("invalid number %s", string(data))
+	}
+	return nil
+}
+
+// datasourceSettings is the JSON data of the data source.
+type datasourceSettings struct {
+	LookupLimit NullableFloat `json:"lookupLimit"`
 }
@@ -0,0 +1,77 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// Dialect is the part of a SQL data source that is specific to its database. NewQueryDataHandler uses it to
+// interpolate the macros of queries and to convert and translate the errors of query results, and OpenDB uses it to
+// connect to the database.
+//
+// A dialect is created for each data source instance, and can hold the connection settings that its data source
+// derives from the instance settings, such as a generated connection string. Connect is only called with the
+// settings of that instance.
+type Dialect interface {
+	SQLMacroEngine
+	SqlQueryResultTransformer
+
+	// QuoteIdentifier quotes an identifier, such as a table or a column name, so that it can be used in a query.
+	QuoteIdentifier(identifier string) string
+	// Connect opens the database of the data source. The engine sets the connection limits of the data source.
+	Connect(ctx context.Context, settings backend.DataSourceInstanceSettings, dsInfo DataSourceInfo) (*sql.DB, error)
+}
+
+// NewDataSourceInfo reads the settings of a data source. The JSON data of the settings overrides the given defaults.
+func NewDataSourceInfo(settings backend.DataSourceInstanceSettings, jsonData JsonData) (DataSourceInfo, error) {
+	if len(settings.JSONData) > 0 {
+		if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+			return DataSourceInfo{}, fmt.Errorf("error reading settings: %w", err)
+		}
+	}
+
+	database := jsonData.Database
+	if database == "" {
+		database = settings.Database
+	}
+
+	return DataSourceInfo{
+		JsonData:                jsonData,
+		URL:                     settings.URL,
+		User:                    settings.User,
+		Database:                database,
+		ID:                      settings.ID,
+		Updated:                 settings.Updated,
+		UID:                     settings.UID,
+		DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+	}, nil
+}
+
+// OpenDB connects to the database of a data source with its dialect, and applies the connection limits of the
+// data source.
+func OpenDB(ctx context.Context, settings backend.DataSourceInstanceSettings, dsInfo DataSourceInfo, dialect Dialect) (*sql.DB, error) {
+	db, err := dialect.Connect(ctx, settings, dsInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(dsInfo.JsonData.MaxOpenConns)
+	db.SetMaxIdleConns(dsInfo.JsonData.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(dsInfo.JsonData.ConnMaxLifetime) * time.Second)
+	return db, nil
+}
+
+// CheckHealth pings the database and returns the translated error if it cannot be reached.
+func (e *DataSourceHandler) CheckHealth(ctx context.Context) *backend.CheckHealthResult {
+	if err := e.db.PingContext(ctx); err != nil {
+		logger := e.log.FromContext(ctx)
+		logger.Error("Check health failed", "error", err)
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: e.TransformQueryError(logger, err).Error()}
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}
+}
@@ -0,0 +1,58 @@
+package sqleng
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDataSourceInfo(t *testing.T) {
+	defaults := JsonData{MaxOpenConns: 100, MaxIdleConns: 100, ConnMaxLifetime: 14400}
+
+	t.Run("applies the JSON data over the defaults", func(t *testing.T) {
+		dsInfo, err := NewDataSourceInfo(backend.DataSourceInstanceSettings{
+			ID:       1,
+			UID:      "sql",
+			URL:      "localhost:5432",
+			User:     "grafana",
+			Database: "grafana",
+			JSONData: []byte(`{"maxOpenConns": 10, "timeInterval": "1m"}`),
+			DecryptedSecureJSONData: map[string]string{
+				"password": "secret",
+			},
+		}, defaults)
+		require.NoError(t, err)
+
+		require.Equal(t, 10, dsInfo.JsonData.MaxOpenConns)
+		require.Equal(t, 100, dsInfo.JsonData.MaxIdleConns)
+		require.Equal(t, 14400, dsInfo.JsonData.ConnMaxLifetime)
+		require.Equal(t, "1m", dsInfo.JsonData.TimeInterval)
+		require.Equal(t, int64(1), dsInfo.ID)
+		require.Equal(t, "sql", dsInfo.UID)
+		require.Equal(t, "localhost:5432", dsInfo.URL)
+		require.Equal(t, "grafana", dsInfo.User)
+		require.Equal(t, "grafana", dsInfo.Database)
+		require.Equal(t, "secret", dsInfo.DecryptedSecureJSONData["password"])
+	})
+
+	t.Run("prefers the database of the JSON data", func(t *testing.T) {
+		dsInfo, err := NewDataSourceInfo(backend.DataSourceInstanceSettings{
+			Database: "legacy",
+			JSONData: []byte(`{"database": "grafana"}`),
+		}, defaults)
+		require.NoError(t, err)
+		require.Equal(t, "grafana", dsInfo.Database)
+	})
+
+	t.Run("keeps the defaults without JSON data", func(t *testing.T) {
+		dsInfo, err := NewDataSourceInfo(backend.DataSourceInstanceSettings{}, defaults)
+		require.NoError(t, err)
+		require.Equal(t, defaults, dsInfo.JsonData)
+	})
+
+	t.Run("returns an error for invalid JSON data", func(t *testing.T) {
+		_, err := NewDataSourceInfo(backend.DataSourceInstanceSettings{JSONData: []byte(`{`)}, defaults)
+		require.ErrorContains(t, err, "error reading settings")
+	})
+}
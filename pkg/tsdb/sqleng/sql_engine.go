@@ -38,6 +38,13 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// DynamicTypesTransformer is implemented by the result transformers of databases whose columns can contain values
+// of any type, such as SQLite. When DynamicTypes returns true, the types of the fields of a result are found from the
+// values of its rows rather than from the types of its columns.
+type DynamicTypesTransformer interface {
+	DynamicTypes() bool
+}
+
 type JsonData struct {
 	MaxOpenConns            int    `json:"maxOpenConns"`
 	MaxIdleConns            int    `json:"maxIdleConns"`
@@ -114,11 +121,11 @@ func (e *DataSourceHandler) TransformQueryError(logger log.Logger, err error) er
 	return e.queryResultTransformer.TransformQueryError(logger, err)
 }
 
-func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataPluginConfiguration, queryResultTransformer SqlQueryResultTransformer,
-	macroEngine SQLMacroEngine, log log.Logger) (*DataSourceHandler, error) {
+func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataPluginConfiguration, dialect Dialect,
+	log log.Logger) (*DataSourceHandler, error) {
 	queryDataHandler := DataSourceHandler{
-		queryResultTransformer: queryResultTransformer,
-		macroEngine:            macroEngine,
+		queryResultTransformer: dialect,
+		macroEngine:            dialect,
 		timeColumnNames:        []string{"time"},
 		log:                    log,
 		dsInfo:                 config.DSInfo,
@@ -266,8 +273,11 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	}
 
 	// Convert row.Rows to dataframe
-	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	converters := sqlutil.ToConverters(e.queryResultTransformer.GetConverterList()...)
+	if t, ok := e.queryResultTransformer.(DynamicTypesTransformer); ok && t.DynamicTypes() {
+		converters = append(converters, sqlutil.Converter{Name: "dynamic types", Dynamic: true})
+	}
+	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, converters...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
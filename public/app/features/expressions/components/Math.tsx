@@ -87,6 +87,26 @@ export const Math = ({ labelWidth, onChange, query, onRunQuery }: Props) => {
                       name="floor"
                       description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
                     />
+                    <DocumentedFunction
+                      name="rate"
+                      description="returns the per-second rate of increase between points, treating decreases as counter resets. It only operates on series."
+                    />
+                    <DocumentedFunction
+                      name="delta"
+                      description="returns the difference between each point and the previous point. It only operates on series."
+                    />
+                    <DocumentedFunction
+                      name="deriv"
+                      description="returns the per-second derivative between each point and the previous point. It only operates on series."
+                    />
+                    <DocumentedFunction
+                      name="moving_avg"
+                      description='returns the average over a trailing window, for example moving_avg($A, "5m"). It only operates on series.'
+                    />
+                    <DocumentedFunction
+                      name="shift"
+                      description='moves the timestamps of a series by a duration, for example shift($A, "1d"). It only operates on series.'
+                    />
                   </div>
                 </div>
               }
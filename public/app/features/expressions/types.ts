@@ -82,6 +82,13 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of values that are not null' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the maximum and minimum value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the population standard deviation' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile' },
 ];
 
 export enum ReducerMode {
@@ -114,6 +121,8 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: ReducerID.first, label: 'First', description: 'Fill with the first value' },
+  { value: 'median', label: 'Median', description: 'Fill with the median value' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
@@ -0,0 +1,12 @@
+import React from 'react';
+
+import { QueryEditorProps } from '@grafana/data';
+import { SqlQueryEditor, SQLOptions, SQLQuery, QueryHeaderProps } from '@grafana/sql';
+
+import { SQLiteDatasource } from './datasource';
+
+const queryHeaderProps: Pick<QueryHeaderProps, 'dialect'> = { dialect: 'sqlite' };
+
+export function SQLiteQueryEditor(props: QueryEditorProps<SQLiteDatasource, SQLQuery, SQLOptions>) {
+  return <SqlQueryEditor {...props} queryHeaderProps={queryHeaderProps} />;
+}
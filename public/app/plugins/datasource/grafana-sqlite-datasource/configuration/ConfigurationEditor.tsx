@@ -0,0 +1,69 @@
+import React from 'react';
+
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, ConfigSubSection, DataSourceDescription } from '@grafana/experimental';
+import { ConnectionLimits, Divider } from '@grafana/sql';
+import { Alert, Field, Input } from '@grafana/ui';
+
+import { SQLiteOptions } from '../types';
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+
+  const WIDTH_LONG = 40;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlite/"
+        hasRequiredFields={true}
+      />
+
+      <Divider />
+
+      <Alert title="Allowed database files" severity="info">
+        The database file is opened read-only, and only if it matches one of the paths allowed by the{' '}
+        <code>sqlite_allowed_paths</code> option of the <code>[sql_datasources]</code> section of the Grafana server
+        configuration.
+      </Alert>
+
+      <ConfigSection title="Connection">
+        <Field
+          label="Database file"
+          description="Absolute path of the SQLite database file on the Grafana server."
+          required
+        >
+          <Input
+            width={WIDTH_LONG}
+            name="database"
+            value={jsonData.database || ''}
+            placeholder="/var/lib/metrics/metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'database')}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings" isCollapsible>
+        <ConfigSubSection title="SQLite Options">
+          <Field
+            label="Min time interval"
+            description="A lower limit for the auto group by time interval. Recommended to be set to write frequency, for example 1m if your data is written every minute."
+          >
+            <Input
+              width={WIDTH_LONG}
+              placeholder="1m"
+              value={jsonData.timeInterval || ''}
+              onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
+            />
+          </Field>
+        </ConfigSubSection>
+
+        <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
+      </ConfigSection>
+    </>
+  );
+};
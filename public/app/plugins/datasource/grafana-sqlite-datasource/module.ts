@@ -0,0 +1,13 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { SQLQuery } from '@grafana/sql';
+
+import { CheatSheet } from './CheatSheet';
+import { SQLiteQueryEditor } from './SQLiteQueryEditor';
+import { ConfigurationEditor } from './configuration/ConfigurationEditor';
+import { SQLiteDatasource } from './datasource';
+import { SQLiteOptions } from './types';
+
+export const plugin = new DataSourcePlugin<SQLiteDatasource, SQLQuery, SQLiteOptions>(SQLiteDatasource)
+  .setQueryEditor(SQLiteQueryEditor)
+  .setQueryEditorHelp(CheatSheet)
+  .setConfigEditor(ConfigurationEditor);
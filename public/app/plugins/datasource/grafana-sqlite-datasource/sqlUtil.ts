@@ -0,0 +1,65 @@
+import { isEmpty } from 'lodash';
+
+import { createSelectClause, haveColumns, RAQBFieldTypes, SQLQuery } from '@grafana/sql';
+
+// getFieldConfig follows the rules of SQLite to determine the affinity of a column from its declared type.
+export function getFieldConfig(type: string): { raqbFieldType: RAQBFieldTypes; icon: string } {
+  const t = type.toUpperCase();
+  if (t === 'BOOLEAN' || t === 'BOOL') {
+    return { raqbFieldType: 'boolean', icon: 'toggle-off' };
+  }
+  if (t === 'DATE') {
+    return { raqbFieldType: 'date', icon: 'clock-nine' };
+  }
+  if (t === 'DATETIME' || t === 'TIMESTAMP') {
+    return { raqbFieldType: 'datetime', icon: 'clock-nine' };
+  }
+  if (t.includes('INT')) {
+    return { raqbFieldType: 'number', icon: 'calculator-alt' };
+  }
+  if (t.includes('CHAR') || t.includes('CLOB') || t.includes('TEXT')) {
+    return { raqbFieldType: 'text', icon: 'text' };
+  }
+  if (['REAL', 'FLOA', 'DOUB', 'NUMERIC', 'DECIMAL'].some((n) => t.includes(n))) {
+    return { raqbFieldType: 'number', icon: 'calculator-alt' };
+  }
+  return { raqbFieldType: 'text', icon: 'text' };
+}
+
+export function toRawSql({ sql, table }: SQLQuery): string {
+  let rawQuery = '';
+
+  // Return early with empty string if there is no sql column
+  if (!sql || !haveColumns(sql.columns)) {
+    return rawQuery;
+  }
+
+  rawQuery += createSelectClause(sql.columns);
+
+  if (table) {
+    rawQuery += `FROM ${table} `;
+  }
+
+  if (sql.whereString) {
+    rawQuery += `WHERE ${sql.whereString} `;
+  }
+
+  if (sql.groupBy?.[0]?.property.name) {
+    const groupBy = sql.groupBy.map((g) => g.property.name).filter((g) => !isEmpty(g));
+    rawQuery += `GROUP BY ${groupBy.join(', ')} `;
+  }
+
+  if (sql.orderBy?.property.name) {
+    rawQuery += `ORDER BY ${sql.orderBy.property.name} `;
+  }
+
+  if (sql.orderBy?.property.name && sql.orderByDirection) {
+    rawQuery += `${sql.orderByDirection} `;
+  }
+
+  // Altough LIMIT 0 doesn't make sense, it is still possible to have LIMIT 0
+  if (sql.limit !== undefined && sql.limit >= 0) {
+    rawQuery += `LIMIT ${sql.limit} `;
+  }
+  return rawQuery;
+}
@@ -0,0 +1,19 @@
+import { getSchema, quoteIdentifierIfNecessary, unquoteIdentifier } from './sqliteMetaQuery';
+
+describe('getSchema', () => {
+  it('should escape single quotes in the table name', () => {
+    expect(getSchema("metric's")).toContain("pragma_table_info('metric''s')");
+  });
+
+  it('should unquote the table name', () => {
+    expect(getSchema('"my ""table"""')).toContain(`pragma_table_info('my "table"')`);
+  });
+});
+
+describe('quoteIdentifierIfNecessary', () => {
+  it('should only quote identifiers that need quotes', () => {
+    expect(quoteIdentifierIfNecessary('metric')).toBe('metric');
+    expect(quoteIdentifierIfNecessary('my table')).toBe('"my table"');
+    expect(unquoteIdentifier(quoteIdentifierIfNecessary('a"b'))).toBe('a"b');
+  });
+});
@@ -0,0 +1,24 @@
+export function showTables() {
+  return `SELECT name AS "table" FROM sqlite_master
+    WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+    ORDER BY name`;
+}
+
+export function getSchema(table: string) {
+  // we will put table-name between single-quotes, so we need to escape single-quotes
+  // in the table-name
+  const tableNamePart = "'" + unquoteIdentifier(table).replace(/'/g, "''") + "'";
+
+  return `SELECT name AS "column", type AS "type" FROM pragma_table_info(${tableNamePart})`;
+}
+
+export function quoteIdentifierIfNecessary(value: string) {
+  return /^[_a-zA-Z][_a-zA-Z0-9]*$/.test(value) ? value : '"' + value.replace(/"/g, '""') + '"';
+}
+
+export function unquoteIdentifier(value: string) {
+  if (value.length > 1 && value[0] === '"' && value[value.length - 1] === '"') {
+    return value.substring(1, value.length - 1).replace(/""/g, '"');
+  }
+  return value;
+}